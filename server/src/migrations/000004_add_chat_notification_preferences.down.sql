@@ -0,0 +1,7 @@
+-- Remove notification preferences and do-not-disturb schedules
+
+DROP TABLE IF EXISTS user_dnd_schedules;
+
+ALTER TABLE userinchat DROP CONSTRAINT IF EXISTS userinchat_notification_level_check;
+ALTER TABLE userinchat DROP COLUMN IF EXISTS muted_until;
+ALTER TABLE userinchat DROP COLUMN IF EXISTS notification_level;
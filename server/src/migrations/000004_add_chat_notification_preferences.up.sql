@@ -0,0 +1,21 @@
+-- Per-member notification preferences and per-user do-not-disturb schedule
+
+ALTER TABLE userinchat ADD COLUMN IF NOT EXISTS notification_level VARCHAR(20) NOT NULL DEFAULT 'all';
+ALTER TABLE userinchat ADD COLUMN IF NOT EXISTS muted_until TIMESTAMP;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'userinchat_notification_level_check') THEN
+    ALTER TABLE userinchat ADD CONSTRAINT userinchat_notification_level_check
+      CHECK (notification_level IN ('all', 'mentions'));
+  END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS user_dnd_schedules (
+  usersid INT4 PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  start_time TIME NOT NULL,
+  end_time TIME NOT NULL,
+  timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS chat_invite_redemptions;
+DROP TABLE IF EXISTS chat_invites;
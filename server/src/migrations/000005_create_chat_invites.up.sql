@@ -0,0 +1,28 @@
+-- Invite links for chats and audit of who joined through which link
+
+CREATE TABLE IF NOT EXISTS chat_invites (
+  id SERIAL PRIMARY KEY,
+  chatsid INT4 NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+  code VARCHAR(64) NOT NULL,
+  created_by INT4 NOT NULL REFERENCES users(id),
+  expires_at TIMESTAMP,
+  max_uses INT4 CHECK (max_uses IS NULL OR max_uses > 0),
+  uses INT4 NOT NULL DEFAULT 0,
+  revoked_at TIMESTAMP,
+  revoked_by INT4 REFERENCES users(id),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS chat_invites_code_idx ON chat_invites(code);
+CREATE INDEX IF NOT EXISTS chat_invites_chatsid_idx ON chat_invites(chatsid);
+
+CREATE TABLE IF NOT EXISTS chat_invite_redemptions (
+  id SERIAL PRIMARY KEY,
+  invite_id INT4 NOT NULL REFERENCES chat_invites(id) ON DELETE CASCADE,
+  chatsid INT4 NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+  usersid INT4 NOT NULL REFERENCES users(id),
+  redeemed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS chat_invite_redemptions_invite_idx ON chat_invite_redemptions(invite_id);
+CREATE INDEX IF NOT EXISTS chat_invite_redemptions_usersid_idx ON chat_invite_redemptions(usersid);
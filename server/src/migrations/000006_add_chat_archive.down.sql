@@ -0,0 +1,9 @@
+-- Remove chat archiving and delayed deletion
+
+DROP INDEX IF EXISTS idx_chats_delete_scheduled_at;
+DROP INDEX IF EXISTS idx_chats_archived_at;
+
+ALTER TABLE chats DROP COLUMN IF EXISTS delete_requested_by;
+ALTER TABLE chats DROP COLUMN IF EXISTS delete_scheduled_at;
+ALTER TABLE chats DROP COLUMN IF EXISTS archived_by;
+ALTER TABLE chats DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,9 @@
+-- Chat archiving (read-only mode) and delayed hard deletion
+
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS archived_by INT4 REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS delete_scheduled_at TIMESTAMP;
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS delete_requested_by INT4 REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_chats_archived_at ON chats(archived_at);
+CREATE INDEX IF NOT EXISTS idx_chats_delete_scheduled_at ON chats(delete_scheduled_at) WHERE delete_scheduled_at IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Remove message retention policies and legal hold
+
+DROP INDEX IF EXISTS idx_messages_chatsid_date;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS anonymized_at;
+
+ALTER TABLE chats DROP COLUMN IF EXISTS legal_hold_reason;
+ALTER TABLE chats DROP COLUMN IF EXISTS legal_hold;
+
+DROP TABLE IF EXISTS workspace_retention_policies;
+
+ALTER TABLE tariffs DROP CONSTRAINT IF EXISTS tariffs_retention_action_check;
+ALTER TABLE tariffs DROP CONSTRAINT IF EXISTS tariffs_message_retention_days_check;
+ALTER TABLE tariffs DROP COLUMN IF EXISTS retention_action;
+ALTER TABLE tariffs DROP COLUMN IF EXISTS message_retention_days;
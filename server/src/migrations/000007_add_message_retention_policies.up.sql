@@ -0,0 +1,33 @@
+-- Message retention: tariff defaults, per-workspace overrides and legal hold
+
+ALTER TABLE tariffs ADD COLUMN IF NOT EXISTS message_retention_days INT4;
+ALTER TABLE tariffs ADD COLUMN IF NOT EXISTS retention_action VARCHAR(20) NOT NULL DEFAULT 'delete';
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'tariffs_message_retention_days_check') THEN
+    ALTER TABLE tariffs ADD CONSTRAINT tariffs_message_retention_days_check
+      CHECK (message_retention_days IS NULL OR message_retention_days > 0);
+  END IF;
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'tariffs_retention_action_check') THEN
+    ALTER TABLE tariffs ADD CONSTRAINT tariffs_retention_action_check
+      CHECK (retention_action IN ('delete', 'anonymize'));
+  END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS workspace_retention_policies (
+  workspacesid INT4 PRIMARY KEY REFERENCES workspaces(id) ON DELETE CASCADE,
+  retention_days INT4 CHECK (retention_days IS NULL OR retention_days > 0),
+  retention_action VARCHAR(20) CHECK (retention_action IS NULL OR retention_action IN ('delete', 'anonymize')),
+  legal_hold BOOLEAN NOT NULL DEFAULT FALSE,
+  legal_hold_reason VARCHAR(500),
+  updated_by INT4,
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS legal_hold BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS legal_hold_reason VARCHAR(500);
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_messages_chatsid_date ON messages(chatsid, date);
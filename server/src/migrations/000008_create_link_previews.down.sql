@@ -0,0 +1,4 @@
+-- Remove link previews
+
+DROP TABLE IF EXISTS message_link_previews;
+DROP TABLE IF EXISTS link_previews;
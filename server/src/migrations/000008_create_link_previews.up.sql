@@ -0,0 +1,18 @@
+-- Link previews (unfurling) cache and message attachments
+
+CREATE TABLE IF NOT EXISTS link_previews (
+  url TEXT PRIMARY KEY,
+  status VARCHAR(20) NOT NULL DEFAULT 'ok' CHECK (status IN ('ok', 'failed')),
+  title VARCHAR(300),
+  description VARCHAR(1000),
+  image_url TEXT,
+  site_name VARCHAR(200),
+  fetched_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS message_link_previews (
+  messagesid INT4 PRIMARY KEY REFERENCES messages(id) ON DELETE CASCADE,
+  url TEXT NOT NULL REFERENCES link_previews(url) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_link_previews_url ON message_link_previews(url);
@@ -0,0 +1,5 @@
+-- Remove configurable task workflows
+
+DROP FUNCTION IF EXISTS task_status_name(INT4, INT4);
+DROP TABLE IF EXISTS task_status_transitions;
+DROP TABLE IF EXISTS task_statuses;
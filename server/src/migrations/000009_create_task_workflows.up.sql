@@ -0,0 +1,61 @@
+-- Configurable task workflows: per-workspace statuses, transitions and allowed roles.
+-- Rows with workspacesid IS NULL describe the default workflow that is used by
+-- workspaces without their own definition. Codes 1-5 keep the meaning of the
+-- previously hard-coded statuses, so existing tasks.status values stay valid.
+
+CREATE TABLE IF NOT EXISTS task_statuses (
+  id SERIAL PRIMARY KEY,
+  workspacesid INT4 REFERENCES workspaces(id) ON DELETE CASCADE,
+  code INT4 NOT NULL CHECK (code > 0),
+  name VARCHAR(50) NOT NULL,
+  category VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (category IN ('open', 'in_progress', 'done', 'cancelled')),
+  is_initial BOOLEAN NOT NULL DEFAULT FALSE,
+  position INT4 NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_statuses_workspace_code ON task_statuses(COALESCE(workspacesid, 0), code);
+
+CREATE TABLE IF NOT EXISTS task_status_transitions (
+  id SERIAL PRIMARY KEY,
+  workspacesid INT4 REFERENCES workspaces(id) ON DELETE CASCADE,
+  from_status INT4 NOT NULL,
+  to_status INT4 NOT NULL,
+  allowed_roles TEXT[] NOT NULL DEFAULT '{}',
+  CHECK (from_status <> to_status)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_status_transitions_workspace_pair ON task_status_transitions(COALESCE(workspacesid, 0), from_status, to_status);
+
+-- Default workflow
+INSERT INTO task_statuses (workspacesid, code, name, category, is_initial, position) VALUES
+  (NULL, 1, 'Создана', 'open', TRUE, 1),
+  (NULL, 2, 'В работе', 'in_progress', FALSE, 2),
+  (NULL, 3, 'На проверке', 'in_progress', FALSE, 3),
+  (NULL, 4, 'Завершена', 'done', FALSE, 4),
+  (NULL, 5, 'Отменена', 'cancelled', FALSE, 5)
+ON CONFLICT DO NOTHING;
+
+-- Пустой allowed_roles означает, что переход доступен любому участнику РП
+INSERT INTO task_status_transitions (workspacesid, from_status, to_status, allowed_roles) VALUES
+  (NULL, 1, 2, '{}'),
+  (NULL, 1, 5, '{creator,leader}'),
+  (NULL, 2, 1, '{}'),
+  (NULL, 2, 3, '{}'),
+  (NULL, 2, 5, '{creator,leader}'),
+  (NULL, 3, 2, '{}'),
+  (NULL, 3, 4, '{creator,leader}'),
+  (NULL, 3, 5, '{creator,leader}'),
+  (NULL, 4, 2, '{creator,leader}'),
+  (NULL, 5, 1, '{creator,leader}')
+ON CONFLICT DO NOTHING;
+
+-- Название статуса задачи с учетом workflow рабочего пространства
+CREATE OR REPLACE FUNCTION task_status_name(p_workspace INT4, p_code INT4) RETURNS VARCHAR AS $$
+  SELECT COALESCE(
+    (SELECT name FROM task_statuses WHERE workspacesid = p_workspace AND code = p_code),
+    (SELECT name FROM task_statuses
+      WHERE workspacesid IS NULL AND code = p_code
+        AND NOT EXISTS (SELECT 1 FROM task_statuses WHERE workspacesid = p_workspace)),
+    'Неизвестный статус'
+  )
+$$ LANGUAGE SQL STABLE;
@@ -0,0 +1,13 @@
+-- Remove structured task change history columns
+
+DROP INDEX IF EXISTS idx_taskchanges_task_actor;
+DROP INDEX IF EXISTS idx_taskchanges_task_field;
+DROP INDEX IF EXISTS idx_taskchanges_task_id;
+
+ALTER TABLE taskchanges DROP CONSTRAINT IF EXISTS taskchanges_source_check;
+ALTER TABLE taskchanges DROP COLUMN IF EXISTS changed_at;
+ALTER TABLE taskchanges DROP COLUMN IF EXISTS source;
+ALTER TABLE taskchanges DROP COLUMN IF EXISTS new_value;
+ALTER TABLE taskchanges DROP COLUMN IF EXISTS old_value;
+ALTER TABLE taskchanges DROP COLUMN IF EXISTS field;
+ALTER TABLE taskchanges DROP COLUMN IF EXISTS actor_id;
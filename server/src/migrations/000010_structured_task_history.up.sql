@@ -0,0 +1,55 @@
+-- Structured task change history: actor, changed field, old/new values, timestamp and source
+
+ALTER TABLE taskchanges ADD COLUMN IF NOT EXISTS actor_id INT4 REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE taskchanges ADD COLUMN IF NOT EXISTS field VARCHAR(50);
+ALTER TABLE taskchanges ADD COLUMN IF NOT EXISTS old_value TEXT;
+ALTER TABLE taskchanges ADD COLUMN IF NOT EXISTS new_value TEXT;
+ALTER TABLE taskchanges ADD COLUMN IF NOT EXISTS source VARCHAR(20);
+-- Время изменения старых записей неизвестно, поэтому для них changed_at остается NULL
+ALTER TABLE taskchanges ADD COLUMN IF NOT EXISTS changed_at TIMESTAMP;
+ALTER TABLE taskchanges ALTER COLUMN changed_at SET DEFAULT NOW();
+
+-- Перенос текстовых записей истории в структурированный вид
+UPDATE taskchanges SET field = 'created', new_value = substring(description FROM '^Задача создана: (.*)$')
+WHERE field IS NULL AND description LIKE 'Задача создана: %';
+
+UPDATE taskchanges SET field = 'task'
+WHERE field IS NULL AND description = 'Задача обновлена';
+
+-- Для статусов вместо названия сохраняется код статуса workflow по умолчанию
+UPDATE taskchanges tc SET field = 'status', new_value = COALESCE(
+  (SELECT s.code::text FROM task_statuses s
+    WHERE s.workspacesid IS NULL AND s.name = substring(tc.description FROM '^Статус изменен на: (.*)$')),
+  substring(tc.description FROM '^Статус изменен на: (.*)$'))
+WHERE tc.field IS NULL AND tc.description LIKE 'Статус изменен на: %';
+
+UPDATE taskchanges SET field = 'assignee', new_value = substring(description FROM '(\d+)$')
+WHERE field IS NULL AND description LIKE 'Добавлен исполнитель с ID: %';
+
+UPDATE taskchanges SET field = 'assignee', old_value = substring(description FROM '(\d+)$')
+WHERE field IS NULL AND description LIKE 'Удален исполнитель с ID: %';
+
+UPDATE taskchanges SET field = 'chat', new_value = substring(description FROM '(\d+)$')
+WHERE field IS NULL AND description LIKE 'Прикреплена к чату ID: %';
+
+UPDATE taskchanges SET field = 'chat', old_value = substring(description FROM '(\d+)$')
+WHERE field IS NULL AND description LIKE 'Откреплена от чата ID: %';
+
+UPDATE taskchanges SET field = 'task' WHERE field IS NULL;
+UPDATE taskchanges SET source = 'legacy' WHERE source IS NULL;
+
+ALTER TABLE taskchanges ALTER COLUMN field SET NOT NULL;
+ALTER TABLE taskchanges ALTER COLUMN source SET DEFAULT 'rest';
+ALTER TABLE taskchanges ALTER COLUMN source SET NOT NULL;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'taskchanges_source_check') THEN
+    ALTER TABLE taskchanges ADD CONSTRAINT taskchanges_source_check
+      CHECK (source IN ('rest', 'chat', 'automation', 'legacy'));
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_taskchanges_task_id ON taskchanges(tasksid, id DESC);
+CREATE INDEX IF NOT EXISTS idx_taskchanges_task_field ON taskchanges(tasksid, field);
+CREATE INDEX IF NOT EXISTS idx_taskchanges_task_actor ON taskchanges(tasksid, actor_id);
@@ -0,0 +1,13 @@
+-- Remove task list indexes (the pg_trgm extension is left installed)
+
+DROP INDEX IF EXISTS idx_tasks_description_trgm;
+DROP INDEX IF EXISTS idx_tasks_title_trgm;
+DROP INDEX IF EXISTS idx_taskinchat_task;
+DROP INDEX IF EXISTS idx_taskinchat_chat_task;
+DROP INDEX IF EXISTS idx_userintask_task;
+DROP INDEX IF EXISTS idx_userintask_user_task;
+DROP INDEX IF EXISTS idx_tasks_workspace_creator;
+DROP INDEX IF EXISTS idx_tasks_workspace_id;
+DROP INDEX IF EXISTS idx_tasks_workspace_status_id;
+DROP INDEX IF EXISTS idx_tasks_workspace_title_id;
+DROP INDEX IF EXISTS idx_tasks_workspace_date_id;
@@ -0,0 +1,18 @@
+-- Indexes for task list filtering, sorting and keyset pagination
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_workspace_date_id ON tasks(workspacesid, date, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_workspace_title_id ON tasks(workspacesid, title, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_workspace_status_id ON tasks(workspacesid, status, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_workspace_id ON tasks(workspacesid, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_workspace_creator ON tasks(workspacesid, creator);
+
+CREATE INDEX IF NOT EXISTS idx_userintask_user_task ON userintask(usersid, tasksid);
+CREATE INDEX IF NOT EXISTS idx_userintask_task ON userintask(tasksid);
+CREATE INDEX IF NOT EXISTS idx_taskinchat_chat_task ON taskinchat(chatsid, tasksid);
+CREATE INDEX IF NOT EXISTS idx_taskinchat_task ON taskinchat(tasksid);
+
+-- Поиск по подстроке (ILIKE) в названии и описании
+CREATE INDEX IF NOT EXISTS idx_tasks_title_trgm ON tasks USING gin (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_tasks_description_trgm ON tasks USING gin (description gin_trgm_ops);
@@ -0,0 +1,4 @@
+-- Remove task comments
+
+DROP TABLE IF EXISTS task_comment_mentions;
+DROP TABLE IF EXISTS task_comments;
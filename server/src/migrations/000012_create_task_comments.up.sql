@@ -0,0 +1,20 @@
+-- Task comments with @mentions of workspace members
+
+CREATE TABLE IF NOT EXISTS task_comments (
+  id SERIAL PRIMARY KEY,
+  tasksid INT4 NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  author_id INT4 REFERENCES users(id) ON DELETE SET NULL,
+  text VARCHAR(4000) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comments_task_id ON task_comments(tasksid, id);
+
+CREATE TABLE IF NOT EXISTS task_comment_mentions (
+  commentsid INT4 NOT NULL REFERENCES task_comments(id) ON DELETE CASCADE,
+  usersid INT4 NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  PRIMARY KEY (commentsid, usersid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comment_mentions_user ON task_comment_mentions(usersid);
@@ -0,0 +1,6 @@
+-- Remove subtasks and task dependencies
+
+DROP FUNCTION IF EXISTS task_status_category(INT4, INT4);
+DROP TABLE IF EXISTS task_dependencies;
+DROP INDEX IF EXISTS idx_tasks_parent_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_id;
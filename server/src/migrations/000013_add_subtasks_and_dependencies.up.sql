@@ -0,0 +1,27 @@
+-- Subtasks (parent/child) and "blocks / blocked by" dependencies between tasks
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS parent_id INT4 REFERENCES tasks(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks(parent_id) WHERE parent_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+  blocker_id INT4 NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  blocked_id INT4 NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  created_by INT4 REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (blocker_id, blocked_id),
+  CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocked ON task_dependencies(blocked_id);
+
+-- Категория статуса задачи с учетом workflow рабочего пространства
+CREATE OR REPLACE FUNCTION task_status_category(p_workspace INT4, p_code INT4) RETURNS VARCHAR AS $$
+  SELECT COALESCE(
+    (SELECT category FROM task_statuses WHERE workspacesid = p_workspace AND code = p_code),
+    (SELECT category FROM task_statuses
+      WHERE workspacesid IS NULL AND code = p_code
+        AND NOT EXISTS (SELECT 1 FROM task_statuses WHERE workspacesid = p_workspace)),
+    'open'
+  )
+$$ LANGUAGE SQL STABLE;
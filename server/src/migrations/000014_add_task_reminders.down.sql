@@ -0,0 +1,5 @@
+-- Remove due-date reminders
+
+DROP INDEX IF EXISTS idx_tasks_date;
+DROP TABLE IF EXISTS task_reminders_sent;
+DROP TABLE IF EXISTS workspace_task_reminder_settings;
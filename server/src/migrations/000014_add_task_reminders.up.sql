@@ -0,0 +1,30 @@
+-- Due-date reminders: per-workspace reminder offsets and log of sent reminders
+
+CREATE TABLE IF NOT EXISTS workspace_task_reminder_settings (
+  workspacesid INT4 PRIMARY KEY REFERENCES workspaces(id) ON DELETE CASCADE,
+  offset_days INT4[] NOT NULL DEFAULT '{}',
+  notify_overdue BOOLEAN NOT NULL DEFAULT TRUE,
+  updated_by INT4 REFERENCES users(id) ON DELETE SET NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS task_reminders_sent (
+  tasksid INT4 NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  usersid INT4 NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  kind VARCHAR(20) NOT NULL,
+  offset_days INT4 NOT NULL DEFAULT 0,
+  due_date DATE NOT NULL,
+  sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (tasksid, usersid, kind, offset_days, due_date)
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'task_reminders_sent_kind_check') THEN
+    ALTER TABLE task_reminders_sent ADD CONSTRAINT task_reminders_sent_kind_check
+      CHECK (kind IN ('due', 'overdue'));
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_task_reminders_sent_at ON task_reminders_sent(sent_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_date ON tasks(date);
@@ -0,0 +1,29 @@
+-- Recurring tasks: series with a recurrence rule and the tasks generated from it
+
+CREATE TABLE IF NOT EXISTS task_series (
+  id SERIAL PRIMARY KEY,
+  workspacesid INT4 NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  creator INT4 REFERENCES users(id) ON DELETE SET NULL,
+  rule VARCHAR(200) NOT NULL,
+  mode VARCHAR(20) NOT NULL DEFAULT 'on_complete',
+  start_date DATE NOT NULL,
+  occurrences INT4 NOT NULL DEFAULT 1,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP,
+  cancelled_at TIMESTAMP
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'task_series_mode_check') THEN
+    ALTER TABLE task_series ADD CONSTRAINT task_series_mode_check
+      CHECK (mode IN ('on_complete', 'schedule'));
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_task_series_active ON task_series(mode) WHERE active;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS series_id INT4 REFERENCES task_series(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_series_id ON tasks(series_id, date) WHERE series_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Remove Kanban board positions
+
+DROP INDEX IF EXISTS idx_tasks_board_position;
+ALTER TABLE tasks DROP COLUMN IF EXISTS board_position;
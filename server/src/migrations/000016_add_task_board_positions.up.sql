@@ -0,0 +1,14 @@
+-- Kanban board: position of a task inside its status column
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS board_position INT4;
+
+-- Existing tasks keep the order they were created in
+UPDATE tasks t
+SET board_position = ranked.position
+FROM (
+  SELECT id, ROW_NUMBER() OVER (PARTITION BY workspacesid, status ORDER BY date, id) AS position
+  FROM tasks
+) ranked
+WHERE t.id = ranked.id AND t.board_position IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_board_position ON tasks(workspacesid, status, board_position);
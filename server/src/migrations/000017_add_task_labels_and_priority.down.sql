@@ -0,0 +1,7 @@
+-- Remove task labels and priority
+
+DROP TABLE IF EXISTS task_label_links;
+DROP TABLE IF EXISTS task_labels;
+DROP INDEX IF EXISTS idx_tasks_priority;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_priority_check;
+ALTER TABLE tasks DROP COLUMN IF EXISTS priority;
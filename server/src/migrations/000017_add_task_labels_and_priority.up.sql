@@ -0,0 +1,41 @@
+-- Task priority and workspace-scoped labels
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority VARCHAR(10) NOT NULL DEFAULT 'normal';
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'tasks_priority_check') THEN
+    ALTER TABLE tasks ADD CONSTRAINT tasks_priority_check
+      CHECK (priority IN ('low', 'normal', 'high', 'critical'));
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_priority ON tasks(workspacesid, priority);
+
+CREATE TABLE IF NOT EXISTS task_labels (
+  id SERIAL PRIMARY KEY,
+  workspacesid INT4 NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  name VARCHAR(50) NOT NULL,
+  color VARCHAR(7) NOT NULL,
+  created_by INT4 REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'task_labels_color_check') THEN
+    ALTER TABLE task_labels ADD CONSTRAINT task_labels_color_check
+      CHECK (color ~ '^#[0-9a-f]{6}$');
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_labels_workspace_name ON task_labels(workspacesid, LOWER(name));
+
+CREATE TABLE IF NOT EXISTS task_label_links (
+  tasksid INT4 NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  labelsid INT4 NOT NULL REFERENCES task_labels(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (tasksid, labelsid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_label_links_label ON task_label_links(labelsid);
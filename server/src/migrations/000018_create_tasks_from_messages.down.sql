@@ -0,0 +1,7 @@
+-- Remove tasks created from chat messages and system messages
+
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_kind_check;
+ALTER TABLE messages DROP COLUMN IF EXISTS tasksid;
+ALTER TABLE messages DROP COLUMN IF EXISTS kind;
+DROP INDEX IF EXISTS idx_tasks_source_message_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS source_message_id;
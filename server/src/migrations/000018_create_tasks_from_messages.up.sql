@@ -0,0 +1,16 @@
+-- Tasks created from chat messages and system messages in chats
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS source_message_id INT4 REFERENCES messages(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_source_message_id ON tasks(source_message_id) WHERE source_message_id IS NOT NULL;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'text';
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS tasksid INT4 REFERENCES tasks(id) ON DELETE SET NULL;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'messages_kind_check') THEN
+    ALTER TABLE messages ADD CONSTRAINT messages_kind_check
+      CHECK (kind IN ('text', 'system'));
+  END IF;
+END $$;
@@ -0,0 +1,5 @@
+-- Remove task estimates and time log entries
+
+DROP TABLE IF EXISTS task_time_entries;
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_estimate_minutes_check;
+ALTER TABLE tasks DROP COLUMN IF EXISTS estimate_minutes;
@@ -0,0 +1,34 @@
+-- Task estimates and time log entries
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS estimate_minutes INT4;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'tasks_estimate_minutes_check') THEN
+    ALTER TABLE tasks ADD CONSTRAINT tasks_estimate_minutes_check
+      CHECK (estimate_minutes IS NULL OR estimate_minutes > 0);
+  END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS task_time_entries (
+  id SERIAL PRIMARY KEY,
+  tasksid INT4 NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  usersid INT4 NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  started_at TIMESTAMP NOT NULL,
+  ended_at TIMESTAMP,
+  note TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'task_time_entries_period_check') THEN
+    ALTER TABLE task_time_entries ADD CONSTRAINT task_time_entries_period_check
+      CHECK (ended_at IS NULL OR ended_at >= started_at);
+  END IF;
+END $$;
+
+-- A user can have only one running timer
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_time_entries_running ON task_time_entries(usersid) WHERE ended_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_task_time_entries_task ON task_time_entries(tasksid);
+CREATE INDEX IF NOT EXISTS idx_task_time_entries_user_started ON task_time_entries(usersid, started_at);
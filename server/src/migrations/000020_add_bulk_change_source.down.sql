@@ -0,0 +1,7 @@
+-- Remove bulk change source from task change history
+
+UPDATE taskchanges SET source = 'rest' WHERE source = 'bulk';
+
+ALTER TABLE taskchanges DROP CONSTRAINT IF EXISTS taskchanges_source_check;
+ALTER TABLE taskchanges ADD CONSTRAINT taskchanges_source_check
+  CHECK (source IN ('rest', 'chat', 'automation', 'legacy'));
@@ -0,0 +1,4 @@
+-- Remove workspace task templates
+
+DROP TABLE IF EXISTS task_template_subtasks;
+DROP TABLE IF EXISTS task_templates;
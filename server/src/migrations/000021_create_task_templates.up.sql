@@ -0,0 +1,49 @@
+-- Workspace task templates with default assignees, labels and subtasks
+
+CREATE TABLE IF NOT EXISTS task_templates (
+  id SERIAL PRIMARY KEY,
+  workspacesid INT4 NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  title VARCHAR(200) NOT NULL,
+  description TEXT,
+  priority VARCHAR(10) NOT NULL DEFAULT 'normal',
+  estimate_minutes INT4,
+  assignee_ids INT4[] NOT NULL DEFAULT '{}',
+  label_ids INT4[] NOT NULL DEFAULT '{}',
+  created_by INT4 REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'task_templates_priority_check') THEN
+    ALTER TABLE task_templates ADD CONSTRAINT task_templates_priority_check
+      CHECK (priority IN ('low', 'normal', 'high', 'critical'));
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_templates_workspace_name ON task_templates(workspacesid, LOWER(name));
+
+CREATE TABLE IF NOT EXISTS task_template_subtasks (
+  id SERIAL PRIMARY KEY,
+  templatesid INT4 NOT NULL REFERENCES task_templates(id) ON DELETE CASCADE,
+  position INT4 NOT NULL,
+  title VARCHAR(200) NOT NULL,
+  description TEXT,
+  priority VARCHAR(10) NOT NULL DEFAULT 'normal',
+  estimate_minutes INT4,
+  due_offset_days INT4 NOT NULL DEFAULT 0,
+  assignee_ids INT4[] NOT NULL DEFAULT '{}',
+  label_ids INT4[] NOT NULL DEFAULT '{}'
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'task_template_subtasks_priority_check') THEN
+    ALTER TABLE task_template_subtasks ADD CONSTRAINT task_template_subtasks_priority_check
+      CHECK (priority IN ('low', 'normal', 'high', 'critical'));
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_task_template_subtasks_template ON task_template_subtasks(templatesid, position);
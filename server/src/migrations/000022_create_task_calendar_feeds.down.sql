@@ -0,0 +1,3 @@
+-- Remove task calendar feeds
+
+DROP TABLE IF EXISTS task_calendar_feeds;
@@ -0,0 +1,13 @@
+-- Token-protected iCalendar feeds with task due dates, per user and optionally per workspace
+
+CREATE TABLE IF NOT EXISTS task_calendar_feeds (
+  id SERIAL PRIMARY KEY,
+  usersid INT4 NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  workspacesid INT4 REFERENCES workspaces(id) ON DELETE CASCADE,
+  token VARCHAR(64) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  last_accessed_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_calendar_feeds_token ON task_calendar_feeds(token);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_calendar_feeds_user_scope ON task_calendar_feeds(usersid, COALESCE(workspacesid, 0));
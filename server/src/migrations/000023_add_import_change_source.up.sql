@@ -0,0 +1,5 @@
+-- Allow task change history entries written by task import
+
+ALTER TABLE taskchanges DROP CONSTRAINT IF EXISTS taskchanges_source_check;
+ALTER TABLE taskchanges ADD CONSTRAINT taskchanges_source_check
+  CHECK (source IN ('rest', 'chat', 'automation', 'bulk', 'import', 'legacy'));
@@ -0,0 +1,3 @@
+-- Remove task watchers
+
+DROP TABLE IF EXISTS task_watchers;
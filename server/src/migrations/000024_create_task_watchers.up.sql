@@ -0,0 +1,19 @@
+-- Task watchers: users following a task without being its assignees
+
+CREATE TABLE IF NOT EXISTS task_watchers (
+  tasksid INT4 NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+  usersid INT4 NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (tasksid, usersid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_watchers_user ON task_watchers(usersid);
+
+-- Creators and comment authors of existing tasks watch them, as for new tasks
+INSERT INTO task_watchers (tasksid, usersid)
+SELECT id, creator FROM tasks WHERE creator IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+INSERT INTO task_watchers (tasksid, usersid)
+SELECT DISTINCT tasksid, author_id FROM task_comments WHERE author_id IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+-- Remove task analytics indexes
+
+DROP INDEX IF EXISTS idx_taskchanges_status_history;
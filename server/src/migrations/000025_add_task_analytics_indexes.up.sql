@@ -0,0 +1,4 @@
+-- Indexes for workspace analytics built from the status history of tasks
+
+CREATE INDEX IF NOT EXISTS idx_taskchanges_status_history ON taskchanges(tasksid, changed_at)
+  WHERE field IN ('created', 'status');
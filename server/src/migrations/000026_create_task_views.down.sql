@@ -0,0 +1,3 @@
+-- Remove saved task views
+
+DROP TABLE IF EXISTS task_views;
@@ -0,0 +1,39 @@
+-- Saved task views: named filter, sort and grouping of the workspace task list
+
+CREATE TABLE IF NOT EXISTS task_views (
+  id SERIAL PRIMARY KEY,
+  workspacesid INT4 NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  owner_id INT4 NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  is_shared BOOLEAN NOT NULL DEFAULT FALSE,
+  statuses INT4[] NOT NULL DEFAULT '{}',
+  priorities TEXT[] NOT NULL DEFAULT '{}',
+  label_ids INT4[] NOT NULL DEFAULT '{}',
+  assignee_id INT4,
+  creator_id INT4,
+  chat_id INT4,
+  overdue BOOLEAN,
+  due_from DATE,
+  due_to DATE,
+  search VARCHAR(100) NOT NULL DEFAULT '',
+  sort_field VARCHAR(20) NOT NULL DEFAULT 'date',
+  sort_desc BOOLEAN NOT NULL DEFAULT TRUE,
+  group_by VARCHAR(20),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'task_views_sort_field_check') THEN
+    ALTER TABLE task_views ADD CONSTRAINT task_views_sort_field_check
+      CHECK (sort_field IN ('date', 'title', 'status', 'created'));
+  END IF;
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'task_views_group_by_check') THEN
+    ALTER TABLE task_views ADD CONSTRAINT task_views_group_by_check
+      CHECK (group_by IN ('status', 'priority', 'label'));
+  END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_views_owner_name ON task_views(workspacesid, owner_id, LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_task_views_workspace_shared ON task_views(workspacesid) WHERE is_shared;
@@ -0,0 +1,7 @@
+-- Remove version counters from tasks, chats and workspaces
+
+ALTER TABLE workspaces DROP COLUMN IF EXISTS version;
+
+ALTER TABLE chats DROP COLUMN IF EXISTS version;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
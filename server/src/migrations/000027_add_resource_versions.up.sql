@@ -0,0 +1,7 @@
+-- Add version counters to tasks, chats and workspaces for optimistic concurrency control
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version INT4 NOT NULL DEFAULT 1;
+
+ALTER TABLE chats ADD COLUMN IF NOT EXISTS version INT4 NOT NULL DEFAULT 1;
+
+ALTER TABLE workspaces ADD COLUMN IF NOT EXISTS version INT4 NOT NULL DEFAULT 1;
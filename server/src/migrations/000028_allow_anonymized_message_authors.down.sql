@@ -0,0 +1,10 @@
+-- Restore NOT NULL on messages.usersid; refuses to roll back while anonymized messages without an author exist
+
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM messages WHERE usersid IS NULL) THEN
+    RAISE EXCEPTION 'messages without an author exist (anonymized by retention); reassign or remove them before rolling back 000028';
+  END IF;
+END $$;
+
+ALTER TABLE messages ALTER COLUMN usersid SET NOT NULL;
@@ -0,0 +1,3 @@
+-- Anonymized messages lose their author: messages.usersid becomes nullable
+
+ALTER TABLE messages ALTER COLUMN usersid DROP NOT NULL;
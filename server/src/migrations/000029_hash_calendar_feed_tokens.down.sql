@@ -0,0 +1,10 @@
+-- Restore plain calendar feed tokens; raw tokens cannot be recovered from hashes, so existing feeds are removed
+
+DELETE FROM task_calendar_feeds;
+
+DROP INDEX IF EXISTS idx_task_calendar_feeds_token_hash;
+ALTER TABLE task_calendar_feeds DROP COLUMN IF EXISTS token_hash;
+
+ALTER TABLE task_calendar_feeds ADD COLUMN IF NOT EXISTS token VARCHAR(64) NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_calendar_feeds_token ON task_calendar_feeds(token);
@@ -0,0 +1,12 @@
+-- Calendar feed tokens are stored as SHA-256 hashes; existing links keep working
+
+ALTER TABLE task_calendar_feeds ADD COLUMN IF NOT EXISTS token_hash CHAR(64);
+
+UPDATE task_calendar_feeds SET token_hash = encode(sha256(convert_to(token, 'UTF8')), 'hex');
+
+ALTER TABLE task_calendar_feeds ALTER COLUMN token_hash SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_task_calendar_feeds_token;
+ALTER TABLE task_calendar_feeds DROP COLUMN IF EXISTS token;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_calendar_feeds_token_hash ON task_calendar_feeds(token_hash);
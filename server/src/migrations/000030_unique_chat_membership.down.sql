@@ -0,0 +1,3 @@
+-- Drop the unique chat membership index; removed duplicate rows are not restored
+
+DROP INDEX IF EXISTS idx_userinchat_chat_user;
@@ -0,0 +1,10 @@
+-- One membership row per user and chat: duplicates keep the highest role (then the earliest row)
+
+DELETE FROM userinchat u
+USING (
+  SELECT id, ROW_NUMBER() OVER (PARTITION BY chatsid, usersid ORDER BY role DESC, id) AS rn
+  FROM userinchat
+) ranked
+WHERE u.id = ranked.id AND ranked.rn > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_userinchat_chat_user ON userinchat(chatsid, usersid);
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -19,6 +21,17 @@ type Config struct {
 	WorkspaceServiceURL   string
 	WebSocketEnabled      bool
 	WebSocketPingInterval int
+	KafkaBrokers          []string
+	InviteBaseURL         string
+	ChatDeleteRetention   time.Duration
+	ChatPurgeInterval     time.Duration
+	RetentionInterval     time.Duration
+	RetentionBatchSize    int
+	LinkPreviewEnabled    bool
+	LinkPreviewTimeout    time.Duration
+	LinkPreviewMaxBytes   int64
+	LinkPreviewCacheTTL   time.Duration
+	LinkPreviewAllowLocal bool
 }
 
 func Load() (*Config, error) {
@@ -33,6 +46,57 @@ func Load() (*Config, error) {
 		}
 	}
 
+	kafkaBrokersStr := getEnv("KAFKA_BROKERS", "")
+	var kafkaBrokers []string
+	if kafkaBrokersStr != "" {
+		// Разделяем по запятой и убираем пробелы
+		parts := strings.Split(kafkaBrokersStr, ",")
+		for _, part := range parts {
+			trimmed := strings.TrimSpace(part)
+			if trimmed != "" {
+				kafkaBrokers = append(kafkaBrokers, trimmed)
+			}
+		}
+	}
+
+	// Срок хранения чата после запроса на удаление (в днях)
+	retentionDays := 30
+	if days, err := parseInt(getEnv("CHAT_DELETE_RETENTION_DAYS", "30")); err == nil && days >= 0 {
+		retentionDays = days
+	}
+
+	// Интервал запуска очистки чатов, срок хранения которых истек (в минутах)
+	purgeInterval := 60
+	if minutes, err := parseInt(getEnv("CHAT_PURGE_INTERVAL_MINUTES", "60")); err == nil && minutes > 0 {
+		purgeInterval = minutes
+	}
+
+	// Интервал запуска очистки сообщений по политике хранения (в минутах)
+	retentionInterval := 60
+	if minutes, err := parseInt(getEnv("RETENTION_PURGE_INTERVAL_MINUTES", "60")); err == nil && minutes > 0 {
+		retentionInterval = minutes
+	}
+
+	// Размер порции сообщений, удаляемых за один запрос
+	retentionBatchSize := 500
+	if size, err := parseInt(getEnv("RETENTION_PURGE_BATCH_SIZE", "500")); err == nil && size > 0 {
+		retentionBatchSize = size
+	}
+
+	// Превью ссылок: таймаут загрузки (сек), лимит размера страницы (КБ) и срок жизни кэша (ч)
+	previewTimeout := 5
+	if seconds, err := parseInt(getEnv("LINK_PREVIEW_TIMEOUT_SECONDS", "5")); err == nil && seconds > 0 {
+		previewTimeout = seconds
+	}
+	previewMaxKB := 512
+	if kb, err := parseInt(getEnv("LINK_PREVIEW_MAX_KB", "512")); err == nil && kb > 0 {
+		previewMaxKB = kb
+	}
+	previewCacheTTL := 24
+	if hours, err := parseInt(getEnv("LINK_PREVIEW_CACHE_TTL_HOURS", "24")); err == nil && hours > 0 {
+		previewCacheTTL = hours
+	}
+
 	return &Config{
 		Port:                  getEnv("PORT", "8084"),
 		DBHost:                getEnv("DB_HOST", "postgres"),
@@ -45,6 +109,17 @@ func Load() (*Config, error) {
 		WorkspaceServiceURL:   getEnv("WORKSPACE_SERVICE_URL", "http://localhost:8083"),
 		WebSocketEnabled:      websocketEnabled,
 		WebSocketPingInterval: pingInterval,
+		KafkaBrokers:          kafkaBrokers,
+		InviteBaseURL:         getEnv("INVITE_BASE_URL", "http://localhost:3000/invite/"),
+		ChatDeleteRetention:   time.Duration(retentionDays) * 24 * time.Hour,
+		ChatPurgeInterval:     time.Duration(purgeInterval) * time.Minute,
+		RetentionInterval:     time.Duration(retentionInterval) * time.Minute,
+		RetentionBatchSize:    retentionBatchSize,
+		LinkPreviewEnabled:    getEnv("LINK_PREVIEW_ENABLED", "true") == "true",
+		LinkPreviewTimeout:    time.Duration(previewTimeout) * time.Second,
+		LinkPreviewMaxBytes:   int64(previewMaxKB) * 1024,
+		LinkPreviewCacheTTL:   time.Duration(previewCacheTTL) * time.Hour,
+		LinkPreviewAllowLocal: getEnv("LINK_PREVIEW_ALLOW_PRIVATE", "false") == "true",
 	}, nil
 }
 
@@ -2,50 +2,144 @@ package databaseModels
 
 import (
 	"time"
+
+	"github.com/diploma/shared/dnd"
 )
 
 // Chat представляет структуру чата в БД
 type Chat struct {
-	ID          int    `db:"id"`
-	Name        string `db:"name"`
-	Type        int    `db:"type"`
-	WorkspaceID int    `db:"workspacesid"`
+	ID                int        `db:"id"`
+	Name              string     `db:"name"`
+	Type              int        `db:"type"`
+	WorkspaceID       int        `db:"workspacesid"`
+	ArchivedAt        *time.Time `db:"archived_at"`         // Чат в архиве (только чтение), если задано
+	ArchivedBy        *int       `db:"archived_by"`         // Кто архивировал чат
+	DeleteScheduledAt *time.Time `db:"delete_scheduled_at"` // Время окончательного удаления чата
+	LegalHold         bool       `db:"legal_hold"`          // Очистка и удаление чата приостановлены
+	Version           int        `db:"version"`             // Увеличивается при каждом изменении чата
+}
+
+// RetentionPolicy представляет действующую политику хранения сообщений РП
+type RetentionPolicy struct {
+	WorkspaceID   int    `db:"workspacesid"`
+	RetentionDays int    `db:"retention_days"`
+	Action        string `db:"retention_action"` // delete или anonymize
 }
 
+// Действия с сообщениями по истечении срока хранения
+const (
+	RetentionActionDelete    = "delete"
+	RetentionActionAnonymize = "anonymize"
+)
+
 // Message представляет структуру сообщения в БД
 type Message struct {
 	ID     int    `db:"id"`
 	ChatID int    `db:"chatsid"`
-	UserID int    `db:"usersid"`
+	UserID int    `db:"usersid"` // 0 у обезличенного сообщения (в базе NULL)
 	Text   string `db:"text"`
-	Date   int    `db:"date"`   // Unix timestamp
-	Status string `db:"status"` // JSON строка с информацией о прочитанности
+	Date   int    `db:"date"`    // Unix timestamp
+	Status string `db:"status"`  // JSON строка с информацией о прочитанности
+	Kind   string `db:"kind"`    // text или system
+	TaskID *int   `db:"tasksid"` // Задача, о которой сообщает системное сообщение
 }
 
+// Виды сообщений
+const (
+	MessageKindText   = "text"
+	MessageKindSystem = "system"
+)
+
+// LinkPreview представляет закэшированное превью ссылки
+type LinkPreview struct {
+	URL         string    `db:"url"`
+	Status      string    `db:"status"` // ok или failed
+	Title       string    `db:"title"`
+	Description string    `db:"description"`
+	ImageURL    string    `db:"image_url"`
+	SiteName    string    `db:"site_name"`
+	FetchedAt   time.Time `db:"fetched_at"`
+}
+
+// Статусы загрузки превью ссылки
+const (
+	LinkPreviewStatusOK     = "ok"
+	LinkPreviewStatusFailed = "failed"
+)
+
 // UserInChat представляет связь пользователя с чатом
 type UserInChat struct {
-	ID     int       `db:"id"`
-	ChatID int       `db:"chatsid"`
-	UserID int       `db:"usersid"`
-	Role   int       `db:"role"` // 1 = участник, 2 = администратор
-	Date   time.Time `db:"date"` // Дата присоединения
+	ID                int        `db:"id"`
+	ChatID            int        `db:"chatsid"`
+	UserID            int        `db:"usersid"`
+	Role              int        `db:"role"`               // 1 = участник, 2 = администратор
+	Date              time.Time  `db:"date"`               // Дата присоединения
+	NotificationLevel string     `db:"notification_level"` // all или mentions
+	MutedUntil        *time.Time `db:"muted_until"`        // Уведомления отключены до указанного времени
+}
+
+// Уровни уведомлений участника чата
+const (
+	NotificationLevelAll      = "all"      // Уведомлять обо всех сообщениях
+	NotificationLevelMentions = "mentions" // Уведомлять только об упоминаниях
+)
+
+// DNDSchedule представляет расписание режима «не беспокоить» пользователя.
+// Само расписание и его проверка — в общем модуле dnd, которым пользуется и task-service
+type DNDSchedule struct {
+	UserID int `db:"usersid"`
+	dnd.Schedule
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// ChatInvite представляет приглашение в чат
+type ChatInvite struct {
+	ID        int        `db:"id"`
+	ChatID    int        `db:"chatsid"`
+	Code      string     `db:"code"`
+	CreatedBy int        `db:"created_by"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	MaxUses   *int       `db:"max_uses"`
+	Uses      int        `db:"uses"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	RevokedBy *int       `db:"revoked_by"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// ChatInviteRedemption представляет факт присоединения к чату по приглашению
+type ChatInviteRedemption struct {
+	ID         int       `db:"id"`
+	InviteID   int       `db:"invite_id"`
+	ChatID     int       `db:"chatsid"`
+	UserID     int       `db:"usersid"`
+	UserName   string    `db:"user_name"`
+	RedeemedAt time.Time `db:"redeemed_at"`
 }
 
 // ChatTask представляет задачу, прикрепленную к чату
 type ChatTask struct {
-	ID             int    `db:"id"`
-	ChatID         int    `db:"chatsid"`
-	TaskID         int    `db:"tasksid"`
-	AttachedAt     string `db:"attached_at"`
-	Creator        int    `db:"creator"`
-	CreatorName    string `db:"creator_name"`
-	Date           string `db:"date"`
-	Description    string `db:"description"`
-	Status         int    `db:"status"`
-	StatusName     string `db:"status_name"`
-	Title          string `db:"title"`
-	WorkspaceID    int    `db:"workspace_id"`
-	WorkspaceName  string `db:"workspace_name"`
+	ID            int             `db:"id"`
+	ChatID        int             `db:"chatsid"`
+	TaskID        int             `db:"tasksid"`
+	AttachedAt    string          `db:"attached_at"`
+	Creator       int             `db:"creator"`
+	CreatorName   string          `db:"creator_name"`
+	Date          string          `db:"date"`
+	Description   string          `db:"description"`
+	Status        int             `db:"status"`
+	StatusName    string          `db:"status_name"`
+	Priority      string          `db:"priority"`
+	Labels        []ChatTaskLabel `db:"labels"`
+	Title         string          `db:"title"`
+	WorkspaceID   int             `db:"workspace_id"`
+	WorkspaceName string          `db:"workspace_name"`
+}
+
+// ChatTaskLabel метка задачи, прикрепленной к чату
+type ChatTaskLabel struct {
+	ID    int    `db:"id"`
+	Name  string `db:"name"`
+	Color string `db:"color"`
 }
 
 
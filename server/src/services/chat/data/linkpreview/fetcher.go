@@ -0,0 +1,306 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	maxRedirects      = 3
+	maxTitleLength    = 300
+	maxDescLength     = 1000
+	maxSiteNameLength = 200
+)
+
+// ErrBlockedAddress возвращается при попытке обратиться к внутреннему адресу
+var ErrBlockedAddress = errors.New("address is not allowed")
+
+// urlPattern находит http(s) ссылки в тексте сообщения
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+// blockedPrefixes — диапазоны адресов, к которым запрещено обращаться (защита от SSRF)
+var blockedPrefixes = mustParsePrefixes(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// Preview содержит метаданные страницы
+type Preview struct {
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+// Fetcher загружает страницы и извлекает из них OpenGraph/HTML метаданные
+type Fetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// dialFunc устанавливает соединение с сервером страницы
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// NewFetcher создает загрузчик превью.
+// allowPrivate разрешает обращения к внутренним адресам (только для локальной разработки и тестов)
+func NewFetcher(timeout time.Duration, maxBytes int64, allowPrivate bool) *Fetcher {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = blockPrivateAddresses
+	}
+	return newFetcher(timeout, maxBytes, dialer.DialContext)
+}
+
+// newFetcher создает загрузчик превью с заданной функцией установки соединения
+func newFetcher(timeout time.Duration, maxBytes int64, dial dialFunc) *Fetcher {
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dial,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &Fetcher{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("too many redirects")
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("unsupported redirect scheme: %s", req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+		maxBytes: maxBytes,
+	}
+}
+
+// ExtractURL возвращает первую http(s) ссылку из текста или пустую строку
+func ExtractURL(text string) string {
+	match := urlPattern.FindString(text)
+	return strings.TrimRight(match, ".,!?;:)]}")
+}
+
+// Fetch загружает страницу и возвращает ее превью
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme: %s", parsed.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "MessengerLinkPreview/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch url: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("unsupported content type: %s", mediaType)
+	}
+
+	preview := parseHTML(io.LimitReader(resp.Body, f.maxBytes), resp.Request.URL)
+	preview.URL = rawURL
+	if preview.Title == "" && preview.Description == "" {
+		return nil, fmt.Errorf("no metadata found")
+	}
+
+	return preview, nil
+}
+
+// parseHTML извлекает метаданные из заголовка HTML документа
+func parseHTML(body io.Reader, pageURL *url.URL) *Preview {
+	preview := &Preview{}
+	var htmlTitle, metaDescription string
+
+	tokenizer := html.NewTokenizer(body)
+	inTitle := false
+parse:
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch token.Data {
+			case "title":
+				inTitle = true
+			case "body":
+				// Метаданные находятся в <head>, дальше читать не нужно
+				break parse
+			case "meta":
+				key, content := metaAttributes(token)
+				switch key {
+				case "og:title":
+					preview.Title = content
+				case "og:description":
+					preview.Description = content
+				case "og:image", "og:image:url":
+					if preview.ImageURL == "" {
+						preview.ImageURL = resolveURL(pageURL, content)
+					}
+				case "og:site_name":
+					preview.SiteName = content
+				case "description":
+					metaDescription = content
+				}
+			}
+		case html.TextToken:
+			if inTitle && htmlTitle == "" {
+				htmlTitle = strings.TrimSpace(token.Data)
+			}
+		case html.EndTagToken:
+			switch token.Data {
+			case "title":
+				inTitle = false
+			case "head":
+				break parse
+			}
+		}
+	}
+
+	if preview.Title == "" {
+		preview.Title = htmlTitle
+	}
+	if preview.Description == "" {
+		preview.Description = metaDescription
+	}
+	if preview.SiteName == "" && pageURL != nil {
+		preview.SiteName = pageURL.Hostname()
+	}
+
+	preview.Title = truncate(preview.Title, maxTitleLength)
+	preview.Description = truncate(preview.Description, maxDescLength)
+	preview.SiteName = truncate(preview.SiteName, maxSiteNameLength)
+
+	return preview
+}
+
+// metaAttributes возвращает имя (property или name) и содержимое meta-тега
+func metaAttributes(token html.Token) (string, string) {
+	var key, content string
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(attr.Val))
+			}
+		case "content":
+			content = strings.TrimSpace(attr.Val)
+		}
+	}
+	return key, content
+}
+
+// resolveURL преобразует относительную ссылку в абсолютную; допускаются только http(s) ссылки
+func resolveURL(base *url.URL, ref string) string {
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ""
+	}
+	return parsed.String()
+}
+
+// truncate обрезает строку до limit символов
+func truncate(value string, limit int) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:limit-1]) + "…"
+}
+
+// blockPrivateAddresses запрещает соединения с внутренними адресами.
+// Адрес проверяется уже после DNS-резолва, чтобы нельзя было обойти защиту через DNS
+func blockPrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if isBlockedHost(host) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// isBlockedHost проверяет, относится ли IP адрес к запрещенным диапазонам
+func isBlockedHost(host string) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return true
+	}
+	addr = addr.Unmap()
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParsePrefixes(values ...string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		prefixes = append(prefixes, netip.MustParsePrefix(value))
+	}
+	return prefixes
+}
@@ -0,0 +1,255 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMaxBytes = 64 * 1024
+
+// newPageServer поднимает сервер, отдающий страницу с заданным типом содержимого
+func newPageServer(t *testing.T, contentType, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// resolveTo имитирует DNS: любое имя хоста разрешается в address.
+// Проверка внутренних адресов выполняется так же, как в NewFetcher
+func resolveTo(address string, allowPrivate bool) dialFunc {
+	dialer := &net.Dialer{Timeout: time.Second}
+	if !allowPrivate {
+		dialer.Control = blockPrivateAddresses
+	}
+	return func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+}
+
+func TestFetchOpenGraph(t *testing.T) {
+	server := newPageServer(t, "text/html; charset=utf-8", `<html><head>
+		<title>HTML title</title>
+		<meta property="og:title" content="OG title">
+		<meta property="og:description" content="OG description">
+		<meta property="og:image" content="/images/cover.png">
+		<meta property="og:site_name" content="Example">
+		<meta name="description" content="Meta description">
+	</head><body>Hello</body></html>`)
+
+	preview, err := NewFetcher(time.Second, testMaxBytes, true).Fetch(context.Background(), server.URL+"/article")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if preview.URL != server.URL+"/article" {
+		t.Errorf("URL = %q, want %q", preview.URL, server.URL+"/article")
+	}
+	if preview.Title != "OG title" {
+		t.Errorf("Title = %q, want %q", preview.Title, "OG title")
+	}
+	if preview.Description != "OG description" {
+		t.Errorf("Description = %q, want %q", preview.Description, "OG description")
+	}
+	if preview.ImageURL != server.URL+"/images/cover.png" {
+		t.Errorf("ImageURL = %q, want %q", preview.ImageURL, server.URL+"/images/cover.png")
+	}
+	if preview.SiteName != "Example" {
+		t.Errorf("SiteName = %q, want %q", preview.SiteName, "Example")
+	}
+}
+
+func TestFetchFallsBackToTitleTag(t *testing.T) {
+	server := newPageServer(t, "text/html", `<html><head>
+		<title>  Plain   page  </title>
+		<meta name="description" content="Meta description">
+	</head><body><meta property="og:title" content="Ignored"></body></html>`)
+
+	preview, err := NewFetcher(time.Second, testMaxBytes, true).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if preview.Title != "Plain page" {
+		t.Errorf("Title = %q, want %q", preview.Title, "Plain page")
+	}
+	if preview.Description != "Meta description" {
+		t.Errorf("Description = %q, want %q", preview.Description, "Meta description")
+	}
+	if preview.SiteName != "127.0.0.1" {
+		t.Errorf("SiteName = %q, want host name %q", preview.SiteName, "127.0.0.1")
+	}
+}
+
+func TestFetchTruncatesOversizedBody(t *testing.T) {
+	const maxBytes = 1024
+	body := `<html><head><title>Early title</title>` +
+		`<meta name="keywords" content="` + strings.Repeat("x", 4*maxBytes) + `">` +
+		`<meta property="og:title" content="Beyond the limit">` +
+		`</head><body></body></html>`
+	server := newPageServer(t, "text/html", body)
+
+	preview, err := NewFetcher(time.Second, maxBytes, true).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if preview.Title != "Early title" {
+		t.Errorf("Title = %q, want %q: metadata after the size limit must be ignored", preview.Title, "Early title")
+	}
+}
+
+func TestFetchTruncatesLongFields(t *testing.T) {
+	server := newPageServer(t, "text/html", `<html><head>
+		<meta property="og:title" content="`+strings.Repeat("а", maxTitleLength+50)+`">
+	</head></html>`)
+
+	preview, err := NewFetcher(time.Second, testMaxBytes, true).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	runes := []rune(preview.Title)
+	if len(runes) != maxTitleLength {
+		t.Fatalf("len(Title) = %d runes, want %d", len(runes), maxTitleLength)
+	}
+	if runes[len(runes)-1] != '…' {
+		t.Errorf("Title must end with an ellipsis, got %q", string(runes[len(runes)-1]))
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	start := time.Now()
+	_, err := NewFetcher(100*time.Millisecond, testMaxBytes, true).Fetch(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("Fetch() error = nil, want timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Fetch() took %v, want it to give up after the timeout", elapsed)
+	}
+}
+
+func TestFetchRejectsNonHTML(t *testing.T) {
+	server := newPageServer(t, "application/json", `{"title": "not a page"}`)
+
+	_, err := NewFetcher(time.Second, testMaxBytes, true).Fetch(context.Background(), server.URL)
+	if err == nil || !strings.Contains(err.Error(), "unsupported content type") {
+		t.Fatalf("Fetch() error = %v, want unsupported content type", err)
+	}
+}
+
+func TestFetchRejectsPageWithoutMetadata(t *testing.T) {
+	server := newPageServer(t, "text/html", `<html><head></head><body>Text</body></html>`)
+
+	_, err := NewFetcher(time.Second, testMaxBytes, true).Fetch(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("Fetch() error = nil, want no metadata error")
+	}
+}
+
+func TestFetchBlocksLoopback(t *testing.T) {
+	server := newPageServer(t, "text/html", `<html><head><title>Internal</title></head></html>`)
+
+	_, err := NewFetcher(time.Second, testMaxBytes, false).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("Fetch() error = %v, want ErrBlockedAddress", err)
+	}
+}
+
+func TestFetchBlocksHostResolvedToPrivateAddress(t *testing.T) {
+	server := newPageServer(t, "text/html", `<html><head><title>Internal</title></head></html>`)
+
+	tests := []struct {
+		name    string
+		address string
+	}{
+		{name: "loopback", address: server.Listener.Addr().String()},
+		{name: "private network", address: "10.0.0.1:80"},
+		{name: "link-local metadata", address: "169.254.169.254:80"},
+		{name: "IPv6 unique local", address: "[fd00::1]:80"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := newFetcher(time.Second, testMaxBytes, resolveTo(tt.address, false))
+
+			_, err := fetcher.Fetch(context.Background(), "http://preview.example.com/")
+			if !errors.Is(err, ErrBlockedAddress) {
+				t.Fatalf("Fetch() error = %v, want ErrBlockedAddress", err)
+			}
+		})
+	}
+}
+
+func TestFetchAllowsResolvedHostWhenPrivateAllowed(t *testing.T) {
+	server := newPageServer(t, "text/html", `<html><head><title>Resolved</title></head></html>`)
+	fetcher := newFetcher(time.Second, testMaxBytes, resolveTo(server.Listener.Addr().String(), true))
+
+	preview, err := fetcher.Fetch(context.Background(), "http://preview.example.com/")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if preview.SiteName != "preview.example.com" {
+		t.Errorf("SiteName = %q, want %q", preview.SiteName, "preview.example.com")
+	}
+}
+
+func TestIsBlockedHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		blocked bool
+	}{
+		{host: "127.0.0.1", blocked: true},
+		{host: "10.1.2.3", blocked: true},
+		{host: "172.16.0.1", blocked: true},
+		{host: "192.168.1.1", blocked: true},
+		{host: "169.254.169.254", blocked: true},
+		{host: "::1", blocked: true},
+		{host: "::ffff:127.0.0.1", blocked: true},
+		{host: "fe80::1", blocked: true},
+		{host: "not-an-ip", blocked: true},
+		{host: "93.184.216.34", blocked: false},
+		{host: "2606:4700:4700::1111", blocked: false},
+	}
+
+	for _, tt := range tests {
+		if got := isBlockedHost(tt.host); got != tt.blocked {
+			t.Errorf("isBlockedHost(%q) = %v, want %v", tt.host, got, tt.blocked)
+		}
+	}
+}
+
+func TestExtractURL(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "see https://example.com/page.", want: "https://example.com/page"},
+		{text: "(http://example.com/a?b=1)", want: "http://example.com/a?b=1"},
+		{text: "no links here", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := ExtractURL(tt.text); got != tt.want {
+			t.Errorf("ExtractURL(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -3,13 +3,16 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/diploma/chat-service/data/database"
 	"github.com/diploma/chat-service/data/databaseModels"
+	"github.com/diploma/shared/dnd"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type Repository struct {
@@ -48,7 +51,7 @@ func (r *Repository) CreateChat(ctx context.Context, name string, chatType int,
 // GetChatByID получает чат по ID
 func (r *Repository) GetChatByID(ctx context.Context, chatID int) (*databaseModels.Chat, error) {
 	query := `
-		SELECT id, name, type, workspacesid
+		SELECT id, name, type, workspacesid, archived_at, archived_by, delete_scheduled_at, legal_hold, version
 		FROM chats
 		WHERE id = $1
 	`
@@ -59,6 +62,11 @@ func (r *Repository) GetChatByID(ctx context.Context, chatID int) (*databaseMode
 		&chat.Name,
 		&chat.Type,
 		&chat.WorkspaceID,
+		&chat.ArchivedAt,
+		&chat.ArchivedBy,
+		&chat.DeleteScheduledAt,
+		&chat.LegalHold,
+		&chat.Version,
 	)
 
 	if err != nil {
@@ -71,26 +79,31 @@ func (r *Repository) GetChatByID(ctx context.Context, chatID int) (*databaseMode
 	return &chat, nil
 }
 
-// UpdateChat обновляет настройки чата
-func (r *Repository) UpdateChat(ctx context.Context, chatID int, name string) (*databaseModels.Chat, error) {
+// UpdateChat обновляет настройки чата версии version.
+// Если чат успел измениться, возвращает ошибку "chat version changed"
+func (r *Repository) UpdateChat(ctx context.Context, chatID, version int, name string) (*databaseModels.Chat, error) {
 	query := `
 		UPDATE chats
-		SET name = $1
-		WHERE id = $2
-		RETURNING id, name, type, workspacesid
+		SET name = $1, version = version + 1
+		WHERE id = $2 AND ($3::INT4 = 0 OR version = $3::INT4)
+		RETURNING id, name, type, workspacesid, version
 	`
 
 	var chat databaseModels.Chat
-	err := r.db.Pool.QueryRow(ctx, query, name, chatID).Scan(
+	err := r.db.Pool.QueryRow(ctx, query, name, chatID, version).Scan(
 		&chat.ID,
 		&chat.Name,
 		&chat.Type,
 		&chat.WorkspaceID,
+		&chat.Version,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("chat not found")
+			if version == 0 {
+				return nil, fmt.Errorf("chat not found")
+			}
+			return nil, fmt.Errorf("chat version changed")
 		}
 		return nil, fmt.Errorf("failed to update chat: %w", err)
 	}
@@ -114,44 +127,152 @@ func (r *Repository) DeleteChat(ctx context.Context, chatID int) error {
 	return nil
 }
 
-// GetUserChats получает список чатов пользователя
-func (r *Repository) GetUserChats(ctx context.Context, userID int, workspaceID *int, chatType *int) ([]databaseModels.Chat, error) {
+// ArchiveChat переводит чат в архив (режим только для чтения)
+func (r *Repository) ArchiveChat(ctx context.Context, chatID, userID int) error {
+	query := `
+		UPDATE chats
+		SET archived_at = NOW(), archived_by = $2, version = version + 1
+		WHERE id = $1 AND archived_at IS NULL
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, chatID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to archive chat: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("chat not found or already archived")
+	}
+
+	return nil
+}
+
+// RestoreChat возвращает чат из архива и отменяет запланированное удаление
+func (r *Repository) RestoreChat(ctx context.Context, chatID int) error {
+	query := `
+		UPDATE chats
+		SET archived_at = NULL, archived_by = NULL, delete_scheduled_at = NULL, delete_requested_by = NULL,
+		    version = version + 1
+		WHERE id = $1 AND archived_at IS NOT NULL
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to restore chat: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("chat not found or not archived")
+	}
+
+	return nil
+}
+
+// ScheduleChatDeletion архивирует чат и планирует его окончательное удаление
+func (r *Repository) ScheduleChatDeletion(ctx context.Context, chatID, userID int, deleteAt time.Time) error {
+	query := `
+		UPDATE chats
+		SET archived_at = COALESCE(archived_at, NOW()),
+		    archived_by = COALESCE(archived_by, $2),
+		    delete_scheduled_at = $3,
+		    delete_requested_by = $2,
+		    version = version + 1
+		WHERE id = $1
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, chatID, userID, deleteAt)
+	if err != nil {
+		return fmt.Errorf("failed to schedule chat deletion: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("chat not found")
+	}
+
+	return nil
+}
+
+// PurgeScheduledChats окончательно удаляет чаты, срок хранения которых истек.
+// Чаты под legal hold (своим или РП) не удаляются
+func (r *Repository) PurgeScheduledChats(ctx context.Context) (int, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT c.id
+		FROM chats c
+		LEFT JOIN workspace_retention_policies p ON p.workspacesid = c.workspacesid
+		WHERE c.delete_scheduled_at IS NOT NULL AND c.delete_scheduled_at <= NOW()
+		  AND c.legal_hold = FALSE AND COALESCE(p.legal_hold, FALSE) = FALSE
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get chats scheduled for deletion: %w", err)
+	}
+
+	var chatIDs []int
+	for rows.Next() {
+		var chatID int
+		if err := rows.Scan(&chatID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan chat id: %w", err)
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+	rows.Close()
+
+	purged := 0
+	for _, chatID := range chatIDs {
+		if err := r.DeleteChat(ctx, chatID); err != nil {
+			log.Printf("PurgeScheduledChats: failed to delete chat %d: %v", chatID, err)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// GetUserChats получает список чатов пользователя.
+// archived = false возвращает активные чаты, archived = true — только архивные
+func (r *Repository) GetUserChats(ctx context.Context, userID int, workspaceID *int, chatType *int, archived bool) ([]databaseModels.Chat, error) {
 	var query string
 	var args []interface{}
 
+	archivedFilter := "c.archived_at IS NULL"
+	if archived {
+		archivedFilter = "c.archived_at IS NOT NULL"
+	}
+
 	if workspaceID != nil && chatType != nil {
 		query = `
-			SELECT DISTINCT c.id, c.name, c.type, c.workspacesid
+			SELECT DISTINCT c.id, c.name, c.type, c.workspacesid, c.archived_at, c.archived_by, c.delete_scheduled_at, c.legal_hold
 			FROM chats c
 			INNER JOIN "userinchat" uic ON c.id = uic.chatsid
-			WHERE uic.usersid = $1 AND c.workspacesid = $2 AND c.type = $3
+			WHERE uic.usersid = $1 AND c.workspacesid = $2 AND c.type = $3 AND ` + archivedFilter + `
 			ORDER BY c.id
 		`
 		args = []interface{}{userID, *workspaceID, *chatType}
 	} else if workspaceID != nil {
 		query = `
-			SELECT DISTINCT c.id, c.name, c.type, c.workspacesid
+			SELECT DISTINCT c.id, c.name, c.type, c.workspacesid, c.archived_at, c.archived_by, c.delete_scheduled_at, c.legal_hold
 			FROM chats c
 			INNER JOIN "userinchat" uic ON c.id = uic.chatsid
-			WHERE uic.usersid = $1 AND c.workspacesid = $2
+			WHERE uic.usersid = $1 AND c.workspacesid = $2 AND ` + archivedFilter + `
 			ORDER BY c.id
 		`
 		args = []interface{}{userID, *workspaceID}
 	} else if chatType != nil {
 		query = `
-			SELECT DISTINCT c.id, c.name, c.type, c.workspacesid
+			SELECT DISTINCT c.id, c.name, c.type, c.workspacesid, c.archived_at, c.archived_by, c.delete_scheduled_at, c.legal_hold
 			FROM chats c
 			INNER JOIN "userinchat" uic ON c.id = uic.chatsid
-			WHERE uic.usersid = $1 AND c.type = $2
+			WHERE uic.usersid = $1 AND c.type = $2 AND ` + archivedFilter + `
 			ORDER BY c.id
 		`
 		args = []interface{}{userID, *chatType}
 	} else {
 		query = `
-			SELECT DISTINCT c.id, c.name, c.type, c.workspacesid
+			SELECT DISTINCT c.id, c.name, c.type, c.workspacesid, c.archived_at, c.archived_by, c.delete_scheduled_at, c.legal_hold
 			FROM chats c
 			INNER JOIN "userinchat" uic ON c.id = uic.chatsid
-			WHERE uic.usersid = $1
+			WHERE uic.usersid = $1 AND ` + archivedFilter + `
 			ORDER BY c.id
 		`
 		args = []interface{}{userID}
@@ -171,6 +292,10 @@ func (r *Repository) GetUserChats(ctx context.Context, userID int, workspaceID *
 			&chat.Name,
 			&chat.Type,
 			&chat.WorkspaceID,
+			&chat.ArchivedAt,
+			&chat.ArchivedBy,
+			&chat.DeleteScheduledAt,
+			&chat.LegalHold,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan chat: %w", err)
@@ -183,20 +308,32 @@ func (r *Repository) GetUserChats(ctx context.Context, userID int, workspaceID *
 
 // UserInChat operations
 
-// AddUserToChat добавляет пользователя в чат
+// execer — пул соединений или транзакция, в которых выполняется запрос
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// AddUserToChat добавляет пользователя в чат. Если пользователь уже участник, ничего не меняется
 func (r *Repository) AddUserToChat(ctx context.Context, chatID, userID, role int) error {
+	_, err := addUserToChat(ctx, r.db.Pool, chatID, userID, role)
+	return err
+}
+
+// addUserToChat добавляет пользователя в чат через db и сообщает, была ли добавлена новая запись.
+// Уникальный индекс (chatsid, usersid) не дает добавить участника повторно, в том числе параллельно
+func addUserToChat(ctx context.Context, db execer, chatID, userID, role int) (bool, error) {
 	query := `
 		INSERT INTO "userinchat" (chatsid, usersid, role, date)
 		VALUES ($1, $2, $3, CURRENT_DATE)
-		ON CONFLICT DO NOTHING
+		ON CONFLICT (chatsid, usersid) DO NOTHING
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query, chatID, userID, role)
+	result, err := db.Exec(ctx, query, chatID, userID, role)
 	if err != nil {
-		return fmt.Errorf("failed to add user to chat: %w", err)
+		return false, fmt.Errorf("failed to add user to chat: %w", err)
 	}
 
-	return nil
+	return result.RowsAffected() > 0, nil
 }
 
 // RemoveUserFromChat удаляет пользователя из чата
@@ -365,6 +502,23 @@ func (r *Repository) IsUserInWorkspace(ctx context.Context, userID, workspaceID
 	return isMember, nil
 }
 
+// IsWorkspaceLeader проверяет, является ли пользователь руководителем рабочего пространства
+func (r *Repository) IsWorkspaceLeader(ctx context.Context, userID, workspaceID int) (bool, error) {
+	query := `
+		SELECT COUNT(*) > 0
+		FROM "userinworkspace"
+		WHERE usersid = $1 AND workspacesid = $2 AND role = 2
+	`
+
+	var isLeader bool
+	err := r.db.Pool.QueryRow(ctx, query, userID, workspaceID).Scan(&isLeader)
+	if err != nil {
+		return false, fmt.Errorf("failed to check workspace leader: %w", err)
+	}
+
+	return isLeader, nil
+}
+
 // WorkspaceExists проверяет наличие рабочего пространства
 func (r *Repository) WorkspaceExists(ctx context.Context, workspaceID int) (bool, error) {
 	query := `
@@ -381,6 +535,586 @@ func (r *Repository) WorkspaceExists(ctx context.Context, workspaceID int) (bool
 	return exists, nil
 }
 
+// Notification settings operations
+
+// GetMemberNotificationSettings получает настройки уведомлений участника чата
+func (r *Repository) GetMemberNotificationSettings(ctx context.Context, chatID, userID int) (*databaseModels.UserInChat, error) {
+	query := `
+		SELECT id, chatsid, usersid, role, date, notification_level, muted_until
+		FROM "userinchat"
+		WHERE chatsid = $1 AND usersid = $2
+	`
+
+	var member databaseModels.UserInChat
+	err := r.db.Pool.QueryRow(ctx, query, chatID, userID).Scan(
+		&member.ID,
+		&member.ChatID,
+		&member.UserID,
+		&member.Role,
+		&member.Date,
+		&member.NotificationLevel,
+		&member.MutedUntil,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user not in chat")
+		}
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+
+	return &member, nil
+}
+
+// UpdateMemberNotificationSettings обновляет уровень уведомлений и время отключения уведомлений участника
+func (r *Repository) UpdateMemberNotificationSettings(ctx context.Context, chatID, userID int, level string, mutedUntil *time.Time) error {
+	query := `
+		UPDATE "userinchat"
+		SET notification_level = $1, muted_until = $2
+		WHERE chatsid = $3 AND usersid = $4
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, level, mutedUntil, chatID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update notification settings: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not in chat")
+	}
+
+	return nil
+}
+
+// GetDNDSchedule получает расписание «не беспокоить» пользователя (nil, если не задано)
+func (r *Repository) GetDNDSchedule(ctx context.Context, userID int) (*databaseModels.DNDSchedule, error) {
+	query := `
+		SELECT usersid, enabled, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), timezone, updated_at
+		FROM user_dnd_schedules
+		WHERE usersid = $1
+	`
+
+	var schedule databaseModels.DNDSchedule
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(
+		&schedule.UserID,
+		&schedule.Enabled,
+		&schedule.StartTime,
+		&schedule.EndTime,
+		&schedule.Timezone,
+		&schedule.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // Расписание не задано
+		}
+		return nil, fmt.Errorf("failed to get dnd schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+// UpsertDNDSchedule создает или обновляет расписание «не беспокоить» пользователя
+func (r *Repository) UpsertDNDSchedule(ctx context.Context, schedule *databaseModels.DNDSchedule) (*databaseModels.DNDSchedule, error) {
+	query := `
+		INSERT INTO user_dnd_schedules (usersid, enabled, start_time, end_time, timezone, updated_at)
+		VALUES ($1, $2, $3::time, $4::time, $5, NOW())
+		ON CONFLICT (usersid) DO UPDATE
+		SET enabled = EXCLUDED.enabled,
+		    start_time = EXCLUDED.start_time,
+		    end_time = EXCLUDED.end_time,
+		    timezone = EXCLUDED.timezone,
+		    updated_at = NOW()
+		RETURNING usersid, enabled, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), timezone, updated_at
+	`
+
+	var saved databaseModels.DNDSchedule
+	err := r.db.Pool.QueryRow(ctx, query,
+		schedule.UserID,
+		schedule.Enabled,
+		schedule.StartTime,
+		schedule.EndTime,
+		schedule.Timezone,
+	).Scan(
+		&saved.UserID,
+		&saved.Enabled,
+		&saved.StartTime,
+		&saved.EndTime,
+		&saved.Timezone,
+		&saved.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to save dnd schedule: %w", err)
+	}
+
+	return &saved, nil
+}
+
+// DeleteDNDSchedule удаляет расписание «не беспокоить» пользователя
+func (r *Repository) DeleteDNDSchedule(ctx context.Context, userID int) error {
+	query := `DELETE FROM user_dnd_schedules WHERE usersid = $1`
+
+	if _, err := r.db.Pool.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to delete dnd schedule: %w", err)
+	}
+
+	return nil
+}
+
+// NotificationRecipient представляет участника чата с настройками уведомлений
+type NotificationRecipient struct {
+	UserID            int
+	Login             string
+	Name              string
+	NotificationLevel string
+	MutedUntil        *time.Time
+	DND               *dnd.Schedule
+}
+
+// GetNotificationRecipients получает участников чата вместе с их настройками уведомлений
+func (r *Repository) GetNotificationRecipients(ctx context.Context, chatID int) ([]NotificationRecipient, error) {
+	query := `
+		SELECT u.id, u.login, COALESCE(u.surname || ' ' || u.name, 'Unknown'),
+		       uic.notification_level, uic.muted_until,
+		       dnd.enabled, to_char(dnd.start_time, 'HH24:MI'), to_char(dnd.end_time, 'HH24:MI'), dnd.timezone
+		FROM "userinchat" uic
+		INNER JOIN users u ON uic.usersid = u.id
+		LEFT JOIN user_dnd_schedules dnd ON dnd.usersid = u.id
+		WHERE uic.chatsid = $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []NotificationRecipient
+	for rows.Next() {
+		var recipient NotificationRecipient
+		var dndEnabled sql.NullBool
+		var dndStart, dndEnd, dndTimezone sql.NullString
+		err := rows.Scan(
+			&recipient.UserID,
+			&recipient.Login,
+			&recipient.Name,
+			&recipient.NotificationLevel,
+			&recipient.MutedUntil,
+			&dndEnabled,
+			&dndStart,
+			&dndEnd,
+			&dndTimezone,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification recipient: %w", err)
+		}
+		if dndEnabled.Valid {
+			recipient.DND = &dnd.Schedule{
+				Enabled:   dndEnabled.Bool,
+				StartTime: dndStart.String,
+				EndTime:   dndEnd.String,
+				Timezone:  dndTimezone.String,
+			}
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, nil
+}
+
+// Invite operations
+
+// inviteColumns перечисляет колонки chat_invites в порядке scanInvite
+const inviteColumns = `id, chatsid, code, created_by, expires_at, max_uses, uses, revoked_at, revoked_by, created_at`
+
+func scanInvite(row pgx.Row) (*databaseModels.ChatInvite, error) {
+	var invite databaseModels.ChatInvite
+	err := row.Scan(
+		&invite.ID,
+		&invite.ChatID,
+		&invite.Code,
+		&invite.CreatedBy,
+		&invite.ExpiresAt,
+		&invite.MaxUses,
+		&invite.Uses,
+		&invite.RevokedAt,
+		&invite.RevokedBy,
+		&invite.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// CreateInvite создает приглашение в чат
+func (r *Repository) CreateInvite(ctx context.Context, chatID, createdBy int, code string, expiresAt *time.Time, maxUses *int) (*databaseModels.ChatInvite, error) {
+	query := `
+		INSERT INTO chat_invites (chatsid, code, created_by, expires_at, max_uses)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + inviteColumns
+
+	invite, err := scanInvite(r.db.Pool.QueryRow(ctx, query, chatID, code, createdBy, expiresAt, maxUses))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	return invite, nil
+}
+
+// GetInviteByID получает приглашение по ID в рамках чата
+func (r *Repository) GetInviteByID(ctx context.Context, chatID, inviteID int) (*databaseModels.ChatInvite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM chat_invites WHERE id = $1 AND chatsid = $2`
+
+	invite, err := scanInvite(r.db.Pool.QueryRow(ctx, query, inviteID, chatID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("invite not found")
+		}
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+
+	return invite, nil
+}
+
+// GetInviteByCode получает приглашение по коду
+func (r *Repository) GetInviteByCode(ctx context.Context, code string) (*databaseModels.ChatInvite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM chat_invites WHERE code = $1`
+
+	invite, err := scanInvite(r.db.Pool.QueryRow(ctx, query, code))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("invite not found")
+		}
+		return nil, fmt.Errorf("failed to get invite: %w", err)
+	}
+
+	return invite, nil
+}
+
+// GetChatInvites получает все приглашения чата
+func (r *Repository) GetChatInvites(ctx context.Context, chatID int) ([]databaseModels.ChatInvite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM chat_invites WHERE chatsid = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.Pool.Query(ctx, query, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat invites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []databaseModels.ChatInvite
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invite: %w", err)
+		}
+		invites = append(invites, *invite)
+	}
+
+	return invites, nil
+}
+
+// RevokeInvite отзывает приглашение
+func (r *Repository) RevokeInvite(ctx context.Context, chatID, inviteID, revokedBy int) error {
+	query := `
+		UPDATE chat_invites
+		SET revoked_at = NOW(), revoked_by = $1
+		WHERE id = $2 AND chatsid = $3 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, revokedBy, inviteID, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("invite not found")
+	}
+
+	return nil
+}
+
+// RedeemInvite одной транзакцией добавляет пользователя в чат, расходует использование приглашения
+// и записывает присоединение в аудит. Возвращает false, если приглашение уже недействительно,
+// и ошибку "user already in chat", если пользователь уже участник чата
+func (r *Repository) RedeemInvite(ctx context.Context, inviteID, chatID, userID, role int) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Участник, уже состоящий в чате, не расходует приглашение и не попадает в аудит
+	added, err := addUserToChat(ctx, tx, chatID, userID, role)
+	if err != nil {
+		return false, err
+	}
+	if !added {
+		return false, fmt.Errorf("user already in chat")
+	}
+
+	consumed, err := consumeInvite(ctx, tx, inviteID)
+	if err != nil || !consumed {
+		return false, err
+	}
+
+	if err := addInviteRedemption(ctx, tx, inviteID, chatID, userID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// consumeInvite атомарно увеличивает счетчик использований, если приглашение еще действительно
+func consumeInvite(ctx context.Context, tx pgx.Tx, inviteID int) (bool, error) {
+	query := `
+		UPDATE chat_invites
+		SET uses = uses + 1
+		WHERE id = $1
+		  AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		  AND (max_uses IS NULL OR uses < max_uses)
+	`
+
+	result, err := tx.Exec(ctx, query, inviteID)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume invite: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// addInviteRedemption записывает в аудит присоединение пользователя по приглашению
+func addInviteRedemption(ctx context.Context, tx pgx.Tx, inviteID, chatID, userID int) error {
+	query := `
+		INSERT INTO chat_invite_redemptions (invite_id, chatsid, usersid)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := tx.Exec(ctx, query, inviteID, chatID, userID); err != nil {
+		return fmt.Errorf("failed to add invite redemption: %w", err)
+	}
+
+	return nil
+}
+
+// GetInviteRedemptions получает аудит присоединений по приглашению
+func (r *Repository) GetInviteRedemptions(ctx context.Context, inviteID int) ([]databaseModels.ChatInviteRedemption, error) {
+	query := `
+		SELECT cir.id, cir.invite_id, cir.chatsid, cir.usersid,
+		       COALESCE(u.surname || ' ' || u.name, 'Unknown') as user_name,
+		       cir.redeemed_at
+		FROM chat_invite_redemptions cir
+		LEFT JOIN users u ON cir.usersid = u.id
+		WHERE cir.invite_id = $1
+		ORDER BY cir.redeemed_at DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, inviteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite redemptions: %w", err)
+	}
+	defer rows.Close()
+
+	var redemptions []databaseModels.ChatInviteRedemption
+	for rows.Next() {
+		var redemption databaseModels.ChatInviteRedemption
+		err := rows.Scan(
+			&redemption.ID,
+			&redemption.InviteID,
+			&redemption.ChatID,
+			&redemption.UserID,
+			&redemption.UserName,
+			&redemption.RedeemedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invite redemption: %w", err)
+		}
+		redemptions = append(redemptions, redemption)
+	}
+
+	return redemptions, nil
+}
+
+// Retention operations
+
+// SetChatLegalHold включает или снимает legal hold для чата
+func (r *Repository) SetChatLegalHold(ctx context.Context, chatID int, legalHold bool, reason *string) error {
+	query := `
+		UPDATE chats
+		SET legal_hold = $2, legal_hold_reason = $3, version = version + 1
+		WHERE id = $1
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, chatID, legalHold, reason)
+	if err != nil {
+		return fmt.Errorf("failed to set legal hold: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("chat not found")
+	}
+
+	return nil
+}
+
+// GetRetentionPolicies возвращает действующие политики хранения сообщений для РП без legal hold.
+// Значения РП переопределяют значения тарифа; РП с бессрочным хранением не возвращаются
+func (r *Repository) GetRetentionPolicies(ctx context.Context) ([]databaseModels.RetentionPolicy, error) {
+	query := `
+		SELECT w.id,
+		       COALESCE(p.retention_days, t.message_retention_days),
+		       COALESCE(p.retention_action, t.retention_action, 'delete')
+		FROM workspaces w
+		LEFT JOIN tariffs t ON t.id = w.tariffsid
+		LEFT JOIN workspace_retention_policies p ON p.workspacesid = w.id
+		WHERE COALESCE(p.retention_days, t.message_retention_days) IS NOT NULL
+		  AND COALESCE(p.legal_hold, FALSE) = FALSE
+		ORDER BY w.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []databaseModels.RetentionPolicy
+	for rows.Next() {
+		var policy databaseModels.RetentionPolicy
+		if err := rows.Scan(&policy.WorkspaceID, &policy.RetentionDays, &policy.Action); err != nil {
+			return nil, fmt.Errorf("failed to scan retention policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// PurgeExpiredMessages удаляет или обезличивает одну порцию сообщений РП, отправленных раньше before.
+// Сообщения чатов под legal hold не затрагиваются. Возвращает количество обработанных сообщений
+func (r *Repository) PurgeExpiredMessages(ctx context.Context, workspaceID int, before time.Time, action string, batchSize int) (int, error) {
+	var query string
+	if action == databaseModels.RetentionActionAnonymize {
+		// Обезличенное сообщение теряет текст, автора и вложения
+		query = `
+			WITH batch AS (
+				SELECT m.id
+				FROM messages m
+				INNER JOIN chats c ON c.id = m.chatsid
+				WHERE c.workspacesid = $1 AND c.legal_hold = FALSE
+				  AND m.date < $2 AND m.anonymized_at IS NULL
+				LIMIT $3
+			), dropped_attachments AS (
+				DELETE FROM message_link_previews
+				WHERE messagesid IN (SELECT id FROM batch)
+			)
+			UPDATE messages
+			SET text = '', usersid = NULL, anonymized_at = NOW()
+			WHERE id IN (SELECT id FROM batch)
+		`
+	} else {
+		query = `
+			DELETE FROM messages
+			WHERE id IN (
+				SELECT m.id
+				FROM messages m
+				INNER JOIN chats c ON c.id = m.chatsid
+				WHERE c.workspacesid = $1 AND c.legal_hold = FALSE
+				  AND m.date < $2
+				LIMIT $3
+			)
+		`
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, workspaceID, before.Unix(), batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired messages: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// Link preview operations
+
+// GetLinkPreview получает закэшированное превью ссылки, загруженное не раньше notBefore
+func (r *Repository) GetLinkPreview(ctx context.Context, url string, notBefore time.Time) (*databaseModels.LinkPreview, error) {
+	query := `
+		SELECT url, status, COALESCE(title, ''), COALESCE(description, ''),
+		       COALESCE(image_url, ''), COALESCE(site_name, ''), fetched_at
+		FROM link_previews
+		WHERE url = $1 AND fetched_at >= $2
+	`
+
+	var preview databaseModels.LinkPreview
+	err := r.db.Pool.QueryRow(ctx, query, url, notBefore).Scan(
+		&preview.URL,
+		&preview.Status,
+		&preview.Title,
+		&preview.Description,
+		&preview.ImageURL,
+		&preview.SiteName,
+		&preview.FetchedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get link preview: %w", err)
+	}
+
+	return &preview, nil
+}
+
+// SaveLinkPreview сохраняет превью ссылки в кэш (в том числе неудачную попытку загрузки)
+func (r *Repository) SaveLinkPreview(ctx context.Context, preview *databaseModels.LinkPreview) error {
+	query := `
+		INSERT INTO link_previews (url, status, title, description, image_url, site_name, fetched_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NOW())
+		ON CONFLICT (url) DO UPDATE
+		SET status = EXCLUDED.status,
+		    title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    image_url = EXCLUDED.image_url,
+		    site_name = EXCLUDED.site_name,
+		    fetched_at = NOW()
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		preview.URL,
+		preview.Status,
+		preview.Title,
+		preview.Description,
+		preview.ImageURL,
+		preview.SiteName,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save link preview: %w", err)
+	}
+
+	return nil
+}
+
+// AttachLinkPreview прикрепляет превью ссылки к сообщению
+func (r *Repository) AttachLinkPreview(ctx context.Context, messageID int, url string) error {
+	query := `
+		INSERT INTO message_link_previews (messagesid, url)
+		VALUES ($1, $2)
+		ON CONFLICT (messagesid) DO UPDATE SET url = EXCLUDED.url
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, messageID, url); err != nil {
+		return fmt.Errorf("failed to attach link preview: %w", err)
+	}
+
+	return nil
+}
+
 // Message operations
 
 // CreateMessage создает новое сообщение
@@ -393,7 +1127,7 @@ func (r *Repository) CreateMessage(ctx context.Context, chatID, userID int, text
 	query := `
 		INSERT INTO messages (chatsid, usersid, text, date, status)
 		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, chatsid, usersid, text, date, status
+		RETURNING id, chatsid, usersid, text, date, status, kind, tasksid
 	`
 
 	var message databaseModels.Message
@@ -404,6 +1138,8 @@ func (r *Repository) CreateMessage(ctx context.Context, chatID, userID int, text
 		&message.Text,
 		&message.Date,
 		&message.Status,
+		&message.Kind,
+		&message.TaskID,
 	)
 
 	if err != nil {
@@ -413,10 +1149,39 @@ func (r *Repository) CreateMessage(ctx context.Context, chatID, userID int, text
 	return &message, nil
 }
 
+// CreateSystemMessage создает системное сообщение о задаче от имени пользователя, выполнившего действие
+func (r *Repository) CreateSystemMessage(ctx context.Context, chatID, actorID int, text string, taskID int) (*databaseModels.Message, error) {
+	now := int(time.Now().Unix())
+
+	query := `
+		INSERT INTO messages (chatsid, usersid, text, date, status, kind, tasksid)
+		VALUES ($1, $2, $3, $4, '{}', $5, $6)
+		RETURNING id, chatsid, usersid, text, date, status, kind, tasksid
+	`
+
+	var message databaseModels.Message
+	err := r.db.Pool.QueryRow(ctx, query, chatID, actorID, text, now, databaseModels.MessageKindSystem, taskID).Scan(
+		&message.ID,
+		&message.ChatID,
+		&message.UserID,
+		&message.Text,
+		&message.Date,
+		&message.Status,
+		&message.Kind,
+		&message.TaskID,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create system message: %w", err)
+	}
+
+	return &message, nil
+}
+
 // GetMessageByID получает сообщение по ID
 func (r *Repository) GetMessageByID(ctx context.Context, messageID int) (*databaseModels.Message, error) {
 	query := `
-		SELECT id, chatsid, usersid, text, date, status
+		SELECT id, chatsid, COALESCE(usersid, 0), text, date, status, kind, tasksid
 		FROM messages
 		WHERE id = $1
 	`
@@ -429,6 +1194,8 @@ func (r *Repository) GetMessageByID(ctx context.Context, messageID int) (*databa
 		&message.Text,
 		&message.Date,
 		&message.Status,
+		&message.Kind,
+		&message.TaskID,
 	)
 
 	if err != nil {
@@ -447,7 +1214,7 @@ func (r *Repository) UpdateMessage(ctx context.Context, messageID int, text stri
 		UPDATE messages
 		SET text = $1
 		WHERE id = $2
-		RETURNING id, chatsid, usersid, text, date, status
+		RETURNING id, chatsid, COALESCE(usersid, 0), text, date, status, kind, tasksid
 	`
 
 	var message databaseModels.Message
@@ -458,6 +1225,8 @@ func (r *Repository) UpdateMessage(ctx context.Context, messageID int, text stri
 		&message.Text,
 		&message.Date,
 		&message.Status,
+		&message.Kind,
+		&message.TaskID,
 	)
 
 	if err != nil {
@@ -488,13 +1257,16 @@ func (r *Repository) DeleteMessage(ctx context.Context, messageID int) error {
 
 // GetChatMessages получает историю сообщений чата
 type MessageWithUser struct {
-	ID       int
-	ChatID   int
-	UserID   int
-	UserName string
-	Text     string
-	Date     int
-	Status   string
+	ID          int
+	ChatID      int
+	UserID      int
+	UserName    string
+	Text        string
+	Date        int
+	Status      string
+	Kind        string
+	TaskID      *int
+	LinkPreview *databaseModels.LinkPreview
 }
 
 func (r *Repository) GetChatMessages(ctx context.Context, chatID int, limit, offset int, before *int) ([]MessageWithUser, error) {
@@ -503,11 +1275,14 @@ func (r *Repository) GetChatMessages(ctx context.Context, chatID int, limit, off
 
 	if before != nil {
 		query = `
-			SELECT m.id, m.chatsid, m.usersid, 
+			SELECT m.id, m.chatsid, COALESCE(m.usersid, 0), 
 			       COALESCE(u.surname || ' ' || u.name, 'Unknown') as user_name,
-			       m.text, m.date, m.status
+			       m.text, m.date, m.status, m.kind, m.tasksid,
+			       lp.url, lp.title, lp.description, lp.image_url, lp.site_name
 			FROM messages m
 			LEFT JOIN users u ON m.usersid = u.id
+			LEFT JOIN message_link_previews mlp ON mlp.messagesid = m.id
+			LEFT JOIN link_previews lp ON lp.url = mlp.url AND lp.status = 'ok'
 			WHERE m.chatsid = $1 AND m.date < $2
 			ORDER BY m.date DESC
 			LIMIT $3 OFFSET $4
@@ -515,11 +1290,14 @@ func (r *Repository) GetChatMessages(ctx context.Context, chatID int, limit, off
 		args = []interface{}{chatID, *before, limit, offset}
 	} else {
 		query = `
-			SELECT m.id, m.chatsid, m.usersid,
+			SELECT m.id, m.chatsid, COALESCE(m.usersid, 0),
 			       COALESCE(u.surname || ' ' || u.name, 'Unknown') as user_name,
-			       m.text, m.date, m.status
+			       m.text, m.date, m.status, m.kind, m.tasksid,
+			       lp.url, lp.title, lp.description, lp.image_url, lp.site_name
 			FROM messages m
 			LEFT JOIN users u ON m.usersid = u.id
+			LEFT JOIN message_link_previews mlp ON mlp.messagesid = m.id
+			LEFT JOIN link_previews lp ON lp.url = mlp.url AND lp.status = 'ok'
 			WHERE m.chatsid = $1
 			ORDER BY m.date DESC
 			LIMIT $2 OFFSET $3
@@ -536,6 +1314,7 @@ func (r *Repository) GetChatMessages(ctx context.Context, chatID int, limit, off
 	var messages []MessageWithUser
 	for rows.Next() {
 		var msg MessageWithUser
+		var previewURL, previewTitle, previewDescription, previewImage, previewSite *string
 		err := rows.Scan(
 			&msg.ID,
 			&msg.ChatID,
@@ -544,10 +1323,27 @@ func (r *Repository) GetChatMessages(ctx context.Context, chatID int, limit, off
 			&msg.Text,
 			&msg.Date,
 			&msg.Status,
+			&msg.Kind,
+			&msg.TaskID,
+			&previewURL,
+			&previewTitle,
+			&previewDescription,
+			&previewImage,
+			&previewSite,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
+		if previewURL != nil {
+			msg.LinkPreview = &databaseModels.LinkPreview{
+				URL:         *previewURL,
+				Status:      databaseModels.LinkPreviewStatusOK,
+				Title:       stringValue(previewTitle),
+				Description: stringValue(previewDescription),
+				ImageURL:    stringValue(previewImage),
+				SiteName:    stringValue(previewSite),
+			}
+		}
 		messages = append(messages, msg)
 	}
 
@@ -557,9 +1353,9 @@ func (r *Repository) GetChatMessages(ctx context.Context, chatID int, limit, off
 // GetLastMessage получает последнее сообщение в чате
 func (r *Repository) GetLastMessage(ctx context.Context, chatID int) (*MessageWithUser, error) {
 	query := `
-		SELECT m.id, m.chatsid, m.usersid,
+		SELECT m.id, m.chatsid, COALESCE(m.usersid, 0),
 		       COALESCE(u.surname || ' ' || u.name, 'Unknown') as user_name,
-		       m.text, m.date, m.status
+		       m.text, m.date, m.status, m.kind, m.tasksid
 		FROM messages m
 		LEFT JOIN users u ON m.usersid = u.id
 		WHERE m.chatsid = $1
@@ -576,6 +1372,8 @@ func (r *Repository) GetLastMessage(ctx context.Context, chatID int) (*MessageWi
 		&msg.Text,
 		&msg.Date,
 		&msg.Status,
+		&msg.Kind,
+		&msg.TaskID,
 	)
 
 	if err != nil {
@@ -646,14 +1444,11 @@ func (r *Repository) GetChatTasks(ctx context.Context, chatID int) ([]databaseMo
 			t.date::text,
 			t.description,
 			t.status,
-			CASE t.status
-				WHEN 1 THEN 'Создана'
-				WHEN 2 THEN 'В работе'
-				WHEN 3 THEN 'На проверке'
-				WHEN 4 THEN 'Завершена'
-				WHEN 5 THEN 'Отменена'
-				ELSE 'Неизвестно'
-			END as status_name,
+			task_status_name(t.workspacesid, t.status) as status_name,
+			t.priority,
+			COALESCE((SELECT json_agg(json_build_object('id', l.id, 'name', l.name, 'color', l.color) ORDER BY l.name)
+				FROM task_label_links ll JOIN task_labels l ON ll.labelsid = l.id
+				WHERE ll.tasksid = t.id), '[]') as labels,
 			t.title,
 			t.workspacesid as workspace_id,
 			COALESCE(w.name, 'Unknown Workspace') as workspace_name
@@ -674,6 +1469,7 @@ func (r *Repository) GetChatTasks(ctx context.Context, chatID int) ([]databaseMo
 	var tasks []databaseModels.ChatTask
 	for rows.Next() {
 		var task databaseModels.ChatTask
+		var labels []byte
 		err := rows.Scan(
 			&task.ID,
 			&task.ChatID,
@@ -685,6 +1481,8 @@ func (r *Repository) GetChatTasks(ctx context.Context, chatID int) ([]databaseMo
 			&task.Description,
 			&task.Status,
 			&task.StatusName,
+			&task.Priority,
+			&labels,
 			&task.Title,
 			&task.WorkspaceID,
 			&task.WorkspaceName,
@@ -692,6 +1490,9 @@ func (r *Repository) GetChatTasks(ctx context.Context, chatID int) ([]databaseMo
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan chat task: %w", err)
 		}
+		if err := json.Unmarshal(labels, &task.Labels); err != nil {
+			return nil, fmt.Errorf("failed to decode chat task labels: %w", err)
+		}
 		tasks = append(tasks, task)
 	}
 
@@ -721,3 +1522,11 @@ func (r *Repository) GetUserName(ctx context.Context, userID int) (string, error
 
 	return name, nil
 }
+
+// stringValue возвращает значение строки или пустую строку для NULL
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"log"
 	"net/http"
 	"os"
@@ -11,9 +12,11 @@ import (
 
 	"github.com/diploma/chat-service/config"
 	"github.com/diploma/chat-service/data/database"
+	"github.com/diploma/chat-service/data/linkpreview"
 	"github.com/diploma/chat-service/data/repository"
 	"github.com/diploma/chat-service/docs"
 	"github.com/diploma/chat-service/presentation/handlers"
+	"github.com/diploma/shared/kafka"
 	metrics "github.com/diploma/shared/metrics"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -55,23 +58,105 @@ func main() {
 	}
 	defer db.Close()
 
+	// Инициализируем Kafka producer для уведомлений о сообщениях
+	var kafkaProducer *kafka.Producer
+	if len(cfg.KafkaBrokers) > 0 {
+		kafkaProducer, err = kafka.NewProducer(cfg.KafkaBrokers)
+		if err != nil {
+			log.Printf("Failed to create Kafka producer: %v", err)
+			log.Println("Kafka producer disabled, offline notifications will not be sent")
+		} else {
+			defer kafkaProducer.Close()
+			log.Println("Kafka producer initialized successfully")
+		}
+	} else {
+		log.Println("Kafka brokers not configured, offline notifications disabled")
+	}
+
 	// Создаем репозиторий
 	repo := repository.NewRepository(db)
 
-	// Создаем обработчики
-	chatHandler := handlers.NewChatHandler(repo)
-	memberHandler := handlers.NewMemberHandler(repo)
-	messageHandler := handlers.NewMessageHandler(repo)
+	// Запускаем окончательное удаление чатов, срок хранения которых истек
+	go runChatPurger(repo, cfg.ChatPurgeInterval)
+
+	// Запускаем очистку сообщений по политикам хранения РП
+	go runRetentionPurger(repo, cfg.RetentionInterval, cfg.RetentionBatchSize)
 
 	// Создаем WebSocket Hub
-	wsHub := handlers.NewWSHub(repo)
+	// Превью ссылок в сообщениях
+	var previewFetcher *linkpreview.Fetcher
+	if cfg.LinkPreviewEnabled {
+		previewFetcher = linkpreview.NewFetcher(cfg.LinkPreviewTimeout, cfg.LinkPreviewMaxBytes, cfg.LinkPreviewAllowLocal)
+	}
+
+	wsHub := handlers.NewWSHub(repo, kafkaProducer, previewFetcher, cfg.LinkPreviewCacheTTL)
 	go wsHub.Run()
 
+	// Инициализируем Kafka консьюмер для системных сообщений и изменений задач
+	if len(cfg.KafkaBrokers) > 0 {
+		kafkaConsumer, err := kafka.NewConsumer(cfg.KafkaBrokers)
+		if err != nil {
+			log.Printf("Failed to create Kafka consumer: %v", err)
+			log.Println("System messages and live updates about tasks will not be delivered")
+		} else {
+			// Обработчик событий создания задач из сообщений
+			taskFromMessageHandler := func(topic string, message []byte) error {
+				var event kafka.TaskCreatedFromMessageEvent
+				if err := json.Unmarshal(message, &event); err != nil {
+					log.Printf("Failed to unmarshal task created from message event: %v", err)
+					return err
+				}
+
+				log.Printf("Processing task created from message event for task %d in chat %d", event.TaskID, event.ChatID)
+
+				if err := wsHub.HandleTaskCreatedFromMessage(context.Background(), event); err != nil {
+					log.Printf("Failed to post system message about task %d: %v", event.TaskID, err)
+					return err
+				}
+
+				return nil
+			}
+
+			go func() {
+				if err := kafkaConsumer.Subscribe(kafka.TopicTaskCreatedFromMessage, taskFromMessageHandler); err != nil {
+					log.Printf("Failed to subscribe to Kafka topic: %v", err)
+				}
+			}()
+
+			// Обработчик изменений задач для обновления клиентов в реальном времени
+			taskChangedHandler := func(topic string, message []byte) error {
+				var event kafka.TaskChangedEvent
+				if err := json.Unmarshal(message, &event); err != nil {
+					log.Printf("Failed to unmarshal task changed event: %v", err)
+					return err
+				}
+
+				wsHub.HandleTaskChanged(event)
+				return nil
+			}
+
+			go func() {
+				if err := kafkaConsumer.Subscribe(kafka.TopicTaskChanged, taskChangedHandler); err != nil {
+					log.Printf("Failed to subscribe to Kafka topic: %v", err)
+				}
+			}()
+
+			defer kafkaConsumer.Close()
+			log.Println("Kafka consumer initialized for task system messages and task updates")
+		}
+	}
+
+	// Создаем обработчики
+	chatHandler := handlers.NewChatHandler(repo, cfg.ChatDeleteRetention)
+	memberHandler := handlers.NewMemberHandler(repo)
+	messageHandler := handlers.NewMessageHandler(repo, wsHub)
+	inviteHandler := handlers.NewInviteHandler(repo, cfg.InviteBaseURL)
+
 	// Создаем метрики
 	serviceMetrics := metrics.NewServiceMetrics("chat-service")
 
 	// Настраиваем роутер
-	router := setupRouter(chatHandler, memberHandler, messageHandler, wsHub, serviceMetrics)
+	router := setupRouter(chatHandler, memberHandler, messageHandler, inviteHandler, wsHub, serviceMetrics)
 
 	// Выводим все маршруты для отладки
 	log.Printf("Registered routes:")
@@ -110,7 +195,7 @@ func main() {
 	log.Println("Server exited")
 }
 
-func setupRouter(chatHandler *handlers.ChatHandler, memberHandler *handlers.MemberHandler, messageHandler *handlers.MessageHandler, wsHub *handlers.WSHub, serviceMetrics *metrics.ServiceMetrics) *gin.Engine {
+func setupRouter(chatHandler *handlers.ChatHandler, memberHandler *handlers.MemberHandler, messageHandler *handlers.MessageHandler, inviteHandler *handlers.InviteHandler, wsHub *handlers.WSHub, serviceMetrics *metrics.ServiceMetrics) *gin.Engine {
 	router := gin.Default()
 
 	// Swagger документация
@@ -137,6 +222,14 @@ func setupRouter(chatHandler *handlers.ChatHandler, memberHandler *handlers.Memb
 		api.POST("", chatHandler.CreateChat)
 		api.GET("", chatHandler.GetChats)
 
+		// Режим «не беспокоить» (статический маршрут регистрируем до /:id)
+		api.GET("/notifications/dnd", memberHandler.GetDNDSchedule)
+		api.PUT("/notifications/dnd", memberHandler.UpdateDNDSchedule)
+		api.DELETE("/notifications/dnd", memberHandler.DeleteDNDSchedule)
+
+		// Присоединение по приглашению (статический маршрут регистрируем до /:id)
+		api.POST("/invites/:code/join", inviteHandler.JoinByInvite)
+
 		// ВАЖНО: Регистрируем более специфичные маршруты ПЕРЕД общими /:id
 		// Это критично для правильной работы роутера Gin
 
@@ -155,9 +248,24 @@ func setupRouter(chatHandler *handlers.ChatHandler, memberHandler *handlers.Memb
 		api.PUT("/:id/members/:user_id", memberHandler.UpdateMemberRole)
 		api.DELETE("/:id/members/:user_id", memberHandler.RemoveMember)
 
+		// Настройки уведомлений текущего пользователя в чате
+		api.GET("/:id/notifications", memberHandler.GetNotificationSettings)
+		api.PUT("/:id/notifications", memberHandler.UpdateNotificationSettings)
+
+		// Приглашения в чат
+		api.POST("/:id/invites", inviteHandler.CreateInvite)
+		api.GET("/:id/invites", inviteHandler.GetInvites)
+		api.DELETE("/:id/invites/:invite_id", inviteHandler.RevokeInvite)
+		api.GET("/:id/invites/:invite_id/redemptions", inviteHandler.GetInviteRedemptions)
+
 		// Задачи чата
 		api.GET("/:id/tasks", chatHandler.GetChatTasks)
 
+		// Архив чата
+		api.POST("/:id/archive", chatHandler.ArchiveChat)
+		api.POST("/:id/restore", chatHandler.RestoreChat)
+		api.PUT("/:id/legal-hold", chatHandler.UpdateLegalHold)
+
 		// Чаты (общие маршруты с :id - регистрируем ПОСЛЕДНИМИ)
 		api.GET("/:id", chatHandler.GetChat)
 		api.PUT("/:id", chatHandler.UpdateChat)
@@ -172,11 +280,63 @@ func setupRouter(chatHandler *handlers.ChatHandler, memberHandler *handlers.Memb
 	return router
 }
 
+// runChatPurger периодически удаляет чаты, у которых истек срок хранения после запроса на удаление
+func runChatPurger(repo *repository.Repository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := repo.PurgeScheduledChats(context.Background())
+		if err != nil {
+			log.Printf("Chat purger: %v", err)
+		} else if purged > 0 {
+			log.Printf("Chat purger: deleted %d chats", purged)
+		}
+		<-ticker.C
+	}
+}
+
+// runRetentionPurger периодически удаляет или обезличивает сообщения старше срока хранения РП.
+// Сообщения обрабатываются порциями, чтобы не блокировать таблицу надолго
+func runRetentionPurger(repo *repository.Repository, interval time.Duration, batchSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx := context.Background()
+		policies, err := repo.GetRetentionPolicies(ctx)
+		if err != nil {
+			log.Printf("Retention purger: %v", err)
+		}
+
+		for _, policy := range policies {
+			before := time.Now().AddDate(0, 0, -policy.RetentionDays)
+			total := 0
+			for {
+				processed, err := repo.PurgeExpiredMessages(ctx, policy.WorkspaceID, before, policy.Action, batchSize)
+				if err != nil {
+					log.Printf("Retention purger: workspace %d: %v", policy.WorkspaceID, err)
+					break
+				}
+				total += processed
+				if processed < batchSize {
+					break
+				}
+			}
+			if total > 0 {
+				log.Printf("Retention purger: workspace %d: %s %d messages older than %d days", policy.WorkspaceID, policy.Action, total, policy.RetentionDays)
+			}
+		}
+		<-ticker.C
+	}
+}
+
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-User-ID, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-User-ID, accept, origin, Cache-Control, X-Requested-With, If-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"log"
@@ -9,17 +10,29 @@ import (
 	"strings"
 	"time"
 
+	"github.com/diploma/chat-service/data/databaseModels"
 	"github.com/diploma/chat-service/data/repository"
 	"github.com/diploma/chat-service/presentation/models"
+	"github.com/diploma/shared/etag"
 	"github.com/gin-gonic/gin"
 )
 
 type ChatHandler struct {
-	repo *repository.Repository
+	repo            *repository.Repository
+	deleteRetention time.Duration
 }
 
-func NewChatHandler(repo *repository.Repository) *ChatHandler {
-	return &ChatHandler{repo: repo}
+func NewChatHandler(repo *repository.Repository, deleteRetention time.Duration) *ChatHandler {
+	return &ChatHandler{repo: repo, deleteRetention: deleteRetention}
+}
+
+// formatOptionalTime форматирует необязательную временную метку в RFC3339
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.UTC().Format(time.RFC3339)
+	return &formatted
 }
 
 // getUserIDFromHeader извлекает userID из заголовка
@@ -191,13 +204,14 @@ func (h *ChatHandler) CreateChat(c *gin.Context) {
 
 // GetChats получает список чатов пользователя
 // @Summary Получить список чатов пользователя
-// @Description Возвращает список всех чатов, в которых участвует пользователь
+// @Description Возвращает список чатов, в которых участвует пользователь. Архивные чаты возвращаются только с archived=true
 // @Tags chats
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param workspace_id query int false "Фильтр по рабочему пространству"
 // @Param type query int false "Фильтр по типу чата (1=личный, 2=групповой, 3=канал)"
+// @Param archived query bool false "Вернуть архивные чаты вместо активных"
 // @Success 200 {object} models.ChatListResponse
 // @Failure 401 {object} map[string]string "Не авторизован"
 // @Router /chats [get]
@@ -222,7 +236,9 @@ func (h *ChatHandler) GetChats(c *gin.Context) {
 		}
 	}
 
-	chats, err := h.repo.GetUserChats(c.Request.Context(), userID, workspaceID, chatType)
+	archived := c.Query("archived") == "true"
+
+	chats, err := h.repo.GetUserChats(c.Request.Context(), userID, workspaceID, chatType, archived)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -240,12 +256,15 @@ func (h *ChatHandler) GetChats(c *gin.Context) {
 		members, _ := h.repo.GetChatMembers(c.Request.Context(), chat.ID)
 
 		item := models.ChatListItem{
-			ID:           chat.ID,
-			Name:         chat.Name,
-			Type:         chat.Type,
-			WorkspaceID:  chat.WorkspaceID,
-			UnreadCount:  unreadCount,
-			MembersCount: len(members),
+			ID:                chat.ID,
+			Name:              chat.Name,
+			Type:              chat.Type,
+			WorkspaceID:       chat.WorkspaceID,
+			UnreadCount:       unreadCount,
+			MembersCount:      len(members),
+			Archived:          chat.ArchivedAt != nil,
+			ArchivedAt:        formatOptionalTime(chat.ArchivedAt),
+			DeleteScheduledAt: formatOptionalTime(chat.DeleteScheduledAt),
 		}
 
 		if lastMsg != nil {
@@ -312,38 +331,50 @@ func (h *ChatHandler) GetChat(c *gin.Context) {
 		return
 	}
 
+	etag.Set(c, chat.Version)
+	c.JSON(http.StatusOK, h.chatDetails(c.Request.Context(), chat, userID))
+}
+
+// chatDetails собирает детальную информацию о чате для пользователя
+func (h *ChatHandler) chatDetails(ctx context.Context, chat *databaseModels.Chat, userID int) models.ChatResponse {
 	// Получаем роль пользователя
-	role, _ := h.repo.GetUserRoleInChat(c.Request.Context(), userID, chatID)
+	role, _ := h.repo.GetUserRoleInChat(ctx, userID, chat.ID)
 
 	// Считаем участников
-	members, _ := h.repo.GetChatMembers(c.Request.Context(), chatID)
-
-	response := models.ChatResponse{
-		ID:           chat.ID,
-		Name:         chat.Name,
-		Type:         chat.Type,
-		WorkspaceID:  chat.WorkspaceID,
-		MembersCount: len(members),
-		MyRole:       role,
+	members, _ := h.repo.GetChatMembers(ctx, chat.ID)
+
+	return models.ChatResponse{
+		ID:                chat.ID,
+		Name:              chat.Name,
+		Type:              chat.Type,
+		WorkspaceID:       chat.WorkspaceID,
+		MembersCount:      len(members),
+		MyRole:            role,
+		Archived:          chat.ArchivedAt != nil,
+		ArchivedAt:        formatOptionalTime(chat.ArchivedAt),
+		DeleteScheduledAt: formatOptionalTime(chat.DeleteScheduledAt),
+		LegalHold:         chat.LegalHold,
+		Version:           chat.Version,
 	}
-
-	c.JSON(http.StatusOK, response)
 }
 
 // UpdateChat обновляет настройки чата
 // @Summary Обновить настройки чата
-// @Description Обновляет название и другие настройки чата (только для администраторов)
+// @Description Обновляет название и другие настройки чата (только для администраторов). Заголовок If-Match должен содержать ETag текущей версии чата
 // @Tags chats
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID чата"
+// @Param If-Match header string true "ETag версии чата или *"
 // @Param request body models.UpdateChatRequest true "Данные для обновления чата"
 // @Success 200 {object} models.ChatResponse
 // @Failure 400 {object} map[string]string "Невалидные данные"
 // @Failure 401 {object} map[string]string "Не авторизован"
 // @Failure 403 {object} map[string]string "Недостаточно прав"
 // @Failure 404 {object} map[string]string "Чат не найден"
+// @Failure 412 {object} map[string]interface{} "Чат изменен другим пользователем, в поле current - текущее состояние"
+// @Failure 428 {object} map[string]string "Не передан заголовок If-Match"
 // @Router /chats/{id} [put]
 func (h *ChatHandler) UpdateChat(c *gin.Context) {
 	userID, err := getUserIDFromHeader(c)
@@ -384,29 +415,59 @@ func (h *ChatHandler) UpdateChat(c *gin.Context) {
 		return
 	}
 
-	updatedChat, err := h.repo.UpdateChat(c.Request.Context(), chatID, req.Name)
+	ifMatch, ok := etag.IfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	if !etag.Matches(ifMatch, chat.Version) {
+		etag.RespondConflict(c, "chat was modified by another user", chat.Version, h.chatDetails(c.Request.Context(), chat, userID))
+		return
+	}
+
+	updatedChat, err := h.repo.UpdateChat(c.Request.Context(), chatID, etag.ExpectedVersion(ifMatch, chat.Version), req.Name)
 	if err != nil {
+		if err.Error() == "chat version changed" {
+			h.respondChatConflict(c, chatID, userID)
+			return
+		}
+		if err.Error() == "chat not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	response := models.ChatResponse{
-		ID:   updatedChat.ID,
-		Name: updatedChat.Name,
+		ID:      updatedChat.ID,
+		Name:    updatedChat.Name,
+		Version: updatedChat.Version,
 	}
 
+	etag.Set(c, updatedChat.Version)
 	c.JSON(http.StatusOK, response)
 }
 
-// DeleteChat удаляет чат
+// respondChatConflict отвечает 412 с текущим состоянием чата
+func (h *ChatHandler) respondChatConflict(c *gin.Context, chatID, userID int) {
+	chat, err := h.repo.GetChatByID(c.Request.Context(), chatID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+	etag.RespondConflict(c, "chat was modified by another user", chat.Version, h.chatDetails(c.Request.Context(), chat, userID))
+}
+
+// DeleteChat планирует окончательное удаление чата
 // @Summary Удалить чат
-// @Description Удаляет чат и все связанные данные (только для администраторов)
+// @Description Архивирует чат и планирует его окончательное удаление после истечения срока хранения (только для руководителя РП). До удаления чат можно восстановить
 // @Tags chats
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "ID чата"
-// @Success 204 "No Content"
+// @Success 202 {object} models.ChatDeletionResponse
 // @Failure 401 {object} map[string]string "Не авторизован"
 // @Failure 403 {object} map[string]string "Недостаточно прав"
 // @Failure 404 {object} map[string]string "Чат не найден"
@@ -425,6 +486,72 @@ func (h *ChatHandler) DeleteChat(c *gin.Context) {
 		return
 	}
 
+	chat, err := h.repo.GetChatByID(c.Request.Context(), chatID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+
+	// Окончательно удалить чат может только руководитель РП
+	isLeader, err := h.repo.IsWorkspaceLeader(c.Request.Context(), userID, chat.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check workspace role"})
+		return
+	}
+	if !isLeader {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only workspace leader can delete chats"})
+		return
+	}
+
+	// Повторный запрос не сдвигает уже назначенную дату удаления
+	deleteAt := time.Now().Add(h.deleteRetention).UTC()
+	if chat.DeleteScheduledAt != nil {
+		deleteAt = chat.DeleteScheduledAt.UTC()
+	} else if err := h.repo.ScheduleChatDeletion(c.Request.Context(), chatID, userID, deleteAt); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.ChatDeletionResponse{
+		ChatID:            chatID,
+		DeleteScheduledAt: deleteAt.Format(time.RFC3339),
+	})
+}
+
+// ArchiveChat переводит чат в архив
+// @Summary Архивировать чат
+// @Description Переводит чат в архив: он скрывается из списка чатов и становится доступен только для чтения (только для администраторов)
+// @Tags chats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID чата"
+// @Success 200 {object} models.ChatResponse
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Недостаточно прав"
+// @Failure 404 {object} map[string]string "Чат не найден"
+// @Failure 409 {object} map[string]string "Чат уже в архиве"
+// @Router /chats/{id}/archive [post]
+func (h *ChatHandler) ArchiveChat(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
+
+	chatIDStr := c.Param("id")
+	chatID, err := strconv.Atoi(chatIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chat ID"})
+		return
+	}
+
+	chat, err := h.repo.GetChatByID(c.Request.Context(), chatID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+
 	// Проверяем права (должен быть администратором)
 	role, err := h.repo.GetUserRoleInChat(c.Request.Context(), userID, chatID)
 	if err != nil || role != 2 {
@@ -432,12 +559,173 @@ func (h *ChatHandler) DeleteChat(c *gin.Context) {
 		return
 	}
 
-	if err := h.repo.DeleteChat(c.Request.Context(), chatID); err != nil {
+	if chat.ArchivedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "chat is already archived"})
+		return
+	}
+
+	if err := h.repo.ArchiveChat(c.Request.Context(), chatID, userID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondWithChat(c, chatID, role)
+}
+
+// RestoreChat возвращает чат из архива
+// @Summary Восстановить чат из архива
+// @Description Возвращает чат из архива (только для администраторов). Если запланировано удаление чата, восстановить его может только руководитель РП
+// @Tags chats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID чата"
+// @Success 200 {object} models.ChatResponse
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Недостаточно прав"
+// @Failure 404 {object} map[string]string "Чат не найден"
+// @Failure 409 {object} map[string]string "Чат не в архиве"
+// @Router /chats/{id}/restore [post]
+func (h *ChatHandler) RestoreChat(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
+
+	chatIDStr := c.Param("id")
+	chatID, err := strconv.Atoi(chatIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chat ID"})
+		return
+	}
+
+	chat, err := h.repo.GetChatByID(c.Request.Context(), chatID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+
+	// Проверяем права (должен быть администратором)
+	role, err := h.repo.GetUserRoleInChat(c.Request.Context(), userID, chatID)
+	if err != nil || role != 2 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
+
+	if chat.ArchivedAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "chat is not archived"})
+		return
+	}
+
+	// Отменить удаление, назначенное руководителем РП, может только руководитель РП
+	if chat.DeleteScheduledAt != nil {
+		isLeader, err := h.repo.IsWorkspaceLeader(c.Request.Context(), userID, chat.WorkspaceID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check workspace role"})
+			return
+		}
+		if !isLeader {
+			c.JSON(http.StatusForbidden, gin.H{"error": "chat is scheduled for deletion, only workspace leader can restore it"})
+			return
+		}
+	}
+
+	if err := h.repo.RestoreChat(c.Request.Context(), chatID); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondWithChat(c, chatID, role)
+}
+
+// UpdateLegalHold включает или снимает legal hold для чата
+// @Summary Включить или снять legal hold
+// @Description Приостанавливает (или возобновляет) очистку сообщений по политике хранения и окончательное удаление чата (только для руководителя РП)
+// @Tags chats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID чата"
+// @Param request body models.UpdateLegalHoldRequest true "Состояние legal hold"
+// @Success 200 {object} models.ChatResponse
+// @Failure 400 {object} map[string]string "Невалидные данные"
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Недостаточно прав"
+// @Failure 404 {object} map[string]string "Чат не найден"
+// @Router /chats/{id}/legal-hold [put]
+func (h *ChatHandler) UpdateLegalHold(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
+
+	chatIDStr := c.Param("id")
+	chatID, err := strconv.Atoi(chatIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chat ID"})
+		return
+	}
+
+	chat, err := h.repo.GetChatByID(c.Request.Context(), chatID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+
+	isLeader, err := h.repo.IsWorkspaceLeader(c.Request.Context(), userID, chat.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check workspace role"})
+		return
+	}
+	if !isLeader {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only workspace leader can change legal hold"})
+		return
+	}
+
+	var req models.UpdateLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reason := req.Reason
+	if !*req.LegalHold {
+		reason = nil
+	}
+
+	if err := h.repo.SetChatLegalHold(c.Request.Context(), chatID, *req.LegalHold, reason); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	role, _ := h.repo.GetUserRoleInChat(c.Request.Context(), userID, chatID)
+	h.respondWithChat(c, chatID, role)
+}
+
+// respondWithChat возвращает актуальное состояние чата
+func (h *ChatHandler) respondWithChat(c *gin.Context, chatID, role int) {
+	chat, err := h.repo.GetChatByID(c.Request.Context(), chatID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+
+	members, _ := h.repo.GetChatMembers(c.Request.Context(), chatID)
+
+	c.JSON(http.StatusOK, models.ChatResponse{
+		ID:                chat.ID,
+		Name:              chat.Name,
+		Type:              chat.Type,
+		WorkspaceID:       chat.WorkspaceID,
+		MembersCount:      len(members),
+		MyRole:            role,
+		Archived:          chat.ArchivedAt != nil,
+		ArchivedAt:        formatOptionalTime(chat.ArchivedAt),
+		DeleteScheduledAt: formatOptionalTime(chat.DeleteScheduledAt),
+		LegalHold:         chat.LegalHold,
+	})
 }
 
 // GetChatTasks получает список задач, прикрепленных к чату
@@ -495,6 +783,10 @@ func (h *ChatHandler) GetChatTasks(c *gin.Context) {
 	// Преобразуем в формат ответа
 	var taskInfos []models.ChatTaskInfo
 	for _, task := range tasks {
+		labels := make([]models.ChatTaskLabel, 0, len(task.Labels))
+		for _, label := range task.Labels {
+			labels = append(labels, models.ChatTaskLabel{ID: label.ID, Name: label.Name, Color: label.Color})
+		}
 		taskInfos = append(taskInfos, models.ChatTaskInfo{
 			AttachedAt:    task.AttachedAt,
 			Creator:       task.Creator,
@@ -504,6 +796,8 @@ func (h *ChatHandler) GetChatTasks(c *gin.Context) {
 			ID:            task.ID,
 			Status:        task.Status,
 			StatusName:    task.StatusName,
+			Priority:      task.Priority,
+			Labels:        labels,
 			Title:         task.Title,
 			WorkspaceID:   task.WorkspaceID,
 			WorkspaceName: task.WorkspaceName,
@@ -0,0 +1,412 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/diploma/chat-service/data/databaseModels"
+	"github.com/diploma/chat-service/data/repository"
+	"github.com/diploma/chat-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+type InviteHandler struct {
+	repo    *repository.Repository
+	baseURL string
+}
+
+func NewInviteHandler(repo *repository.Repository, baseURL string) *InviteHandler {
+	return &InviteHandler{repo: repo, baseURL: baseURL}
+}
+
+// CreateInvite создает приглашение в чат
+// @Summary Создать приглашение в чат
+// @Description Создает код/ссылку приглашения с опциональным сроком действия и лимитом использований (только для администраторов)
+// @Tags chat-invites
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID чата"
+// @Param request body models.CreateInviteRequest false "Параметры приглашения"
+// @Success 201 {object} models.InviteResponse
+// @Failure 400 {object} map[string]string "Невалидные данные"
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Недостаточно прав"
+// @Failure 404 {object} map[string]string "Чат не найден"
+// @Router /chats/{id}/invites [post]
+func (h *InviteHandler) CreateInvite(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
+
+	chatIDStr := c.Param("id")
+	chatID, err := strconv.Atoi(chatIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chat ID"})
+		return
+	}
+
+	chat, err := h.repo.GetChatByID(c.Request.Context(), chatID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+
+	// Личные чаты не поддерживают приглашения
+	if chat.Type == 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot create invites for personal chat"})
+		return
+	}
+
+	if chat.ArchivedAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot create invites for archived chat"})
+		return
+	}
+
+	// Проверяем права (должен быть администратором)
+	role, err := h.repo.GetUserRoleInChat(c.Request.Context(), userID, chatID)
+	if err != nil || role != 2 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
+
+	var req models.CreateInviteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil && *req.ExpiresAt != "" {
+		parsed, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid expires_at format, expected RFC3339"})
+			return
+		}
+		if !parsed.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+			return
+		}
+		parsed = parsed.UTC()
+		expiresAt = &parsed
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate invite code"})
+		return
+	}
+
+	invite, err := h.repo.CreateInvite(c.Request.Context(), chatID, userID, code, expiresAt, req.MaxUses)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, h.toInviteResponse(invite))
+}
+
+// GetInvites получает список приглашений чата
+// @Summary Получить приглашения чата
+// @Description Возвращает все приглашения чата, включая отозванные и истекшие (только для администраторов)
+// @Tags chat-invites
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID чата"
+// @Success 200 {object} models.InvitesResponse
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Недостаточно прав"
+// @Router /chats/{id}/invites [get]
+func (h *InviteHandler) GetInvites(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
+
+	chatIDStr := c.Param("id")
+	chatID, err := strconv.Atoi(chatIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chat ID"})
+		return
+	}
+
+	// Проверяем права (должен быть администратором)
+	role, err := h.repo.GetUserRoleInChat(c.Request.Context(), userID, chatID)
+	if err != nil || role != 2 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
+
+	invites, err := h.repo.GetChatInvites(c.Request.Context(), chatID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var inviteResponses []models.InviteResponse
+	for i := range invites {
+		inviteResponses = append(inviteResponses, h.toInviteResponse(&invites[i]))
+	}
+
+	c.JSON(http.StatusOK, models.InvitesResponse{
+		Invites: inviteResponses,
+		Total:   len(inviteResponses),
+	})
+}
+
+// RevokeInvite отзывает приглашение
+// @Summary Отозвать приглашение
+// @Description Отзывает приглашение, после чего по нему нельзя присоединиться (только для администраторов)
+// @Tags chat-invites
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID чата"
+// @Param invite_id path int true "ID приглашения"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Недостаточно прав"
+// @Failure 404 {object} map[string]string "Приглашение не найдено или уже отозвано"
+// @Router /chats/{id}/invites/{invite_id} [delete]
+func (h *InviteHandler) RevokeInvite(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
+
+	chatIDStr := c.Param("id")
+	chatID, err := strconv.Atoi(chatIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chat ID"})
+		return
+	}
+
+	inviteIDStr := c.Param("invite_id")
+	inviteID, err := strconv.Atoi(inviteIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invite ID"})
+		return
+	}
+
+	// Проверяем права (должен быть администратором)
+	role, err := h.repo.GetUserRoleInChat(c.Request.Context(), userID, chatID)
+	if err != nil || role != 2 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
+
+	if err := h.repo.RevokeInvite(c.Request.Context(), chatID, inviteID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetInviteRedemptions получает аудит присоединений по приглашению
+// @Summary Получить присоединения по приглашению
+// @Description Возвращает список пользователей, присоединившихся к чату по приглашению (только для администраторов)
+// @Tags chat-invites
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID чата"
+// @Param invite_id path int true "ID приглашения"
+// @Success 200 {object} models.InviteRedemptionsResponse
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Недостаточно прав"
+// @Failure 404 {object} map[string]string "Приглашение не найдено"
+// @Router /chats/{id}/invites/{invite_id}/redemptions [get]
+func (h *InviteHandler) GetInviteRedemptions(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
+
+	chatIDStr := c.Param("id")
+	chatID, err := strconv.Atoi(chatIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chat ID"})
+		return
+	}
+
+	inviteIDStr := c.Param("invite_id")
+	inviteID, err := strconv.Atoi(inviteIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invite ID"})
+		return
+	}
+
+	// Проверяем права (должен быть администратором)
+	role, err := h.repo.GetUserRoleInChat(c.Request.Context(), userID, chatID)
+	if err != nil || role != 2 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
+
+	if _, err := h.repo.GetInviteByID(c.Request.Context(), chatID, inviteID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invite not found"})
+		return
+	}
+
+	redemptions, err := h.repo.GetInviteRedemptions(c.Request.Context(), inviteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var redemptionResponses []models.InviteRedemptionResponse
+	for _, redemption := range redemptions {
+		redemptionResponses = append(redemptionResponses, models.InviteRedemptionResponse{
+			UserID:     redemption.UserID,
+			UserName:   redemption.UserName,
+			RedeemedAt: redemption.RedeemedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	c.JSON(http.StatusOK, models.InviteRedemptionsResponse{
+		InviteID:    inviteID,
+		Redemptions: redemptionResponses,
+		Total:       len(redemptionResponses),
+	})
+}
+
+// JoinByInvite присоединяет текущего пользователя к чату по коду приглашения
+// @Summary Присоединиться к чату по приглашению
+// @Description Добавляет текущего пользователя в чат по коду приглашения. Пользователь должен быть участником РП чата
+// @Tags chat-invites
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Код приглашения"
+// @Success 200 {object} models.JoinByInviteResponse "Пользователь уже участник чата"
+// @Success 201 {object} models.JoinByInviteResponse
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Пользователь не является участником РП"
+// @Failure 404 {object} map[string]string "Приглашение не найдено"
+// @Failure 410 {object} map[string]string "Приглашение отозвано, истекло или исчерпано"
+// @Router /chats/invites/{code}/join [post]
+func (h *InviteHandler) JoinByInvite(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	invite, err := h.repo.GetInviteByCode(ctx, c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invite not found"})
+		return
+	}
+
+	if reason := inviteInactiveReason(invite, time.Now()); reason != "" {
+		c.JSON(http.StatusGone, gin.H{"error": reason})
+		return
+	}
+
+	chat, err := h.repo.GetChatByID(ctx, invite.ChatID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return
+	}
+
+	if chat.ArchivedAt != nil {
+		c.JSON(http.StatusGone, gin.H{"error": "chat is archived"})
+		return
+	}
+
+	// Присоединиться может только участник РП чата
+	isWorkspaceMember, err := h.repo.IsUserInWorkspace(ctx, userID, chat.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check workspace membership"})
+		return
+	}
+	if !isWorkspaceMember {
+		c.JSON(http.StatusForbidden, gin.H{"error": "user is not a member of this workspace"})
+		return
+	}
+
+	// Повторное использование приглашения участником чата не расходует лимит и не пишется в аудит
+	redeemed, err := h.repo.RedeemInvite(ctx, invite.ID, chat.ID, userID, 1)
+	if err != nil {
+		if err.Error() == "user already in chat" {
+			role, _ := h.repo.GetUserRoleInChat(ctx, userID, chat.ID)
+			c.JSON(http.StatusOK, models.JoinByInviteResponse{
+				ChatID:        chat.ID,
+				Role:          role,
+				AlreadyMember: true,
+			})
+			return
+		}
+		log.Printf("JoinByInvite: failed to redeem invite %d by user %d: %v", invite.ID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to join chat by invite"})
+		return
+	}
+	if !redeemed {
+		c.JSON(http.StatusGone, gin.H{"error": "invite is no longer valid"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.JoinByInviteResponse{
+		ChatID: chat.ID,
+		Role:   1,
+	})
+}
+
+// generateInviteCode генерирует случайный URL-безопасный код приглашения
+func generateInviteCode() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// inviteInactiveReason возвращает причину недействительности приглашения или пустую строку
+func inviteInactiveReason(invite *databaseModels.ChatInvite, now time.Time) string {
+	switch {
+	case invite.RevokedAt != nil:
+		return "invite has been revoked"
+	case invite.ExpiresAt != nil && !invite.ExpiresAt.After(now):
+		return "invite has expired"
+	case invite.MaxUses != nil && invite.Uses >= *invite.MaxUses:
+		return "invite usage limit reached"
+	default:
+		return ""
+	}
+}
+
+// toInviteResponse преобразует приглашение в ответ API
+func (h *InviteHandler) toInviteResponse(invite *databaseModels.ChatInvite) models.InviteResponse {
+	response := models.InviteResponse{
+		ID:        invite.ID,
+		ChatID:    invite.ChatID,
+		Code:      invite.Code,
+		Link:      h.baseURL + invite.Code,
+		CreatedBy: invite.CreatedBy,
+		MaxUses:   invite.MaxUses,
+		Uses:      invite.Uses,
+		Revoked:   invite.RevokedAt != nil,
+		Active:    inviteInactiveReason(invite, time.Now()) == "",
+		CreatedAt: invite.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if invite.ExpiresAt != nil {
+		expiresAt := invite.ExpiresAt.UTC().Format(time.RFC3339)
+		response.ExpiresAt = &expiresAt
+	}
+	return response
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/diploma/chat-service/data/databaseModels"
+	"github.com/diploma/chat-service/data/linkpreview"
+	"github.com/diploma/chat-service/presentation/models"
+)
+
+// unfurlMessage загружает превью первой ссылки сообщения (или берет его из кэша),
+// прикрепляет к сообщению и рассылает участникам событие message_updated
+func (h *WSHub) unfurlMessage(message models.MessageResponse) {
+	url := linkpreview.ExtractURL(message.Text)
+	if url == "" {
+		return
+	}
+
+	ctx := context.Background()
+
+	preview, err := h.repo.GetLinkPreview(ctx, url, time.Now().Add(-h.previewTTL))
+	if err != nil {
+		log.Printf("Link preview: failed to read cache for %s: %v", url, err)
+		return
+	}
+
+	if preview == nil {
+		preview = &databaseModels.LinkPreview{URL: url, Status: databaseModels.LinkPreviewStatusOK}
+
+		fetched, err := h.previews.Fetch(ctx, url)
+		if err != nil {
+			log.Printf("Link preview: failed to fetch %s: %v", url, err)
+			preview.Status = databaseModels.LinkPreviewStatusFailed
+		} else {
+			preview.Title = fetched.Title
+			preview.Description = fetched.Description
+			preview.ImageURL = fetched.ImageURL
+			preview.SiteName = fetched.SiteName
+		}
+
+		// Неудачные попытки тоже кэшируются, чтобы не загружать недоступную страницу повторно
+		if err := h.repo.SaveLinkPreview(ctx, preview); err != nil {
+			log.Printf("Link preview: failed to cache %s: %v", url, err)
+			return
+		}
+	}
+
+	if preview.Status != databaseModels.LinkPreviewStatusOK {
+		return
+	}
+
+	if err := h.repo.AttachLinkPreview(ctx, message.ID, url); err != nil {
+		log.Printf("Link preview: failed to attach preview to message %d: %v", message.ID, err)
+		return
+	}
+
+	message.LinkPreview = toLinkPreviewResponse(preview)
+	h.broadcast <- models.WSServerMessage{
+		Type:    "message_updated",
+		ChatID:  message.ChatID,
+		Message: &message,
+	}
+}
+
+// toLinkPreviewResponse преобразует превью ссылки в ответ API
+func toLinkPreviewResponse(preview *databaseModels.LinkPreview) *models.LinkPreview {
+	return &models.LinkPreview{
+		URL:         preview.URL,
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageURL:    preview.ImageURL,
+		SiteName:    preview.SiteName,
+	}
+}
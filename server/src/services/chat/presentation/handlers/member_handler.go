@@ -3,9 +3,12 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/diploma/chat-service/data/databaseModels"
 	"github.com/diploma/chat-service/data/repository"
 	"github.com/diploma/chat-service/presentation/models"
+	"github.com/diploma/shared/dnd"
 	"github.com/gin-gonic/gin"
 )
 
@@ -304,12 +307,242 @@ func (h *MemberHandler) RemoveMember(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// GetNotificationSettings получает настройки уведомлений текущего пользователя в чате
+// @Summary Получить настройки уведомлений в чате
+// @Description Возвращает уровень уведомлений и время, до которого чат заглушен, для текущего пользователя
+// @Tags chat-members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID чата"
+// @Success 200 {object} models.MemberNotificationSettingsResponse
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Пользователь не является участником чата"
+// @Router /chats/{id}/notifications [get]
+func (h *MemberHandler) GetNotificationSettings(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
 
+	chatIDStr := c.Param("id")
+	chatID, err := strconv.Atoi(chatIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chat ID"})
+		return
+	}
 
+	member, err := h.repo.GetMemberNotificationSettings(c.Request.Context(), chatID, userID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "user is not a member of this chat"})
+		return
+	}
 
+	c.JSON(http.StatusOK, toNotificationSettingsResponse(member))
+}
 
+// UpdateNotificationSettings изменяет настройки уведомлений текущего пользователя в чате
+// @Summary Изменить настройки уведомлений в чате
+// @Description Устанавливает уровень уведомлений (all/mentions) и, опционально, время, до которого чат заглушен (RFC3339). Пустое muted_until снимает mute
+// @Tags chat-members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID чата"
+// @Param request body models.UpdateMemberNotificationSettingsRequest true "Настройки уведомлений"
+// @Success 200 {object} models.MemberNotificationSettingsResponse
+// @Failure 400 {object} map[string]string "Невалидные данные"
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 403 {object} map[string]string "Пользователь не является участником чата"
+// @Router /chats/{id}/notifications [put]
+func (h *MemberHandler) UpdateNotificationSettings(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
 
+	chatIDStr := c.Param("id")
+	chatID, err := strconv.Atoi(chatIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid chat ID"})
+		return
+	}
+
+	var req models.UpdateMemberNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
+	var mutedUntil *time.Time
+	if req.MutedUntil != nil && *req.MutedUntil != "" {
+		parsed, err := time.Parse(time.RFC3339, *req.MutedUntil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid muted_until format, expected RFC3339"})
+			return
+		}
+		parsed = parsed.UTC()
+		mutedUntil = &parsed
+	}
 
+	if err := h.repo.UpdateMemberNotificationSettings(c.Request.Context(), chatID, userID, req.NotificationLevel, mutedUntil); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "user is not a member of this chat"})
+		return
+	}
 
+	member, err := h.repo.GetMemberNotificationSettings(c.Request.Context(), chatID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toNotificationSettingsResponse(member))
+}
+
+// GetDNDSchedule получает расписание «не беспокоить» текущего пользователя
+// @Summary Получить расписание «не беспокоить»
+// @Description Возвращает расписание режима «не беспокоить», действующее для всех чатов пользователя
+// @Tags chat-members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.DNDScheduleResponse
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Failure 404 {object} map[string]string "Расписание не задано"
+// @Router /chats/notifications/dnd [get]
+func (h *MemberHandler) GetDNDSchedule(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
 
+	schedule, err := h.repo.GetDNDSchedule(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if schedule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "dnd schedule not set"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toDNDScheduleResponse(schedule))
+}
+
+// UpdateDNDSchedule создает или изменяет расписание «не беспокоить» текущего пользователя
+// @Summary Изменить расписание «не беспокоить»
+// @Description Задает интервал HH:MM–HH:MM (может переходить через полночь) в указанном часовом поясе, в течение которого уведомления не отправляются
+// @Tags chat-members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateDNDScheduleRequest true "Расписание"
+// @Success 200 {object} models.DNDScheduleResponse
+// @Failure 400 {object} map[string]string "Невалидные данные"
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Router /chats/notifications/dnd [put]
+func (h *MemberHandler) UpdateDNDSchedule(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
+
+	var req models.UpdateDNDScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, ok := dnd.ParseClock(req.StartTime); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_time format, expected HH:MM"})
+		return
+	}
+	if _, ok := dnd.ParseClock(req.EndTime); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_time format, expected HH:MM"})
+		return
+	}
+	if req.StartTime == req.EndTime {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_time and end_time must differ"})
+		return
+	}
+
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timezone"})
+		return
+	}
+
+	schedule, err := h.repo.UpsertDNDSchedule(c.Request.Context(), &databaseModels.DNDSchedule{
+		UserID: userID,
+		Schedule: dnd.Schedule{
+			Enabled:   req.Enabled,
+			StartTime: req.StartTime,
+			EndTime:   req.EndTime,
+			Timezone:  req.Timezone,
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, toDNDScheduleResponse(schedule))
+}
+
+// DeleteDNDSchedule удаляет расписание «не беспокоить» текущего пользователя
+// @Summary Удалить расписание «не беспокоить»
+// @Description Отключает режим «не беспокоить» для всех чатов пользователя
+// @Tags chat-members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "Не авторизован"
+// @Router /chats/notifications/dnd [delete]
+func (h *MemberHandler) DeleteDNDSchedule(c *gin.Context) {
+	userID, err := getUserIDFromHeader(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user ID not found"})
+		return
+	}
+
+	if err := h.repo.DeleteDNDSchedule(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// toNotificationSettingsResponse преобразует настройки участника в ответ API
+func toNotificationSettingsResponse(member *databaseModels.UserInChat) models.MemberNotificationSettingsResponse {
+	response := models.MemberNotificationSettingsResponse{
+		ChatID:            member.ChatID,
+		UserID:            member.UserID,
+		NotificationLevel: member.NotificationLevel,
+	}
+	if member.MutedUntil != nil {
+		mutedUntil := member.MutedUntil.UTC().Format(time.RFC3339)
+		response.MutedUntil = &mutedUntil
+		response.Muted = member.MutedUntil.After(time.Now())
+	}
+	return response
+}
+
+// toDNDScheduleResponse преобразует расписание «не беспокоить» в ответ API
+func toDNDScheduleResponse(schedule *databaseModels.DNDSchedule) models.DNDScheduleResponse {
+	return models.DNDScheduleResponse{
+		Enabled:   schedule.Enabled,
+		StartTime: schedule.StartTime,
+		EndTime:   schedule.EndTime,
+		Timezone:  schedule.Timezone,
+		Active:    schedule.ActiveAt(time.Now()),
+		UpdatedAt: schedule.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/diploma/chat-service/data/databaseModels"
 	"github.com/diploma/chat-service/data/repository"
 	"github.com/diploma/chat-service/presentation/models"
 	"github.com/gin-gonic/gin"
@@ -13,10 +14,11 @@ import (
 
 type MessageHandler struct {
 	repo *repository.Repository
+	hub  *WSHub
 }
 
-func NewMessageHandler(repo *repository.Repository) *MessageHandler {
-	return &MessageHandler{repo: repo}
+func NewMessageHandler(repo *repository.Repository, hub *WSHub) *MessageHandler {
+	return &MessageHandler{repo: repo, hub: hub}
 }
 
 // GetMessages получает историю сообщений чата
@@ -110,6 +112,11 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 			Date:     msg.Date,
 			Status:   status,
 			Edited:   false, // TODO: добавить поле edited в БД
+			Kind:     msg.Kind,
+			TaskID:   msg.TaskID,
+		}
+		if msg.LinkPreview != nil {
+			response.LinkPreview = toLinkPreviewResponse(msg.LinkPreview)
 		}
 		messageResponses = append(messageResponses, response)
 	}
@@ -156,6 +163,12 @@ func (h *MessageHandler) CreateMessage(c *gin.Context) {
 		return
 	}
 
+	// Архивный чат доступен только для чтения
+	if chat.ArchivedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "chat is archived and read-only"})
+		return
+	}
+
 	// Проверяем, является ли пользователь участником чата
 	log.Printf("HTTP GetMessages: checking membership for user %d in chat %d", userID, chatID)
 	isMember, err := h.repo.IsUserInChat(c.Request.Context(), userID, chatID)
@@ -207,8 +220,12 @@ func (h *MessageHandler) CreateMessage(c *gin.Context) {
 		Date:     message.Date,
 		Status:   "sent",
 		Edited:   false,
+		Kind:     message.Kind,
 	}
 
+	// Рассылаем сообщение через WebSocket и уведомляем участников
+	h.hub.BroadcastNewMessage(chat, &response)
+
 	c.JSON(http.StatusCreated, response)
 }
 
@@ -254,6 +271,15 @@ func (h *MessageHandler) UpdateMessage(c *gin.Context) {
 		return
 	}
 
+	if message.Kind == databaseModels.MessageKindSystem {
+		c.JSON(http.StatusForbidden, gin.H{"error": "system messages cannot be edited"})
+		return
+	}
+
+	if archived, err := h.isChatArchived(c, message.ChatID); err != nil || archived {
+		return
+	}
+
 	var req models.UpdateMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -321,6 +347,10 @@ func (h *MessageHandler) DeleteMessage(c *gin.Context) {
 		}
 	}
 
+	if archived, err := h.isChatArchived(c, message.ChatID); err != nil || archived {
+		return
+	}
+
 	if err := h.repo.DeleteMessage(c.Request.Context(), messageID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -391,3 +421,19 @@ func (h *MessageHandler) MarkAsRead(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// isChatArchived проверяет, находится ли чат в архиве, и при необходимости сам отвечает клиенту
+func (h *MessageHandler) isChatArchived(c *gin.Context, chatID int) (bool, error) {
+	chat, err := h.repo.GetChatByID(c.Request.Context(), chatID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chat not found"})
+		return false, err
+	}
+
+	if chat.ArchivedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "chat is archived and read-only"})
+		return true, nil
+	}
+
+	return false, nil
+}
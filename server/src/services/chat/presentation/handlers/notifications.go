@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/diploma/chat-service/data/databaseModels"
+	"github.com/diploma/chat-service/data/repository"
+	"github.com/diploma/chat-service/presentation/models"
+	"github.com/diploma/shared/kafka"
+)
+
+// mentionPattern находит упоминания вида @login или @login@example.com
+var mentionPattern = regexp.MustCompile(`@([\p{L}\p{N}._%+\-@]+)`)
+
+// extractMentions возвращает упомянутые в тексте логины в нижнем регистре
+func extractMentions(text string) []string {
+	var mentions []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		mention := strings.ToLower(strings.TrimRight(match[1], ".-@"))
+		if mention != "" {
+			mentions = append(mentions, mention)
+		}
+	}
+	return mentions
+}
+
+// isMentioned проверяет, упомянут ли пользователь: по полному логину или по его части до @
+func isMentioned(login string, mentions []string) bool {
+	login = strings.ToLower(login)
+	localPart := login
+	if idx := strings.Index(login, "@"); idx > 0 {
+		localPart = login[:idx]
+	}
+
+	for _, mention := range mentions {
+		if mention == login || mention == localPart {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldNotify решает, нужно ли уведомлять участника о сообщении с учетом mute, уровня уведомлений и DND
+func shouldNotify(recipient repository.NotificationRecipient, mentioned bool, now time.Time) bool {
+	if recipient.MutedUntil != nil && recipient.MutedUntil.After(now) {
+		return false
+	}
+	if recipient.NotificationLevel == databaseModels.NotificationLevelMentions && !mentioned {
+		return false
+	}
+	return !recipient.DND.ActiveAt(now)
+}
+
+// notifyMembers рассылает уведомления о новом сообщении участникам, которые не просматривают чат:
+// фоновым WebSocket сессиям отправляется событие notification, пользователям без соединения — событие в Kafka
+func (h *WSHub) notifyMembers(chat *databaseModels.Chat, message *models.MessageResponse) {
+	ctx := context.Background()
+
+	recipients, err := h.repo.GetNotificationRecipients(ctx, chat.ID)
+	if err != nil {
+		log.Printf("WebSocket notifyMembers: failed to get recipients for chat %d: %v", chat.ID, err)
+		return
+	}
+
+	// Собираем снимок подключенных клиентов
+	sessions := make(map[int][]*WSClient)
+	viewing := make(map[int]bool)
+	h.mu.RLock()
+	for client := range h.clients {
+		sessions[client.UserID] = append(sessions[client.UserID], client)
+		client.mu.RLock()
+		if client.Chats[chat.ID] {
+			viewing[client.UserID] = true
+		}
+		client.mu.RUnlock()
+	}
+	h.mu.RUnlock()
+
+	mentions := extractMentions(message.Text)
+	now := time.Now()
+
+	for _, recipient := range recipients {
+		// Автор и пользователи, открывшие чат, получают сообщение через new_message
+		if recipient.UserID == message.UserID || viewing[recipient.UserID] {
+			continue
+		}
+
+		mentioned := isMentioned(recipient.Login, mentions)
+		if !shouldNotify(recipient, mentioned, now) {
+			continue
+		}
+
+		if clients := sessions[recipient.UserID]; len(clients) > 0 {
+			notification := models.WSServerMessage{
+				Type:      "notification",
+				ChatID:    chat.ID,
+				Message:   message,
+				Mentioned: mentioned,
+			}
+			for _, client := range clients {
+				select {
+				case client.Send <- notification:
+				default:
+					log.Printf("WebSocket notifyMembers: channel full for user %d, notification dropped", client.UserID)
+				}
+			}
+			continue
+		}
+
+		if h.producer == nil {
+			continue
+		}
+
+		event := kafka.ChatMessageNotificationEvent{
+			ChatID:         chat.ID,
+			ChatName:       chat.Name,
+			MessageID:      message.ID,
+			AuthorID:       message.UserID,
+			AuthorName:     message.UserName,
+			Text:           message.Text,
+			RecipientID:    recipient.UserID,
+			RecipientEmail: recipient.Login,
+			RecipientName:  recipient.Name,
+			Mentioned:      mentioned,
+			SentAt:         time.Unix(int64(message.Date), 0).UTC().Format(time.RFC3339),
+		}
+		if err := h.producer.Publish(kafka.TopicChatMessageNotification, event); err != nil {
+			log.Printf("Failed to publish chat message notification for user %d: %v", recipient.UserID, err)
+		}
+	}
+}
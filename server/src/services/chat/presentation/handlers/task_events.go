@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/diploma/chat-service/presentation/models"
+	"github.com/diploma/shared/kafka"
+)
+
+// HandleTaskCreatedFromMessage публикует в чат системное сообщение о задаче, созданной из сообщения,
+// и рассылает его участникам, открывшим чат
+func (h *WSHub) HandleTaskCreatedFromMessage(ctx context.Context, event kafka.TaskCreatedFromMessageEvent) error {
+	chat, err := h.repo.GetChatByID(ctx, event.ChatID)
+	if err != nil {
+		return err
+	}
+
+	// В архивный чат системные сообщения не пишутся
+	if chat.ArchivedAt != nil {
+		log.Printf("Task %d: chat %d is archived, system message skipped", event.TaskID, event.ChatID)
+		return nil
+	}
+
+	text := fmt.Sprintf("%s создал(а) задачу «%s» из сообщения", event.ActorName, event.TaskTitle)
+	message, err := h.repo.CreateSystemMessage(ctx, chat.ID, event.ActorID, text, event.TaskID)
+	if err != nil {
+		return err
+	}
+
+	// Системные сообщения не требуют уведомлений и превью ссылок
+	h.broadcast <- models.WSServerMessage{
+		Type:   "new_message",
+		ChatID: chat.ID,
+		Message: &models.MessageResponse{
+			ID:       message.ID,
+			ChatID:   message.ChatID,
+			UserID:   message.UserID,
+			UserName: event.ActorName,
+			Text:     message.Text,
+			Date:     message.Date,
+			Status:   "sent",
+			Edited:   false,
+			Kind:     message.Kind,
+			TaskID:   message.TaskID,
+		},
+	}
+
+	return nil
+}
+
+// HandleTaskChanged рассылает изменение задачи исполнителям и наблюдателям задачи, клиентам, открывшим
+// прикрепленные к задаче чаты, и клиентам, подписанным на задачи рабочего пространства.
+// Каждый клиент получает событие один раз
+func (h *WSHub) HandleTaskChanged(event kafka.TaskChangedEvent) {
+	message := models.WSServerMessage{
+		Type:        "task_event",
+		WorkspaceID: event.WorkspaceID,
+		Task: &models.WSTaskEvent{
+			Type:        event.Type,
+			TaskID:      event.TaskID,
+			WorkspaceID: event.WorkspaceID,
+			ChatIDs:     event.ChatIDs,
+			ActorID:     event.ActorID,
+			Title:       event.Title,
+			Status:      event.Status,
+			StatusName:  event.StatusName,
+			Priority:    event.Priority,
+			DueDate:     event.DueDate,
+			AssigneeIDs: event.AssigneeIDs,
+			WatcherIDs:  event.WatcherIDs,
+			ChangedAt:   event.ChangedAt,
+		},
+	}
+
+	sentCount := 0
+	h.mu.RLock()
+	for client := range h.clients {
+		if !client.receivesTaskEvent(event) {
+			continue
+		}
+		select {
+		case client.Send <- message:
+			sentCount++
+		default:
+			log.Printf("WebSocket failed to send task event to client UserID=%d (channel full)", client.UserID)
+		}
+	}
+	h.mu.RUnlock()
+	log.Printf("WebSocket task %d %s event sent to %d clients", event.TaskID, event.Type, sentCount)
+}
+
+// receivesTaskEvent проверяет, что пользователь клиента — исполнитель или наблюдатель задачи
+// либо у клиента открыт чат задачи или подписка на задачи ее рабочего пространства
+func (c *WSClient) receivesTaskEvent(event kafka.TaskChangedEvent) bool {
+	for _, userID := range event.AssigneeIDs {
+		if userID == c.UserID {
+			return true
+		}
+	}
+	for _, userID := range event.WatcherIDs {
+		if userID == c.UserID {
+			return true
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.Tasks[event.WorkspaceID] {
+		return true
+	}
+	for _, chatID := range event.ChatIDs {
+		if c.Chats[chatID] {
+			return true
+		}
+	}
+	return false
+}
+
+// handleSubscribeTasks подписывает клиента на изменения задач рабочего пространства
+func (c *WSClient) handleSubscribeTasks(workspaceID int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	isMember, err := c.Hub.repo.IsUserInWorkspace(ctx, c.UserID, workspaceID)
+	if err != nil {
+		log.Printf("WebSocket handleSubscribeTasks error checking membership: %v", err)
+		c.sendError("INTERNAL_ERROR", "Failed to check membership")
+		return
+	}
+	if !isMember {
+		c.sendError("UNAUTHORIZED", "You are not a member of this workspace")
+		return
+	}
+
+	c.mu.Lock()
+	c.Tasks[workspaceID] = true
+	c.mu.Unlock()
+
+	c.Send <- models.WSServerMessage{
+		Type:        "subscribed_tasks",
+		WorkspaceID: workspaceID,
+		UserID:      c.UserID,
+	}
+}
+
+// handleUnsubscribeTasks отменяет подписку клиента на изменения задач рабочего пространства
+func (c *WSClient) handleUnsubscribeTasks(workspaceID int) {
+	c.mu.Lock()
+	delete(c.Tasks, workspaceID)
+	c.mu.Unlock()
+
+	c.Send <- models.WSServerMessage{
+		Type:        "unsubscribed_tasks",
+		WorkspaceID: workspaceID,
+		UserID:      c.UserID,
+	}
+}
@@ -12,7 +12,10 @@ import (
 	"time"
 
 	"github.com/diploma/chat-service/data/repository"
+	"github.com/diploma/chat-service/data/databaseModels"
+	"github.com/diploma/chat-service/data/linkpreview"
 	"github.com/diploma/chat-service/presentation/models"
+	"github.com/diploma/shared/kafka"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
@@ -31,6 +34,7 @@ type WSClient struct {
 	Send    chan models.WSServerMessage
 	Hub     *WSHub
 	Chats   map[int]bool // Чаты, к которым подключен клиент
+	Tasks   map[int]bool // Рабочие пространства, на задачи которых подписан клиент
 	mu      sync.RWMutex
 }
 
@@ -41,16 +45,22 @@ type WSHub struct {
 	register   chan *WSClient
 	unregister chan *WSClient
 	repo       *repository.Repository
-	mu         sync.RWMutex // Защита map клиентов
+	producer   *kafka.Producer      // Может быть nil, если Kafka не настроена
+	previews   *linkpreview.Fetcher // Может быть nil, если превью ссылок отключены
+	previewTTL time.Duration        // Срок жизни кэша превью ссылок
+	mu         sync.RWMutex         // Защита map клиентов
 }
 
-func NewWSHub(repo *repository.Repository) *WSHub {
+func NewWSHub(repo *repository.Repository, producer *kafka.Producer, previews *linkpreview.Fetcher, previewTTL time.Duration) *WSHub {
 	return &WSHub{
 		clients:    make(map[*WSClient]bool),
 		broadcast:  make(chan models.WSServerMessage, 256),
 		register:   make(chan *WSClient),
 		unregister: make(chan *WSClient),
 		repo:       repo,
+		producer:   producer,
+		previews:   previews,
+		previewTTL: previewTTL,
 	}
 }
 
@@ -105,6 +115,22 @@ func (h *WSHub) Run() {
 	}
 }
 
+// BroadcastNewMessage рассылает новое сообщение участникам, открывшим чат,
+// и уведомляет остальных участников с учетом их настроек уведомлений
+func (h *WSHub) BroadcastNewMessage(chat *databaseModels.Chat, message *models.MessageResponse) {
+	h.broadcast <- models.WSServerMessage{
+		Type:    "new_message",
+		ChatID:  chat.ID, // Важно: заполняем ChatID для broadcast
+		Message: message,
+	}
+
+	go h.notifyMembers(chat, message)
+
+	if h.previews != nil {
+		go h.unfurlMessage(*message)
+	}
+}
+
 // broadcastToOthers отправляет сообщение всем клиентам в чате, кроме указанного пользователя
 func (h *WSHub) broadcastToOthers(chatID int, message models.WSServerMessage, excludeUserID int) {
 	log.Printf("WebSocket broadcasting to others: type=%s, chatID=%d, excludeUserID=%d", message.Type, chatID, excludeUserID)
@@ -251,6 +277,10 @@ func (c *WSClient) handleMessage(msg models.WSClientMessage) {
 		c.handleTyping(msg.ChatID)
 	case "stop_typing":
 		c.handleStopTyping(msg.ChatID)
+	case "subscribe_tasks":
+		c.handleSubscribeTasks(msg.WorkspaceID)
+	case "unsubscribe_tasks":
+		c.handleUnsubscribeTasks(msg.WorkspaceID)
 	default:
 		log.Printf("WebSocket client %d: unknown message type %s", c.UserID, msg.Type)
 		c.sendError("UNKNOWN_TYPE", "Unknown message type")
@@ -344,6 +374,12 @@ func (c *WSClient) handleSendMessage(chatID int, text string) {
 		return
 	}
 
+	// Архивный чат доступен только для чтения
+	if chat.ArchivedAt != nil {
+		c.sendError("CHAT_ARCHIVED", "Chat is archived and read-only")
+		return
+	}
+
 	if chat.Type == 3 {
 		role, _ := c.Hub.repo.GetUserRoleInChat(context.Background(), c.UserID, chatID)
 		if role != 2 {
@@ -367,20 +403,17 @@ func (c *WSClient) handleSendMessage(chatID int, text string) {
 	}
 
 	// Отправляем новое сообщение всем участникам чата
-	c.Hub.broadcast <- models.WSServerMessage{
-		Type:   "new_message",
-		ChatID: chatID, // Важно: заполняем ChatID для broadcast
-		Message: &models.MessageResponse{
-			ID:       message.ID,
-			ChatID:   message.ChatID,
-			UserID:   message.UserID,
-			UserName: userName,
-			Text:     message.Text,
-			Date:     message.Date,
-			Status:   "sent",
-			Edited:   false,
-		},
-	}
+	c.Hub.BroadcastNewMessage(chat, &models.MessageResponse{
+		ID:       message.ID,
+		ChatID:   message.ChatID,
+		UserID:   message.UserID,
+		UserName: userName,
+		Text:     message.Text,
+		Date:     message.Date,
+		Status:   "sent",
+		Edited:   false,
+		Kind:     message.Kind,
+	})
 }
 
 func (c *WSClient) handleTyping(chatID int) {
@@ -521,6 +554,7 @@ func HandleWebSocket(hub *WSHub) gin.HandlerFunc {
 			Send:    make(chan models.WSServerMessage, 256),
 			Hub:     hub,
 			Chats:   make(map[int]bool),
+			Tasks:   make(map[int]bool),
 		}
 
 		client.Hub.register <- client
@@ -3,13 +3,25 @@ package models
 // ChatResponse представляет ответ с данными чата
 // @Description Информация о чате
 type ChatResponse struct {
-	ID           int    `json:"id" example:"1"`
-	Name         string `json:"name" example:"Project Discussion"`
-	Type         int    `json:"type" example:"2"`
-	WorkspaceID  int    `json:"workspace_id" example:"1"`
-	CreatedAt    string `json:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`
-	MembersCount int    `json:"members_count,omitempty" example:"4"`
-	MyRole       int    `json:"my_role,omitempty" example:"2"`
+	ID                int     `json:"id" example:"1"`
+	Name              string  `json:"name" example:"Project Discussion"`
+	Type              int     `json:"type" example:"2"`
+	WorkspaceID       int     `json:"workspace_id" example:"1"`
+	CreatedAt         string  `json:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	MembersCount      int     `json:"members_count,omitempty" example:"4"`
+	MyRole            int     `json:"my_role,omitempty" example:"2"`
+	Archived          bool    `json:"archived" example:"false"`
+	ArchivedAt        *string `json:"archived_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	DeleteScheduledAt *string `json:"delete_scheduled_at,omitempty" example:"2024-01-31T00:00:00Z"`
+	LegalHold         bool    `json:"legal_hold,omitempty" example:"false"`
+	Version           int     `json:"version,omitempty" example:"3"` // совпадает со значением ETag
+}
+
+// UpdateLegalHoldRequest представляет запрос на включение или снятие legal hold
+// @Description Legal hold приостанавливает очистку сообщений и удаление чата
+type UpdateLegalHoldRequest struct {
+	LegalHold *bool   `json:"legal_hold" binding:"required" example:"true"`
+	Reason    *string `json:"reason" binding:"omitempty,max=500" example:"Судебный запрос №123"`
 }
 
 // CreateChatRequest представляет запрос на создание чата
@@ -27,16 +39,26 @@ type UpdateChatRequest struct {
 	Name string `json:"name" binding:"required,min=3,max=100" example:"Updated Project Discussion"`
 }
 
+// ChatDeletionResponse представляет ответ на запрос удаления чата
+// @Description Чат архивирован и будет окончательно удален после истечения срока хранения
+type ChatDeletionResponse struct {
+	ChatID            int    `json:"chat_id" example:"1"`
+	DeleteScheduledAt string `json:"delete_scheduled_at" example:"2024-01-31T00:00:00Z"`
+}
+
 // ChatListItem представляет элемент списка чатов
 // @Description Информация о чате в списке
 type ChatListItem struct {
-	ID           int              `json:"id" example:"1"`
-	Name         string           `json:"name" example:"Project Discussion"`
-	Type         int              `json:"type" example:"2"`
-	WorkspaceID  int              `json:"workspace_id" example:"1"`
-	LastMessage  *LastMessageInfo `json:"last_message,omitempty"`
-	UnreadCount  int              `json:"unread_count" example:"5"`
-	MembersCount int              `json:"members_count" example:"4"`
+	ID                int              `json:"id" example:"1"`
+	Name              string           `json:"name" example:"Project Discussion"`
+	Type              int              `json:"type" example:"2"`
+	WorkspaceID       int              `json:"workspace_id" example:"1"`
+	LastMessage       *LastMessageInfo `json:"last_message,omitempty"`
+	UnreadCount       int              `json:"unread_count" example:"5"`
+	MembersCount      int              `json:"members_count" example:"4"`
+	Archived          bool             `json:"archived" example:"false"`
+	ArchivedAt        *string          `json:"archived_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	DeleteScheduledAt *string          `json:"delete_scheduled_at,omitempty" example:"2024-01-31T00:00:00Z"`
 }
 
 // LastMessageInfo представляет информацию о последнем сообщении
@@ -102,17 +124,121 @@ type UpdateMemberRoleResponse struct {
 	Role   int `json:"role" example:"2"`
 }
 
+// MemberNotificationSettingsResponse представляет настройки уведомлений участника чата
+// @Description Настройки уведомлений текущего пользователя в чате
+type MemberNotificationSettingsResponse struct {
+	ChatID            int     `json:"chat_id" example:"1"`
+	UserID            int     `json:"user_id" example:"3"`
+	NotificationLevel string  `json:"notification_level" example:"mentions"`
+	MutedUntil        *string `json:"muted_until,omitempty" example:"2024-01-01T18:00:00Z"`
+	Muted             bool    `json:"muted" example:"true"`
+}
+
+// UpdateMemberNotificationSettingsRequest представляет запрос на изменение настроек уведомлений
+// @Description Уровень уведомлений (all — все сообщения, mentions — только упоминания) и время, до которого чат заглушен
+type UpdateMemberNotificationSettingsRequest struct {
+	NotificationLevel string  `json:"notification_level" binding:"required,oneof=all mentions" example:"mentions"`
+	MutedUntil        *string `json:"muted_until,omitempty" example:"2024-01-01T18:00:00Z"`
+}
+
+// DNDScheduleResponse представляет расписание режима «не беспокоить»
+// @Description Расписание режима «не беспокоить» пользователя
+type DNDScheduleResponse struct {
+	Enabled   bool   `json:"enabled" example:"true"`
+	StartTime string `json:"start_time" example:"22:00"`
+	EndTime   string `json:"end_time" example:"08:00"`
+	Timezone  string `json:"timezone" example:"Europe/Moscow"`
+	Active    bool   `json:"active" example:"false"`
+	UpdatedAt string `json:"updated_at,omitempty" example:"2024-01-01T12:00:00Z"`
+}
+
+// UpdateDNDScheduleRequest представляет запрос на изменение расписания «не беспокоить»
+// @Description Интервал в формате HH:MM, может переходить через полночь
+type UpdateDNDScheduleRequest struct {
+	Enabled   bool   `json:"enabled" example:"true"`
+	StartTime string `json:"start_time" binding:"required" example:"22:00"`
+	EndTime   string `json:"end_time" binding:"required" example:"08:00"`
+	Timezone  string `json:"timezone,omitempty" example:"Europe/Moscow"`
+}
+
+// CreateInviteRequest представляет запрос на создание приглашения в чат
+// @Description Параметры приглашения: срок действия (RFC3339) и максимальное число использований (опционально)
+type CreateInviteRequest struct {
+	ExpiresAt *string `json:"expires_at,omitempty" example:"2024-01-08T00:00:00Z"`
+	MaxUses   *int    `json:"max_uses,omitempty" binding:"omitempty,min=1" example:"10"`
+}
+
+// InviteResponse представляет приглашение в чат
+// @Description Информация о приглашении в чат
+type InviteResponse struct {
+	ID        int     `json:"id" example:"1"`
+	ChatID    int     `json:"chat_id" example:"1"`
+	Code      string  `json:"code" example:"k3J9sQp0XbL2mN7w"`
+	Link      string  `json:"link" example:"http://localhost:3000/invite/k3J9sQp0XbL2mN7w"`
+	CreatedBy int     `json:"created_by" example:"1"`
+	ExpiresAt *string `json:"expires_at,omitempty" example:"2024-01-08T00:00:00Z"`
+	MaxUses   *int    `json:"max_uses,omitempty" example:"10"`
+	Uses      int     `json:"uses" example:"3"`
+	Revoked   bool    `json:"revoked" example:"false"`
+	Active    bool    `json:"active" example:"true"`
+	CreatedAt string  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// InvitesResponse представляет список приглашений чата
+// @Description Список приглашений чата
+type InvitesResponse struct {
+	Invites []InviteResponse `json:"invites"`
+	Total   int              `json:"total" example:"2"`
+}
+
+// InviteRedemptionResponse представляет запись аудита присоединения по приглашению
+// @Description Пользователь, присоединившийся по приглашению
+type InviteRedemptionResponse struct {
+	UserID     int    `json:"user_id" example:"5"`
+	UserName   string `json:"user_name" example:"Ivan Ivanov"`
+	RedeemedAt string `json:"redeemed_at" example:"2024-01-02T10:00:00Z"`
+}
+
+// InviteRedemptionsResponse представляет аудит присоединений по приглашению
+// @Description Список присоединений по приглашению
+type InviteRedemptionsResponse struct {
+	InviteID    int                        `json:"invite_id" example:"1"`
+	Redemptions []InviteRedemptionResponse `json:"redemptions"`
+	Total       int                        `json:"total" example:"3"`
+}
+
+// JoinByInviteResponse представляет результат присоединения по приглашению
+// @Description Результат присоединения к чату по приглашению
+type JoinByInviteResponse struct {
+	ChatID        int  `json:"chat_id" example:"1"`
+	Role          int  `json:"role" example:"1"`
+	AlreadyMember bool `json:"already_member" example:"false"`
+}
+
 // MessageResponse представляет ответ с данными сообщения
 // @Description Информация о сообщении
 type MessageResponse struct {
-	ID       int    `json:"id" example:"1"`
-	ChatID   int    `json:"chat_id" example:"1"`
-	UserID   int    `json:"user_id" example:"1"`
-	UserName string `json:"user_name" example:"Ivan Ivanov"`
-	Text     string `json:"text" example:"Hello everyone!"`
-	Date     int    `json:"date" example:"1704110400"`
-	Status   string `json:"status" example:"read"`
-	Edited   bool   `json:"edited" example:"false"`
+	ID          int          `json:"id" example:"1"`
+	ChatID      int          `json:"chat_id" example:"1"`
+	UserID      int          `json:"user_id" example:"1"`
+	UserName    string       `json:"user_name" example:"Ivan Ivanov"`
+	Text        string       `json:"text" example:"Hello everyone!"`
+	Date        int          `json:"date" example:"1704110400"`
+	Status      string       `json:"status" example:"read"`
+	Edited      bool         `json:"edited" example:"false"`
+	Kind        string       `json:"kind" example:"text"`           // text или system
+	TaskID      *int         `json:"task_id,omitempty" example:"5"` // Задача системного сообщения
+	LinkPreview *LinkPreview `json:"link_preview,omitempty"`
+}
+
+// LinkPreview представляет превью ссылки из сообщения
+// @Description OpenGraph/HTML метаданные первой ссылки в сообщении
+type LinkPreview struct {
+	URL         string `json:"url" example:"https://example.com/article"`
+	Title       string `json:"title" example:"Example article"`
+	Description string `json:"description,omitempty" example:"Short description of the page"`
+	ImageURL    string `json:"image_url,omitempty" example:"https://example.com/cover.png"`
+	SiteName    string `json:"site_name,omitempty" example:"Example"`
 }
 
 // CreateMessageRequest представляет запрос на создание сообщения
@@ -163,22 +289,43 @@ type MarkAsReadResponse struct {
 
 // WSClientMessage представляет сообщение от клиента через WebSocket
 type WSClientMessage struct {
-	Type   string `json:"type"`
-	ChatID int    `json:"chat_id,omitempty"`
-	Text   string `json:"text,omitempty"`
+	Type        string `json:"type"`
+	ChatID      int    `json:"chat_id,omitempty"`
+	WorkspaceID int    `json:"workspace_id,omitempty"`
+	Text        string `json:"text,omitempty"`
 }
 
 // WSServerMessage представляет сообщение от сервера через WebSocket
 type WSServerMessage struct {
-	Type      string           `json:"type"`
-	Message   *MessageResponse `json:"message,omitempty"`
-	MessageID int              `json:"message_id,omitempty"`
-	ChatID    int              `json:"chat_id,omitempty"`
-	Text      string           `json:"text,omitempty"`
-	EditedAt  int              `json:"edited_at,omitempty"`
-	UserID    int              `json:"user_id,omitempty"`
-	UserName  string           `json:"user_name,omitempty"`
-	Error     *WSError         `json:"error,omitempty"`
+	Type        string           `json:"type"`
+	Message     *MessageResponse `json:"message,omitempty"`
+	MessageID   int              `json:"message_id,omitempty"`
+	ChatID      int              `json:"chat_id,omitempty"`
+	WorkspaceID int              `json:"workspace_id,omitempty"`
+	Text        string           `json:"text,omitempty"`
+	EditedAt    int              `json:"edited_at,omitempty"`
+	UserID      int              `json:"user_id,omitempty"`
+	UserName    string           `json:"user_name,omitempty"`
+	Mentioned   bool             `json:"mentioned,omitempty"`
+	Task        *WSTaskEvent     `json:"task,omitempty"`
+	Error       *WSError         `json:"error,omitempty"`
+}
+
+// WSTaskEvent представляет изменение задачи в WebSocket событии task_event
+type WSTaskEvent struct {
+	Type        string `json:"type"` // created, updated, status_changed, assignees_changed, deleted
+	TaskID      int    `json:"task_id"`
+	WorkspaceID int    `json:"workspace_id"`
+	ChatIDs     []int  `json:"chat_ids"`
+	ActorID     int    `json:"actor_id"`
+	Title       string `json:"title,omitempty"`
+	Status      int    `json:"status,omitempty"`
+	StatusName  string `json:"status_name,omitempty"`
+	Priority    string `json:"priority,omitempty"`
+	DueDate     string `json:"due_date,omitempty"`
+	AssigneeIDs []int  `json:"assignee_ids,omitempty"`
+	WatcherIDs  []int  `json:"watcher_ids,omitempty"`
+	ChangedAt   string `json:"changed_at"`
 }
 
 // WSError представляет ошибку в WebSocket сообщении
@@ -190,17 +337,27 @@ type WSError struct {
 // ChatTaskInfo представляет информацию о задаче в контексте чата
 // @Description Информация о задаче в контексте чата
 type ChatTaskInfo struct {
-	AttachedAt    string `json:"attached_at" example:"2024-01-01T10:00:00Z"`
-	Creator       int    `json:"creator" example:"1"`
-	CreatorName   string `json:"creator_name" example:"Ivan Ivanov"`
-	Date          string `json:"date" example:"2024-01-15"`
-	Description   string `json:"description,omitempty" example:"Implement user authentication system"`
-	ID            int    `json:"id" example:"1"`
-	Status        int    `json:"status" example:"2"`
-	StatusName    string `json:"status_name" example:"В работе"`
-	Title         string `json:"title" example:"Implement authentication"`
-	WorkspaceID   int    `json:"workspace_id" example:"1"`
-	WorkspaceName string `json:"workspace_name" example:"Main Project"`
+	AttachedAt    string          `json:"attached_at" example:"2024-01-01T10:00:00Z"`
+	Creator       int             `json:"creator" example:"1"`
+	CreatorName   string          `json:"creator_name" example:"Ivan Ivanov"`
+	Date          string          `json:"date" example:"2024-01-15"`
+	Description   string          `json:"description,omitempty" example:"Implement user authentication system"`
+	ID            int             `json:"id" example:"1"`
+	Status        int             `json:"status" example:"2"`
+	StatusName    string          `json:"status_name" example:"В работе"`
+	Priority      string          `json:"priority" example:"high"`
+	Labels        []ChatTaskLabel `json:"labels"`
+	Title         string          `json:"title" example:"Implement authentication"`
+	WorkspaceID   int             `json:"workspace_id" example:"1"`
+	WorkspaceName string          `json:"workspace_name" example:"Main Project"`
+}
+
+// ChatTaskLabel представляет метку задачи
+// @Description Метка задачи рабочего пространства
+type ChatTaskLabel struct {
+	ID    int    `json:"id" example:"1"`
+	Name  string `json:"name" example:"backend"`
+	Color string `json:"color" example:"#1e88e5"`
 }
 
 // ChatTasksResponse представляет ответ со списком задач чата
@@ -41,8 +41,8 @@ func main() {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowedHeaders:   []string{"*", "Authorization", "Content-Type", "Accept", "Origin"},
-		ExposedHeaders:   []string{"X-User-ID", "X-User-Roles"},
+		AllowedHeaders:   []string{"*", "Authorization", "Content-Type", "Accept", "Origin", "If-Match"},
+		ExposedHeaders:   []string{"X-User-ID", "X-User-Roles", "ETag"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
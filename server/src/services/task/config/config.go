@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,12 +20,56 @@ type Config struct {
 	UserServiceURL string
 	WorkspaceServiceURL string
 	ChatServiceURL string
+	KafkaBrokers   []string
+	ReminderInterval    time.Duration
+	ReminderDefaultDays []int
+	RecurrenceInterval  time.Duration
+	AnalyticsCacheTTL   time.Duration
 }
 
 func Load() (*Config, error) {
 	// Загружаем .env файл если он существует (не критично если его нет)
 	_ = godotenv.Load()
 
+	kafkaBrokersStr := getEnv("KAFKA_BROKERS", "")
+	var kafkaBrokers []string
+	if kafkaBrokersStr != "" {
+		// Разделяем по запятой и убираем пробелы
+		parts := strings.Split(kafkaBrokersStr, ",")
+		for _, part := range parts {
+			trimmed := strings.TrimSpace(part)
+			if trimmed != "" {
+				kafkaBrokers = append(kafkaBrokers, trimmed)
+			}
+		}
+	}
+
+	// Интервал запуска планировщика напоминаний о сроках задач (в минутах)
+	reminderInterval := 15
+	if minutes, err := strconv.Atoi(getEnv("TASK_REMINDER_INTERVAL_MINUTES", "15")); err == nil && minutes > 0 {
+		reminderInterval = minutes
+	}
+
+	// За сколько дней до срока напоминать о задачах, если РП не задало свои настройки
+	var reminderDays []int
+	for _, part := range strings.Split(getEnv("TASK_REMINDER_DEFAULT_DAYS", "1"), ",") {
+		if days, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && days >= 0 && days <= 30 {
+			reminderDays = append(reminderDays, days)
+		}
+	}
+
+	// Интервал запуска планировщика повторяющихся задач (в минутах)
+	recurrenceInterval := 60
+	if minutes, err := strconv.Atoi(getEnv("TASK_RECURRENCE_INTERVAL_MINUTES", "60")); err == nil && minutes > 0 {
+		recurrenceInterval = minutes
+	}
+
+	// Сколько хранятся в кэше рассчитанные отчеты аналитики (в секундах, 0 — без кэша)
+	analyticsCacheTTL := 300
+	if seconds, err := strconv.Atoi(getEnv("TASK_ANALYTICS_CACHE_TTL_SECONDS", "300")); err == nil && seconds >= 0 {
+		analyticsCacheTTL = seconds
+	}
+
 	return &Config{
 		Port:               getEnv("PORT", "8085"),
 		DBHost:             getEnv("DB_HOST", "postgres"),
@@ -34,6 +81,11 @@ func Load() (*Config, error) {
 		UserServiceURL:     getEnv("USER_SERVICE_URL", "http://user-service:8082"),
 		WorkspaceServiceURL: getEnv("WORKSPACE_SERVICE_URL", "http://workspace-service:8083"),
 		ChatServiceURL:     getEnv("CHAT_SERVICE_URL", "http://chat-service:8084"),
+		KafkaBrokers:       kafkaBrokers,
+		ReminderInterval:    time.Duration(reminderInterval) * time.Minute,
+		ReminderDefaultDays: reminderDays,
+		RecurrenceInterval:  time.Duration(recurrenceInterval) * time.Minute,
+		AnalyticsCacheTTL:   time.Duration(analyticsCacheTTL) * time.Second,
 	}, nil
 }
 
@@ -4,14 +4,19 @@ import "time"
 
 // Task представляет задачу
 type Task struct {
-	ID          int       `db:"id"`
-	Creator     int       `db:"creator"`
-	WorkspaceID int       `db:"workspacesid"`
-	Title       string    `db:"title"`
-	Description *string   `db:"description"`
-	Date        time.Time `db:"date"`
-	Status      int       `db:"status"`
-	CreatedAt   time.Time `db:"created_at,omitempty"`
+	ID              int       `db:"id"`
+	Creator         int       `db:"creator"`
+	WorkspaceID     int       `db:"workspacesid"`
+	Title           string    `db:"title"`
+	Description     *string   `db:"description"`
+	Date            time.Time `db:"date"`
+	Status          int       `db:"status"`
+	Priority        string    `db:"priority"` // одно из TaskPriority*
+	ParentID        *int      `db:"parent_id"`
+	SeriesID        *int      `db:"series_id"`
+	SourceMessageID *int      `db:"source_message_id"` // сообщение чата, из которого создана задача
+	EstimateMinutes *int      `db:"estimate_minutes"`  // оценка трудозатрат
+	CreatedAt       time.Time `db:"created_at,omitempty"`
 }
 
 // TaskWithDetails представляет задачу с дополнительной информацией
@@ -26,9 +31,343 @@ type TaskWithDetails struct {
 	Description   *string   `db:"description"`
 	Date          time.Time `db:"date"`
 	Status        int       `db:"status"`
+	StatusName    string    `db:"status_name"`
+	Priority      string    `db:"priority"`
+	Labels        []Label   `db:"labels"`
 	AssigneeCount int       `db:"assignee_count"`
 	ChatCount     int       `db:"chat_count"`
+	CommentCount  int       `db:"comment_count"`
+	ParentID      *int      `db:"parent_id"`
+	SubtaskCount  int       `db:"subtask_count"`
+	SubtaskDone   int       `db:"subtask_done_count"`
+	OpenBlockers  int       `db:"open_blocker_count"`
+	Overdue       bool      `db:"overdue"`
+	SeriesID      *int      `db:"series_id"`
+	SourceMessageID *int    `db:"source_message_id"`
+	EstimateMinutes *int    `db:"estimate_minutes"`
+	LoggedMinutes int       `db:"logged_minutes"` // учтенное время по завершенным записям
 	CreatedAt     time.Time `db:"created_at"`
+	Version       int       `db:"version"` // увеличивается при каждом изменении полей и статуса задачи
+}
+
+// Приоритеты задач
+const (
+	TaskPriorityLow      = "low"
+	TaskPriorityNormal   = "normal"
+	TaskPriorityHigh     = "high"
+	TaskPriorityCritical = "critical"
+)
+
+// Label представляет метку задач рабочего пространства
+type Label struct {
+	ID          int       `db:"id"`
+	WorkspaceID int       `db:"workspacesid"`
+	Name        string    `db:"name"`
+	Color       string    `db:"color"` // #rrggbb
+	CreatedBy   *int      `db:"created_by"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// TimeEntry запись учета времени исполнителя по задаче.
+// Запись без EndedAt — запущенный таймер
+type TimeEntry struct {
+	ID        int        `db:"id"`
+	TaskID    int        `db:"tasksid"`
+	UserID    int        `db:"usersid"`
+	UserName  string     `db:"user_name"`
+	StartedAt time.Time  `db:"started_at"`
+	EndedAt   *time.Time `db:"ended_at"`
+	Minutes   int        `db:"minutes"` // длительность завершенной записи
+	Note      *string    `db:"note"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// TimeReportFilter параметры отчета по учтенному времени рабочего пространства
+type TimeReportFilter struct {
+	WorkspaceID int
+	UserID      *int
+	TaskID      *int
+	From        *time.Time // дата начала записи не раньше
+	To          *time.Time // дата начала записи не позже
+}
+
+// TimeReportRow учтенное время пользователя по задаче
+type TimeReportRow struct {
+	UserID    int    `db:"usersid"`
+	UserName  string `db:"user_name"`
+	TaskID    int    `db:"tasksid"`
+	TaskTitle string `db:"task_title"`
+	Entries   int    `db:"entries"`
+	Minutes   int    `db:"minutes"`
+}
+
+// AnalyticsFilter параметры аналитики рабочего пространства
+type AnalyticsFilter struct {
+	WorkspaceID int
+	LabelID     *int      // только задачи с меткой
+	From        time.Time // начало периода
+	To          time.Time // конец периода (включительно)
+}
+
+// StatusTimeRow время, проведенное задачами в статусе.
+// Учитываются пребывания в статусе, закончившиеся в периоде
+type StatusTimeRow struct {
+	Status       int     `db:"status"`
+	StatusName   string  `db:"status_name"`
+	Category     string  `db:"category"`
+	Transitions  int     `db:"transitions"`   // сколько раз задачи покинули статус
+	AvgSeconds   float64 `db:"avg_seconds"`   // среднее время в статусе
+	CurrentTasks int     `db:"current_tasks"` // задач в статусе сейчас
+}
+
+// CycleTimeSummary время выполнения задач, завершенных в периоде
+type CycleTimeSummary struct {
+	Completed       int      `db:"completed"`
+	AvgCycleSeconds *float64 `db:"avg_cycle_seconds"` // от первого перехода в работу до завершения
+	AvgLeadSeconds  *float64 `db:"avg_lead_seconds"`  // от создания до завершения
+}
+
+// ThroughputWeek созданные и закрытые за неделю задачи
+type ThroughputWeek struct {
+	WeekStart time.Time `db:"week_start"`
+	Created   int       `db:"created"`
+	Completed int       `db:"completed"`
+	Cancelled int       `db:"cancelled"`
+}
+
+// BurndownDay состояние задач на конец дня
+type BurndownDay struct {
+	Day       time.Time `db:"day"`
+	Remaining int       `db:"remaining"` // незавершенных задач на конец дня
+	Created   int       `db:"created"`
+	Completed int       `db:"completed"`
+}
+
+// TaskSeries серия повторяющихся задач
+type TaskSeries struct {
+	ID          int        `db:"id"`
+	WorkspaceID int        `db:"workspacesid"`
+	Creator     *int       `db:"creator"`
+	Rule        string     `db:"rule"` // правило повторения в формате RRULE
+	Mode        string     `db:"mode"` // одно из SeriesMode*
+	StartDate   time.Time  `db:"start_date"`
+	Occurrences int        `db:"occurrences"` // количество созданных задач серии
+	Active      bool       `db:"active"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at"`
+	CancelledAt *time.Time `db:"cancelled_at"`
+}
+
+// Режимы создания следующей задачи серии
+const (
+	SeriesModeOnComplete = "on_complete" // После завершения текущей задачи
+	SeriesModeSchedule   = "schedule"    // По расписанию, когда наступает срок текущей задачи
+)
+
+// TaskTemplate шаблон задачи рабочего пространства. Title и Description могут содержать переменные {{name}}
+type TaskTemplate struct {
+	ID              int       `db:"id"`
+	WorkspaceID     int       `db:"workspacesid"`
+	Name            string    `db:"name"`
+	Title           string    `db:"title"`
+	Description     *string   `db:"description"`
+	Priority        string    `db:"priority"`
+	EstimateMinutes *int      `db:"estimate_minutes"`
+	AssigneeIDs     []int     `db:"assignee_ids"` // исполнители по умолчанию
+	LabelIDs        []int     `db:"label_ids"`
+	Subtasks        []TaskTemplateSubtask
+	CreatedBy       *int      `db:"created_by"`
+	CreatedAt       time.Time `db:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at"`
+}
+
+// TaskTemplateSubtask подзадача шаблона
+type TaskTemplateSubtask struct {
+	ID              int     `db:"id"`
+	Position        int     `db:"position"`
+	Title           string  `db:"title"`
+	Description     *string `db:"description"`
+	Priority        string  `db:"priority"`
+	EstimateMinutes *int    `db:"estimate_minutes"`
+	DueOffsetDays   int     `db:"due_offset_days"` // смещение срока относительно основной задачи
+	AssigneeIDs     []int   `db:"assignee_ids"`
+	LabelIDs        []int   `db:"label_ids"`
+}
+
+// TaskView сохраненное представление списка задач рабочего пространства
+type TaskView struct {
+	ID          int        `db:"id"`
+	WorkspaceID int        `db:"workspacesid"`
+	OwnerID     int        `db:"owner_id"`
+	OwnerName   string     `db:"owner_name"`
+	Name        string     `db:"name"`
+	IsShared    bool       `db:"is_shared"` // доступно всем участникам РП
+	Statuses    []int      `db:"statuses"`
+	Priorities  []string   `db:"priorities"`
+	LabelIDs    []int      `db:"label_ids"`
+	AssigneeID  *int       `db:"assignee_id"`
+	CreatorID   *int       `db:"creator_id"`
+	ChatID      *int       `db:"chat_id"`
+	Overdue     *bool      `db:"overdue"`
+	DueFrom     *time.Time `db:"due_from"`
+	DueTo       *time.Time `db:"due_to"`
+	Search      string     `db:"search"`
+	SortField   string     `db:"sort_field"` // одно из TaskSort*
+	SortDesc    bool       `db:"sort_desc"`
+	GroupBy     *string    `db:"group_by"` // одно из TaskGroupBy*
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
+
+// TaskDraft задача, создаваемая вместе с исполнителями и метками (например, по шаблону)
+type TaskDraft struct {
+	Task        Task
+	AssigneeIDs []int
+	LabelIDs    []int
+}
+
+// TaskExport задача рабочего пространства в формате импорта и экспорта
+type TaskExport struct {
+	Title          string    `db:"title"`
+	Description    *string   `db:"description"`
+	Date           time.Time `db:"date"`
+	StatusName     string    `db:"status_name"`
+	AssigneeLogins []string  `db:"assignee_logins"`
+}
+
+// TaskLink представляет задачу, связанную зависимостью (блокирующую или блокируемую)
+type TaskLink struct {
+	ID             int       `db:"id"`
+	Title          string    `db:"title"`
+	Status         int       `db:"status"`
+	StatusName     string    `db:"status_name"`
+	StatusCategory string    `db:"status_category"`
+	LinkedAt       time.Time `db:"linked_at"`
+}
+
+// IsResolved проверяет, что связанная задача завершена или отменена
+func (l *TaskLink) IsResolved() bool {
+	return l.StatusCategory == StatusCategoryDone || l.StatusCategory == StatusCategoryCancelled
+}
+
+// TaskFilter параметры выборки задач рабочего пространства
+type TaskFilter struct {
+	WorkspaceID int
+	UserID      int // пользователь, от имени которого выполняется выборка
+	Statuses    []int
+	AssigneeID  *int
+	CreatorID   *int
+	DueFrom     *time.Time
+	DueTo       *time.Time
+	ChatID      *int
+	Overdue     *bool
+	Priorities  []string
+	LabelIDs    []int // задачи хотя бы с одной из меток
+	Search      string
+	SortField   string // одно из TaskSort*
+	SortDesc    bool
+	Cursor      *TaskCursor
+	Limit       int
+}
+
+// BoardFilter параметры выборки задач Kanban-доски рабочего пространства
+type BoardFilter struct {
+	AssigneeID *int
+	Limit      int // максимальное количество задач в колонке
+}
+
+// BulkTaskOperation изменения, применяемые массовой операцией к каждой задаче
+type BulkTaskOperation struct {
+	Status          *TaskStatus // новый статус; задачи, уже имеющие его, не меняются
+	AddAssignees    []int
+	RemoveAssignees []int
+	AddLabels       []int
+	RemoveLabels    []int
+	Delete          bool
+}
+
+// ReminderSettings настройки напоминаний о сроках задач рабочего пространства
+type ReminderSettings struct {
+	WorkspaceID   int        `db:"workspacesid"`
+	OffsetDays    []int      `db:"offset_days"` // за сколько дней до срока отправлять напоминания
+	NotifyOverdue bool       `db:"notify_overdue"`
+	IsDefault     bool       // РП не задало свои настройки
+	UpdatedBy     *int       `db:"updated_by"`
+	UpdatedAt     *time.Time `db:"updated_at"`
+}
+
+// TaskReminder напоминание о сроке задачи, которое нужно отправить исполнителям
+type TaskReminder struct {
+	TaskID        int
+	TaskTitle     string
+	WorkspaceID   int
+	WorkspaceName string
+	StatusName    string
+	DueDate       time.Time
+	DaysLeft      int    // дней до срока; отрицательное значение — срок прошел
+	Kind          string // одно из ReminderKind*
+	OffsetDays    int
+}
+
+// Виды напоминаний о сроках задач
+const (
+	ReminderKindDue     = "due"     // Срок приближается или наступил сегодня
+	ReminderKindOverdue = "overdue" // Срок прошел
+)
+
+// CalendarFeed календарная подписка пользователя на сроки задач
+type CalendarFeed struct {
+	ID             int        `db:"id"`
+	UserID         int        `db:"usersid"`
+	WorkspaceID    *int       `db:"workspacesid"` // nil — задачи всех РП пользователя
+	WorkspaceName  *string    `db:"workspace_name"`
+	CreatedAt      time.Time  `db:"created_at"`
+	LastAccessedAt *time.Time `db:"last_accessed_at"`
+}
+
+// CalendarEvent задача со сроком в календарной ленте
+type CalendarEvent struct {
+	TaskID         int
+	WorkspaceName  string
+	Title          string
+	Description    *string
+	DueDate        time.Time
+	StatusName     string
+	StatusCategory string
+	Priority       string
+	Sequence       int       // количество изменений задачи
+	UpdatedAt      time.Time // время последнего изменения задачи
+}
+
+// TaskCursor позиция последней полученной задачи для постраничной выборки
+type TaskCursor struct {
+	Value string `json:"v"`  // значение поля сортировки
+	ID    int    `json:"id"` // ID задачи
+}
+
+// Поля сортировки задач
+const (
+	TaskSortDate    = "date"    // Срок
+	TaskSortTitle   = "title"   // Название
+	TaskSortStatus  = "status"  // Код статуса
+	TaskSortCreated = "created" // Порядок создания
+)
+
+// Группировки задач сохраненного представления
+const (
+	TaskGroupByStatus   = "status"   // По статусу
+	TaskGroupByPriority = "priority" // По приоритету
+	TaskGroupByLabel    = "label"    // По метке (задача попадает в группу каждой своей метки)
+)
+
+// TaskGroupNone — ключ группы задач без меток
+const TaskGroupNone = "none"
+
+// TaskGroupCount количество задач фильтра в группе представления
+type TaskGroupCount struct {
+	Key   string
+	Name  string
+	Count int
 }
 
 // UserInTask представляет связь пользователя с задачей (исполнитель)
@@ -47,6 +386,15 @@ type TaskAssignee struct {
 	AssignedAt string `db:"assigned_at"`
 }
 
+// TaskWatcher представляет наблюдателя задачи
+type TaskWatcher struct {
+	UserID    int       `db:"user_id"`
+	Login     string    `db:"login"`
+	Name      string    `db:"name"`
+	Surname   string    `db:"surname"`
+	WatchedAt time.Time `db:"watched_at"`
+}
+
 // TaskInChat представляет связь задачи с чатом
 type TaskInChat struct {
 	ID     int `db:"id"`
@@ -63,46 +411,175 @@ type TaskChat struct {
 	AttachedAt  string `db:"attached_at"`
 }
 
+// ChatMessage представляет сообщение чата, из которого создается задача
+type ChatMessage struct {
+	ID          int        `db:"id"`
+	ChatID      int        `db:"chatsid"`
+	WorkspaceID int        `db:"workspacesid"`
+	Text        string     `db:"text"`
+	ArchivedAt  *time.Time `db:"archived_at"`
+}
+
+// TaskComment представляет комментарий к задаче
+type TaskComment struct {
+	ID         int             `db:"id"`
+	TaskID     int             `db:"tasksid"`
+	AuthorID   *int            `db:"author_id"`
+	AuthorName *string         `db:"author_name"`
+	Text       string          `db:"text"`
+	CreatedAt  time.Time       `db:"created_at"`
+	UpdatedAt  *time.Time      `db:"updated_at"`
+	Mentions   []WorkspaceUser `db:"-"`
+}
+
+// WorkspaceUser представляет участника рабочего пространства (упоминание, получатель уведомления)
+type WorkspaceUser struct {
+	UserID int    `db:"user_id"`
+	Login  string `db:"login"`
+	Name   string `db:"name"`
+}
+
 // TaskChange представляет изменение задачи (история)
 type TaskChange struct {
-	ID          int       `db:"id"`
-	Description string    `db:"description"`
-	TaskID      int       `db:"tasksid"`
-	ChangedAt   time.Time `db:"changed_at,omitempty"`
+	ID          int        `db:"id"`
+	TaskID      int        `db:"tasksid"`
+	ActorID     *int       `db:"actor_id"`
+	ActorName   *string    `db:"actor_name"`
+	Field       string     `db:"field"`
+	OldValue    *string    `db:"old_value"`
+	NewValue    *string    `db:"new_value"`
+	Description string     `db:"description"`
+	Source      string     `db:"source"`
+	ChangedAt   *time.Time `db:"changed_at"`
+}
+
+// TaskHistoryFilter параметры выборки истории изменений задачи
+type TaskHistoryFilter struct {
+	TaskID  int
+	Field   string
+	ActorID *int
+	Limit   int
+	Offset  int
 }
 
-// Константы статусов задач
+// Поля задачи, изменения которых записываются в историю
 const (
-	TaskStatusCreated   = 1 // Создана
-	TaskStatusInWork    = 2 // В работе
-	TaskStatusReview    = 3 // На проверке
-	TaskStatusCompleted = 4 // Завершена
-	TaskStatusCancelled = 5 // Отменена
+	ChangeFieldCreated     = "created"     // Создание задачи
+	ChangeFieldTask        = "task"        // Изменение без указания поля (старые записи)
+	ChangeFieldTitle       = "title"       // Название
+	ChangeFieldDescription = "description" // Описание
+	ChangeFieldDate        = "date"        // Срок
+	ChangeFieldStatus      = "status"      // Статус (значения — коды статусов)
+	ChangeFieldAssignee    = "assignee"    // Исполнители (значения — ID пользователей)
+	ChangeFieldChat        = "chat"        // Прикрепленные чаты (значения — ID чатов)
+	ChangeFieldComment     = "comment"     // Комментарии (значения — ID комментариев)
+	ChangeFieldParent      = "parent"      // Родительская задача (значения — ID задач)
+	ChangeFieldDependency  = "dependency"  // Блокирующие задачи (значения — ID задач)
+	ChangeFieldPosition    = "position"    // Позиция в колонке доски (значения — позиции с нуля)
+	ChangeFieldPriority    = "priority"    // Приоритет
+	ChangeFieldLabel       = "label"       // Метки (значения — ID меток)
+	ChangeFieldEstimate    = "estimate"    // Оценка трудозатрат (значения — минуты)
 )
 
-// IsValidTaskStatus проверяет валидность статуса задачи
-func IsValidTaskStatus(status int) bool {
-	return status >= TaskStatusCreated && status <= TaskStatusCancelled
-}
-
-// GetTaskStatusName возвращает название статуса задачи
-func GetTaskStatusName(status int) string {
-	switch status {
-	case TaskStatusCreated:
-		return "Создана"
-	case TaskStatusInWork:
-		return "В работе"
-	case TaskStatusReview:
-		return "На проверке"
-	case TaskStatusCompleted:
-		return "Завершена"
-	case TaskStatusCancelled:
-		return "Отменена"
-	default:
-		return "Неизвестный статус"
+// Источники изменений задач
+const (
+	ChangeSourceREST       = "rest"       // REST API
+	ChangeSourceChat       = "chat"       // Действие из чата
+	ChangeSourceAutomation = "automation" // Автоматическое изменение сервисом
+	ChangeSourceLegacy     = "legacy"     // Записи, перенесенные из текстовой истории
+	ChangeSourceBulk       = "bulk"       // Массовая операция над задачами
+	ChangeSourceImport     = "import"     // Импорт задач из CSV или JSON
+)
+
+// Категории статусов задач
+const (
+	StatusCategoryOpen       = "open"        // Работа не начата
+	StatusCategoryInProgress = "in_progress" // Работа ведется
+	StatusCategoryDone       = "done"        // Задача выполнена
+	StatusCategoryCancelled  = "cancelled"   // Задача отменена
+)
+
+// Роли, которым может быть разрешен переход между статусами
+const (
+	TransitionRoleMember   = "member"   // Любой участник РП
+	TransitionRoleLeader   = "leader"   // Руководитель РП
+	TransitionRoleCreator  = "creator"  // Создатель задачи
+	TransitionRoleAssignee = "assignee" // Исполнитель задачи
+)
+
+// TaskStatus представляет статус задачи в workflow рабочего пространства
+type TaskStatus struct {
+	Code      int    `db:"code"`
+	Name      string `db:"name"`
+	Category  string `db:"category"`
+	IsInitial bool   `db:"is_initial"`
+	Position  int    `db:"position"`
+}
+
+// TaskStatusTransition представляет допустимый переход между статусами
+type TaskStatusTransition struct {
+	FromStatus   int      `db:"from_status"`
+	ToStatus     int      `db:"to_status"`
+	AllowedRoles []string `db:"allowed_roles"`
+}
+
+// Workflow описывает статусы задач рабочего пространства и переходы между ними
+type Workflow struct {
+	WorkspaceID int
+	IsDefault   bool // true, если у РП нет собственного workflow и используется workflow по умолчанию
+	Statuses    []TaskStatus
+	Transitions []TaskStatusTransition
+}
+
+// Status возвращает статус по коду
+func (w *Workflow) Status(code int) (*TaskStatus, bool) {
+	for i := range w.Statuses {
+		if w.Statuses[i].Code == code {
+			return &w.Statuses[i], true
+		}
 	}
+	return nil, false
 }
 
+// InitialStatus возвращает начальный статус workflow
+func (w *Workflow) InitialStatus() *TaskStatus {
+	for i := range w.Statuses {
+		if w.Statuses[i].IsInitial {
+			return &w.Statuses[i]
+		}
+	}
+	if len(w.Statuses) > 0 {
+		return &w.Statuses[0]
+	}
+	return nil
+}
 
+// Transition возвращает переход между статусами, если он разрешен workflow
+func (w *Workflow) Transition(from, to int) (*TaskStatusTransition, bool) {
+	for i := range w.Transitions {
+		if w.Transitions[i].FromStatus == from && w.Transitions[i].ToStatus == to {
+			return &w.Transitions[i], true
+		}
+	}
+	return nil, false
+}
 
+// IsValidStatusCategory проверяет валидность категории статуса
+func IsValidStatusCategory(category string) bool {
+	switch category {
+	case StatusCategoryOpen, StatusCategoryInProgress, StatusCategoryDone, StatusCategoryCancelled:
+		return true
+	default:
+		return false
+	}
+}
 
+// IsValidTransitionRole проверяет валидность роли перехода
+func IsValidTransitionRole(role string) bool {
+	switch role {
+	case TransitionRoleMember, TransitionRoleLeader, TransitionRoleCreator, TransitionRoleAssignee:
+		return true
+	default:
+		return false
+	}
+}
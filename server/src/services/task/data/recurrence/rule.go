@@ -0,0 +1,275 @@
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Частоты повторения
+const (
+	FrequencyDaily   = "DAILY"
+	FrequencyWeekly  = "WEEKLY"
+	FrequencyMonthly = "MONTHLY"
+)
+
+const (
+	maxInterval = 365
+	maxCount    = 1000
+	dateLayout  = "20060102"
+)
+
+// weekdayCodes сопоставляет коды дней недели RRULE с днями недели
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule правило повторения задачи — подмножество RRULE (RFC 5545):
+// FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, BYDAY (для WEEKLY), BYMONTHDAY (для MONTHLY), COUNT и UNTIL
+type Rule struct {
+	Frequency string
+	Interval  int
+	Weekdays  []time.Weekday // дни недели для WEEKLY; пусто — день недели первой задачи
+	MonthDay  int            // день месяца для MONTHLY (1..31, -1 — последний день); 0 — день первой задачи
+	Count     int            // максимальное количество задач серии; 0 — без ограничения
+	Until     *time.Time     // последняя допустимая дата задачи
+}
+
+// Parse разбирает правило в формате RRULE, например "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("empty rule")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		val = strings.ToUpper(strings.TrimSpace(val))
+		if !ok || key == "" || val == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate rule part %s", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			rule.Frequency = val
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(val)
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				weekday, ok := weekdayCodes[code]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY value %q", code)
+				}
+				rule.Weekdays = append(rule.Weekdays, weekday)
+			}
+		case "BYMONTHDAY":
+			// 0 в правиле означает «день первой задачи» только при отсутствии BYMONTHDAY
+			rule.MonthDay, err = strconv.Atoi(val)
+			if err == nil && rule.MonthDay == 0 {
+				err = fmt.Errorf("month day must not be zero")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err == nil && rule.Count == 0 {
+				err = fmt.Errorf("count must be positive")
+			}
+		case "UNTIL":
+			// Допускается дата (20261231) или дата со временем (20261231T235959Z); время отбрасывается
+			if len(val) > len(dateLayout) {
+				val = val[:len(dateLayout)]
+			}
+			var until time.Time
+			until, err = time.Parse(dateLayout, val)
+			rule.Until = &until
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", key, val)
+		}
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Validate проверяет, что правило входит в поддерживаемое подмножество RRULE
+func (r *Rule) Validate() error {
+	switch r.Frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+	default:
+		return fmt.Errorf("invalid frequency, expected DAILY, WEEKLY or MONTHLY")
+	}
+	if r.Interval < 1 || r.Interval > maxInterval {
+		return fmt.Errorf("interval must be between 1 and %d", maxInterval)
+	}
+	if len(r.Weekdays) > 0 && r.Frequency != FrequencyWeekly {
+		return fmt.Errorf("BYDAY is supported only for WEEKLY frequency")
+	}
+	if r.MonthDay != 0 {
+		if r.Frequency != FrequencyMonthly {
+			return fmt.Errorf("BYMONTHDAY is supported only for MONTHLY frequency")
+		}
+		if r.MonthDay < -1 || r.MonthDay > 31 {
+			return fmt.Errorf("month day must be between 1 and 31 or -1 for the last day")
+		}
+	}
+	if r.Count < 0 || r.Count > maxCount {
+		return fmt.Errorf("count must be between 1 and %d", maxCount)
+	}
+	return nil
+}
+
+// String возвращает правило в формате RRULE
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Frequency}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.Weekdays) > 0 {
+		codes := make([]string, 0, len(r.Weekdays))
+		for _, weekday := range sortedWeekdays(r.Weekdays) {
+			for code, day := range weekdayCodes {
+				if day == weekday {
+					codes = append(codes, code)
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.MonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.MonthDay))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format(dateLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next возвращает дату задачи серии, следующей за датой after.
+// start — дата первой задачи серии, от нее отсчитываются интервалы.
+// occurrences — количество уже созданных задач серии.
+// Возвращает false, если серия завершена по COUNT или UNTIL
+func (r *Rule) Next(start, after time.Time, occurrences int) (time.Time, bool) {
+	if r.Count > 0 && occurrences >= r.Count {
+		return time.Time{}, false
+	}
+
+	start = truncateDay(start)
+	after = truncateDay(after)
+
+	var next time.Time
+	switch r.Frequency {
+	case FrequencyDaily:
+		next = after.AddDate(0, 0, r.Interval)
+	case FrequencyWeekly:
+		next = r.nextWeekly(start, after)
+	case FrequencyMonthly:
+		next = r.nextMonthly(start, after)
+	default:
+		return time.Time{}, false
+	}
+
+	if r.Until != nil && next.After(truncateDay(*r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// nextWeekly находит ближайший после after день из BYDAY в неделе, кратной интервалу от недели start
+func (r *Rule) nextWeekly(start, after time.Time) time.Time {
+	weekdays := r.Weekdays
+	if len(weekdays) == 0 {
+		weekdays = []time.Weekday{start.Weekday()}
+	}
+	allowed := make(map[time.Weekday]bool, len(weekdays))
+	for _, weekday := range weekdays {
+		allowed[weekday] = true
+	}
+
+	startWeek := weekStart(start)
+	for day := after.AddDate(0, 0, 1); ; day = day.AddDate(0, 0, 1) {
+		weeks := int(weekStart(day).Sub(startWeek).Hours() / (24 * 7))
+		if weeks%r.Interval == 0 && allowed[day.Weekday()] {
+			return day
+		}
+	}
+}
+
+// nextMonthly находит ближайшую после after дату в месяце, кратном интервалу от месяца start
+func (r *Rule) nextMonthly(start, after time.Time) time.Time {
+	monthDay := r.MonthDay
+	if monthDay == 0 {
+		monthDay = start.Day()
+	}
+
+	startMonths := start.Year()*12 + int(start.Month()) - 1
+	for month := after.Year()*12 + int(after.Month()) - 1; ; month++ {
+		if (month-startMonths)%r.Interval != 0 {
+			continue
+		}
+		year, mon := month/12, time.Month(month%12+1)
+		last := time.Date(year, mon+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		day := monthDay
+		if day == -1 || day > last {
+			day = last
+		}
+		candidate := time.Date(year, mon, day, 0, 0, 0, 0, time.UTC)
+		if candidate.After(after) {
+			return candidate
+		}
+	}
+}
+
+// ParseWeekday разбирает код дня недели RRULE (MO, TU, ...)
+func ParseWeekday(code string) (time.Weekday, bool) {
+	weekday, ok := weekdayCodes[strings.ToUpper(strings.TrimSpace(code))]
+	return weekday, ok
+}
+
+// truncateDay отбрасывает время, оставляя дату в UTC
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// weekStart возвращает понедельник недели, в которую входит дата
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
+
+// sortedWeekdays возвращает дни недели без повторов в порядке с понедельника
+func sortedWeekdays(weekdays []time.Weekday) []time.Weekday {
+	present := make(map[time.Weekday]bool, len(weekdays))
+	for _, weekday := range weekdays {
+		present[weekday] = true
+	}
+	sorted := make([]time.Weekday, 0, len(present))
+	for i := 1; i <= 7; i++ {
+		weekday := time.Weekday(i % 7)
+		if present[weekday] {
+			sorted = append(sorted, weekday)
+		}
+	}
+	return sorted
+}
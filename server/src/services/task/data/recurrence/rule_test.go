@@ -0,0 +1,230 @@
+package recurrence
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// occurrences возвращает до limit дат серии, начиная с первой задачи start
+func occurrences(t *testing.T, rule *Rule, start time.Time, limit int) []time.Time {
+	t.Helper()
+	dates := []time.Time{start}
+	for len(dates) < limit {
+		next, ok := rule.Next(start, dates[len(dates)-1], len(dates))
+		if !ok {
+			break
+		}
+		dates = append(dates, next)
+	}
+	return dates
+}
+
+func formatDates(dates []time.Time) string {
+	formatted := make([]string, 0, len(dates))
+	for _, d := range dates {
+		formatted = append(formatted, d.Format("2006-01-02"))
+	}
+	return strings.Join(formatted, " ")
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "empty", value: ""},
+		{name: "prefix only", value: "RRULE:"},
+		{name: "missing frequency", value: "INTERVAL=2"},
+		{name: "unsupported frequency", value: "FREQ=YEARLY"},
+		{name: "part without value", value: "FREQ=DAILY;INTERVAL"},
+		{name: "duplicate part", value: "FREQ=DAILY;FREQ=WEEKLY"},
+		{name: "unsupported part", value: "FREQ=DAILY;BYHOUR=10"},
+		{name: "non-numeric interval", value: "FREQ=DAILY;INTERVAL=two"},
+		{name: "zero interval", value: "FREQ=DAILY;INTERVAL=0"},
+		{name: "interval too large", value: "FREQ=DAILY;INTERVAL=366"},
+		{name: "invalid weekday", value: "FREQ=WEEKLY;BYDAY=MO,XX"},
+		{name: "BYDAY for monthly", value: "FREQ=MONTHLY;BYDAY=MO"},
+		{name: "zero month day", value: "FREQ=MONTHLY;BYMONTHDAY=0"},
+		{name: "month day too large", value: "FREQ=MONTHLY;BYMONTHDAY=32"},
+		{name: "month day below -1", value: "FREQ=MONTHLY;BYMONTHDAY=-2"},
+		{name: "BYMONTHDAY for weekly", value: "FREQ=WEEKLY;BYMONTHDAY=10"},
+		{name: "zero count", value: "FREQ=DAILY;COUNT=0"},
+		{name: "negative count", value: "FREQ=DAILY;COUNT=-1"},
+		{name: "count too large", value: "FREQ=DAILY;COUNT=1001"},
+		{name: "invalid until", value: "FREQ=DAILY;UNTIL=2026-12-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rule, err := Parse(tt.value); err == nil {
+				t.Fatalf("Parse(%q) = %+v, want error", tt.value, rule)
+			}
+		})
+	}
+}
+
+func TestParseAndString(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{value: "RRULE:freq=weekly;interval=2;byday=fr,mo", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"},
+		{value: "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=12", want: "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=12"},
+		{value: "FREQ=DAILY;UNTIL=20261231T235959Z", want: "FREQ=DAILY;UNTIL=20261231"},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.value)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.value, err)
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNextDaily(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY;INTERVAL=3")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := formatDates(occurrences(t, rule, date(2026, time.February, 26), 4))
+	want := "2026-02-26 2026-03-01 2026-03-04 2026-03-07"
+	if got != want {
+		t.Errorf("occurrences = %s, want %s", got, want)
+	}
+}
+
+func TestNextWeeklyWithIntervalAndByDay(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// 2026-10-19 — понедельник; следующая неделя пропускается из-за INTERVAL=2
+	got := formatDates(occurrences(t, rule, date(2026, time.October, 19), 6))
+	want := "2026-10-19 2026-10-23 2026-11-02 2026-11-06 2026-11-16 2026-11-20"
+	if got != want {
+		t.Errorf("occurrences = %s, want %s", got, want)
+	}
+}
+
+func TestNextWeeklyDefaultsToStartWeekday(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// 2026-10-21 — среда
+	got := formatDates(occurrences(t, rule, date(2026, time.October, 21), 3))
+	want := "2026-10-21 2026-10-28 2026-11-04"
+	if got != want {
+		t.Errorf("occurrences = %s, want %s", got, want)
+	}
+}
+
+func TestNextMonthlyClampsDay31(t *testing.T) {
+	rule, err := Parse("FREQ=MONTHLY;BYMONTHDAY=31")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// В коротких месяцах задача переносится на последний день, но не «съезжает» в следующих месяцах
+	got := formatDates(occurrences(t, rule, date(2027, time.January, 31), 5))
+	want := "2027-01-31 2027-02-28 2027-03-31 2027-04-30 2027-05-31"
+	if got != want {
+		t.Errorf("occurrences = %s, want %s", got, want)
+	}
+}
+
+func TestNextMonthlyDefaultsToStartDay(t *testing.T) {
+	rule, err := Parse("FREQ=MONTHLY")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := formatDates(occurrences(t, rule, date(2028, time.January, 30), 3))
+	want := "2028-01-30 2028-02-29 2028-03-30"
+	if got != want {
+		t.Errorf("occurrences = %s, want %s", got, want)
+	}
+}
+
+func TestNextMonthlyLastDay(t *testing.T) {
+	rule, err := Parse("FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=-1")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := formatDates(occurrences(t, rule, date(2028, time.January, 31), 4))
+	want := "2028-01-31 2028-02-29 2028-03-31 2028-04-30"
+	if got != want {
+		t.Errorf("occurrences = %s, want %s", got, want)
+	}
+}
+
+func TestNextMonthlyWithInterval(t *testing.T) {
+	rule, err := Parse("FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=15")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := formatDates(occurrences(t, rule, date(2026, time.November, 15), 3))
+	want := "2026-11-15 2027-02-15 2027-05-15"
+	if got != want {
+		t.Errorf("occurrences = %s, want %s", got, want)
+	}
+}
+
+func TestNextStopsAtCount(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY;COUNT=3")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := occurrences(t, rule, date(2026, time.October, 18), 10)
+	if len(got) != 3 {
+		t.Fatalf("occurrences = %s, want 3 dates", formatDates(got))
+	}
+	if _, ok := rule.Next(date(2026, time.October, 18), got[2], 3); ok {
+		t.Error("Next() after COUNT occurrences must report the end of the series")
+	}
+}
+
+func TestNextStopsAtUntil(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=MO;UNTIL=20261102T120000Z")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// UNTIL включительно: задача на саму дату UNTIL еще создается
+	got := formatDates(occurrences(t, rule, date(2026, time.October, 19), 10))
+	want := "2026-10-19 2026-10-26 2026-11-02"
+	if got != want {
+		t.Errorf("occurrences = %s, want %s", got, want)
+	}
+}
+
+func TestNextIgnoresTimeOfDay(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	start := time.Date(2026, time.October, 18, 23, 30, 0, 0, time.UTC)
+	next, ok := rule.Next(start, start, 1)
+	if !ok {
+		t.Fatal("Next() = false, want next date")
+	}
+	if !next.Equal(date(2026, time.October, 19)) {
+		t.Errorf("Next() = %v, want 2026-10-19", next)
+	}
+}
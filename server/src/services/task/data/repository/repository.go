@@ -2,12 +2,20 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/diploma/shared/dnd"
 	"github.com/diploma/task-service/data/database"
 	"github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/recurrence"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type Repository struct {
@@ -23,9 +31,9 @@ func NewRepository(db *database.DB) *Repository {
 // CreateTask создает новую задачу
 func (r *Repository) CreateTask(ctx context.Context, task *models.Task) (*models.Task, error) {
 	query := `
-		INSERT INTO tasks (creator, workspacesid, title, description, date, status)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, creator, workspacesid, title, description, date, status
+		INSERT INTO tasks (creator, workspacesid, title, description, date, status, parent_id, priority, source_message_id, estimate_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, creator, workspacesid, title, description, date, status, parent_id, priority, source_message_id, estimate_minutes
 	`
 
 	err := r.db.Pool.QueryRow(ctx, query,
@@ -35,6 +43,10 @@ func (r *Repository) CreateTask(ctx context.Context, task *models.Task) (*models
 		task.Description,
 		task.Date,
 		task.Status,
+		task.ParentID,
+		task.Priority,
+		task.SourceMessageID,
+		task.EstimateMinutes,
 	).Scan(
 		&task.ID,
 		&task.Creator,
@@ -43,376 +55,4187 @@ func (r *Repository) CreateTask(ctx context.Context, task *models.Task) (*models
 		&task.Description,
 		&task.Date,
 		&task.Status,
+		&task.ParentID,
+		&task.Priority,
+		&task.SourceMessageID,
+		&task.EstimateMinutes,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
+	// Создатель автоматически наблюдает за задачей
+	if err := insertTaskWatcher(ctx, r.db.Pool, task.ID, task.Creator); err != nil {
+		fmt.Printf("Warning: failed to add task watcher: %v\n", err)
+	}
+
 	// Добавляем запись в историю изменений
-	changeDesc := fmt.Sprintf("Задача создана: %s", task.Title)
-	if err := r.addTaskChange(ctx, task.ID, changeDesc); err != nil {
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      task.ID,
+		ActorID:     actorRef(task.Creator),
+		Field:       models.ChangeFieldCreated,
+		NewValue:    &task.Title,
+		Description: fmt.Sprintf("Задача создана: %s", task.Title),
+	})
+	if err != nil {
 		// Не возвращаем ошибку, так как задача уже создана
 		fmt.Printf("Warning: failed to add task change: %v\n", err)
 	}
 
-	return task, nil
+	return task, nil
+}
+
+// taskOverdueCondition истинно для незавершенных задач, срок которых прошел
+const taskOverdueCondition = `(t.date < CURRENT_DATE AND task_status_category(t.workspacesid, t.status) NOT IN ('done', 'cancelled'))`
+
+// taskDetailsQuery выбирает задачи с дополнительной информацией, доступные пользователю $1
+const taskDetailsQuery = `
+	SELECT
+		t.id,
+		t.creator,
+		u.surname || ' ' || u.name as creator_name,
+		u.surname as creator_surname,
+		t.workspacesid as workspace_id,
+		w.name as workspace_name,
+		t.title,
+		t.description,
+		t.date,
+		t.status,
+		task_status_name(t.workspacesid, t.status) as status_name,
+		t.priority,
+		COALESCE((SELECT json_agg(json_build_object('id', l.id, 'name', l.name, 'color', l.color) ORDER BY l.name)
+			FROM task_label_links ll INNER JOIN task_labels l ON ll.labelsid = l.id
+			WHERE ll.tasksid = t.id), '[]') as labels,
+		COALESCE((SELECT COUNT(*) FROM "userintask" WHERE tasksid = t.id), 0) as assignee_count,
+		COALESCE((SELECT COUNT(*) FROM "taskinchat" WHERE tasksid = t.id), 0) as chat_count,
+		COALESCE((SELECT COUNT(*) FROM task_comments WHERE tasksid = t.id), 0) as comment_count,
+		t.parent_id,
+		(SELECT COUNT(*) FROM tasks s WHERE s.parent_id = t.id) as subtask_count,
+		(SELECT COUNT(*) FROM tasks s
+			WHERE s.parent_id = t.id AND task_status_category(s.workspacesid, s.status) = 'done') as subtask_done_count,
+		(SELECT COUNT(*) FROM task_dependencies d
+			INNER JOIN tasks b ON d.blocker_id = b.id
+			WHERE d.blocked_id = t.id
+			  AND task_status_category(b.workspacesid, b.status) NOT IN ('done', 'cancelled')) as open_blocker_count,
+		` + taskOverdueCondition + ` as overdue,
+		t.series_id,
+		t.source_message_id,
+		t.estimate_minutes,
+		(SELECT COALESCE(SUM(EXTRACT(EPOCH FROM te.ended_at - te.started_at)), 0)::INT8 / 60
+			FROM task_time_entries te WHERE te.tasksid = t.id AND te.ended_at IS NOT NULL) as logged_minutes,
+		t.date as created_at,
+		t.version
+	FROM tasks t
+	INNER JOIN users u ON t.creator = u.id
+	INNER JOIN workspaces w ON t.workspacesid = w.id
+	INNER JOIN "userinworkspace" uiw ON w.id = uiw.workspacesid AND uiw.usersid = $1
+`
+
+// scanTaskDetails читает строку, выбранную taskDetailsQuery
+func scanTaskDetails(row pgx.Row) (*models.TaskWithDetails, error) {
+	var task models.TaskWithDetails
+	var labels []byte
+	err := row.Scan(
+		&task.ID,
+		&task.Creator,
+		&task.CreatorName,
+		&task.CreatorSurname,
+		&task.WorkspaceID,
+		&task.WorkspaceName,
+		&task.Title,
+		&task.Description,
+		&task.Date,
+		&task.Status,
+		&task.StatusName,
+		&task.Priority,
+		&labels,
+		&task.AssigneeCount,
+		&task.ChatCount,
+		&task.CommentCount,
+		&task.ParentID,
+		&task.SubtaskCount,
+		&task.SubtaskDone,
+		&task.OpenBlockers,
+		&task.Overdue,
+		&task.SeriesID,
+		&task.SourceMessageID,
+		&task.EstimateMinutes,
+		&task.LoggedMinutes,
+		&task.CreatedAt,
+		&task.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(labels, &task.Labels); err != nil {
+		return nil, fmt.Errorf("failed to decode task labels: %w", err)
+	}
+	return &task, nil
+}
+
+// taskSortColumns сопоставляет поля сортировки с колонками и типами значений курсора
+var taskSortColumns = map[string]struct {
+	column string
+	cast   string
+}{
+	models.TaskSortDate:    {column: "t.date", cast: "date"},
+	models.TaskSortTitle:   {column: "t.title", cast: "text"},
+	models.TaskSortStatus:  {column: "t.status", cast: "int4"},
+	models.TaskSortCreated: {column: "t.id", cast: ""},
+}
+
+// taskFilterConditions строит условия WHERE и аргументы запроса задач по фильтру.
+// $1 — пользователь (участник РП), $2 — рабочее пространство; условия рассчитаны на псевдоним t таблицы tasks
+func taskFilterConditions(filter models.TaskFilter) ([]string, []interface{}) {
+	conditions := []string{"t.workspacesid = $2"}
+	args := []interface{}{filter.UserID, filter.WorkspaceID}
+	argNum := 3
+
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, fmt.Sprintf("t.status = ANY($%d)", argNum))
+		args = append(args, filter.Statuses)
+		argNum++
+	}
+
+	if filter.AssigneeID != nil {
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (SELECT 1 FROM "userintask" WHERE tasksid = t.id AND usersid = $%d)`, argNum))
+		args = append(args, *filter.AssigneeID)
+		argNum++
+	}
+
+	if filter.CreatorID != nil {
+		conditions = append(conditions, fmt.Sprintf("t.creator = $%d", argNum))
+		args = append(args, *filter.CreatorID)
+		argNum++
+	}
+
+	if filter.DueFrom != nil {
+		conditions = append(conditions, fmt.Sprintf("t.date >= $%d", argNum))
+		args = append(args, *filter.DueFrom)
+		argNum++
+	}
+
+	if filter.DueTo != nil {
+		conditions = append(conditions, fmt.Sprintf("t.date <= $%d", argNum))
+		args = append(args, *filter.DueTo)
+		argNum++
+	}
+
+	if filter.ChatID != nil {
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (SELECT 1 FROM "taskinchat" WHERE tasksid = t.id AND chatsid = $%d)`, argNum))
+		args = append(args, *filter.ChatID)
+		argNum++
+	}
+
+	if filter.Overdue != nil {
+		if *filter.Overdue {
+			conditions = append(conditions, taskOverdueCondition)
+		} else {
+			conditions = append(conditions, "NOT "+taskOverdueCondition)
+		}
+	}
+
+	if len(filter.Priorities) > 0 {
+		conditions = append(conditions, fmt.Sprintf("t.priority = ANY($%d)", argNum))
+		args = append(args, filter.Priorities)
+		argNum++
+	}
+
+	if len(filter.LabelIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf(`EXISTS (SELECT 1 FROM task_label_links WHERE tasksid = t.id AND labelsid = ANY($%d))`, argNum))
+		args = append(args, filter.LabelIDs)
+		argNum++
+	}
+
+	if filter.Search != "" {
+		conditions = append(conditions, fmt.Sprintf("(t.title ILIKE $%d OR t.description ILIKE $%d)", argNum, argNum))
+		args = append(args, "%"+escapeLike(filter.Search)+"%")
+		argNum++
+	}
+
+	return conditions, args
+}
+
+// GetTasksByWorkspace получает страницу задач рабочего пространства с учетом фильтров
+// и общее количество задач, подходящих под фильтры
+func (r *Repository) GetTasksByWorkspace(ctx context.Context, filter models.TaskFilter) ([]models.TaskWithDetails, int, error) {
+	sort, ok := taskSortColumns[filter.SortField]
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid sort field")
+	}
+
+	conditions, args := taskFilterConditions(filter)
+	argNum := len(args) + 1
+
+	var total int
+	countQuery := `
+		SELECT COUNT(*) FROM tasks t
+		INNER JOIN "userinworkspace" uiw ON t.workspacesid = uiw.workspacesid AND uiw.usersid = $1
+		WHERE ` + strings.Join(conditions, " AND ")
+	if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	// Keyset-пагинация: следующая страница начинается после (значение сортировки, id) из курсора
+	if filter.Cursor != nil {
+		if sort.cast == "" {
+			conditions = append(conditions, fmt.Sprintf("t.id %s $%d", comparison, argNum))
+			args = append(args, filter.Cursor.ID)
+			argNum++
+		} else {
+			conditions = append(conditions, fmt.Sprintf("(%s, t.id) %s ($%d::%s, $%d)", sort.column, comparison, argNum, sort.cast, argNum+1))
+			args = append(args, filter.Cursor.Value, filter.Cursor.ID)
+			argNum += 2
+		}
+	}
+
+	orderBy := "t.id " + direction
+	if sort.cast != "" {
+		orderBy = fmt.Sprintf("%s %s, t.id %s", sort.column, direction, direction)
+	}
+
+	query := fmt.Sprintf("%s WHERE %s ORDER BY %s LIMIT $%d",
+		taskDetailsQuery, strings.Join(conditions, " AND "), orderBy, argNum)
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.TaskWithDetails
+	for rows.Next() {
+		task, err := scanTaskDetails(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, *task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating tasks: %w", err)
+	}
+
+	return tasks, total, nil
+}
+
+// CountTaskGroups считает задачи, подходящие под фильтр, по группам представления.
+// Счет идет по всем задачам фильтра, а не по странице; курсор и размер страницы не учитываются.
+// При группировке по метке задача считается в каждой своей метке, задачи без меток — в группе TaskGroupNone
+func (r *Repository) CountTaskGroups(ctx context.Context, filter models.TaskFilter, groupBy string) ([]models.TaskGroupCount, error) {
+	var key, name, joins string
+	switch groupBy {
+	case models.TaskGroupByStatus:
+		key, name = "t.status::TEXT", "task_status_name(t.workspacesid, t.status)"
+	case models.TaskGroupByPriority:
+		key, name = "t.priority", "t.priority"
+	case models.TaskGroupByLabel:
+		key = "COALESCE(l.id::TEXT, '" + models.TaskGroupNone + "')"
+		name = "COALESCE(l.name, 'Без метки')"
+		joins = `
+		LEFT JOIN task_label_links ll ON ll.tasksid = t.id
+		LEFT JOIN task_labels l ON ll.labelsid = l.id`
+	default:
+		return nil, fmt.Errorf("invalid group by")
+	}
+
+	conditions, args := taskFilterConditions(filter)
+	query := fmt.Sprintf(`
+		SELECT %s, %s, COUNT(*) FROM tasks t
+		INNER JOIN "userinworkspace" uiw ON t.workspacesid = uiw.workspacesid AND uiw.usersid = $1%s
+		WHERE %s
+		GROUP BY 1, 2`, key, name, joins, strings.Join(conditions, " AND "))
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count task groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []models.TaskGroupCount
+	for rows.Next() {
+		var group models.TaskGroupCount
+		if err := rows.Scan(&group.Key, &group.Name, &group.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan task group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// GetTaskByID получает информацию о задаче по ID
+func (r *Repository) GetTaskByID(ctx context.Context, taskID, userID int) (*models.TaskWithDetails, error) {
+	query := taskDetailsQuery + ` WHERE t.id = $2`
+
+	task, err := scanTaskDetails(r.db.Pool.QueryRow(ctx, query, userID, taskID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("task not found")
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	return task, nil
+}
+
+// UpdateTask обновляет задачу, записывает в историю изменение каждого поля и возвращает новую версию задачи.
+// Если version не 0 и версия задачи успела измениться, возвращает ошибку "task version changed"
+func (r *Repository) UpdateTask(ctx context.Context, taskID, actorID, version int, title, description *string, date *time.Time, priority *string, estimate *int) (int, error) {
+	// Старые значения читаются с блокировкой строки в том же запросе, что и обновление.
+	// Оценка 0 удаляет оценку задачи
+	query := `
+		UPDATE tasks t
+		SET title = COALESCE($2, t.title),
+		    description = COALESCE($3, t.description),
+		    date = COALESCE($4, t.date),
+		    priority = COALESCE($5, t.priority),
+		    estimate_minutes = CASE WHEN $6::INT4 IS NULL THEN t.estimate_minutes ELSE NULLIF($6::INT4, 0) END,
+		    version = t.version + 1
+		FROM (SELECT id, title, description, date, priority, estimate_minutes, version FROM tasks WHERE id = $1 FOR UPDATE) old
+		WHERE t.id = old.id AND ($7::INT4 = 0 OR old.version = $7::INT4)
+		RETURNING old.title, old.description, old.date, old.priority, old.estimate_minutes,
+		          t.title, t.description, t.date, t.priority, t.estimate_minutes, t.version
+	`
+
+	var oldTitle, newTitle string
+	var oldDescription, newDescription *string
+	var oldDate, newDate time.Time
+	var oldPriority, newPriority string
+	var oldEstimate, newEstimate *int
+	var newVersion int
+	err := r.db.Pool.QueryRow(ctx, query, taskID, title, description, date, priority, estimate, version).Scan(
+		&oldTitle, &oldDescription, &oldDate, &oldPriority, &oldEstimate,
+		&newTitle, &newDescription, &newDate, &newPriority, &newEstimate, &newVersion,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			if version != 0 {
+				return 0, fmt.Errorf("task version changed")
+			}
+			return 0, fmt.Errorf("task not found")
+		}
+		return 0, fmt.Errorf("failed to update task: %w", err)
+	}
+
+	// Добавляем записи в историю изменений
+	var changes []models.TaskChange
+	if oldTitle != newTitle {
+		changes = append(changes, models.TaskChange{
+			Field:       models.ChangeFieldTitle,
+			OldValue:    &oldTitle,
+			NewValue:    &newTitle,
+			Description: "Название изменено",
+		})
+	}
+	if stringValue(oldDescription) != stringValue(newDescription) {
+		changes = append(changes, models.TaskChange{
+			Field:       models.ChangeFieldDescription,
+			OldValue:    oldDescription,
+			NewValue:    newDescription,
+			Description: "Описание изменено",
+		})
+	}
+	if !oldDate.Equal(newDate) {
+		oldValue := oldDate.Format("2006-01-02")
+		newValue := newDate.Format("2006-01-02")
+		changes = append(changes, models.TaskChange{
+			Field:       models.ChangeFieldDate,
+			OldValue:    &oldValue,
+			NewValue:    &newValue,
+			Description: fmt.Sprintf("Срок изменен на: %s", newValue),
+		})
+	}
+	if oldPriority != newPriority {
+		changes = append(changes, models.TaskChange{
+			Field:       models.ChangeFieldPriority,
+			OldValue:    &oldPriority,
+			NewValue:    &newPriority,
+			Description: fmt.Sprintf("Приоритет изменен на: %s", newPriority),
+		})
+	}
+	if intValue(oldEstimate) != intValue(newEstimate) {
+		change := models.TaskChange{
+			Field:       models.ChangeFieldEstimate,
+			Description: "Оценка трудозатрат удалена",
+		}
+		if oldEstimate != nil {
+			value := strconv.Itoa(*oldEstimate)
+			change.OldValue = &value
+		}
+		if newEstimate != nil {
+			value := strconv.Itoa(*newEstimate)
+			change.NewValue = &value
+			change.Description = fmt.Sprintf("Оценка трудозатрат изменена на: %d мин", *newEstimate)
+		}
+		changes = append(changes, change)
+	}
+
+	for _, change := range changes {
+		change.TaskID = taskID
+		change.ActorID = actorRef(actorID)
+		if err := r.addTaskChange(ctx, change); err != nil {
+			fmt.Printf("Warning: failed to add task change: %v\n", err)
+		}
+	}
+
+	return newVersion, nil
+}
+
+// DeleteTask удаляет задачу
+func (r *Repository) DeleteTask(ctx context.Context, taskID int) error {
+	query := `DELETE FROM tasks WHERE id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("task not found")
+	}
+
+	return nil
+}
+
+// UpdateTaskStatus переводит задачу версии version из статуса fromStatus в toStatus и возвращает новую версию задачи.
+// Версия 0 не проверяется. Если задача успела измениться, возвращает ошибку "task version changed"
+func (r *Repository) UpdateTaskStatus(ctx context.Context, taskID, actorID, version, fromStatus, toStatus int, statusName string) (int, error) {
+	// Задача со сменившимся статусом попадает в конец колонки доски
+	query := `
+		UPDATE tasks SET status = $3, board_position = NULL, version = version + 1
+		WHERE id = $1 AND status = $2 AND ($4::INT4 = 0 OR version = $4::INT4)
+		RETURNING version
+	`
+
+	var newVersion int
+	err := r.db.Pool.QueryRow(ctx, query, taskID, fromStatus, toStatus, version).Scan(&newVersion)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("task version changed")
+		}
+		return 0, fmt.Errorf("failed to update task status: %w", err)
+	}
+
+	r.addStatusChange(ctx, taskID, actorID, fromStatus, toStatus, statusName)
+
+	return newVersion, nil
+}
+
+// addStatusChange добавляет в историю запись о смене статуса задачи
+func (r *Repository) addStatusChange(ctx context.Context, taskID, actorID, fromStatus, toStatus int, statusName string) {
+	oldValue := strconv.Itoa(fromStatus)
+	newValue := strconv.Itoa(toStatus)
+	err := r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldStatus,
+		OldValue:    &oldValue,
+		NewValue:    &newValue,
+		Description: fmt.Sprintf("Статус изменен на: %s", statusName),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+}
+
+// ========== Board Operations ==========
+
+// taskBoardLockClass — класс advisory-блокировки, под которой изменяется порядок задач на доске рабочего пространства
+const taskBoardLockClass = 36
+
+// boardOrder — порядок задач в колонке доски. Задачи без позиции (новые и сменившие статус) идут в конце
+const boardOrder = `board_position NULLS LAST, id`
+
+// GetBoardTasks получает задачи доски рабочего пространства: не более filter.Limit первых задач
+// каждого статуса в порядке колонки, а также общее количество задач каждого статуса
+func (r *Repository) GetBoardTasks(ctx context.Context, workspaceID, userID int, filter models.BoardFilter) ([]models.TaskWithDetails, map[int]int, error) {
+	// boardCondition отбирает задачи доски; workspaceArg и assigneeArg — номера параметров запроса
+	boardCondition := func(workspaceArg, assigneeArg int) string {
+		condition := fmt.Sprintf("workspacesid = $%d", workspaceArg)
+		if filter.AssigneeID != nil {
+			condition += fmt.Sprintf(
+				" AND EXISTS (SELECT 1 FROM userintask uit WHERE uit.tasksid = tasks.id AND uit.usersid = $%d)", assigneeArg)
+		}
+		return condition
+	}
+
+	args := []interface{}{userID, workspaceID, filter.Limit}
+	countArgs := []interface{}{workspaceID}
+	if filter.AssigneeID != nil {
+		args = append(args, *filter.AssigneeID)
+		countArgs = append(countArgs, *filter.AssigneeID)
+	}
+
+	query := fmt.Sprintf(`
+		WITH ranked AS (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY status ORDER BY %s) AS rank
+			FROM tasks
+			WHERE %s
+		)
+	`, boardOrder, boardCondition(2, 4)) +
+		taskDetailsQuery + `
+		INNER JOIN ranked r ON r.id = t.id
+		WHERE r.rank <= $3
+		ORDER BY t.status, r.rank
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get board tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.TaskWithDetails
+	for rows.Next() {
+		task, err := scanTaskDetails(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate board tasks: %w", err)
+	}
+
+	countQuery := fmt.Sprintf(`SELECT status, COUNT(*) FROM tasks WHERE %s GROUP BY status`, boardCondition(1, 2))
+
+	countRows, err := r.db.Pool.Query(ctx, countQuery, countArgs...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count board tasks: %w", err)
+	}
+	defer countRows.Close()
+
+	totals := make(map[int]int)
+	for countRows.Next() {
+		var status, total int
+		if err := countRows.Scan(&status, &total); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan board task count: %w", err)
+		}
+		totals[status] = total
+	}
+	if err := countRows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate board task counts: %w", err)
+	}
+
+	return tasks, totals, nil
+}
+
+// MoveTask перемещает задачу в колонку toStatus на позицию position (с нуля) одной транзакцией.
+// Позиция ограничивается размером колонки. Если статус задачи уже не fromStatus, возвращается ошибка
+func (r *Repository) MoveTask(ctx context.Context, taskID, actorID, fromStatus, toStatus int, statusName string, position int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var workspaceID int
+	err = tx.QueryRow(ctx, `SELECT workspacesid FROM tasks WHERE id = $1`, taskID).Scan(&workspaceID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("task not found")
+		}
+		return fmt.Errorf("failed to get task: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, taskBoardLockClass, workspaceID); err != nil {
+		return fmt.Errorf("failed to lock task board: %w", err)
+	}
+
+	var status int
+	err = tx.QueryRow(ctx, `SELECT status FROM tasks WHERE id = $1 FOR UPDATE`, taskID).Scan(&status)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("task not found")
+		}
+		return fmt.Errorf("failed to get task: %w", err)
+	}
+	if status != fromStatus {
+		return fmt.Errorf("task status changed")
+	}
+
+	source, err := boardColumn(ctx, tx, workspaceID, fromStatus)
+	if err != nil {
+		return err
+	}
+	oldPosition := indexOf(source, taskID)
+
+	column := source
+	if toStatus != fromStatus {
+		column, err = boardColumn(ctx, tx, workspaceID, toStatus)
+		if err != nil {
+			return err
+		}
+	}
+
+	ids := make([]int, 0, len(column)+1)
+	for _, id := range column {
+		if id != taskID {
+			ids = append(ids, id)
+		}
+	}
+	if position > len(ids) {
+		position = len(ids)
+	}
+	if toStatus == fromStatus && position == oldPosition {
+		return nil
+	}
+	ids = append(ids[:position], append([]int{taskID}, ids[position:]...)...)
+
+	if toStatus != fromStatus {
+		if _, err := tx.Exec(ctx, `UPDATE tasks SET status = $2, version = version + 1 WHERE id = $1`, taskID, toStatus); err != nil {
+			return fmt.Errorf("failed to update task status: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE tasks t SET board_position = o.position
+		FROM unnest($1::int4[]) WITH ORDINALITY AS o(id, position)
+		WHERE t.id = o.id
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to update board positions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Добавляем записи в историю изменений
+	if toStatus != fromStatus {
+		r.addStatusChange(ctx, taskID, actorID, fromStatus, toStatus, statusName)
+	}
+
+	oldValue := strconv.Itoa(oldPosition)
+	newValue := strconv.Itoa(position)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldPosition,
+		OldValue:    &oldValue,
+		NewValue:    &newValue,
+		Description: fmt.Sprintf("Задача перемещена на позицию %d в колонке «%s»", position+1, statusName),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return nil
+}
+
+// boardColumn возвращает ID задач колонки доски в порядке колонки
+func boardColumn(ctx context.Context, tx pgx.Tx, workspaceID, status int) ([]int, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT id FROM tasks WHERE workspacesid = $1 AND status = $2 ORDER BY `+boardOrder,
+		workspaceID, status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get board column: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan board column: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate board column: %w", err)
+	}
+
+	return ids, nil
+}
+
+// indexOf возвращает индекс id в ids или -1
+func indexOf(ids []int, id int) int {
+	for i := range ids {
+		if ids[i] == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// ========== Bulk Operations ==========
+
+// GetTasksByIDs получает задачи рабочего пространства по списку ID. Отсутствующие задачи пропускаются
+func (r *Repository) GetTasksByIDs(ctx context.Context, workspaceID, userID int, taskIDs []int) ([]models.TaskWithDetails, error) {
+	rows, err := r.db.Pool.Query(ctx, taskDetailsQuery+`
+		WHERE t.workspacesid = $2 AND t.id = ANY($3)
+		ORDER BY t.id
+	`, userID, workspaceID, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.TaskWithDetails
+	for rows.Next() {
+		task, err := scanTaskDetails(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, *task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ApplyBulkTaskOperation применяет операцию к задачам в одной транзакции и записывает историю
+// изменений в той же транзакции. Возвращает количество записанных изменений по каждой задаче.
+// Если статус задачи изменился после проверки, ничего не применяется и возвращается ошибка "task status changed"
+func (r *Repository) ApplyBulkTaskOperation(ctx context.Context, actorID int, tasks []models.TaskWithDetails, op models.BulkTaskOperation) (map[int]int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	taskIDs := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+
+	// Блокируем задачи и сверяем статусы, по которым проверялись переходы
+	rows, err := tx.Query(ctx, `SELECT id, status FROM tasks WHERE id = ANY($1) ORDER BY id FOR UPDATE`, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock tasks: %w", err)
+	}
+	statuses := make(map[int]int, len(tasks))
+	for rows.Next() {
+		var id, status int
+		if err := rows.Scan(&id, &status); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan task status: %w", err)
+		}
+		statuses[id] = status
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task statuses: %w", err)
+	}
+
+	changed := make(map[int]int, len(tasks))
+	for _, task := range tasks {
+		status, ok := statuses[task.ID]
+		if !ok {
+			return nil, fmt.Errorf("task not found")
+		}
+		if status != task.Status {
+			return nil, fmt.Errorf("task status changed")
+		}
+
+		var changes []models.TaskChange
+		versionBumped := false
+		if op.Delete {
+			if _, err := tx.Exec(ctx, `DELETE FROM tasks WHERE id = $1`, task.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete task: %w", err)
+			}
+			changed[task.ID] = 1
+			continue
+		}
+
+		if op.Status != nil && task.Status != op.Status.Code {
+			// Задача со сменившимся статусом попадает в конец колонки доски
+			_, err := tx.Exec(ctx, `
+				UPDATE tasks SET status = $2, board_position = NULL, version = version + 1
+				WHERE id = $1
+			`, task.ID, op.Status.Code)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update task status: %w", err)
+			}
+			versionBumped = true
+			oldValue := strconv.Itoa(task.Status)
+			newValue := strconv.Itoa(op.Status.Code)
+			changes = append(changes, models.TaskChange{
+				Field:       models.ChangeFieldStatus,
+				OldValue:    &oldValue,
+				NewValue:    &newValue,
+				Description: fmt.Sprintf("Статус изменен на: %s", op.Status.Name),
+			})
+		}
+
+		for _, userID := range op.AddAssignees {
+			result, err := tx.Exec(ctx, `
+				INSERT INTO "userintask" (tasksid, usersid)
+				SELECT $1, $2
+				WHERE NOT EXISTS (
+					SELECT 1 FROM "userintask" WHERE tasksid = $1 AND usersid = $2
+				)
+			`, task.ID, userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to add task assignee: %w", err)
+			}
+			if result.RowsAffected() > 0 {
+				value := strconv.Itoa(userID)
+				changes = append(changes, models.TaskChange{
+					Field:       models.ChangeFieldAssignee,
+					NewValue:    &value,
+					Description: fmt.Sprintf("Добавлен исполнитель с ID: %d", userID),
+				})
+			}
+		}
+
+		for _, userID := range op.RemoveAssignees {
+			result, err := tx.Exec(ctx, `DELETE FROM "userintask" WHERE tasksid = $1 AND usersid = $2`, task.ID, userID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to remove task assignee: %w", err)
+			}
+			if result.RowsAffected() > 0 {
+				value := strconv.Itoa(userID)
+				changes = append(changes, models.TaskChange{
+					Field:       models.ChangeFieldAssignee,
+					OldValue:    &value,
+					Description: fmt.Sprintf("Удален исполнитель с ID: %d", userID),
+				})
+			}
+		}
+
+		for _, labelID := range op.AddLabels {
+			var labelName string
+			err := tx.QueryRow(ctx, `
+				INSERT INTO task_label_links (tasksid, labelsid)
+				SELECT $1, l.id FROM task_labels l
+				WHERE l.id = $2 AND l.workspacesid = $3
+				ON CONFLICT DO NOTHING
+				RETURNING (SELECT name FROM task_labels WHERE id = $2)
+			`, task.ID, labelID, task.WorkspaceID).Scan(&labelName)
+			if err == pgx.ErrNoRows {
+				continue // Метка уже добавлена
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to add task label: %w", err)
+			}
+			value := strconv.Itoa(labelID)
+			changes = append(changes, models.TaskChange{
+				Field:       models.ChangeFieldLabel,
+				NewValue:    &value,
+				Description: fmt.Sprintf("Добавлена метка: %s", labelName),
+			})
+		}
+
+		for _, labelID := range op.RemoveLabels {
+			var labelName string
+			err := tx.QueryRow(ctx, `
+				DELETE FROM task_label_links ll
+				USING task_labels l
+				WHERE ll.tasksid = $1 AND ll.labelsid = $2 AND l.id = ll.labelsid
+				RETURNING l.name
+			`, task.ID, labelID).Scan(&labelName)
+			if err == pgx.ErrNoRows {
+				continue // Метки у задачи нет
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to remove task label: %w", err)
+			}
+			value := strconv.Itoa(labelID)
+			changes = append(changes, models.TaskChange{
+				Field:       models.ChangeFieldLabel,
+				OldValue:    &value,
+				Description: fmt.Sprintf("Удалена метка: %s", labelName),
+			})
+		}
+
+		// Смена статуса уже увеличила версию; изменения исполнителей и меток увеличивают ее один раз
+		if len(changes) > 0 && !versionBumped {
+			if err := bumpTaskVersion(ctx, tx, task.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		// История пишется в той же транзакции, поэтому все записи операции получают одно время изменения
+		for _, change := range changes {
+			change.TaskID = task.ID
+			change.ActorID = actorRef(actorID)
+			if err := insertTaskChange(ctx, tx, change); err != nil {
+				return nil, err
+			}
+		}
+		changed[task.ID] = len(changes)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return changed, nil
+}
+
+// ========== Workflow Operations ==========
+
+// GetWorkflow возвращает workflow рабочего пространства.
+// Если у РП нет собственных статусов, возвращается workflow по умолчанию
+func (r *Repository) GetWorkflow(ctx context.Context, workspaceID int) (*models.Workflow, error) {
+	var custom bool
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM task_statuses WHERE workspacesid = $1)`,
+		workspaceID,
+	).Scan(&custom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check workflow: %w", err)
+	}
+
+	var owner *int
+	if custom {
+		owner = &workspaceID
+	}
+
+	workflow := &models.Workflow{WorkspaceID: workspaceID, IsDefault: !custom}
+
+	statusRows, err := r.db.Pool.Query(ctx, `
+		SELECT code, name, category, is_initial, position
+		FROM task_statuses
+		WHERE workspacesid IS NOT DISTINCT FROM $1
+		ORDER BY position, code
+	`, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task statuses: %w", err)
+	}
+	defer statusRows.Close()
+
+	for statusRows.Next() {
+		var status models.TaskStatus
+		if err := statusRows.Scan(&status.Code, &status.Name, &status.Category, &status.IsInitial, &status.Position); err != nil {
+			return nil, fmt.Errorf("failed to scan task status: %w", err)
+		}
+		workflow.Statuses = append(workflow.Statuses, status)
+	}
+	if err := statusRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task statuses: %w", err)
+	}
+
+	transitionRows, err := r.db.Pool.Query(ctx, `
+		SELECT from_status, to_status, allowed_roles
+		FROM task_status_transitions
+		WHERE workspacesid IS NOT DISTINCT FROM $1
+		ORDER BY from_status, to_status
+	`, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task status transitions: %w", err)
+	}
+	defer transitionRows.Close()
+
+	for transitionRows.Next() {
+		var transition models.TaskStatusTransition
+		if err := transitionRows.Scan(&transition.FromStatus, &transition.ToStatus, &transition.AllowedRoles); err != nil {
+			return nil, fmt.Errorf("failed to scan task status transition: %w", err)
+		}
+		workflow.Transitions = append(workflow.Transitions, transition)
+	}
+	if err := transitionRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task status transitions: %w", err)
+	}
+
+	return workflow, nil
+}
+
+// ReplaceWorkflow заменяет workflow рабочего пространства целиком.
+// Если задачи РП находятся в статусе, которого нет в новом workflow, возвращает ошибку "status in use"
+func (r *Repository) ReplaceWorkflow(ctx context.Context, workspaceID int, statuses []models.TaskStatus, transitions []models.TaskStatusTransition) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	codes := make([]int, 0, len(statuses))
+	for _, status := range statuses {
+		codes = append(codes, status.Code)
+	}
+	if err := ensureStatusesNotInUse(ctx, tx, workspaceID, codes); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM task_status_transitions WHERE workspacesid = $1`, workspaceID); err != nil {
+		return fmt.Errorf("failed to delete task status transitions: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM task_statuses WHERE workspacesid = $1`, workspaceID); err != nil {
+		return fmt.Errorf("failed to delete task statuses: %w", err)
+	}
+
+	for _, status := range statuses {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO task_statuses (workspacesid, code, name, category, is_initial, position)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, workspaceID, status.Code, status.Name, status.Category, status.IsInitial, status.Position)
+		if err != nil {
+			return fmt.Errorf("failed to create task status: %w", err)
+		}
+	}
+
+	for _, transition := range transitions {
+		roles := transition.AllowedRoles
+		if roles == nil {
+			roles = []string{}
+		}
+		_, err := tx.Exec(ctx, `
+			INSERT INTO task_status_transitions (workspacesid, from_status, to_status, allowed_roles)
+			VALUES ($1, $2, $3, $4)
+		`, workspaceID, transition.FromStatus, transition.ToStatus, roles)
+		if err != nil {
+			return fmt.Errorf("failed to create task status transition: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit workflow: %w", err)
+	}
+
+	return nil
+}
+
+// ResetWorkflow удаляет собственный workflow РП, после чего используется workflow по умолчанию
+func (r *Repository) ResetWorkflow(ctx context.Context, workspaceID int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var codes []int
+	rows, err := tx.Query(ctx, `SELECT code FROM task_statuses WHERE workspacesid IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to get default task statuses: %w", err)
+	}
+	for rows.Next() {
+		var code int
+		if err := rows.Scan(&code); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan default task status: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating default task statuses: %w", err)
+	}
+
+	if err := ensureStatusesNotInUse(ctx, tx, workspaceID, codes); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM task_status_transitions WHERE workspacesid = $1`, workspaceID); err != nil {
+		return fmt.Errorf("failed to delete task status transitions: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM task_statuses WHERE workspacesid = $1`, workspaceID); err != nil {
+		return fmt.Errorf("failed to delete task statuses: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit workflow reset: %w", err)
+	}
+
+	return nil
+}
+
+// ensureStatusesNotInUse проверяет, что все задачи РП находятся в одном из статусов codes
+func ensureStatusesNotInUse(ctx context.Context, tx pgx.Tx, workspaceID int, codes []int) error {
+	var orphaned int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM tasks
+		WHERE workspacesid = $1 AND NOT (status = ANY($2))
+	`, workspaceID, codes).Scan(&orphaned)
+	if err != nil {
+		return fmt.Errorf("failed to check task statuses in use: %w", err)
+	}
+
+	if orphaned > 0 {
+		return fmt.Errorf("status in use")
+	}
+
+	return nil
+}
+
+// ========== Subtask and Dependency Operations ==========
+
+// taskLinksLockClass — класс advisory-блокировки, под которой изменяются связи задач рабочего пространства.
+// Блокировка исключает одновременное создание связей, которые вместе образуют цикл
+const taskLinksLockClass = 35
+
+// GetSubtasks получает подзадачи задачи
+func (r *Repository) GetSubtasks(ctx context.Context, taskID, userID int) ([]models.TaskWithDetails, error) {
+	query := taskDetailsQuery + ` WHERE t.parent_id = $2 ORDER BY t.id`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.TaskWithDetails
+	for rows.Next() {
+		task, err := scanTaskDetails(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subtask: %w", err)
+		}
+		tasks = append(tasks, *task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating subtasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// SetTaskParent назначает задаче родительскую задачу или убирает ее (parentID == nil).
+// Родитель должен находиться в том же рабочем пространстве и не быть потомком задачи
+func (r *Repository) SetTaskParent(ctx context.Context, taskID int, parentID *int, actorID int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	workspaceID, err := lockTaskLinks(ctx, tx, taskID)
+	if err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		if *parentID == taskID {
+			return fmt.Errorf("task link cycle")
+		}
+		if err := ensureSameWorkspace(ctx, tx, *parentID, workspaceID, "parent task not found"); err != nil {
+			return err
+		}
+
+		// Родитель не может быть потомком задачи: поднимаемся от родителя по цепочке parent_id
+		var cycle bool
+		err = tx.QueryRow(ctx, `
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM tasks WHERE id = $1
+				UNION
+				SELECT t.id, t.parent_id FROM tasks t INNER JOIN ancestors a ON t.id = a.parent_id
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)
+		`, *parentID, taskID).Scan(&cycle)
+		if err != nil {
+			return fmt.Errorf("failed to check task hierarchy: %w", err)
+		}
+		if cycle {
+			return fmt.Errorf("task link cycle")
+		}
+	}
+
+	var oldParentID *int
+	err = tx.QueryRow(ctx, `
+		UPDATE tasks t SET parent_id = $2, version = t.version + CASE WHEN old.parent_id IS DISTINCT FROM $2 THEN 1 ELSE 0 END
+		FROM (SELECT id, parent_id FROM tasks WHERE id = $1) old
+		WHERE t.id = old.id
+		RETURNING old.parent_id
+	`, taskID, parentID).Scan(&oldParentID)
+	if err != nil {
+		return fmt.Errorf("failed to set parent task: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if intValue(oldParentID) == intValue(parentID) {
+		return nil
+	}
+
+	// Добавляем запись в историю изменений
+	change := models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldParent,
+		OldValue:    idValue(oldParentID),
+		NewValue:    idValue(parentID),
+		Description: "Родительская задача удалена",
+	}
+	if parentID != nil {
+		change.Description = fmt.Sprintf("Родительская задача изменена на ID: %d", *parentID)
+	}
+	if err := r.addTaskChange(ctx, change); err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return nil
+}
+
+// AddTaskDependency добавляет зависимость: задача blockerID блокирует задачу blockedID.
+// Задачи должны находиться в одном рабочем пространстве, зависимость не должна образовывать цикл
+func (r *Repository) AddTaskDependency(ctx context.Context, blockedID, blockerID, actorID int) error {
+	if blockedID == blockerID {
+		return fmt.Errorf("task link cycle")
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	workspaceID, err := lockTaskLinks(ctx, tx, blockedID)
+	if err != nil {
+		return err
+	}
+	if err := ensureSameWorkspace(ctx, tx, blockerID, workspaceID, "blocking task not found"); err != nil {
+		return err
+	}
+
+	// Цикл возникает, если блокирующая задача уже (транзитивно) заблокирована блокируемой
+	var cycle bool
+	err = tx.QueryRow(ctx, `
+		WITH RECURSIVE blocked AS (
+			SELECT blocked_id FROM task_dependencies WHERE blocker_id = $1
+			UNION
+			SELECT d.blocked_id FROM task_dependencies d INNER JOIN blocked b ON d.blocker_id = b.blocked_id
+		)
+		SELECT EXISTS (SELECT 1 FROM blocked WHERE blocked_id = $2)
+	`, blockedID, blockerID).Scan(&cycle)
+	if err != nil {
+		return fmt.Errorf("failed to check task dependencies: %w", err)
+	}
+	if cycle {
+		return fmt.Errorf("task link cycle")
+	}
+
+	result, err := tx.Exec(ctx, `
+		WITH added AS (
+			INSERT INTO task_dependencies (blocker_id, blocked_id, created_by)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+			RETURNING blocked_id
+		)
+		UPDATE tasks SET version = version + 1 WHERE id IN (SELECT blocked_id FROM added)
+	`, blockerID, blockedID, actorRef(actorID))
+	if err != nil {
+		return fmt.Errorf("failed to add task dependency: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("dependency already exists")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Добавляем запись в историю изменений
+	value := strconv.Itoa(blockerID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      blockedID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldDependency,
+		NewValue:    &value,
+		Description: fmt.Sprintf("Добавлена блокирующая задача ID: %d", blockerID),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return nil
+}
+
+// GetTaskDependencies получает задачи, блокирующие задачу, и задачи, которые она блокирует
+func (r *Repository) GetTaskDependencies(ctx context.Context, taskID int) ([]models.TaskLink, []models.TaskLink, error) {
+	blockedBy, err := r.queryTaskLinks(ctx, `
+		SELECT t.id, t.title, t.status, task_status_name(t.workspacesid, t.status),
+		       task_status_category(t.workspacesid, t.status), d.created_at
+		FROM task_dependencies d
+		INNER JOIN tasks t ON d.blocker_id = t.id
+		WHERE d.blocked_id = $1
+		ORDER BY d.created_at, t.id
+	`, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blocks, err := r.queryTaskLinks(ctx, `
+		SELECT t.id, t.title, t.status, task_status_name(t.workspacesid, t.status),
+		       task_status_category(t.workspacesid, t.status), d.created_at
+		FROM task_dependencies d
+		INNER JOIN tasks t ON d.blocked_id = t.id
+		WHERE d.blocker_id = $1
+		ORDER BY d.created_at, t.id
+	`, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blockedBy, blocks, nil
+}
+
+// RemoveTaskDependency удаляет зависимость задачи blockedID от задачи blockerID
+func (r *Repository) RemoveTaskDependency(ctx context.Context, blockedID, blockerID, actorID int) error {
+	query := `
+		WITH removed AS (
+			DELETE FROM task_dependencies WHERE blocker_id = $1 AND blocked_id = $2
+			RETURNING blocked_id
+		)
+		UPDATE tasks SET version = version + 1 WHERE id IN (SELECT blocked_id FROM removed)
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, blockerID, blockedID)
+	if err != nil {
+		return fmt.Errorf("failed to remove task dependency: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("dependency not found")
+	}
+
+	// Добавляем запись в историю изменений
+	value := strconv.Itoa(blockerID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      blockedID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldDependency,
+		OldValue:    &value,
+		Description: fmt.Sprintf("Удалена блокирующая задача ID: %d", blockerID),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return nil
+}
+
+// queryTaskLinks выполняет запрос, возвращающий связанные задачи
+func (r *Repository) queryTaskLinks(ctx context.Context, query string, args ...interface{}) ([]models.TaskLink, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	links := []models.TaskLink{}
+	for rows.Next() {
+		var link models.TaskLink
+		err := rows.Scan(&link.ID, &link.Title, &link.Status, &link.StatusName, &link.StatusCategory, &link.LinkedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task dependency: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task dependencies: %w", err)
+	}
+
+	return links, nil
+}
+
+// lockTaskLinks блокирует изменение связей задач рабочего пространства задачи до конца транзакции
+// и возвращает ID рабочего пространства
+func lockTaskLinks(ctx context.Context, tx pgx.Tx, taskID int) (int, error) {
+	var workspaceID int
+	err := tx.QueryRow(ctx, `SELECT workspacesid FROM tasks WHERE id = $1`, taskID).Scan(&workspaceID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("task not found")
+		}
+		return 0, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, taskLinksLockClass, workspaceID); err != nil {
+		return 0, fmt.Errorf("failed to lock task links: %w", err)
+	}
+
+	return workspaceID, nil
+}
+
+// ensureSameWorkspace проверяет, что задача существует и находится в рабочем пространстве workspaceID
+func ensureSameWorkspace(ctx context.Context, tx pgx.Tx, taskID, workspaceID int, notFound string) error {
+	var taskWorkspaceID int
+	err := tx.QueryRow(ctx, `SELECT workspacesid FROM tasks WHERE id = $1`, taskID).Scan(&taskWorkspaceID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("%s", notFound)
+		}
+		return fmt.Errorf("failed to get task: %w", err)
+	}
+
+	if taskWorkspaceID != workspaceID {
+		return fmt.Errorf("tasks belong to different workspaces")
+	}
+
+	return nil
+}
+
+// ========== Series Operations ==========
+
+// CreateTaskSeries делает задачу первой задачей новой серии повторяющихся задач
+func (r *Repository) CreateTaskSeries(ctx context.Context, taskID int, rule, mode string, actorID int) (*models.TaskSeries, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var workspaceID int
+	var date time.Time
+	var seriesID *int
+	err = tx.QueryRow(ctx, `SELECT workspacesid, date, series_id FROM tasks WHERE id = $1 FOR UPDATE`, taskID).
+		Scan(&workspaceID, &date, &seriesID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("task not found")
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	if seriesID != nil {
+		return nil, fmt.Errorf("task already belongs to a series")
+	}
+
+	var id int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO task_series (workspacesid, creator, rule, mode, start_date)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, workspaceID, actorRef(actorID), rule, mode, date).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task series: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE tasks SET series_id = $2, version = version + 1 WHERE id = $1`, taskID, id); err != nil {
+		return nil, fmt.Errorf("failed to attach task to series: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return r.GetTaskSeries(ctx, id)
+}
+
+// GetTaskSeries получает серию повторяющихся задач по ID
+func (r *Repository) GetTaskSeries(ctx context.Context, seriesID int) (*models.TaskSeries, error) {
+	query := `
+		SELECT id, workspacesid, creator, rule, mode, start_date, occurrences, active, created_at, updated_at, cancelled_at
+		FROM task_series
+		WHERE id = $1
+	`
+
+	var series models.TaskSeries
+	err := r.db.Pool.QueryRow(ctx, query, seriesID).Scan(
+		&series.ID,
+		&series.WorkspaceID,
+		&series.Creator,
+		&series.Rule,
+		&series.Mode,
+		&series.StartDate,
+		&series.Occurrences,
+		&series.Active,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+		&series.CancelledAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("series not found")
+		}
+		return nil, fmt.Errorf("failed to get task series: %w", err)
+	}
+
+	return &series, nil
+}
+
+// GetSeriesTasks получает задачи серии в порядке сроков
+func (r *Repository) GetSeriesTasks(ctx context.Context, seriesID, userID int) ([]models.TaskWithDetails, error) {
+	query := taskDetailsQuery + ` WHERE t.series_id = $2 ORDER BY t.date, t.id`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.TaskWithDetails
+	for rows.Next() {
+		task, err := scanTaskDetails(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan series task: %w", err)
+		}
+		tasks = append(tasks, *task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating series tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// UpdateTaskSeries изменяет правило и режим серии. Если переданы title или description,
+// они применяются ко всем незавершенным задачам серии, а через них — к следующим задачам
+func (r *Repository) UpdateTaskSeries(ctx context.Context, seriesID, actorID int, rule, mode *string, title, description *string) error {
+	query := `
+		UPDATE task_series
+		SET rule = COALESCE($2, rule),
+		    mode = COALESCE($3, mode),
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, seriesID, rule, mode)
+	if err != nil {
+		return fmt.Errorf("failed to update task series: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("series not found")
+	}
+
+	if title == nil && description == nil {
+		return nil
+	}
+
+	taskIDs, err := r.getOpenSeriesTaskIDs(ctx, seriesID)
+	if err != nil {
+		return err
+	}
+	for _, taskID := range taskIDs {
+		if _, err := r.UpdateTask(ctx, taskID, actorID, 0, title, description, nil, nil, nil); err != nil && err.Error() != "task not found" {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CancelTaskSeries останавливает создание задач серии. Если deleteOpen, удаляет незавершенные задачи серии.
+// Возвращает количество удаленных задач
+func (r *Repository) CancelTaskSeries(ctx context.Context, seriesID int, deleteOpen bool) (int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE task_series SET active = FALSE, cancelled_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND cancelled_at IS NULL
+	`, seriesID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel task series: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return 0, fmt.Errorf("series not found or already cancelled")
+	}
+
+	deleted := 0
+	if deleteOpen {
+		result, err := tx.Exec(ctx, `
+			DELETE FROM tasks t
+			WHERE t.series_id = $1
+			  AND task_status_category(t.workspacesid, t.status) NOT IN ('done', 'cancelled')
+		`, seriesID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete series tasks: %w", err)
+		}
+		deleted = int(result.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// CreateNextSeriesTask создает следующую задачу серии, если серия активна, работает в режиме mode
+// и ее последняя задача — triggerTaskID (0 — любая). Новая задача получает срок по правилу серии,
+// начальный статус workflow, исполнителей и чаты последней задачи. Возвращает nil, если задача не создана
+func (r *Repository) CreateNextSeriesTask(ctx context.Context, seriesID, triggerTaskID int, mode string) (*models.Task, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка строки серии не дает создать одну и ту же задачу дважды
+	var series models.TaskSeries
+	err = tx.QueryRow(ctx, `
+		SELECT id, workspacesid, creator, rule, mode, start_date, occurrences, active
+		FROM task_series WHERE id = $1 FOR UPDATE
+	`, seriesID).Scan(
+		&series.ID,
+		&series.WorkspaceID,
+		&series.Creator,
+		&series.Rule,
+		&series.Mode,
+		&series.StartDate,
+		&series.Occurrences,
+		&series.Active,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("series not found")
+		}
+		return nil, fmt.Errorf("failed to get task series: %w", err)
+	}
+	if !series.Active || series.Mode != mode {
+		return nil, nil
+	}
+
+	var latest models.Task
+	err = tx.QueryRow(ctx, `
+		SELECT id, creator, title, description, date, parent_id, priority, estimate_minutes
+		FROM tasks WHERE series_id = $1
+		ORDER BY date DESC, id DESC
+		LIMIT 1
+	`, seriesID).Scan(&latest.ID, &latest.Creator, &latest.Title, &latest.Description, &latest.Date, &latest.ParentID, &latest.Priority, &latest.EstimateMinutes)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			// Все задачи серии удалены — продолжать серию не от чего
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest series task: %w", err)
+	}
+	if triggerTaskID != 0 && latest.ID != triggerTaskID {
+		return nil, nil
+	}
+
+	rule, err := recurrence.Parse(series.Rule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule of series %d: %w", seriesID, err)
+	}
+
+	nextDate, ok := rule.Next(series.StartDate, latest.Date, series.Occurrences)
+	if !ok {
+		// Серия исчерпана по COUNT или UNTIL
+		if _, err := tx.Exec(ctx, `UPDATE task_series SET active = FALSE WHERE id = $1`, seriesID); err != nil {
+			return nil, fmt.Errorf("failed to finish task series: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil, nil
+	}
+
+	task := &models.Task{
+		Creator:         latest.Creator,
+		WorkspaceID:     series.WorkspaceID,
+		Title:           latest.Title,
+		Description:     latest.Description,
+		Date:            nextDate,
+		Priority:        latest.Priority,
+		ParentID:        latest.ParentID,
+		SeriesID:        &seriesID,
+		EstimateMinutes: latest.EstimateMinutes,
+	}
+
+	// Начальный статус workflow РП (или workflow по умолчанию)
+	err = tx.QueryRow(ctx, `
+		SELECT code FROM task_statuses
+		WHERE is_initial
+		  AND (workspacesid = $1
+		       OR (workspacesid IS NULL AND NOT EXISTS (SELECT 1 FROM task_statuses WHERE workspacesid = $1)))
+		LIMIT 1
+	`, series.WorkspaceID).Scan(&task.Status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get initial status: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO tasks (creator, workspacesid, title, description, date, status, parent_id, series_id, priority, estimate_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, task.Creator, task.WorkspaceID, task.Title, task.Description, task.Date, task.Status, task.ParentID, task.SeriesID, task.Priority, task.EstimateMinutes).Scan(&task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create series task: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "userintask" (tasksid, usersid)
+		SELECT $1, usersid FROM "userintask" WHERE tasksid = $2
+	`, task.ID, latest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy task assignees: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO "taskinchat" (chatsid, tasksid)
+		SELECT chatsid, $1 FROM "taskinchat" WHERE tasksid = $2
+	`, task.ID, latest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy task chats: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_label_links (tasksid, labelsid)
+		SELECT $1, labelsid FROM task_label_links WHERE tasksid = $2
+	`, task.ID, latest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy task labels: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO task_watchers (tasksid, usersid)
+		SELECT $1, usersid FROM task_watchers WHERE tasksid = $2
+	`, task.ID, latest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy task watchers: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE task_series SET occurrences = occurrences + 1 WHERE id = $1`, seriesID); err != nil {
+		return nil, fmt.Errorf("failed to update task series: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// Добавляем запись в историю изменений
+	err = r.addTaskChange(WithChangeSource(ctx, models.ChangeSourceAutomation), models.TaskChange{
+		TaskID:      task.ID,
+		Field:       models.ChangeFieldCreated,
+		NewValue:    &task.Title,
+		Description: fmt.Sprintf("Задача создана по серии ID: %d", seriesID),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return task, nil
+}
+
+// GetDueSeries получает ID активных серий в режиме schedule, срок последней задачи которых наступил
+func (r *Repository) GetDueSeries(ctx context.Context) ([]int, error) {
+	query := `
+		SELECT s.id
+		FROM task_series s
+		WHERE s.active AND s.mode = 'schedule'
+		  AND (SELECT MAX(t.date) FROM tasks t WHERE t.series_id = s.id) <= CURRENT_DATE
+		ORDER BY s.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due series: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan series id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating series: %w", err)
+	}
+
+	return ids, nil
+}
+
+// getOpenSeriesTaskIDs получает ID незавершенных задач серии
+func (r *Repository) getOpenSeriesTaskIDs(ctx context.Context, seriesID int) ([]int, error) {
+	query := `
+		SELECT t.id FROM tasks t
+		WHERE t.series_id = $1
+		  AND task_status_category(t.workspacesid, t.status) NOT IN ('done', 'cancelled')
+		ORDER BY t.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan task id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ========== Template Operations ==========
+
+// taskTemplateColumns — колонки шаблона задачи в порядке scanTaskTemplate
+const taskTemplateColumns = `id, workspacesid, name, title, description, priority, estimate_minutes, assignee_ids, label_ids, created_by, created_at, updated_at`
+
+// scanTaskTemplate читает строку с колонками taskTemplateColumns
+func scanTaskTemplate(row pgx.Row) (*models.TaskTemplate, error) {
+	var template models.TaskTemplate
+	err := row.Scan(
+		&template.ID,
+		&template.WorkspaceID,
+		&template.Name,
+		&template.Title,
+		&template.Description,
+		&template.Priority,
+		&template.EstimateMinutes,
+		&template.AssigneeIDs,
+		&template.LabelIDs,
+		&template.CreatedBy,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// CreateTaskTemplate создает шаблон задачи вместе с подзадачами
+func (r *Repository) CreateTaskTemplate(ctx context.Context, template *models.TaskTemplate) (*models.TaskTemplate, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := scanTaskTemplate(tx.QueryRow(ctx, `
+		INSERT INTO task_templates (workspacesid, name, title, description, priority, estimate_minutes, assignee_ids, label_ids, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING `+taskTemplateColumns,
+		template.WorkspaceID, template.Name, template.Title, template.Description, template.Priority,
+		template.EstimateMinutes, template.AssigneeIDs, template.LabelIDs, template.CreatedBy,
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("template already exists")
+		}
+		return nil, fmt.Errorf("failed to create task template: %w", err)
+	}
+
+	created.Subtasks, err = insertTemplateSubtasks(ctx, tx, created.ID, template.Subtasks)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetTaskTemplates получает шаблоны задач рабочего пространства без подзадач
+func (r *Repository) GetTaskTemplates(ctx context.Context, workspaceID int) ([]models.TaskTemplate, error) {
+	rows, err := r.db.Pool.Query(ctx,
+		`SELECT `+taskTemplateColumns+` FROM task_templates WHERE workspacesid = $1 ORDER BY name, id`,
+		workspaceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []models.TaskTemplate
+	for rows.Next() {
+		template, err := scanTaskTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task template: %w", err)
+		}
+		templates = append(templates, *template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// GetTaskTemplate получает шаблон задачи рабочего пространства вместе с подзадачами
+func (r *Repository) GetTaskTemplate(ctx context.Context, workspaceID, templateID int) (*models.TaskTemplate, error) {
+	template, err := scanTaskTemplate(r.db.Pool.QueryRow(ctx,
+		`SELECT `+taskTemplateColumns+` FROM task_templates WHERE workspacesid = $1 AND id = $2`,
+		workspaceID, templateID,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("template not found")
+		}
+		return nil, fmt.Errorf("failed to get task template: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, position, title, description, priority, estimate_minutes, due_offset_days, assignee_ids, label_ids
+		FROM task_template_subtasks
+		WHERE templatesid = $1
+		ORDER BY position, id
+	`, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template subtasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var subtask models.TaskTemplateSubtask
+		err := rows.Scan(
+			&subtask.ID,
+			&subtask.Position,
+			&subtask.Title,
+			&subtask.Description,
+			&subtask.Priority,
+			&subtask.EstimateMinutes,
+			&subtask.DueOffsetDays,
+			&subtask.AssigneeIDs,
+			&subtask.LabelIDs,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template subtask: %w", err)
+		}
+		template.Subtasks = append(template.Subtasks, subtask)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating template subtasks: %w", err)
+	}
+
+	return template, nil
+}
+
+// UpdateTaskTemplate заменяет содержимое шаблона задачи, включая список подзадач
+func (r *Repository) UpdateTaskTemplate(ctx context.Context, template *models.TaskTemplate) (*models.TaskTemplate, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updated, err := scanTaskTemplate(tx.QueryRow(ctx, `
+		UPDATE task_templates
+		SET name = $3, title = $4, description = $5, priority = $6, estimate_minutes = $7,
+		    assignee_ids = $8, label_ids = $9, updated_at = NOW()
+		WHERE workspacesid = $1 AND id = $2
+		RETURNING `+taskTemplateColumns,
+		template.WorkspaceID, template.ID, template.Name, template.Title, template.Description,
+		template.Priority, template.EstimateMinutes, template.AssigneeIDs, template.LabelIDs,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("template not found")
+		}
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("template already exists")
+		}
+		return nil, fmt.Errorf("failed to update task template: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM task_template_subtasks WHERE templatesid = $1`, updated.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete template subtasks: %w", err)
+	}
+	updated.Subtasks, err = insertTemplateSubtasks(ctx, tx, updated.ID, template.Subtasks)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteTaskTemplate удаляет шаблон задачи рабочего пространства
+func (r *Repository) DeleteTaskTemplate(ctx context.Context, workspaceID, templateID int) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM task_templates WHERE workspacesid = $1 AND id = $2`, workspaceID, templateID)
+	if err != nil {
+		return fmt.Errorf("failed to delete task template: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("template not found")
+	}
+
+	return nil
+}
+
+// insertTemplateSubtasks сохраняет подзадачи шаблона в порядке следования
+func insertTemplateSubtasks(ctx context.Context, tx pgx.Tx, templateID int, subtasks []models.TaskTemplateSubtask) ([]models.TaskTemplateSubtask, error) {
+	saved := make([]models.TaskTemplateSubtask, 0, len(subtasks))
+	for i, subtask := range subtasks {
+		subtask.Position = i
+		err := tx.QueryRow(ctx, `
+			INSERT INTO task_template_subtasks (templatesid, position, title, description, priority, estimate_minutes, due_offset_days, assignee_ids, label_ids)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id
+		`, templateID, subtask.Position, subtask.Title, subtask.Description, subtask.Priority,
+			subtask.EstimateMinutes, subtask.DueOffsetDays, subtask.AssigneeIDs, subtask.LabelIDs,
+		).Scan(&subtask.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create template subtask: %w", err)
+		}
+		saved = append(saved, subtask)
+	}
+	return saved, nil
+}
+
+// CreateTaskTree создает задачу root и ее подзадачи subtasks в одной транзакции.
+// Исполнители, не состоящие в РП, и метки другого РП пропускаются. Возвращает ID созданных задач
+// (первый — root) в порядке следования
+func (r *Repository) CreateTaskTree(ctx context.Context, root models.TaskDraft, subtasks []models.TaskDraft, chatID *int, note string) ([]int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rootID, err := insertTaskDraft(ctx, tx, root, note)
+	if err != nil {
+		return nil, err
+	}
+	taskIDs := []int{rootID}
+
+	if chatID != nil {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO "taskinchat" (chatsid, tasksid)
+			SELECT c.id, $2 FROM chats c WHERE c.id = $1 AND c.workspacesid = $3
+		`, *chatID, rootID, root.Task.WorkspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach task to chat: %w", err)
+		}
+		value := strconv.Itoa(*chatID)
+		err = insertTaskChange(ctx, tx, models.TaskChange{
+			TaskID:      rootID,
+			ActorID:     actorRef(root.Task.Creator),
+			Field:       models.ChangeFieldChat,
+			NewValue:    &value,
+			Description: fmt.Sprintf("Прикреплена к чату ID: %d", *chatID),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, subtask := range subtasks {
+		subtask.Task.ParentID = &rootID
+		subtaskID, err := insertTaskDraft(ctx, tx, subtask, note)
+		if err != nil {
+			return nil, err
+		}
+		taskIDs = append(taskIDs, subtaskID)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return taskIDs, nil
+}
+
+// insertTaskDraft создает задачу с исполнителями и метками и записывает их в историю
+func insertTaskDraft(ctx context.Context, tx pgx.Tx, draft models.TaskDraft, note string) (int, error) {
+	task := draft.Task
+
+	var taskID int
+	err := tx.QueryRow(ctx, `
+		INSERT INTO tasks (creator, workspacesid, title, description, date, status, parent_id, priority, estimate_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, task.Creator, task.WorkspaceID, task.Title, task.Description, task.Date, task.Status, task.ParentID, task.Priority, task.EstimateMinutes).Scan(&taskID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	if err := insertTaskWatcher(ctx, tx, taskID, task.Creator); err != nil {
+		return 0, err
+	}
+
+	changes := []models.TaskChange{{
+		Field:       models.ChangeFieldCreated,
+		NewValue:    &task.Title,
+		Description: fmt.Sprintf("Задача создана %s: %s", note, task.Title),
+	}}
+
+	// Исполнителями становятся только участники РП
+	rows, err := tx.Query(ctx, `
+		INSERT INTO "userintask" (tasksid, usersid)
+		SELECT DISTINCT $1, uiw.usersid FROM "userinworkspace" uiw
+		WHERE uiw.workspacesid = $2 AND uiw.usersid = ANY($3)
+		RETURNING usersid
+	`, taskID, task.WorkspaceID, draft.AssigneeIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add task assignees: %w", err)
+	}
+	assigneeIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return 0, fmt.Errorf("failed to add task assignees: %w", err)
+	}
+	for _, userID := range assigneeIDs {
+		value := strconv.Itoa(userID)
+		changes = append(changes, models.TaskChange{
+			Field:       models.ChangeFieldAssignee,
+			NewValue:    &value,
+			Description: fmt.Sprintf("Добавлен исполнитель с ID: %d", userID),
+		})
+	}
+
+	// Метки другого РП или удаленные метки пропускаются
+	rows, err = tx.Query(ctx, `
+		INSERT INTO task_label_links (tasksid, labelsid)
+		SELECT $1, l.id FROM task_labels l
+		WHERE l.workspacesid = $2 AND l.id = ANY($3)
+		RETURNING labelsid, (SELECT name FROM task_labels WHERE id = labelsid)
+	`, taskID, task.WorkspaceID, draft.LabelIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add task labels: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var labelID int
+		var labelName string
+		if err := rows.Scan(&labelID, &labelName); err != nil {
+			return 0, fmt.Errorf("failed to scan task label: %w", err)
+		}
+		value := strconv.Itoa(labelID)
+		changes = append(changes, models.TaskChange{
+			Field:       models.ChangeFieldLabel,
+			NewValue:    &value,
+			Description: fmt.Sprintf("Добавлена метка: %s", labelName),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to add task labels: %w", err)
+	}
+	rows.Close()
+
+	for _, change := range changes {
+		change.TaskID = taskID
+		change.ActorID = actorRef(task.Creator)
+		if err := insertTaskChange(ctx, tx, change); err != nil {
+			return 0, err
+		}
+	}
+
+	return taskID, nil
+}
+
+// ========== View Operations ==========
+
+// taskViewColumns — колонки сохраненного представления в порядке scanTaskView (v — task_views, u — владелец)
+const taskViewColumns = `v.id, v.workspacesid, v.owner_id, u.surname || ' ' || u.name, v.name, v.is_shared,
+	v.statuses, v.priorities, v.label_ids, v.assignee_id, v.creator_id, v.chat_id, v.overdue, v.due_from, v.due_to,
+	v.search, v.sort_field, v.sort_desc, v.group_by, v.created_at, v.updated_at`
+
+// scanTaskView читает строку с колонками taskViewColumns
+func scanTaskView(row pgx.Row) (*models.TaskView, error) {
+	var view models.TaskView
+	err := row.Scan(
+		&view.ID,
+		&view.WorkspaceID,
+		&view.OwnerID,
+		&view.OwnerName,
+		&view.Name,
+		&view.IsShared,
+		&view.Statuses,
+		&view.Priorities,
+		&view.LabelIDs,
+		&view.AssigneeID,
+		&view.CreatorID,
+		&view.ChatID,
+		&view.Overdue,
+		&view.DueFrom,
+		&view.DueTo,
+		&view.Search,
+		&view.SortField,
+		&view.SortDesc,
+		&view.GroupBy,
+		&view.CreatedAt,
+		&view.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// CreateTaskView сохраняет представление списка задач.
+// Если у владельца в РП уже есть представление с таким названием, возвращает ошибку "view already exists"
+func (r *Repository) CreateTaskView(ctx context.Context, view *models.TaskView) (*models.TaskView, error) {
+	created, err := scanTaskView(r.db.Pool.QueryRow(ctx, `
+		WITH v AS (
+			INSERT INTO task_views (workspacesid, owner_id, name, is_shared, statuses, priorities, label_ids,
+				assignee_id, creator_id, chat_id, overdue, due_from, due_to, search, sort_field, sort_desc, group_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			RETURNING *
+		)
+		SELECT `+taskViewColumns+`
+		FROM v
+		INNER JOIN users u ON u.id = v.owner_id
+	`,
+		view.WorkspaceID, view.OwnerID, view.Name, view.IsShared, view.Statuses, view.Priorities, view.LabelIDs,
+		view.AssigneeID, view.CreatorID, view.ChatID, view.Overdue, view.DueFrom, view.DueTo, view.Search,
+		view.SortField, view.SortDesc, view.GroupBy,
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("view already exists")
+		}
+		return nil, fmt.Errorf("failed to create task view: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetTaskViews получает представления РП, доступные пользователю: его собственные и общие
+func (r *Repository) GetTaskViews(ctx context.Context, workspaceID, userID int) ([]models.TaskView, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT `+taskViewColumns+`
+		FROM task_views v
+		INNER JOIN users u ON u.id = v.owner_id
+		WHERE v.workspacesid = $1 AND (v.owner_id = $2 OR v.is_shared)
+		ORDER BY v.owner_id <> $2, LOWER(v.name), v.id
+	`, workspaceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []models.TaskView
+	for rows.Next() {
+		view, err := scanTaskView(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task view: %w", err)
+		}
+		views = append(views, *view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task views: %w", err)
+	}
+
+	return views, nil
+}
+
+// GetTaskView получает представление РП, если оно доступно пользователю (собственное или общее).
+// Недоступное представление не отличается от несуществующего: возвращается ошибка "view not found"
+func (r *Repository) GetTaskView(ctx context.Context, workspaceID, viewID, userID int) (*models.TaskView, error) {
+	view, err := scanTaskView(r.db.Pool.QueryRow(ctx, `
+		SELECT `+taskViewColumns+`
+		FROM task_views v
+		INNER JOIN users u ON u.id = v.owner_id
+		WHERE v.workspacesid = $1 AND v.id = $2 AND (v.owner_id = $3 OR v.is_shared)
+	`, workspaceID, viewID, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("view not found")
+		}
+		return nil, fmt.Errorf("failed to get task view: %w", err)
+	}
+
+	return view, nil
+}
+
+// UpdateTaskView заменяет содержимое представления. Владелец представления не меняется
+func (r *Repository) UpdateTaskView(ctx context.Context, view *models.TaskView) (*models.TaskView, error) {
+	updated, err := scanTaskView(r.db.Pool.QueryRow(ctx, `
+		WITH v AS (
+			UPDATE task_views SET
+				name = $3, is_shared = $4, statuses = $5, priorities = $6, label_ids = $7,
+				assignee_id = $8, creator_id = $9, chat_id = $10, overdue = $11, due_from = $12, due_to = $13,
+				search = $14, sort_field = $15, sort_desc = $16, group_by = $17, updated_at = NOW()
+			WHERE workspacesid = $1 AND id = $2
+			RETURNING *
+		)
+		SELECT `+taskViewColumns+`
+		FROM v
+		INNER JOIN users u ON u.id = v.owner_id
+	`,
+		view.WorkspaceID, view.ID, view.Name, view.IsShared, view.Statuses, view.Priorities, view.LabelIDs,
+		view.AssigneeID, view.CreatorID, view.ChatID, view.Overdue, view.DueFrom, view.DueTo, view.Search,
+		view.SortField, view.SortDesc, view.GroupBy,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("view not found")
+		}
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("view already exists")
+		}
+		return nil, fmt.Errorf("failed to update task view: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteTaskView удаляет представление рабочего пространства
+func (r *Repository) DeleteTaskView(ctx context.Context, workspaceID, viewID int) error {
+	result, err := r.db.Pool.Exec(ctx,
+		`DELETE FROM task_views WHERE workspacesid = $1 AND id = $2`,
+		workspaceID, viewID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete task view: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("view not found")
+	}
+
+	return nil
+}
+
+// ========== Import Operations ==========
+
+// CreateTaskDrafts создает задачи в одной транзакции: либо все, либо ни одной.
+// Исполнители, не состоящие в РП, пропускаются. Возвращает ID созданных задач в порядке drafts
+func (r *Repository) CreateTaskDrafts(ctx context.Context, drafts []models.TaskDraft, note string) ([]int, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	taskIDs := make([]int, 0, len(drafts))
+	for _, draft := range drafts {
+		taskID, err := insertTaskDraft(ctx, tx, draft, note)
+		if err != nil {
+			return nil, err
+		}
+		taskIDs = append(taskIDs, taskID)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return taskIDs, nil
+}
+
+// GetWorkspaceUsersByLogins находит участников РП по логинам без учета регистра
+func (r *Repository) GetWorkspaceUsersByLogins(ctx context.Context, workspaceID int, logins []string) ([]models.WorkspaceUser, error) {
+	if len(logins) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT u.id, u.login, u.surname || ' ' || u.name
+		FROM users u
+		INNER JOIN "userinworkspace" uiw ON uiw.usersid = u.id AND uiw.workspacesid = $1
+		WHERE LOWER(u.login) = ANY($2)
+		ORDER BY u.id
+	`
+
+	return r.queryWorkspaceUsers(ctx, query, workspaceID, logins)
+}
+
+// GetTasksForExport получает задачи рабочего пространства с названиями статусов и логинами исполнителей
+func (r *Repository) GetTasksForExport(ctx context.Context, workspaceID int) ([]models.TaskExport, error) {
+	query := `
+		SELECT
+			t.title,
+			t.description,
+			t.date,
+			task_status_name(t.workspacesid, t.status),
+			COALESCE(array_agg(u.login ORDER BY u.login) FILTER (WHERE u.id IS NOT NULL), '{}')
+		FROM tasks t
+		LEFT JOIN "userintask" uit ON uit.tasksid = t.id
+		LEFT JOIN users u ON u.id = uit.usersid
+		WHERE t.workspacesid = $1
+		GROUP BY t.id
+		ORDER BY t.date, t.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks for export: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.TaskExport
+	for rows.Next() {
+		var task models.TaskExport
+		err := rows.Scan(
+			&task.Title,
+			&task.Description,
+			&task.Date,
+			&task.StatusName,
+			&task.AssigneeLogins,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task for export: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tasks for export: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// ========== Reminder Operations ==========
+
+const (
+	// maxReminderOffsetDays — максимальное количество дней до срока, за которое отправляется напоминание
+	maxReminderOffsetDays = 30
+	// overdueReminderWindowDays — напоминание о просрочке не отправляется, если срок прошел давно
+	// (например, для старых задач при первом запуске планировщика)
+	overdueReminderWindowDays = 7
+)
+
+// GetReminderSettings получает настройки напоминаний РП.
+// Если РП не задало свои настройки, возвращаются настройки по умолчанию с offsets defaultDays
+func (r *Repository) GetReminderSettings(ctx context.Context, workspaceID int, defaultDays []int) (*models.ReminderSettings, error) {
+	query := `
+		SELECT workspacesid, offset_days, notify_overdue, updated_by, updated_at
+		FROM workspace_task_reminder_settings
+		WHERE workspacesid = $1
+	`
+
+	var settings models.ReminderSettings
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID).Scan(
+		&settings.WorkspaceID,
+		&settings.OffsetDays,
+		&settings.NotifyOverdue,
+		&settings.UpdatedBy,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return &models.ReminderSettings{
+				WorkspaceID:   workspaceID,
+				OffsetDays:    defaultDays,
+				NotifyOverdue: true,
+				IsDefault:     true,
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to get reminder settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// SaveReminderSettings сохраняет настройки напоминаний РП
+func (r *Repository) SaveReminderSettings(ctx context.Context, settings *models.ReminderSettings) error {
+	query := `
+		INSERT INTO workspace_task_reminder_settings (workspacesid, offset_days, notify_overdue, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (workspacesid) DO UPDATE
+		SET offset_days = EXCLUDED.offset_days,
+		    notify_overdue = EXCLUDED.notify_overdue,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, settings.WorkspaceID, settings.OffsetDays, settings.NotifyOverdue, settings.UpdatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to save reminder settings: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteReminderSettings удаляет настройки напоминаний РП, после чего действуют настройки по умолчанию
+func (r *Repository) DeleteReminderSettings(ctx context.Context, workspaceID int) error {
+	query := `DELETE FROM workspace_task_reminder_settings WHERE workspacesid = $1`
+
+	if _, err := r.db.Pool.Exec(ctx, query, workspaceID); err != nil {
+		return fmt.Errorf("failed to delete reminder settings: %w", err)
+	}
+
+	return nil
+}
+
+// GetDueReminders находит незавершенные задачи с исполнителями, по которым сегодня нужно отправить напоминание.
+// Для приближающегося срока выбирается наименьший из наступивших offsets РП, для прошедшего —
+// напоминание о просрочке. Повторная отправка отсекается MarkReminderSent
+func (r *Repository) GetDueReminders(ctx context.Context, defaultDays []int) ([]models.TaskReminder, error) {
+	query := `
+		SELECT DISTINCT ON (t.id, rem.kind)
+			t.id,
+			t.title,
+			t.workspacesid,
+			w.name,
+			task_status_name(t.workspacesid, t.status),
+			t.date,
+			t.date - CURRENT_DATE,
+			rem.kind,
+			rem.offset_days
+		FROM tasks t
+		INNER JOIN workspaces w ON t.workspacesid = w.id
+		LEFT JOIN workspace_task_reminder_settings s ON s.workspacesid = t.workspacesid
+		CROSS JOIN LATERAL (
+			SELECT 'due' AS kind, o AS offset_days
+			FROM unnest(COALESCE(s.offset_days, $1::int4[])) AS o
+			WHERE t.date >= CURRENT_DATE AND t.date - o <= CURRENT_DATE
+			UNION ALL
+			SELECT 'overdue', 0
+			WHERE COALESCE(s.notify_overdue, TRUE) AND t.date < CURRENT_DATE
+		) rem
+		WHERE t.date BETWEEN CURRENT_DATE - $2::int AND CURRENT_DATE + $3::int
+		  AND task_status_category(t.workspacesid, t.status) NOT IN ('done', 'cancelled')
+		  AND EXISTS (SELECT 1 FROM "userintask" WHERE tasksid = t.id)
+		ORDER BY t.id, rem.kind, rem.offset_days
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, defaultDays, overdueReminderWindowDays, maxReminderOffsetDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []models.TaskReminder
+	for rows.Next() {
+		var reminder models.TaskReminder
+		err := rows.Scan(
+			&reminder.TaskID,
+			&reminder.TaskTitle,
+			&reminder.WorkspaceID,
+			&reminder.WorkspaceName,
+			&reminder.StatusName,
+			&reminder.DueDate,
+			&reminder.DaysLeft,
+			&reminder.Kind,
+			&reminder.OffsetDays,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reminder: %w", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// MarkReminderSent отмечает напоминание пользователю как отправленное.
+// Возвращает false, если такое напоминание уже было отправлено
+func (r *Repository) MarkReminderSent(ctx context.Context, reminder models.TaskReminder, userID int) (bool, error) {
+	query := `
+		INSERT INTO task_reminders_sent (tasksid, usersid, kind, offset_days, due_date)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, reminder.TaskID, userID, reminder.Kind, reminder.OffsetDays, reminder.DueDate)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark reminder sent: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// ReleaseReminder снимает отметку об отправке напоминания, чтобы оно было отправлено повторно
+func (r *Repository) ReleaseReminder(ctx context.Context, reminder models.TaskReminder, userID int) error {
+	query := `
+		DELETE FROM task_reminders_sent
+		WHERE tasksid = $1 AND usersid = $2 AND kind = $3 AND offset_days = $4 AND due_date = $5
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, reminder.TaskID, userID, reminder.Kind, reminder.OffsetDays, reminder.DueDate); err != nil {
+		return fmt.Errorf("failed to release reminder: %w", err)
+	}
+
+	return nil
+}
+
+// ========== Calendar Feed Operations ==========
+
+// calendarFeedPastDays — завершенные и отмененные задачи попадают в календарную ленту,
+// если их срок прошел не раньше указанного количества дней назад
+const calendarFeedPastDays = 90
+
+const calendarFeedColumns = `f.id, f.usersid, f.workspacesid, w.name, f.created_at, f.last_accessed_at`
+
+// hashCalendarToken возвращает SHA-256 токена календарной ленты в hex. В базе хранится только хеш,
+// поэтому утечка таблицы не раскрывает ссылки на ленты
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// scanCalendarFeed читает календарную ленту, выбранную с колонками calendarFeedColumns
+func scanCalendarFeed(row pgx.Row) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := row.Scan(
+		&feed.ID,
+		&feed.UserID,
+		&feed.WorkspaceID,
+		&feed.WorkspaceName,
+		&feed.CreatedAt,
+		&feed.LastAccessedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// SaveCalendarFeed создает календарную ленту пользователя с новым токеном, сохраняя только его хеш.
+// Если лента с той же областью уже есть, ее токен заменяется, и старая ссылка перестает работать
+func (r *Repository) SaveCalendarFeed(ctx context.Context, userID int, workspaceID *int, token string) (*models.CalendarFeed, error) {
+	query := `
+		WITH f AS (
+			INSERT INTO task_calendar_feeds (usersid, workspacesid, token_hash)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (usersid, (COALESCE(workspacesid, 0))) DO UPDATE
+			SET token_hash = EXCLUDED.token_hash,
+			    created_at = NOW(),
+			    last_accessed_at = NULL
+			RETURNING *
+		)
+		SELECT ` + calendarFeedColumns + `
+		FROM f
+		LEFT JOIN workspaces w ON w.id = f.workspacesid
+	`
+
+	feed, err := scanCalendarFeed(r.db.Pool.QueryRow(ctx, query, userID, workspaceID, hashCalendarToken(token)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save calendar feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// GetCalendarFeeds получает календарные ленты пользователя
+func (r *Repository) GetCalendarFeeds(ctx context.Context, userID int) ([]models.CalendarFeed, error) {
+	query := `
+		SELECT ` + calendarFeedColumns + `
+		FROM task_calendar_feeds f
+		LEFT JOIN workspaces w ON w.id = f.workspacesid
+		WHERE f.usersid = $1
+		ORDER BY f.workspacesid NULLS FIRST, f.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar feeds: %w", err)
+	}
+	defer rows.Close()
+
+	var feeds []models.CalendarFeed
+	for rows.Next() {
+		feed, err := scanCalendarFeed(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar feed: %w", err)
+		}
+		feeds = append(feeds, *feed)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating calendar feeds: %w", err)
+	}
+
+	return feeds, nil
+}
+
+// GetCalendarFeedByToken получает календарную ленту по хешу токена и отмечает время обращения к ней
+func (r *Repository) GetCalendarFeedByToken(ctx context.Context, token string) (*models.CalendarFeed, error) {
+	query := `
+		WITH f AS (
+			UPDATE task_calendar_feeds
+			SET last_accessed_at = NOW()
+			WHERE token_hash = $1
+			RETURNING *
+		)
+		SELECT ` + calendarFeedColumns + `
+		FROM f
+		LEFT JOIN workspaces w ON w.id = f.workspacesid
+	`
+
+	feed, err := scanCalendarFeed(r.db.Pool.QueryRow(ctx, query, hashCalendarToken(token)))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("calendar feed not found")
+		}
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// DeleteCalendarFeed удаляет календарную ленту пользователя, после чего ее ссылка перестает работать
+func (r *Repository) DeleteCalendarFeed(ctx context.Context, userID, feedID int) error {
+	result, err := r.db.Pool.Exec(ctx,
+		`DELETE FROM task_calendar_feeds WHERE id = $1 AND usersid = $2`,
+		feedID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete calendar feed: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("calendar feed not found")
+	}
+
+	return nil
+}
+
+// GetCalendarEvents получает задачи со сроками для календарной ленты пользователя: задачи, где он исполнитель,
+// в рабочих пространствах, участником которых он остается. workspaceID ограничивает выборку одним РП
+func (r *Repository) GetCalendarEvents(ctx context.Context, userID int, workspaceID *int) ([]models.CalendarEvent, error) {
+	query := `
+		SELECT
+			t.id,
+			w.name,
+			t.title,
+			t.description,
+			t.date,
+			task_status_name(t.workspacesid, t.status),
+			task_status_category(t.workspacesid, t.status),
+			t.priority,
+			COALESCE(ch.changes, 0),
+			COALESCE(ch.last_changed_at, t.date::timestamp)
+		FROM tasks t
+		INNER JOIN "userintask" uit ON uit.tasksid = t.id AND uit.usersid = $1
+		INNER JOIN "userinworkspace" uiw ON uiw.workspacesid = t.workspacesid AND uiw.usersid = $1
+		INNER JOIN workspaces w ON w.id = t.workspacesid
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS changes, MAX(changed_at) AS last_changed_at
+			FROM "taskchanges"
+			WHERE tasksid = t.id
+		) ch ON TRUE
+		WHERE ($2::int4 IS NULL OR t.workspacesid = $2)
+		  AND (t.date >= CURRENT_DATE - $3::int
+		       OR task_status_category(t.workspacesid, t.status) NOT IN ('done', 'cancelled'))
+		ORDER BY t.date, t.id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, workspaceID, calendarFeedPastDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calendar events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.CalendarEvent
+	for rows.Next() {
+		var event models.CalendarEvent
+		err := rows.Scan(
+			&event.TaskID,
+			&event.WorkspaceName,
+			&event.Title,
+			&event.Description,
+			&event.DueDate,
+			&event.StatusName,
+			&event.StatusCategory,
+			&event.Priority,
+			&event.Sequence,
+			&event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan calendar event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating calendar events: %w", err)
+	}
+
+	return events, nil
+}
+
+// ========== Assignee Operations ==========
+
+// AddTaskAssignee добавляет исполнителя к задаче
+func (r *Repository) AddTaskAssignee(ctx context.Context, taskID, userID, actorID int) error {
+	query := `
+		WITH added AS (
+			INSERT INTO "userintask" (tasksid, usersid)
+			SELECT $1, $2
+			WHERE NOT EXISTS (
+				SELECT 1 FROM "userintask" WHERE tasksid = $1 AND usersid = $2
+			)
+			RETURNING tasksid
+		)
+		UPDATE tasks SET version = version + 1 WHERE id IN (SELECT tasksid FROM added)
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, taskID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to add task assignee: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("assignee already exists or invalid task/user")
+	}
+
+	// Добавляем запись в историю изменений
+	value := strconv.Itoa(userID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldAssignee,
+		NewValue:    &value,
+		Description: fmt.Sprintf("Добавлен исполнитель с ID: %d", userID),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return nil
+}
+
+// GetTaskAssignees получает список исполнителей задачи
+func (r *Repository) GetTaskAssignees(ctx context.Context, taskID int) ([]models.TaskAssignee, error) {
+	query := `
+		SELECT
+			u.id as user_id,
+			u.login,
+			u.name,
+			u.surname,
+			uit.id::text as assigned_at
+		FROM "userintask" uit
+		INNER JOIN users u ON uit.usersid = u.id
+		WHERE uit.tasksid = $1
+		ORDER BY u.surname, u.name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task assignees: %w", err)
+	}
+	defer rows.Close()
+
+	var assignees []models.TaskAssignee
+	for rows.Next() {
+		var assignee models.TaskAssignee
+		err := rows.Scan(
+			&assignee.UserID,
+			&assignee.Login,
+			&assignee.Name,
+			&assignee.Surname,
+			&assignee.AssignedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan assignee: %w", err)
+		}
+		assignees = append(assignees, assignee)
+	}
+
+	return assignees, nil
+}
+
+// RemoveTaskAssignee удаляет исполнителя из задачи
+func (r *Repository) RemoveTaskAssignee(ctx context.Context, taskID, userID, actorID int) error {
+	query := `
+		WITH removed AS (
+			DELETE FROM "userintask" WHERE tasksid = $1 AND usersid = $2
+			RETURNING tasksid
+		)
+		UPDATE tasks SET version = version + 1 WHERE id IN (SELECT tasksid FROM removed)
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, taskID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove task assignee: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("assignee not found")
+	}
+
+	// Добавляем запись в историю изменений
+	value := strconv.Itoa(userID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldAssignee,
+		OldValue:    &value,
+		Description: fmt.Sprintf("Удален исполнитель с ID: %d", userID),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return nil
+}
+
+// ========== Watcher Operations ==========
+
+// insertTaskWatcher добавляет наблюдателя задачи, если он еще не наблюдает за ней.
+// userID 0 (например, автоматическое действие) пропускается
+func insertTaskWatcher(ctx context.Context, db execer, taskID, userID int) error {
+	if userID == 0 {
+		return nil
+	}
+
+	_, err := db.Exec(ctx, `
+		INSERT INTO task_watchers (tasksid, usersid)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, taskID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to add task watcher: %w", err)
+	}
+
+	return nil
+}
+
+// AddTaskWatcher добавляет пользователя в наблюдатели задачи
+func (r *Repository) AddTaskWatcher(ctx context.Context, taskID, userID int) error {
+	return insertTaskWatcher(ctx, r.db.Pool, taskID, userID)
+}
+
+// RemoveTaskWatcher удаляет пользователя из наблюдателей задачи
+func (r *Repository) RemoveTaskWatcher(ctx context.Context, taskID, userID int) error {
+	result, err := r.db.Pool.Exec(ctx,
+		`DELETE FROM task_watchers WHERE tasksid = $1 AND usersid = $2`,
+		taskID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove task watcher: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("watcher not found")
+	}
+
+	return nil
+}
+
+// GetTaskWatchers получает список наблюдателей задачи
+func (r *Repository) GetTaskWatchers(ctx context.Context, taskID int) ([]models.TaskWatcher, error) {
+	query := `
+		SELECT u.id, u.login, u.name, u.surname, tw.created_at
+		FROM task_watchers tw
+		INNER JOIN users u ON tw.usersid = u.id
+		WHERE tw.tasksid = $1
+		ORDER BY u.surname, u.name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task watchers: %w", err)
+	}
+	defer rows.Close()
+
+	var watchers []models.TaskWatcher
+	for rows.Next() {
+		var watcher models.TaskWatcher
+		err := rows.Scan(
+			&watcher.UserID,
+			&watcher.Login,
+			&watcher.Name,
+			&watcher.Surname,
+			&watcher.WatchedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan watcher: %w", err)
+		}
+		watchers = append(watchers, watcher)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating watchers: %w", err)
+	}
+
+	return watchers, nil
+}
+
+// GetWatchedTasks получает задачи, за которыми наблюдает пользователь, в рабочих пространствах,
+// участником которых он остается, и их общее количество. Задачи упорядочены по сроку
+func (r *Repository) GetWatchedTasks(ctx context.Context, userID int, workspaceID *int, includeClosed bool, limit, offset int) ([]models.TaskWithDetails, int, error) {
+	conditions := `
+		WHERE EXISTS (SELECT 1 FROM task_watchers tw WHERE tw.tasksid = t.id AND tw.usersid = $1)
+		  AND ($2::int4 IS NULL OR t.workspacesid = $2)
+		  AND ($3 OR task_status_category(t.workspacesid, t.status) NOT IN ('done', 'cancelled'))
+	`
+
+	var total int
+	countQuery := `
+		SELECT COUNT(*) FROM tasks t
+		INNER JOIN "userinworkspace" uiw ON t.workspacesid = uiw.workspacesid AND uiw.usersid = $1
+	` + conditions
+	if err := r.db.Pool.QueryRow(ctx, countQuery, userID, workspaceID, includeClosed).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count watched tasks: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, taskDetailsQuery+conditions+`
+		ORDER BY t.date, t.id
+		LIMIT $4 OFFSET $5
+	`, userID, workspaceID, includeClosed, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get watched tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.TaskWithDetails
+	for rows.Next() {
+		task, err := scanTaskDetails(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, *task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating watched tasks: %w", err)
+	}
+
+	return tasks, total, nil
+}
+
+// ========== Label Operations ==========
+
+// labelColumns — колонки метки в порядке полей scanLabel
+const labelColumns = `id, workspacesid, name, color, created_by, created_at`
+
+// scanLabel читает метку, выбранную с колонками labelColumns
+func scanLabel(row pgx.Row) (*models.Label, error) {
+	var label models.Label
+	err := row.Scan(
+		&label.ID,
+		&label.WorkspaceID,
+		&label.Name,
+		&label.Color,
+		&label.CreatedBy,
+		&label.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// CreateLabel создает метку рабочего пространства
+func (r *Repository) CreateLabel(ctx context.Context, label *models.Label) (*models.Label, error) {
+	created, err := scanLabel(r.db.Pool.QueryRow(ctx, `
+		INSERT INTO task_labels (workspacesid, name, color, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+labelColumns,
+		label.WorkspaceID, label.Name, label.Color, label.CreatedBy,
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("label already exists")
+		}
+		return nil, fmt.Errorf("failed to create label: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetLabels получает метки рабочего пространства
+func (r *Repository) GetLabels(ctx context.Context, workspaceID int) ([]models.Label, error) {
+	rows, err := r.db.Pool.Query(ctx,
+		`SELECT `+labelColumns+` FROM task_labels WHERE workspacesid = $1 ORDER BY name, id`,
+		workspaceID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels: %w", err)
+	}
+	defer rows.Close()
+
+	var labels []models.Label
+	for rows.Next() {
+		label, err := scanLabel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan label: %w", err)
+		}
+		labels = append(labels, *label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// UpdateLabel изменяет название и (или) цвет метки рабочего пространства
+func (r *Repository) UpdateLabel(ctx context.Context, workspaceID, labelID int, name, color *string) (*models.Label, error) {
+	label, err := scanLabel(r.db.Pool.QueryRow(ctx, `
+		UPDATE task_labels
+		SET name = COALESCE($3, name),
+		    color = COALESCE($4, color)
+		WHERE id = $1 AND workspacesid = $2
+		RETURNING `+labelColumns,
+		labelID, workspaceID, name, color,
+	))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("label not found")
+		}
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("label already exists")
+		}
+		return nil, fmt.Errorf("failed to update label: %w", err)
+	}
+
+	return label, nil
+}
+
+// DeleteLabel удаляет метку рабочего пространства вместе с ее привязками к задачам
+func (r *Repository) DeleteLabel(ctx context.Context, workspaceID, labelID int) error {
+	result, err := r.db.Pool.Exec(ctx,
+		`DELETE FROM task_labels WHERE id = $1 AND workspacesid = $2`,
+		labelID, workspaceID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete label: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("label not found")
+	}
+
+	return nil
+}
+
+// AddTaskLabel добавляет задаче метку ее рабочего пространства
+func (r *Repository) AddTaskLabel(ctx context.Context, taskID, labelID, actorID int) error {
+	var labelName string
+	err := r.db.Pool.QueryRow(ctx, `
+		WITH added AS (
+			INSERT INTO task_label_links (tasksid, labelsid)
+			SELECT t.id, l.id
+			FROM tasks t
+			INNER JOIN task_labels l ON l.workspacesid = t.workspacesid AND l.id = $2
+			WHERE t.id = $1
+			ON CONFLICT DO NOTHING
+			RETURNING tasksid, (SELECT name FROM task_labels WHERE id = $2) AS name
+		), bumped AS (
+			UPDATE tasks SET version = version + 1 WHERE id IN (SELECT tasksid FROM added)
+		)
+		SELECT name FROM added
+	`, taskID, labelID).Scan(&labelName)
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			return fmt.Errorf("failed to add task label: %w", err)
+		}
+		// Ничего не вставлено: метки нет в РП задачи или она уже добавлена
+		var exists bool
+		err = r.db.Pool.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM task_label_links WHERE tasksid = $1 AND labelsid = $2)`,
+			taskID, labelID,
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check task label: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("label already added")
+		}
+		return fmt.Errorf("label not found")
+	}
+
+	// Добавляем запись в историю изменений
+	value := strconv.Itoa(labelID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldLabel,
+		NewValue:    &value,
+		Description: fmt.Sprintf("Добавлена метка: %s", labelName),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return nil
+}
+
+// RemoveTaskLabel удаляет метку задачи
+func (r *Repository) RemoveTaskLabel(ctx context.Context, taskID, labelID, actorID int) error {
+	var labelName string
+	err := r.db.Pool.QueryRow(ctx, `
+		WITH removed AS (
+			DELETE FROM task_label_links ll
+			USING task_labels l
+			WHERE ll.tasksid = $1 AND ll.labelsid = $2 AND l.id = ll.labelsid
+			RETURNING ll.tasksid, l.name
+		), bumped AS (
+			UPDATE tasks SET version = version + 1 WHERE id IN (SELECT tasksid FROM removed)
+		)
+		SELECT name FROM removed
+	`, taskID, labelID).Scan(&labelName)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("task label not found")
+		}
+		return fmt.Errorf("failed to remove task label: %w", err)
+	}
+
+	// Добавляем запись в историю изменений
+	value := strconv.Itoa(labelID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldLabel,
+		OldValue:    &value,
+		Description: fmt.Sprintf("Удалена метка: %s", labelName),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return nil
+}
+
+// ========== Time Tracking Operations ==========
+
+// timeEntryQuery выбирает записи учета времени с именем пользователя в порядке полей scanTimeEntry
+const timeEntryQuery = `
+	SELECT
+		te.id,
+		te.tasksid,
+		te.usersid,
+		u.surname || ' ' || u.name as user_name,
+		te.started_at,
+		te.ended_at,
+		COALESCE(EXTRACT(EPOCH FROM te.ended_at - te.started_at)::INT8 / 60, 0) as minutes,
+		te.note,
+		te.created_at
+	FROM task_time_entries te
+	INNER JOIN users u ON te.usersid = u.id
+`
+
+// scanTimeEntry читает запись, выбранную timeEntryQuery
+func scanTimeEntry(row pgx.Row) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	err := row.Scan(
+		&entry.ID,
+		&entry.TaskID,
+		&entry.UserID,
+		&entry.UserName,
+		&entry.StartedAt,
+		&entry.EndedAt,
+		&entry.Minutes,
+		&entry.Note,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// StartTimer запускает таймер пользователя по задаче. У пользователя может быть запущен только один таймер
+func (r *Repository) StartTimer(ctx context.Context, taskID, userID int, note *string) (*models.TimeEntry, error) {
+	var entryID int
+	err := r.db.Pool.QueryRow(ctx, `
+		INSERT INTO task_time_entries (tasksid, usersid, started_at, note)
+		VALUES ($1, $2, NOW(), $3)
+		RETURNING id
+	`, taskID, userID, note).Scan(&entryID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("timer already running")
+		}
+		return nil, fmt.Errorf("failed to start timer: %w", err)
+	}
+
+	return r.GetTimeEntry(ctx, taskID, entryID)
+}
+
+// StopTimer останавливает запущенный таймер пользователя по задаче
+func (r *Repository) StopTimer(ctx context.Context, taskID, userID int) (*models.TimeEntry, error) {
+	var entryID int
+	err := r.db.Pool.QueryRow(ctx, `
+		UPDATE task_time_entries
+		SET ended_at = NOW()
+		WHERE tasksid = $1 AND usersid = $2 AND ended_at IS NULL
+		RETURNING id
+	`, taskID, userID).Scan(&entryID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("timer not running")
+		}
+		return nil, fmt.Errorf("failed to stop timer: %w", err)
+	}
+
+	return r.GetTimeEntry(ctx, taskID, entryID)
+}
+
+// CreateTimeEntry добавляет завершенную запись учета времени, внесенную вручную
+func (r *Repository) CreateTimeEntry(ctx context.Context, entry *models.TimeEntry) (*models.TimeEntry, error) {
+	var entryID int
+	err := r.db.Pool.QueryRow(ctx, `
+		INSERT INTO task_time_entries (tasksid, usersid, started_at, ended_at, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, entry.TaskID, entry.UserID, entry.StartedAt, entry.EndedAt, entry.Note).Scan(&entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create time entry: %w", err)
+	}
+
+	return r.GetTimeEntry(ctx, entry.TaskID, entryID)
+}
+
+// GetTimeEntry получает запись учета времени задачи
+func (r *Repository) GetTimeEntry(ctx context.Context, taskID, entryID int) (*models.TimeEntry, error) {
+	entry, err := scanTimeEntry(r.db.Pool.QueryRow(ctx, timeEntryQuery+`
+		WHERE te.tasksid = $1 AND te.id = $2
+	`, taskID, entryID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("time entry not found")
+		}
+		return nil, fmt.Errorf("failed to get time entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// GetTimeEntries получает записи учета времени задачи в порядке начала
+func (r *Repository) GetTimeEntries(ctx context.Context, taskID int) ([]models.TimeEntry, error) {
+	rows, err := r.db.Pool.Query(ctx, timeEntryQuery+`
+		WHERE te.tasksid = $1
+		ORDER BY te.started_at, te.id
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.TimeEntry
+	for rows.Next() {
+		entry, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan time entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating time entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteTimeEntry удаляет запись учета времени задачи
+func (r *Repository) DeleteTimeEntry(ctx context.Context, taskID, entryID int) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM task_time_entries WHERE tasksid = $1 AND id = $2`, taskID, entryID)
+	if err != nil {
+		return fmt.Errorf("failed to delete time entry: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("time entry not found")
+	}
+
+	return nil
 }
 
-// GetTasksByWorkspace получает список задач рабочего пространства
-func (r *Repository) GetTasksByWorkspace(ctx context.Context, workspaceID, userID int) ([]models.TaskWithDetails, error) {
+// GetTimeReport суммирует учтенное время рабочего пространства по пользователям и задачам.
+// Учитываются только завершенные записи; период фильтруется по дате начала записи
+func (r *Repository) GetTimeReport(ctx context.Context, filter models.TimeReportFilter) ([]models.TimeReportRow, error) {
+	conditions := []string{"t.workspacesid = $1", "te.ended_at IS NOT NULL"}
+	args := []interface{}{filter.WorkspaceID}
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("te.usersid = $%d", len(args)))
+	}
+	if filter.TaskID != nil {
+		args = append(args, *filter.TaskID)
+		conditions = append(conditions, fmt.Sprintf("te.tasksid = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("te.started_at::date >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("te.started_at::date <= $%d", len(args)))
+	}
+
 	query := `
 		SELECT
-			t.id,
-			t.creator,
-			u.surname || ' ' || u.name as creator_name,
-			u.surname as creator_surname,
-			t.workspacesid as workspace_id,
-			w.name as workspace_name,
-			t.title,
-			t.description,
-			t.date,
-			t.status,
-			COALESCE((SELECT COUNT(*) FROM "userintask" WHERE tasksid = t.id), 0) as assignee_count,
-			COALESCE((SELECT COUNT(*) FROM "taskinchat" WHERE tasksid = t.id), 0) as chat_count,
-			t.date as created_at
+			te.usersid,
+			u.surname || ' ' || u.name as user_name,
+			te.tasksid,
+			t.title as task_title,
+			COUNT(*) as entries,
+			SUM(EXTRACT(EPOCH FROM te.ended_at - te.started_at))::INT8 / 60 as minutes
+		FROM task_time_entries te
+		INNER JOIN tasks t ON te.tasksid = t.id
+		INNER JOIN users u ON te.usersid = u.id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY te.usersid, u.surname, u.name, te.tasksid, t.title
+		ORDER BY u.surname, u.name, te.usersid, te.tasksid
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time report: %w", err)
+	}
+	defer rows.Close()
+
+	var report []models.TimeReportRow
+	for rows.Next() {
+		var row models.TimeReportRow
+		err := rows.Scan(
+			&row.UserID,
+			&row.UserName,
+			&row.TaskID,
+			&row.TaskTitle,
+			&row.Entries,
+			&row.Minutes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan time report row: %w", err)
+		}
+		report = append(report, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating time report: %w", err)
+	}
+
+	return report, nil
+}
+
+// ========== Analytics Operations ==========
+
+// analyticsIntervalsQuery строит по истории изменений интервалы пребывания задач РП $1 в статусах.
+// Если задан $2, учитываются только задачи с этой меткой. Первый интервал задачи начинается с ее
+// создания в статусе, из которого был сделан первый переход (или в текущем, если переходов не было);
+// для задач без времени создания он начинается с -infinity. Категория статуса берется по текущему workflow
+const analyticsIntervalsQuery = `
+	WITH scoped AS (
+		SELECT t.id, t.workspacesid, t.status
 		FROM tasks t
-		INNER JOIN users u ON t.creator = u.id
-		INNER JOIN workspaces w ON t.workspacesid = w.id
-		INNER JOIN "userinworkspace" uiw ON w.id = uiw.workspacesid AND uiw.usersid = $2
 		WHERE t.workspacesid = $1
-		ORDER BY t.date DESC, t.id DESC
+			AND ($2::INT4 IS NULL OR EXISTS (
+				SELECT 1 FROM task_label_links tll WHERE tll.tasksid = t.id AND tll.labelsid = $2
+			))
+	),
+	status_changes AS (
+		SELECT tc.id, tc.tasksid, tc.changed_at, tc.old_value, tc.new_value
+		FROM taskchanges tc
+		INNER JOIN scoped s ON s.id = tc.tasksid
+		WHERE tc.field = 'status' AND tc.changed_at IS NOT NULL AND tc.new_value ~ '^[0-9]+$'
+	),
+	events AS (
+		SELECT s.id AS tasksid, s.workspacesid, 0 AS seq,
+			COALESCE(
+				(SELECT MIN(tc.changed_at) FROM taskchanges tc
+					WHERE tc.tasksid = s.id AND tc.field = 'created' AND tc.changed_at IS NOT NULL),
+				'-infinity'::TIMESTAMP
+			) AS changed_at,
+			COALESCE(
+				(SELECT sc.old_value::INT4 FROM status_changes sc
+					WHERE sc.tasksid = s.id AND sc.old_value ~ '^[0-9]+$'
+					ORDER BY sc.changed_at, sc.id LIMIT 1),
+				s.status
+			) AS status
+		FROM scoped s
+		UNION ALL
+		SELECT sc.tasksid, s.workspacesid, sc.id AS seq, sc.changed_at, sc.new_value::INT4 AS status
+		FROM status_changes sc
+		INNER JOIN scoped s ON s.id = sc.tasksid
+	),
+	categorized AS (
+		SELECT e.*, task_status_category(e.workspacesid, e.status) AS category
+		FROM events e
+	),
+	intervals AS (
+		SELECT
+			c.tasksid,
+			c.workspacesid,
+			c.status,
+			c.category,
+			c.changed_at AS started_at,
+			LEAD(c.changed_at) OVER w AS ended_at,
+			LAG(c.category) OVER w AS prev_category
+		FROM categorized c
+		WINDOW w AS (PARTITION BY c.tasksid ORDER BY c.changed_at, c.seq)
+	)
+`
+
+// analyticsCompletion истинно для интервала, которым задача перешла в категорию done
+const analyticsCompletion = `(i.category = 'done' AND i.prev_category IS NOT NULL AND i.prev_category <> 'done')`
+
+// analyticsCancellation истинно для интервала, которым задача перешла в категорию cancelled
+const analyticsCancellation = `(i.category = 'cancelled' AND i.prev_category IS NOT NULL AND i.prev_category <> 'cancelled')`
+
+// analyticsEndedInPeriod истинно для интервала с известным началом, закончившегося в периоде [$3, $4]
+const analyticsEndedInPeriod = `isfinite(i.started_at) AND i.ended_at >= $3::DATE AND i.ended_at < $4::DATE + 1`
+
+// GetStatusTimes возвращает среднее время пребывания задач в каждом статусе.
+// Учитываются пребывания, закончившиеся в периоде, и известные по времени создания
+func (r *Repository) GetStatusTimes(ctx context.Context, filter models.AnalyticsFilter) ([]models.StatusTimeRow, error) {
+	query := analyticsIntervalsQuery + `
+		SELECT
+			i.status,
+			task_status_name($1, i.status) AS status_name,
+			i.category,
+			COUNT(*) FILTER (WHERE ` + analyticsEndedInPeriod + `) AS transitions,
+			COALESCE(AVG(CASE WHEN ` + analyticsEndedInPeriod + ` THEN EXTRACT(EPOCH FROM i.ended_at - i.started_at) END), 0)::FLOAT8 AS avg_seconds,
+			COUNT(*) FILTER (WHERE i.ended_at IS NULL) AS current_tasks
+		FROM intervals i
+		GROUP BY i.status, i.category
+		ORDER BY i.status
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, workspaceID, userID)
+	rows, err := r.db.Pool.Query(ctx, query, filter.WorkspaceID, filter.LabelID, filter.From, filter.To)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get tasks: %w", err)
+		return nil, fmt.Errorf("failed to get status times: %w", err)
 	}
 	defer rows.Close()
 
-	var tasks []models.TaskWithDetails
+	var result []models.StatusTimeRow
+	for rows.Next() {
+		var row models.StatusTimeRow
+		if err := rows.Scan(&row.Status, &row.StatusName, &row.Category, &row.Transitions, &row.AvgSeconds, &row.CurrentTasks); err != nil {
+			return nil, fmt.Errorf("failed to scan status time: %w", err)
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating status times: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetCycleTime возвращает среднее время выполнения задач, завершенных в периоде.
+// Если задача завершалась несколько раз, учитывается последнее завершение в периоде
+func (r *Repository) GetCycleTime(ctx context.Context, filter models.AnalyticsFilter) (*models.CycleTimeSummary, error) {
+	query := analyticsIntervalsQuery + `,
+	completions AS (
+		SELECT i.tasksid, MAX(i.started_at) AS completed_at
+		FROM intervals i
+		WHERE ` + analyticsCompletion + ` AND i.started_at >= $3::DATE AND i.started_at < $4::DATE + 1
+		GROUP BY i.tasksid
+	),
+	marks AS (
+		SELECT
+			c.tasksid,
+			c.completed_at,
+			MIN(i.started_at) FILTER (WHERE i.category = 'in_progress' AND i.started_at <= c.completed_at) AS work_started_at,
+			MIN(i.started_at) AS created_at
+		FROM completions c
+		INNER JOIN intervals i ON i.tasksid = c.tasksid
+		GROUP BY c.tasksid, c.completed_at
+	)
+		SELECT
+			COUNT(*) AS completed,
+			AVG(CASE WHEN isfinite(m.work_started_at) THEN EXTRACT(EPOCH FROM m.completed_at - m.work_started_at) END)::FLOAT8 AS avg_cycle_seconds,
+			AVG(CASE WHEN isfinite(m.created_at) THEN EXTRACT(EPOCH FROM m.completed_at - m.created_at) END)::FLOAT8 AS avg_lead_seconds
+		FROM marks m
+	`
+
+	var summary models.CycleTimeSummary
+	err := r.db.Pool.QueryRow(ctx, query, filter.WorkspaceID, filter.LabelID, filter.From, filter.To).Scan(
+		&summary.Completed,
+		&summary.AvgCycleSeconds,
+		&summary.AvgLeadSeconds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cycle time: %w", err)
+	}
+
+	return &summary, nil
+}
+
+// GetThroughput возвращает по неделям периода количество созданных, завершенных и отмененных задач.
+// Первая и последняя недели учитываются только в пределах периода
+func (r *Repository) GetThroughput(ctx context.Context, filter models.AnalyticsFilter) ([]models.ThroughputWeek, error) {
+	query := analyticsIntervalsQuery + `,
+	weeks AS (
+		SELECT generate_series(date_trunc('week', $3::DATE::TIMESTAMP), $4::DATE::TIMESTAMP, INTERVAL '1 week') AS week_start
+	)
+		SELECT
+			w.week_start,
+			COUNT(DISTINCT i.tasksid) FILTER (WHERE i.prev_category IS NULL) AS created,
+			COUNT(DISTINCT i.tasksid) FILTER (WHERE ` + analyticsCompletion + `) AS completed,
+			COUNT(DISTINCT i.tasksid) FILTER (WHERE ` + analyticsCancellation + `) AS cancelled
+		FROM weeks w
+		LEFT JOIN intervals i ON i.started_at >= GREATEST(w.week_start, $3::DATE)
+			AND i.started_at < LEAST(w.week_start + INTERVAL '1 week', $4::DATE + 1)
+		GROUP BY w.week_start
+		ORDER BY w.week_start
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.WorkspaceID, filter.LabelID, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get throughput: %w", err)
+	}
+	defer rows.Close()
+
+	var weeks []models.ThroughputWeek
+	for rows.Next() {
+		var week models.ThroughputWeek
+		if err := rows.Scan(&week.WeekStart, &week.Created, &week.Completed, &week.Cancelled); err != nil {
+			return nil, fmt.Errorf("failed to scan throughput week: %w", err)
+		}
+		weeks = append(weeks, week)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating throughput weeks: %w", err)
+	}
+
+	return weeks, nil
+}
+
+// GetBurndown возвращает по дням периода количество незавершенных на конец дня задач,
+// а также созданных и завершенных за день. Отмененные задачи не считаются незавершенными
+func (r *Repository) GetBurndown(ctx context.Context, filter models.AnalyticsFilter) ([]models.BurndownDay, error) {
+	query := analyticsIntervalsQuery + `,
+	days AS (
+		SELECT generate_series($3::DATE::TIMESTAMP, $4::DATE::TIMESTAMP, INTERVAL '1 day')::DATE AS day
+	)
+		SELECT
+			d.day,
+			COUNT(DISTINCT i.tasksid) FILTER (
+				WHERE i.category NOT IN ('done', 'cancelled')
+					AND i.started_at < d.day + 1 AND (i.ended_at IS NULL OR i.ended_at >= d.day + 1)
+			) AS remaining,
+			COUNT(DISTINCT i.tasksid) FILTER (
+				WHERE i.prev_category IS NULL AND i.started_at >= d.day
+			) AS created,
+			COUNT(DISTINCT i.tasksid) FILTER (
+				WHERE ` + analyticsCompletion + ` AND i.started_at >= d.day
+			) AS completed
+		FROM days d
+		LEFT JOIN intervals i ON i.started_at < d.day + 1 AND (i.ended_at IS NULL OR i.ended_at >= d.day)
+		GROUP BY d.day
+		ORDER BY d.day
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.WorkspaceID, filter.LabelID, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get burndown: %w", err)
+	}
+	defer rows.Close()
+
+	var days []models.BurndownDay
+	for rows.Next() {
+		var day models.BurndownDay
+		if err := rows.Scan(&day.Day, &day.Remaining, &day.Created, &day.Completed); err != nil {
+			return nil, fmt.Errorf("failed to scan burndown day: %w", err)
+		}
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating burndown days: %w", err)
+	}
+
+	return days, nil
+}
+
+// ========== Chat Operations ==========
+
+// AttachTaskToChat прикрепляет задачу к чату
+func (r *Repository) AttachTaskToChat(ctx context.Context, taskID, chatID, actorID int) error {
+	query := `
+		WITH attached AS (
+			INSERT INTO "taskinchat" (chatsid, tasksid)
+			SELECT $1, $2
+			WHERE NOT EXISTS (
+				SELECT 1 FROM "taskinchat" WHERE chatsid = $1 AND tasksid = $2
+			)
+			RETURNING tasksid
+		)
+		UPDATE tasks SET version = version + 1 WHERE id IN (SELECT tasksid FROM attached)
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, chatID, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to attach task to chat: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("task already attached to chat or invalid chat/task")
+	}
+
+	// Добавляем запись в историю изменений
+	value := strconv.Itoa(chatID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldChat,
+		NewValue:    &value,
+		Description: fmt.Sprintf("Прикреплена к чату ID: %d", chatID),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return nil
+}
+
+// GetTaskChats получает список чатов, к которым прикреплена задача
+func (r *Repository) GetTaskChats(ctx context.Context, taskID int) ([]models.TaskChat, error) {
+	query := `
+		SELECT
+			c.id as chat_id,
+			c.name as chat_name,
+			c.type as chat_type,
+			c.workspacesid as workspace_id,
+			tic.id::text as attached_at
+		FROM "taskinchat" tic
+		INNER JOIN chats c ON tic.chatsid = c.id
+		WHERE tic.tasksid = $1
+		ORDER BY c.name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task chats: %w", err)
+	}
+	defer rows.Close()
+
+	var chats []models.TaskChat
 	for rows.Next() {
-		var task models.TaskWithDetails
+		var chat models.TaskChat
 		err := rows.Scan(
-			&task.ID,
-			&task.Creator,
-			&task.CreatorName,
-			&task.CreatorSurname,
-			&task.WorkspaceID,
-			&task.WorkspaceName,
-			&task.Title,
-			&task.Description,
-			&task.Date,
-			&task.Status,
-			&task.AssigneeCount,
-			&task.ChatCount,
-			&task.CreatedAt,
+			&chat.ChatID,
+			&chat.ChatName,
+			&chat.ChatType,
+			&chat.WorkspaceID,
+			&chat.AttachedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
+			return nil, fmt.Errorf("failed to scan chat: %w", err)
 		}
-		tasks = append(tasks, task)
+		chats = append(chats, chat)
 	}
 
-	return tasks, nil
+	return chats, nil
 }
 
-// GetTaskByID получает информацию о задаче по ID
-func (r *Repository) GetTaskByID(ctx context.Context, taskID, userID int) (*models.TaskWithDetails, error) {
+// DetachTaskFromChat открепляет задачу от чата
+func (r *Repository) DetachTaskFromChat(ctx context.Context, taskID, chatID, actorID int) error {
 	query := `
-		SELECT
-			t.id,
-			t.creator,
-			u.surname || ' ' || u.name as creator_name,
-			u.surname as creator_surname,
-			t.workspacesid as workspace_id,
-			w.name as workspace_name,
-			t.title,
-			t.description,
-			t.date,
-			t.status,
-			COALESCE((SELECT COUNT(*) FROM "userintask" WHERE tasksid = t.id), 0) as assignee_count,
-			COALESCE((SELECT COUNT(*) FROM "taskinchat" WHERE tasksid = t.id), 0) as chat_count,
-			t.date as created_at
-		FROM tasks t
-		INNER JOIN users u ON t.creator = u.id
-		INNER JOIN workspaces w ON t.workspacesid = w.id
-		INNER JOIN "userinworkspace" uiw ON w.id = uiw.workspacesid AND uiw.usersid = $2
-		WHERE t.id = $1
+		WITH detached AS (
+			DELETE FROM "taskinchat" WHERE chatsid = $1 AND tasksid = $2
+			RETURNING tasksid
+		)
+		UPDATE tasks SET version = version + 1 WHERE id IN (SELECT tasksid FROM detached)
 	`
 
-	var task models.TaskWithDetails
-	err := r.db.Pool.QueryRow(ctx, query, taskID, userID).Scan(
-		&task.ID,
-		&task.Creator,
-		&task.CreatorName,
-		&task.CreatorSurname,
-		&task.WorkspaceID,
-		&task.WorkspaceName,
-		&task.Title,
-		&task.Description,
-		&task.Date,
-		&task.Status,
-		&task.AssigneeCount,
-		&task.ChatCount,
-		&task.CreatedAt,
+	result, err := r.db.Pool.Exec(ctx, query, chatID, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to detach task from chat: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("task not attached to chat")
+	}
+
+	// Добавляем запись в историю изменений
+	value := strconv.Itoa(chatID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldChat,
+		OldValue:    &value,
+		Description: fmt.Sprintf("Откреплена от чата ID: %d", chatID),
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	}
+
+	return nil
+}
+
+// GetChatMessage получает сообщение чата вместе с рабочим пространством и статусом архивации чата
+func (r *Repository) GetChatMessage(ctx context.Context, messageID int) (*models.ChatMessage, error) {
+	query := `
+		SELECT m.id, m.chatsid, c.workspacesid, m.text, c.archived_at
+		FROM messages m
+		INNER JOIN chats c ON m.chatsid = c.id
+		WHERE m.id = $1
+	`
+
+	var message models.ChatMessage
+	err := r.db.Pool.QueryRow(ctx, query, messageID).Scan(
+		&message.ID,
+		&message.ChatID,
+		&message.WorkspaceID,
+		&message.Text,
+		&message.ArchivedAt,
 	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("message not found")
+		}
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	return &message, nil
+}
+
+// ========== Comment Operations ==========
+
+// CreateTaskComment создает комментарий к задаче и сохраняет упомянутых пользователей
+func (r *Repository) CreateTaskComment(ctx context.Context, taskID, authorID int, text string, mentionIDs []int) (*models.TaskComment, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var commentID int
+	err = tx.QueryRow(ctx, `
+		INSERT INTO task_comments (tasksid, author_id, text)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, taskID, authorID, text).Scan(&commentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task comment: %w", err)
+	}
+
+	if err := replaceCommentMentions(ctx, tx, commentID, mentionIDs); err != nil {
+		return nil, err
+	}
+
+	// Автор комментария начинает наблюдать за задачей
+	if err := insertTaskWatcher(ctx, tx, taskID, authorID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit task comment: %w", err)
+	}
 
+	// Добавляем запись в историю изменений
+	value := strconv.Itoa(commentID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(authorID),
+		Field:       models.ChangeFieldComment,
+		NewValue:    &value,
+		Description: "Добавлен комментарий",
+	})
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("task not found")
-		}
-		return nil, fmt.Errorf("failed to get task: %w", err)
+		fmt.Printf("Warning: failed to add task change: %v\n", err)
 	}
 
-	return &task, nil
+	return r.GetTaskComment(ctx, taskID, commentID)
 }
 
-// UpdateTask обновляет задачу
-func (r *Repository) UpdateTask(ctx context.Context, taskID int, title, description *string, date *time.Time) error {
+// GetTaskComments получает комментарии задачи в порядке добавления и их общее количество
+func (r *Repository) GetTaskComments(ctx context.Context, taskID, limit, offset int) ([]models.TaskComment, int, error) {
+	var total int
+	err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM task_comments WHERE tasksid = $1`, taskID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count task comments: %w", err)
+	}
+
 	query := `
-		UPDATE tasks
-		SET title = COALESCE($2, title),
-		    description = COALESCE($3, description),
-		    date = COALESCE($4, date)
-		WHERE id = $1
+		SELECT
+			tc.id,
+			tc.tasksid,
+			tc.author_id,
+			u.surname || ' ' || u.name as author_name,
+			tc.text,
+			tc.created_at,
+			tc.updated_at
+		FROM task_comments tc
+		LEFT JOIN users u ON tc.author_id = u.id
+		WHERE tc.tasksid = $1
+		ORDER BY tc.id
+		LIMIT $2 OFFSET $3
 	`
 
-	result, err := r.db.Pool.Exec(ctx, query, taskID, title, description, date)
+	rows, err := r.db.Pool.Query(ctx, query, taskID, limit, offset)
 	if err != nil {
-		return fmt.Errorf("failed to update task: %w", err)
+		return nil, 0, fmt.Errorf("failed to get task comments: %w", err)
 	}
+	defer rows.Close()
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("task not found")
+	var comments []models.TaskComment
+	var commentIDs []int
+	for rows.Next() {
+		var comment models.TaskComment
+		err := rows.Scan(
+			&comment.ID,
+			&comment.TaskID,
+			&comment.AuthorID,
+			&comment.AuthorName,
+			&comment.Text,
+			&comment.CreatedAt,
+			&comment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan task comment: %w", err)
+		}
+		comments = append(comments, comment)
+		commentIDs = append(commentIDs, comment.ID)
 	}
 
-	// Добавляем запись в историю изменений
-	changeDesc := "Задача обновлена"
-	if err := r.addTaskChange(ctx, taskID, changeDesc); err != nil {
-		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating task comments: %w", err)
 	}
 
-	return nil
+	mentions, err := r.getCommentMentions(ctx, commentIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range comments {
+		comments[i].Mentions = mentions[comments[i].ID]
+	}
+
+	return comments, total, nil
 }
 
-// DeleteTask удаляет задачу
-func (r *Repository) DeleteTask(ctx context.Context, taskID int) error {
-	query := `DELETE FROM tasks WHERE id = $1`
+// GetTaskComment получает комментарий задачи по ID
+func (r *Repository) GetTaskComment(ctx context.Context, taskID, commentID int) (*models.TaskComment, error) {
+	query := `
+		SELECT
+			tc.id,
+			tc.tasksid,
+			tc.author_id,
+			u.surname || ' ' || u.name as author_name,
+			tc.text,
+			tc.created_at,
+			tc.updated_at
+		FROM task_comments tc
+		LEFT JOIN users u ON tc.author_id = u.id
+		WHERE tc.id = $1 AND tc.tasksid = $2
+	`
 
-	result, err := r.db.Pool.Exec(ctx, query, taskID)
+	var comment models.TaskComment
+	err := r.db.Pool.QueryRow(ctx, query, commentID, taskID).Scan(
+		&comment.ID,
+		&comment.TaskID,
+		&comment.AuthorID,
+		&comment.AuthorName,
+		&comment.Text,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to delete task: %w", err)
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("comment not found")
+		}
+		return nil, fmt.Errorf("failed to get task comment: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("task not found")
+	mentions, err := r.getCommentMentions(ctx, []int{comment.ID})
+	if err != nil {
+		return nil, err
 	}
+	comment.Mentions = mentions[comment.ID]
 
-	return nil
+	return &comment, nil
 }
 
-// UpdateTaskStatus обновляет статус задачи
-func (r *Repository) UpdateTaskStatus(ctx context.Context, taskID, status int) error {
-	query := `UPDATE tasks SET status = $2 WHERE id = $1`
+// UpdateTaskComment изменяет текст комментария и список упомянутых пользователей
+func (r *Repository) UpdateTaskComment(ctx context.Context, taskID, commentID, actorID int, text string, mentionIDs []int) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	result, err := r.db.Pool.Exec(ctx, query, taskID, status)
+	result, err := tx.Exec(ctx, `
+		UPDATE task_comments SET text = $3, updated_at = NOW()
+		WHERE id = $1 AND tasksid = $2
+	`, commentID, taskID, text)
 	if err != nil {
-		return fmt.Errorf("failed to update task status: %w", err)
+		return fmt.Errorf("failed to update task comment: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("task not found")
+		return fmt.Errorf("comment not found")
+	}
+
+	if err := replaceCommentMentions(ctx, tx, commentID, mentionIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit task comment: %w", err)
 	}
 
 	// Добавляем запись в историю изменений
-	changeDesc := fmt.Sprintf("Статус изменен на: %s", models.GetTaskStatusName(status))
-	if err := r.addTaskChange(ctx, taskID, changeDesc); err != nil {
+	value := strconv.Itoa(commentID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldComment,
+		OldValue:    &value,
+		NewValue:    &value,
+		Description: "Комментарий изменен",
+	})
+	if err != nil {
 		fmt.Printf("Warning: failed to add task change: %v\n", err)
 	}
 
 	return nil
 }
 
-// ========== Assignee Operations ==========
-
-// AddTaskAssignee добавляет исполнителя к задаче
-func (r *Repository) AddTaskAssignee(ctx context.Context, taskID, userID int) error {
-	query := `
-		INSERT INTO "userintask" (tasksid, usersid)
-		SELECT $1, $2
-		WHERE NOT EXISTS (
-			SELECT 1 FROM "userintask" WHERE tasksid = $1 AND usersid = $2
-		)
-	`
-
-	result, err := r.db.Pool.Exec(ctx, query, taskID, userID)
+// DeleteTaskComment удаляет комментарий задачи
+func (r *Repository) DeleteTaskComment(ctx context.Context, taskID, commentID, actorID int) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM task_comments WHERE id = $1 AND tasksid = $2`, commentID, taskID)
 	if err != nil {
-		return fmt.Errorf("failed to add task assignee: %w", err)
+		return fmt.Errorf("failed to delete task comment: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("assignee already exists or invalid task/user")
+		return fmt.Errorf("comment not found")
 	}
 
 	// Добавляем запись в историю изменений
-	changeDesc := fmt.Sprintf("Добавлен исполнитель с ID: %d", userID)
-	if err := r.addTaskChange(ctx, taskID, changeDesc); err != nil {
+	value := strconv.Itoa(commentID)
+	err = r.addTaskChange(ctx, models.TaskChange{
+		TaskID:      taskID,
+		ActorID:     actorRef(actorID),
+		Field:       models.ChangeFieldComment,
+		OldValue:    &value,
+		Description: "Комментарий удален",
+	})
+	if err != nil {
 		fmt.Printf("Warning: failed to add task change: %v\n", err)
 	}
 
 	return nil
 }
 
-// GetTaskAssignees получает список исполнителей задачи
-func (r *Repository) GetTaskAssignees(ctx context.Context, taskID int) ([]models.TaskAssignee, error) {
+// ResolveWorkspaceMentions находит участников РП по упоминаниям: полному логину или его части до @
+func (r *Repository) ResolveWorkspaceMentions(ctx context.Context, workspaceID int, mentions []string) ([]models.WorkspaceUser, error) {
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+
 	query := `
-		SELECT
-			u.id as user_id,
-			u.login,
-			u.name,
-			u.surname,
-			uit.id::text as assigned_at
-		FROM "userintask" uit
-		INNER JOIN users u ON uit.usersid = u.id
-		WHERE uit.tasksid = $1
-		ORDER BY u.surname, u.name
+		SELECT u.id, u.login, u.surname || ' ' || u.name
+		FROM users u
+		INNER JOIN "userinworkspace" uiw ON uiw.usersid = u.id AND uiw.workspacesid = $1
+		WHERE LOWER(u.login) = ANY($2) OR LOWER(split_part(u.login, '@', 1)) = ANY($2)
+		ORDER BY u.id
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, taskID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get task assignees: %w", err)
-	}
-	defer rows.Close()
+	return r.queryWorkspaceUsers(ctx, query, workspaceID, mentions)
+}
 
-	var assignees []models.TaskAssignee
-	for rows.Next() {
-		var assignee models.TaskAssignee
-		err := rows.Scan(
-			&assignee.UserID,
-			&assignee.Login,
-			&assignee.Name,
-			&assignee.Surname,
-			&assignee.AssignedAt,
+// GetTaskParticipants возвращает создателя, исполнителей и наблюдателей задачи
+func (r *Repository) GetTaskParticipants(ctx context.Context, taskID int) ([]models.WorkspaceUser, error) {
+	query := `
+		SELECT u.id, u.login, u.surname || ' ' || u.name
+		FROM users u
+		WHERE u.id IN (
+			SELECT creator FROM tasks WHERE id = $1
+			UNION
+			SELECT usersid FROM "userintask" WHERE tasksid = $1
+			UNION
+			SELECT usersid FROM task_watchers WHERE tasksid = $1
 		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan assignee: %w", err)
-		}
-		assignees = append(assignees, assignee)
-	}
+		ORDER BY u.id
+	`
 
-	return assignees, nil
+	return r.queryWorkspaceUsers(ctx, query, taskID)
 }
 
-// RemoveTaskAssignee удаляет исполнителя из задачи
-func (r *Repository) RemoveTaskAssignee(ctx context.Context, taskID, userID int) error {
-	query := `DELETE FROM "userintask" WHERE tasksid = $1 AND usersid = $2`
+// GetDNDSchedules возвращает расписания «не беспокоить» пользователей, у которых они заданы.
+// Расписания настраиваются в chat-service; проверка расписания — в общем модуле dnd
+func (r *Repository) GetDNDSchedules(ctx context.Context, userIDs []int) (map[int]*dnd.Schedule, error) {
+	schedules := make(map[int]*dnd.Schedule)
+	if len(userIDs) == 0 {
+		return schedules, nil
+	}
 
-	result, err := r.db.Pool.Exec(ctx, query, taskID, userID)
+	query := `
+		SELECT usersid, enabled, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), timezone
+		FROM user_dnd_schedules
+		WHERE usersid = ANY($1)
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userIDs)
 	if err != nil {
-		return fmt.Errorf("failed to remove task assignee: %w", err)
+		return nil, fmt.Errorf("failed to get dnd schedules: %w", err)
 	}
+	defer rows.Close()
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("assignee not found")
+	for rows.Next() {
+		var userID int
+		var schedule dnd.Schedule
+		if err := rows.Scan(&userID, &schedule.Enabled, &schedule.StartTime, &schedule.EndTime, &schedule.Timezone); err != nil {
+			return nil, fmt.Errorf("failed to scan dnd schedule: %w", err)
+		}
+		schedules[userID] = &schedule
 	}
 
-	// Добавляем запись в историю изменений
-	changeDesc := fmt.Sprintf("Удален исполнитель с ID: %d", userID)
-	if err := r.addTaskChange(ctx, taskID, changeDesc); err != nil {
-		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dnd schedules: %w", err)
 	}
 
-	return nil
+	return schedules, nil
 }
 
-// ========== Chat Operations ==========
-
-// AttachTaskToChat прикрепляет задачу к чату
-func (r *Repository) AttachTaskToChat(ctx context.Context, taskID, chatID int) error {
-	query := `
-		INSERT INTO "taskinchat" (chatsid, tasksid)
-		SELECT $1, $2
-		WHERE NOT EXISTS (
-			SELECT 1 FROM "taskinchat" WHERE chatsid = $1 AND tasksid = $2
-		)
-	`
-
-	result, err := r.db.Pool.Exec(ctx, query, chatID, taskID)
+// queryWorkspaceUsers выполняет запрос, возвращающий id, login и имя пользователей
+func (r *Repository) queryWorkspaceUsers(ctx context.Context, query string, args ...interface{}) ([]models.WorkspaceUser, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to attach task to chat: %w", err)
+		return nil, fmt.Errorf("failed to get users: %w", err)
 	}
+	defer rows.Close()
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("task already attached to chat or invalid chat/task")
+	var users []models.WorkspaceUser
+	for rows.Next() {
+		var user models.WorkspaceUser
+		if err := rows.Scan(&user.UserID, &user.Login, &user.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
 	}
 
-	// Добавляем запись в историю изменений
-	changeDesc := fmt.Sprintf("Прикреплена к чату ID: %d", chatID)
-	if err := r.addTaskChange(ctx, taskID, changeDesc); err != nil {
-		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
 	}
 
-	return nil
+	return users, nil
 }
 
-// GetTaskChats получает список чатов, к которым прикреплена задача
-func (r *Repository) GetTaskChats(ctx context.Context, taskID int) ([]models.TaskChat, error) {
-	query := `
-		SELECT
-			c.id as chat_id,
-			c.name as chat_name,
-			c.type as chat_type,
-			c.workspacesid as workspace_id,
-			tic.id::text as attached_at
-		FROM "taskinchat" tic
-		INNER JOIN chats c ON tic.chatsid = c.id
-		WHERE tic.tasksid = $1
-		ORDER BY c.name
-	`
+// getCommentMentions возвращает упомянутых пользователей для каждого комментария
+func (r *Repository) getCommentMentions(ctx context.Context, commentIDs []int) (map[int][]models.WorkspaceUser, error) {
+	mentions := make(map[int][]models.WorkspaceUser)
+	if len(commentIDs) == 0 {
+		return mentions, nil
+	}
 
-	rows, err := r.db.Pool.Query(ctx, query, taskID)
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT m.commentsid, u.id, u.login, u.surname || ' ' || u.name
+		FROM task_comment_mentions m
+		INNER JOIN users u ON m.usersid = u.id
+		WHERE m.commentsid = ANY($1)
+		ORDER BY m.commentsid, u.id
+	`, commentIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get task chats: %w", err)
+		return nil, fmt.Errorf("failed to get comment mentions: %w", err)
 	}
 	defer rows.Close()
 
-	var chats []models.TaskChat
 	for rows.Next() {
-		var chat models.TaskChat
-		err := rows.Scan(
-			&chat.ChatID,
-			&chat.ChatName,
-			&chat.ChatType,
-			&chat.WorkspaceID,
-			&chat.AttachedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan chat: %w", err)
+		var commentID int
+		var user models.WorkspaceUser
+		if err := rows.Scan(&commentID, &user.UserID, &user.Login, &user.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan comment mention: %w", err)
 		}
-		chats = append(chats, chat)
+		mentions[commentID] = append(mentions[commentID], user)
 	}
 
-	return chats, nil
-}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating comment mentions: %w", err)
+	}
 
-// DetachTaskFromChat открепляет задачу от чата
-func (r *Repository) DetachTaskFromChat(ctx context.Context, taskID, chatID int) error {
-	query := `DELETE FROM "taskinchat" WHERE chatsid = $1 AND tasksid = $2`
+	return mentions, nil
+}
 
-	result, err := r.db.Pool.Exec(ctx, query, chatID, taskID)
-	if err != nil {
-		return fmt.Errorf("failed to detach task from chat: %w", err)
+// replaceCommentMentions заменяет список упомянутых в комментарии пользователей
+func replaceCommentMentions(ctx context.Context, tx pgx.Tx, commentID int, mentionIDs []int) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM task_comment_mentions WHERE commentsid = $1`, commentID); err != nil {
+		return fmt.Errorf("failed to delete comment mentions: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("task not attached to chat")
+	if len(mentionIDs) == 0 {
+		return nil
 	}
 
-	// Добавляем запись в историю изменений
-	changeDesc := fmt.Sprintf("Откреплена от чата ID: %d", chatID)
-	if err := r.addTaskChange(ctx, taskID, changeDesc); err != nil {
-		fmt.Printf("Warning: failed to add task change: %v\n", err)
+	_, err := tx.Exec(ctx, `
+		INSERT INTO task_comment_mentions (commentsid, usersid)
+		SELECT $1, unnest($2::int4[])
+		ON CONFLICT DO NOTHING
+	`, commentID, mentionIDs)
+	if err != nil {
+		return fmt.Errorf("failed to save comment mentions: %w", err)
 	}
 
 	return nil
@@ -420,18 +4243,56 @@ func (r *Repository) DetachTaskFromChat(ctx context.Context, taskID, chatID int)
 
 // ========== History Operations ==========
 
-// GetTaskHistory получает историю изменений задачи
-func (r *Repository) GetTaskHistory(ctx context.Context, taskID int) ([]models.TaskChange, error) {
-	query := `
-		SELECT id, description, tasksid
-		FROM "taskchanges"
-		WHERE tasksid = $1
-		ORDER BY id DESC
-	`
+// GetTaskHistory получает историю изменений задачи с учетом фильтров и общее количество записей
+func (r *Repository) GetTaskHistory(ctx context.Context, filter models.TaskHistoryFilter) ([]models.TaskChange, int, error) {
+	conditions := []string{"tc.tasksid = $1"}
+	args := []interface{}{filter.TaskID}
+	argNum := 2
 
-	rows, err := r.db.Pool.Query(ctx, query, taskID)
+	if filter.Field != "" {
+		conditions = append(conditions, fmt.Sprintf("tc.field = $%d", argNum))
+		args = append(args, filter.Field)
+		argNum++
+	}
+
+	if filter.ActorID != nil {
+		conditions = append(conditions, fmt.Sprintf("tc.actor_id = $%d", argNum))
+		args = append(args, *filter.ActorID)
+		argNum++
+	}
+
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM "taskchanges" tc ` + whereClause
+	if err := r.db.Pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count task history: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			tc.id,
+			tc.tasksid,
+			tc.actor_id,
+			u.surname || ' ' || u.name as actor_name,
+			tc.field,
+			tc.old_value,
+			tc.new_value,
+			tc.description,
+			tc.source,
+			tc.changed_at
+		FROM "taskchanges" tc
+		LEFT JOIN users u ON tc.actor_id = u.id
+		%s
+		ORDER BY tc.id DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argNum, argNum+1)
+
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get task history: %w", err)
+		return nil, 0, fmt.Errorf("failed to get task history: %w", err)
 	}
 	defer rows.Close()
 
@@ -440,16 +4301,27 @@ func (r *Repository) GetTaskHistory(ctx context.Context, taskID int) ([]models.T
 		var change models.TaskChange
 		err := rows.Scan(
 			&change.ID,
-			&change.Description,
 			&change.TaskID,
+			&change.ActorID,
+			&change.ActorName,
+			&change.Field,
+			&change.OldValue,
+			&change.NewValue,
+			&change.Description,
+			&change.Source,
+			&change.ChangedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan change: %w", err)
+			return nil, 0, fmt.Errorf("failed to scan change: %w", err)
 		}
 		changes = append(changes, change)
 	}
 
-	return changes, nil
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating task history: %w", err)
+	}
+
+	return changes, total, nil
 }
 
 // ========== Validation Operations ==========
@@ -511,19 +4383,155 @@ func (r *Repository) ValidateChatOwnership(ctx context.Context, chatID, workspac
 	return nil
 }
 
+// ValidateUserInChat проверяет, что пользователь является участником чата
+func (r *Repository) ValidateUserInChat(ctx context.Context, userID, chatID int) error {
+	query := `
+		SELECT 1 FROM "userinchat"
+		WHERE usersid = $1 AND chatsid = $2
+	`
+
+	var exists int
+	err := r.db.Pool.QueryRow(ctx, query, userID, chatID).Scan(&exists)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("user not in chat")
+		}
+		return fmt.Errorf("failed to validate user in chat: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserRoleInWorkspace возвращает роль пользователя в рабочем пространстве (2 — руководитель)
+func (r *Repository) GetUserRoleInWorkspace(ctx context.Context, userID, workspaceID int) (int, error) {
+	query := `
+		SELECT role FROM "userinworkspace"
+		WHERE usersid = $1 AND workspacesid = $2
+	`
+
+	var role int
+	err := r.db.Pool.QueryRow(ctx, query, userID, workspaceID).Scan(&role)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("user is not a member of workspace")
+		}
+		return 0, fmt.Errorf("failed to get user role: %w", err)
+	}
+
+	return role, nil
+}
+
+// IsTaskAssignee проверяет, что пользователь является исполнителем задачи
+func (r *Repository) IsTaskAssignee(ctx context.Context, taskID, userID int) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM "userintask" WHERE tasksid = $1 AND usersid = $2)`
+
+	var exists bool
+	if err := r.db.Pool.QueryRow(ctx, query, taskID, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check task assignee: %w", err)
+	}
+
+	return exists, nil
+}
+
 // ========== Helper Methods ==========
 
-// addTaskChange добавляет запись в историю изменений задачи
-func (r *Repository) addTaskChange(ctx context.Context, taskID int, description string) error {
+// execer выполняет запросы через пул соединений или в транзакции
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
+// bumpTaskVersion увеличивает версию задачи, чтобы изменение было видно по ETag
+func bumpTaskVersion(ctx context.Context, db execer, taskID int) error {
+	if _, err := db.Exec(ctx, `UPDATE tasks SET version = version + 1 WHERE id = $1`, taskID); err != nil {
+		return fmt.Errorf("failed to update task version: %w", err)
+	}
+	return nil
+}
+
+// addTaskChange добавляет запись в историю изменений задачи.
+// Источник изменения берется из контекста (см. WithChangeSource)
+func (r *Repository) addTaskChange(ctx context.Context, change models.TaskChange) error {
+	return insertTaskChange(ctx, r.db.Pool, change)
+}
+
+// insertTaskChange добавляет запись в историю изменений задачи через db (пул или транзакцию)
+func insertTaskChange(ctx context.Context, db execer, change models.TaskChange) error {
 	query := `
-		INSERT INTO "taskchanges" (description, tasksid)
-		VALUES ($1, $2)
+		INSERT INTO "taskchanges" (description, tasksid, actor_id, field, old_value, new_value, source, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query, description, taskID)
+	_, err := db.Exec(ctx, query,
+		change.Description,
+		change.TaskID,
+		change.ActorID,
+		change.Field,
+		change.OldValue,
+		change.NewValue,
+		changeSource(ctx),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to add task change: %w", err)
 	}
 
 	return nil
 }
+
+type changeSourceKey struct{}
+
+// WithChangeSource возвращает контекст, изменения задач в котором записываются в историю с указанным источником.
+// По умолчанию используется источник rest
+func WithChangeSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, changeSourceKey{}, source)
+}
+
+// changeSource возвращает источник изменений из контекста
+func changeSource(ctx context.Context) string {
+	if source, ok := ctx.Value(changeSourceKey{}).(string); ok && source != "" {
+		return source
+	}
+	return models.ChangeSourceREST
+}
+
+// actorRef возвращает ссылку на ID автора изменения; 0 означает изменение без автора
+func actorRef(actorID int) *int {
+	if actorID == 0 {
+		return nil
+	}
+	return &actorID
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// isUniqueViolation проверяет, что ошибка вызвана нарушением уникальности
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint")
+}
+
+// intValue возвращает значение числа или 0 для nil
+func intValue(value *int) int {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// idValue преобразует необязательный ID в значение для истории изменений
+func idValue(id *int) *string {
+	if id == nil {
+		return nil
+	}
+	value := strconv.Itoa(*id)
+	return &value
+}
+
+// stringValue возвращает значение строки или пустую строку для nil
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -6,14 +6,17 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/diploma/task-service/config"
 	"github.com/diploma/task-service/data/database"
+	dm "github.com/diploma/task-service/data/models"
 	"github.com/diploma/task-service/data/repository"
 	"github.com/diploma/task-service/docs"
 	"github.com/diploma/task-service/presentation/handlers"
+	"github.com/diploma/shared/kafka"
 	metrics "github.com/diploma/shared/metrics"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -56,14 +59,46 @@ func main() {
 	// Создаем репозиторий
 	repo := repository.NewRepository(db)
 
-	// Создаем обработчик
-	taskHandler := handlers.NewTaskHandler(repo)
+	// Инициализируем Kafka producer для уведомлений о комментариях и напоминаний о сроках
+	var kafkaProducer *kafka.Producer
+	if len(cfg.KafkaBrokers) > 0 {
+		kafkaProducer, err = kafka.NewProducer(cfg.KafkaBrokers)
+		if err != nil {
+			log.Printf("Failed to create Kafka producer: %v", err)
+			log.Println("Kafka producer disabled, task notifications will not be sent")
+		} else {
+			defer kafkaProducer.Close()
+			log.Println("Kafka producer initialized successfully")
+		}
+	} else {
+		log.Println("Kafka brokers not configured, task notifications disabled")
+	}
+
+	// Напоминания о сроках задач отправляются через Kafka, без producer планировщик не запускается
+	if kafkaProducer != nil {
+		go runReminderScheduler(repo, kafkaProducer, cfg.ReminderInterval, cfg.ReminderDefaultDays)
+	}
+
+	// Кэш отчетов аналитики сбрасывается при публикации событий изменения задач
+	analyticsCache := handlers.NewAnalyticsCache(cfg.AnalyticsCacheTTL)
+
+	// Планировщик создает следующие задачи серий, работающих по расписанию
+	go runRecurrenceScheduler(repo, kafkaProducer, analyticsCache, cfg.RecurrenceInterval)
+
+	// Создаем обработчики
+	taskHandler := handlers.NewTaskHandler(repo, kafkaProducer, analyticsCache)
+	workflowHandler := handlers.NewWorkflowHandler(repo)
+	commentHandler := handlers.NewCommentHandler(repo, kafkaProducer)
+	reminderHandler := handlers.NewReminderHandler(repo, cfg.ReminderDefaultDays)
+	labelHandler := handlers.NewLabelHandler(repo)
+	calendarHandler := handlers.NewCalendarHandler(repo)
+	analyticsHandler := handlers.NewAnalyticsHandler(repo, analyticsCache)
 
 	// Создаем метрики
 	serviceMetrics := metrics.NewServiceMetrics("task-service")
 
 	// Настраиваем роутер
-	router := setupRouter(taskHandler, serviceMetrics)
+	router := setupRouter(taskHandler, workflowHandler, commentHandler, reminderHandler, labelHandler, calendarHandler, analyticsHandler, serviceMetrics)
 
 	// Создаем HTTP сервер
 	srv := &http.Server{
@@ -96,7 +131,7 @@ func main() {
 	log.Println("Server exited")
 }
 
-func setupRouter(taskHandler *handlers.TaskHandler, serviceMetrics *metrics.ServiceMetrics) *gin.Engine {
+func setupRouter(taskHandler *handlers.TaskHandler, workflowHandler *handlers.WorkflowHandler, commentHandler *handlers.CommentHandler, reminderHandler *handlers.ReminderHandler, labelHandler *handlers.LabelHandler, calendarHandler *handlers.CalendarHandler, analyticsHandler *handlers.AnalyticsHandler, serviceMetrics *metrics.ServiceMetrics) *gin.Engine {
 	router := gin.Default()
 
 	// Swagger документация
@@ -122,19 +157,120 @@ func setupRouter(taskHandler *handlers.TaskHandler, serviceMetrics *metrics.Serv
 		api.PUT("/:id", taskHandler.UpdateTask)
 		api.DELETE("/:id", taskHandler.DeleteTask)
 
+		// Создание задачи из сообщения чата
+		api.POST("/from-message", taskHandler.CreateTaskFromMessage)
+
+		// Массовые операции над задачами
+		api.POST("/bulk", taskHandler.BulkUpdateTasks)
+
+		// Импорт и экспорт задач рабочего пространства (CSV, JSON)
+		api.POST("/import/:workspace_id", taskHandler.ImportTasks)
+		api.GET("/export/:workspace_id", taskHandler.ExportTasks)
+
+		// Задачи, за которыми наблюдает пользователь
+		api.GET("/watching", taskHandler.GetWatchedTasks)
+
+		// Отчет по учтенному времени
+		api.GET("/time-report/:workspace_id", taskHandler.GetTimeReport)
+
+		// Аналитика рабочего пространства по истории статусов
+		api.GET("/analytics/:workspace_id/cycle-time", analyticsHandler.GetCycleTime)
+		api.GET("/analytics/:workspace_id/throughput", analyticsHandler.GetThroughput)
+		api.GET("/analytics/:workspace_id/burndown", analyticsHandler.GetBurndown)
+
+		// Workflow статусов задач рабочего пространства
+		api.GET("/workflows/:workspace_id", workflowHandler.GetWorkflow)
+		api.PUT("/workflows/:workspace_id", workflowHandler.UpdateWorkflow)
+		api.DELETE("/workflows/:workspace_id", workflowHandler.ResetWorkflow)
+
+		// Kanban-доска рабочего пространства
+		api.GET("/board/:workspace_id", taskHandler.GetBoard)
+
+		// Серии повторяющихся задач
+		api.GET("/series/:series_id", taskHandler.GetTaskSeries)
+		api.PUT("/series/:series_id", taskHandler.UpdateTaskSeries)
+		api.DELETE("/series/:series_id", taskHandler.CancelTaskSeries)
+
+		// Настройки напоминаний о сроках
+		api.GET("/reminders/:workspace_id", reminderHandler.GetReminderSettings)
+		api.PUT("/reminders/:workspace_id", reminderHandler.UpdateReminderSettings)
+		api.DELETE("/reminders/:workspace_id", reminderHandler.ResetReminderSettings)
+
+		// Метки задач рабочего пространства
+		api.GET("/labels/:workspace_id", labelHandler.GetLabels)
+		api.POST("/labels/:workspace_id", labelHandler.CreateLabel)
+		api.PUT("/labels/:workspace_id/:label_id", labelHandler.UpdateLabel)
+		api.DELETE("/labels/:workspace_id/:label_id", labelHandler.DeleteLabel)
+
+		// Шаблоны задач рабочего пространства
+		api.GET("/templates/:workspace_id", taskHandler.GetTaskTemplates)
+		api.POST("/templates/:workspace_id", taskHandler.CreateTaskTemplate)
+		api.GET("/templates/:workspace_id/:template_id", taskHandler.GetTaskTemplate)
+		api.PUT("/templates/:workspace_id/:template_id", taskHandler.UpdateTaskTemplate)
+		api.DELETE("/templates/:workspace_id/:template_id", taskHandler.DeleteTaskTemplate)
+		api.POST("/templates/:workspace_id/:template_id/instantiate", taskHandler.InstantiateTaskTemplate)
+
+		// Сохраненные представления списка задач
+		api.GET("/views/:workspace_id", taskHandler.GetTaskViews)
+		api.POST("/views/:workspace_id", taskHandler.CreateTaskView)
+		api.GET("/views/:workspace_id/:view_id", taskHandler.GetTaskView)
+		api.PUT("/views/:workspace_id/:view_id", taskHandler.UpdateTaskView)
+		api.DELETE("/views/:workspace_id/:view_id", taskHandler.DeleteTaskView)
+		api.GET("/views/:workspace_id/:view_id/tasks", taskHandler.GetTaskViewResults)
+
+		// Календарные ленты со сроками задач; сама лента доступна по токену без JWT
+		api.GET("/calendar/feeds", calendarHandler.GetCalendarFeeds)
+		api.POST("/calendar/feeds", calendarHandler.CreateCalendarFeed)
+		api.DELETE("/calendar/feeds/:feed_id", calendarHandler.DeleteCalendarFeed)
+		api.GET("/ical/:token", calendarHandler.GetCalendar)
+
 		// Управление статусом
 		api.PUT("/:id/status", taskHandler.UpdateTaskStatus)
+		api.POST("/:id/move", taskHandler.MoveTask)
 
 		// Управление исполнителями
 		api.POST("/:id/assignees", taskHandler.AddTaskAssignees)
 		api.GET("/:id/assignees", taskHandler.GetTaskAssignees)
 		api.DELETE("/:id/assignees/:user_id", taskHandler.RemoveTaskAssignee)
 
+		// Наблюдатели
+		api.POST("/:id/watch", taskHandler.WatchTask)
+		api.DELETE("/:id/watch", taskHandler.UnwatchTask)
+		api.GET("/:id/watchers", taskHandler.GetTaskWatchers)
+
+		// Метки задачи
+		api.POST("/:id/labels", taskHandler.AddTaskLabels)
+		api.DELETE("/:id/labels/:label_id", taskHandler.RemoveTaskLabel)
+
+		// Учет времени
+		api.GET("/:id/time", taskHandler.GetTimeEntries)
+		api.POST("/:id/time", taskHandler.CreateTimeEntry)
+		api.POST("/:id/time/start", taskHandler.StartTimer)
+		api.POST("/:id/time/stop", taskHandler.StopTimer)
+		api.DELETE("/:id/time/:entry_id", taskHandler.DeleteTimeEntry)
+
 		// Управление прикреплением к чатам
 		api.POST("/:id/chats", taskHandler.AttachTaskToChat)
 		api.GET("/:id/chats", taskHandler.GetTaskChats)
 		api.DELETE("/:id/chats/:chat_id", taskHandler.DetachTaskFromChat)
 
+		// Подзадачи и зависимости
+		api.GET("/:id/subtasks", taskHandler.GetSubtasks)
+		api.PUT("/:id/parent", taskHandler.SetTaskParent)
+		api.DELETE("/:id/parent", taskHandler.RemoveTaskParent)
+		api.GET("/:id/dependencies", taskHandler.GetTaskDependencies)
+		api.POST("/:id/dependencies", taskHandler.AddTaskDependency)
+		api.DELETE("/:id/dependencies/:blocker_id", taskHandler.RemoveTaskDependency)
+
+		// Повторение задачи
+		api.POST("/:id/recurrence", taskHandler.SetTaskRecurrence)
+
+		// Комментарии
+		api.POST("/:id/comments", commentHandler.CreateComment)
+		api.GET("/:id/comments", commentHandler.GetComments)
+		api.PUT("/:id/comments/:comment_id", commentHandler.UpdateComment)
+		api.DELETE("/:id/comments/:comment_id", commentHandler.DeleteComment)
+
 		// История изменений
 		api.GET("/:id/history", taskHandler.GetTaskHistory)
 	}
@@ -151,7 +287,8 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-User-ID, X-User-Role, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-User-ID, X-User-Role, accept, origin, Cache-Control, X-Requested-With, If-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -163,6 +300,121 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// runRecurrenceScheduler периодически создает следующие задачи серий в режиме schedule,
+// срок последней задачи которых наступил. producer может быть nil — тогда события о новых задачах не публикуются
+func runRecurrenceScheduler(repo *repository.Repository, producer *kafka.Producer, analytics *handlers.AnalyticsCache, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx := repository.WithChangeSource(context.Background(), dm.ChangeSourceAutomation)
+		seriesIDs, err := repo.GetDueSeries(ctx)
+		if err != nil {
+			log.Printf("Recurrence scheduler: %v", err)
+		}
+
+		created := 0
+		for _, seriesID := range seriesIDs {
+			task, err := repo.CreateNextSeriesTask(ctx, seriesID, 0, dm.SeriesModeSchedule)
+			if err != nil {
+				log.Printf("Recurrence scheduler: series %d: %v", seriesID, err)
+				continue
+			}
+			if task != nil {
+				created++
+				handlers.PublishTaskChanged(ctx, repo, producer, analytics, kafka.TaskEventCreated, task.ID, 0)
+			}
+		}
+		if created > 0 {
+			log.Printf("Recurrence scheduler: created %d tasks", created)
+		}
+		<-ticker.C
+	}
+}
+
+// runReminderScheduler периодически отправляет исполнителям напоминания о приближающихся и прошедших сроках задач
+func runReminderScheduler(repo *repository.Repository, producer *kafka.Producer, interval time.Duration, defaultDays []int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := sendTaskReminders(context.Background(), repo, producer, defaultDays)
+		if err != nil {
+			log.Printf("Reminder scheduler: %v", err)
+		} else if sent > 0 {
+			log.Printf("Reminder scheduler: sent %d reminders", sent)
+		}
+		<-ticker.C
+	}
+}
 
+// sendTaskReminders отправляет в Kafka напоминания, которые еще не были отправлены, и возвращает их количество
+func sendTaskReminders(ctx context.Context, repo *repository.Repository, producer *kafka.Producer, defaultDays []int) (int, error) {
+	reminders, err := repo.GetDueReminders(ctx, defaultDays)
+	if err != nil {
+		return 0, err
+	}
 
+	sent := 0
+	for _, reminder := range reminders {
+		assignees, err := repo.GetTaskAssignees(ctx, reminder.TaskID)
+		if err != nil {
+			log.Printf("Reminder scheduler: task %d: %v", reminder.TaskID, err)
+			continue
+		}
+
+		assigneeIDs := make([]int, 0, len(assignees))
+		for _, assignee := range assignees {
+			assigneeIDs = append(assigneeIDs, assignee.UserID)
+		}
+		schedules, err := repo.GetDNDSchedules(ctx, assigneeIDs)
+		if err != nil {
+			log.Printf("Reminder scheduler: task %d: %v", reminder.TaskID, err)
+			continue
+		}
 
+		for _, assignee := range assignees {
+			// В режиме «не беспокоить» напоминание откладывается: оно не отмечается отправленным
+			// и будет отправлено первым запуском планировщика после окончания режима
+			if schedules[assignee.UserID].ActiveAt(time.Now()) {
+				continue
+			}
+
+			// Напоминание отмечается до публикации, чтобы несколько экземпляров сервиса не отправили его дважды
+			claimed, err := repo.MarkReminderSent(ctx, reminder, assignee.UserID)
+			if err != nil {
+				log.Printf("Reminder scheduler: task %d: %v", reminder.TaskID, err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+
+			event := kafka.TaskDueReminderEvent{
+				TaskID:         reminder.TaskID,
+				TaskTitle:      reminder.TaskTitle,
+				WorkspaceID:    reminder.WorkspaceID,
+				WorkspaceName:  reminder.WorkspaceName,
+				StatusName:     reminder.StatusName,
+				DueDate:        reminder.DueDate.Format("2006-01-02"),
+				DaysLeft:       reminder.DaysLeft,
+				Overdue:        reminder.Kind == dm.ReminderKindOverdue,
+				RecipientID:    assignee.UserID,
+				RecipientEmail: assignee.Login,
+				RecipientName:  strings.TrimSpace(assignee.Name + " " + assignee.Surname),
+				SentAt:         time.Now().UTC().Format(time.RFC3339),
+			}
+			if err := producer.Publish(kafka.TopicTaskDueReminder, event); err != nil {
+				log.Printf("Reminder scheduler: failed to publish reminder for task %d to user %d: %v", reminder.TaskID, assignee.UserID, err)
+				// Снимаем отметку, чтобы следующий запуск планировщика повторил отправку
+				if err := repo.ReleaseReminder(ctx, reminder, assignee.UserID); err != nil {
+					log.Printf("Reminder scheduler: task %d: %v", reminder.TaskID, err)
+				}
+				continue
+			}
+			sent++
+		}
+	}
+
+	return sent, nil
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/repository"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// analyticsDefaultDays — длина периода аналитики, если он не задан
+	analyticsDefaultDays = 90
+	// analyticsMaxDays — максимальная длина периода аналитики
+	analyticsMaxDays = 366
+	// analyticsCacheMaxEntries — максимальное число отчетов в кэше
+	analyticsCacheMaxEntries = 1000
+)
+
+type AnalyticsHandler struct {
+	repo  *repository.Repository
+	cache *AnalyticsCache
+}
+
+// NewAnalyticsHandler создает обработчик аналитики рабочих пространств.
+// cache — кэш рассчитанных отчетов, общий с TaskHandler, который сбрасывает его при изменении задач
+func NewAnalyticsHandler(repo *repository.Repository, cache *AnalyticsCache) *AnalyticsHandler {
+	return &AnalyticsHandler{repo: repo, cache: cache}
+}
+
+// GetCycleTime godoc
+// @Summary Время в статусах и время выполнения задач
+// @Description Возвращает по статусам workflow среднее время пребывания задач в статусе (по пребываниям, закончившимся в периоде) и число задач в статусе сейчас, а также среднее время выполнения задач, завершенных в периоде: cycle time — от первого перехода в работу, lead time — от создания. Рассчитывается по истории изменений статусов; отчет кэшируется на TASK_ANALYTICS_CACHE_TTL_SECONDS и сбрасывается при изменении задач РП через этот экземпляр сервиса, поэтому изменения, сделанные через другие экземпляры, могут появиться в отчете с задержкой до этого срока. Только руководитель РП
+// @Tags analytics
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param from query string false "Начало периода (YYYY-MM-DD, по умолчанию 90 дней назад)"
+// @Param to query string false "Конец периода (YYYY-MM-DD, включительно, по умолчанию сегодня)"
+// @Param label_id query int false "Только задачи с меткой"
+// @Success 200 {object} models.CycleTimeResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/analytics/{workspace_id}/cycle-time [get]
+func (h *AnalyticsHandler) GetCycleTime(c *gin.Context) {
+	filter, ok := h.parseAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+
+	key := analyticsCacheKey("cycle-time", filter)
+	if cached, ok := h.cache.get(key); ok {
+		c.JSON(http.StatusOK, cached)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	workflow, err := h.repo.GetWorkflow(ctx, filter.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workflow"})
+		return
+	}
+
+	rows, err := h.repo.GetStatusTimes(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get status times"})
+		return
+	}
+
+	summary, err := h.repo.GetCycleTime(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get cycle time"})
+		return
+	}
+
+	response := models.CycleTimeResponse{
+		WorkspaceID:    filter.WorkspaceID,
+		LabelID:        filter.LabelID,
+		From:           filter.From.Format("2006-01-02"),
+		To:             filter.To.Format("2006-01-02"),
+		Statuses:       statusTimesByWorkflow(workflow, rows),
+		CompletedTasks: summary.Completed,
+		AvgCycleHours:  secondsToHours(summary.AvgCycleSeconds),
+		AvgLeadHours:   secondsToHours(summary.AvgLeadSeconds),
+		GeneratedAt:    time.Now(),
+	}
+
+	h.cache.set(filter.WorkspaceID, key, response)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetThroughput godoc
+// @Summary Пропускная способность по неделям
+// @Description Возвращает по неделям периода (с понедельника) количество созданных, завершенных и отмененных задач. Крайние недели учитываются только в пределах периода; отчет кэшируется на TASK_ANALYTICS_CACHE_TTL_SECONDS и сбрасывается при изменении задач РП через этот экземпляр сервиса, поэтому изменения, сделанные через другие экземпляры, могут появиться в отчете с задержкой до этого срока. Только руководитель РП
+// @Tags analytics
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param from query string false "Начало периода (YYYY-MM-DD, по умолчанию 90 дней назад)"
+// @Param to query string false "Конец периода (YYYY-MM-DD, включительно, по умолчанию сегодня)"
+// @Param label_id query int false "Только задачи с меткой"
+// @Success 200 {object} models.ThroughputResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/analytics/{workspace_id}/throughput [get]
+func (h *AnalyticsHandler) GetThroughput(c *gin.Context) {
+	filter, ok := h.parseAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+
+	key := analyticsCacheKey("throughput", filter)
+	if cached, ok := h.cache.get(key); ok {
+		c.JSON(http.StatusOK, cached)
+		return
+	}
+
+	weeks, err := h.repo.GetThroughput(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get throughput"})
+		return
+	}
+
+	response := models.ThroughputResponse{
+		WorkspaceID: filter.WorkspaceID,
+		LabelID:     filter.LabelID,
+		From:        filter.From.Format("2006-01-02"),
+		To:          filter.To.Format("2006-01-02"),
+		Weeks:       make([]models.ThroughputWeekResponse, 0, len(weeks)),
+		GeneratedAt: time.Now(),
+	}
+	for _, week := range weeks {
+		response.TotalCreated += week.Created
+		response.TotalCompleted += week.Completed
+		response.TotalCancelled += week.Cancelled
+		response.Weeks = append(response.Weeks, models.ThroughputWeekResponse{
+			WeekStart: week.WeekStart.Format("2006-01-02"),
+			Created:   week.Created,
+			Completed: week.Completed,
+			Cancelled: week.Cancelled,
+		})
+	}
+
+	h.cache.set(filter.WorkspaceID, key, response)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBurndown godoc
+// @Summary Burndown задач
+// @Description Возвращает по дням периода количество незавершенных на конец дня задач (отмененные не учитываются), созданных и завершенных за день задач, а также идеальную линию от числа незавершенных задач на конец первого дня до нуля. С label_id строит burndown по задачам с меткой; отчет кэшируется на TASK_ANALYTICS_CACHE_TTL_SECONDS и сбрасывается при изменении задач РП через этот экземпляр сервиса, поэтому изменения, сделанные через другие экземпляры, могут появиться в отчете с задержкой до этого срока. Только руководитель РП
+// @Tags analytics
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param from query string false "Начало периода (YYYY-MM-DD, по умолчанию 90 дней назад)"
+// @Param to query string false "Конец периода (YYYY-MM-DD, включительно, по умолчанию сегодня)"
+// @Param label_id query int false "Только задачи с меткой"
+// @Success 200 {object} models.BurndownResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/analytics/{workspace_id}/burndown [get]
+func (h *AnalyticsHandler) GetBurndown(c *gin.Context) {
+	filter, ok := h.parseAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+
+	key := analyticsCacheKey("burndown", filter)
+	if cached, ok := h.cache.get(key); ok {
+		c.JSON(http.StatusOK, cached)
+		return
+	}
+
+	days, err := h.repo.GetBurndown(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get burndown"})
+		return
+	}
+
+	response := models.BurndownResponse{
+		WorkspaceID: filter.WorkspaceID,
+		LabelID:     filter.LabelID,
+		From:        filter.From.Format("2006-01-02"),
+		To:          filter.To.Format("2006-01-02"),
+		Days:        make([]models.BurndownDayResponse, 0, len(days)),
+		GeneratedAt: time.Now(),
+	}
+	for i, day := range days {
+		// Идеальная линия равномерно снижается от остатка первого дня до нуля в последний день
+		ideal := 0.0
+		if len(days) > 1 {
+			ideal = float64(days[0].Remaining) * float64(len(days)-1-i) / float64(len(days)-1)
+		}
+		response.Days = append(response.Days, models.BurndownDayResponse{
+			Date:      day.Day.Format("2006-01-02"),
+			Remaining: day.Remaining,
+			Ideal:     math.Round(ideal*100) / 100,
+			Created:   day.Created,
+			Completed: day.Completed,
+		})
+	}
+
+	h.cache.set(filter.WorkspaceID, key, response)
+	c.JSON(http.StatusOK, response)
+}
+
+// parseAnalyticsFilter проверяет, что пользователь — руководитель РП, и разбирает период и метку отчета
+func (h *AnalyticsHandler) parseAnalyticsFilter(c *gin.Context) (dm.AnalyticsFilter, bool) {
+	workspaceID, ok := authorizeWorkspaceLeader(c, h.repo, "only workspace leader can view analytics")
+	if !ok {
+		return dm.AnalyticsFilter{}, false
+	}
+
+	filter := dm.AnalyticsFilter{WorkspaceID: workspaceID}
+
+	now := time.Now()
+	filter.To = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if to := c.Query("to"); to != "" {
+		parsed, err := parseDate(to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid to, expected YYYY-MM-DD"})
+			return dm.AnalyticsFilter{}, false
+		}
+		filter.To = parsed
+	}
+
+	filter.From = filter.To.AddDate(0, 0, -(analyticsDefaultDays - 1))
+	if from := c.Query("from"); from != "" {
+		parsed, err := parseDate(from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid from, expected YYYY-MM-DD"})
+			return dm.AnalyticsFilter{}, false
+		}
+		filter.From = parsed
+	}
+
+	if filter.To.Before(filter.From) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid period, to is before from"})
+		return dm.AnalyticsFilter{}, false
+	}
+	if filter.To.Sub(filter.From) >= analyticsMaxDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("period is too long, maximum is %d days", analyticsMaxDays)})
+		return dm.AnalyticsFilter{}, false
+	}
+
+	if labelStr := c.Query("label_id"); labelStr != "" {
+		labelID, err := strconv.Atoi(labelStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid label id"})
+			return dm.AnalyticsFilter{}, false
+		}
+
+		labels, err := h.repo.GetLabels(c.Request.Context(), workspaceID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to validate labels"})
+			return dm.AnalyticsFilter{}, false
+		}
+		found := false
+		for _, label := range labels {
+			if label.ID == labelID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "label not found in workspace"})
+			return dm.AnalyticsFilter{}, false
+		}
+		filter.LabelID = &labelID
+	}
+
+	return filter, true
+}
+
+// statusTimesByWorkflow упорядочивает время в статусах по workflow рабочего пространства.
+// Статусы workflow без данных возвращаются с нулями, а статусы из истории, которых уже нет в workflow, — в конце
+func statusTimesByWorkflow(workflow *dm.Workflow, rows []dm.StatusTimeRow) []models.StatusTimeResponse {
+	byCode := make(map[int]dm.StatusTimeRow, len(rows))
+	for _, row := range rows {
+		byCode[row.Status] = row
+	}
+
+	result := make([]models.StatusTimeResponse, 0, len(workflow.Statuses)+len(rows))
+	for _, status := range workflow.Statuses {
+		row, ok := byCode[status.Code]
+		if !ok {
+			row = dm.StatusTimeRow{Status: status.Code}
+		}
+		delete(byCode, status.Code)
+		row.StatusName = status.Name
+		row.Category = status.Category
+		result = append(result, toStatusTimeResponse(row))
+	}
+
+	var removed []dm.StatusTimeRow
+	for _, row := range byCode {
+		removed = append(removed, row)
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Status < removed[j].Status })
+	for _, row := range removed {
+		result = append(result, toStatusTimeResponse(row))
+	}
+
+	return result
+}
+
+// toStatusTimeResponse преобразует время в статусе в ответ API
+func toStatusTimeResponse(row dm.StatusTimeRow) models.StatusTimeResponse {
+	return models.StatusTimeResponse{
+		Status:       row.Status,
+		StatusName:   row.StatusName,
+		Category:     row.Category,
+		Transitions:  row.Transitions,
+		AvgHours:     math.Round(row.AvgSeconds/36) / 100,
+		CurrentTasks: row.CurrentTasks,
+	}
+}
+
+// secondsToHours переводит секунды в часы с точностью до сотых
+func secondsToHours(seconds *float64) *float64 {
+	if seconds == nil {
+		return nil
+	}
+	hours := math.Round(*seconds/36) / 100
+	return &hours
+}
+
+// analyticsCacheKey возвращает ключ кэша отчета
+func analyticsCacheKey(report string, filter dm.AnalyticsFilter) string {
+	label := 0
+	if filter.LabelID != nil {
+		label = *filter.LabelID
+	}
+	return fmt.Sprintf("%s:%d:%d:%s:%s", report, filter.WorkspaceID, label,
+		filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02"))
+}
+
+// AnalyticsCache хранит рассчитанные отчеты аналитики в памяти сервиса. Отчеты РП сбрасываются,
+// когда этот экземпляр сервиса публикует событие изменения задачи РП; изменения, сделанные другими
+// экземплярами или без Kafka, попадают в отчет не позже чем через ttl
+type AnalyticsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]analyticsCacheEntry
+}
+
+type analyticsCacheEntry struct {
+	workspaceID int
+	value       interface{}
+	expiresAt   time.Time
+}
+
+// NewAnalyticsCache создает кэш отчетов. ttl — сколько рассчитанный отчет отдается из кэша (0 — отчеты не кэшируются)
+func NewAnalyticsCache(ttl time.Duration) *AnalyticsCache {
+	return &AnalyticsCache{ttl: ttl, entries: make(map[string]analyticsCacheEntry)}
+}
+
+// get возвращает отчет из кэша, если срок его хранения не истек
+func (c *AnalyticsCache) get(key string) (interface{}, bool) {
+	if c == nil || c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// set сохраняет отчет РП в кэше, попутно удаляя устаревшие. Если кэш заполнен,
+// вытесняется отчет, срок хранения которого истекает раньше других
+func (c *AnalyticsCache) set(workspaceID int, key string, value interface{}) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= analyticsCacheMaxEntries {
+		oldestKey := ""
+		var oldest time.Time
+		for k, entry := range c.entries {
+			if oldestKey == "" || entry.expiresAt.Before(oldest) {
+				oldestKey, oldest = k, entry.expiresAt
+			}
+		}
+		delete(c.entries, oldestKey)
+	}
+	c.entries[key] = analyticsCacheEntry{workspaceID: workspaceID, value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// invalidateWorkspace удаляет из кэша отчеты рабочего пространства
+func (c *AnalyticsCache) invalidateWorkspace(workspaceID int) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, entry := range c.entries {
+		if entry.workspaceID == workspaceID {
+			delete(c.entries, k)
+		}
+	}
+}
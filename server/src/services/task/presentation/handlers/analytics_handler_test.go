@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAnalyticsCacheEvictsWhenFull(t *testing.T) {
+	cache := NewAnalyticsCache(time.Minute)
+	for i := 0; i < analyticsCacheMaxEntries+10; i++ {
+		cache.set(1, fmt.Sprintf("key-%d", i), i)
+	}
+
+	if len(cache.entries) != analyticsCacheMaxEntries {
+		t.Fatalf("entries = %d, want %d", len(cache.entries), analyticsCacheMaxEntries)
+	}
+	if _, ok := cache.get(fmt.Sprintf("key-%d", analyticsCacheMaxEntries+9)); !ok {
+		t.Error("latest report was evicted")
+	}
+}
+
+func TestAnalyticsCacheInvalidateWorkspace(t *testing.T) {
+	cache := NewAnalyticsCache(time.Minute)
+	cache.set(1, "first", 1)
+	cache.set(2, "second", 2)
+
+	cache.invalidateWorkspace(1)
+
+	if _, ok := cache.get("first"); ok {
+		t.Error("report of invalidated workspace is still cached")
+	}
+	if _, ok := cache.get("second"); !ok {
+		t.Error("report of another workspace was invalidated")
+	}
+
+	var disabled *AnalyticsCache
+	disabled.invalidateWorkspace(1)
+	disabled.set(1, "key", 1)
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/repository"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// calendarFeedPath — публичный путь календарной ленты, к нему добавляется токен и расширение .ics.
+// Путь не требует JWT: календарные приложения загружают ленту только по ссылке
+const calendarFeedPath = "/api/v1/tasks/ical/"
+
+type CalendarHandler struct {
+	repo *repository.Repository
+}
+
+func NewCalendarHandler(repo *repository.Repository) *CalendarHandler {
+	return &CalendarHandler{repo: repo}
+}
+
+// GetCalendarFeeds godoc
+// @Summary Получить календарные ленты
+// @Description Возвращает iCalendar-ленты пользователя. Ссылка на ленту в списке не возвращается: токен выдается только при создании ленты
+// @Tags calendar
+// @Produce json
+// @Success 200 {object} models.CalendarFeedListResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/calendar/feeds [get]
+func (h *CalendarHandler) GetCalendarFeeds(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	feeds, err := h.repo.GetCalendarFeeds(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get calendar feeds"})
+		return
+	}
+
+	response := models.CalendarFeedListResponse{Feeds: []models.CalendarFeedResponse{}}
+	for i := range feeds {
+		response.Feeds = append(response.Feeds, toCalendarFeedResponse(&feeds[i], ""))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateCalendarFeed godoc
+// @Summary Создать календарную ленту
+// @Description Создает iCalendar-ленту со сроками задач, где пользователь исполнитель: по всем его рабочим пространствам или, если указан workspace_id, по одному РП. Если лента с той же областью уже есть, для нее выпускается новый токен, а старая ссылка перестает работать. Ссылка с токеном возвращается только в этом ответе, в базе хранится лишь хеш токена
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param request body models.CreateCalendarFeedRequest false "Область ленты"
+// @Success 201 {object} models.CalendarFeedResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/calendar/feeds [post]
+func (h *CalendarHandler) CreateCalendarFeed(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req models.CreateCalendarFeedRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+
+	if req.WorkspaceID != nil {
+		if err := h.repo.ValidateUserInWorkspace(ctx, userID, *req.WorkspaceID); err != nil {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
+			return
+		}
+	}
+
+	token, err := generateCalendarToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to generate calendar token"})
+		return
+	}
+
+	feed, err := h.repo.SaveCalendarFeed(ctx, userID, req.WorkspaceID, token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to create calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toCalendarFeedResponse(feed, token))
+}
+
+// DeleteCalendarFeed godoc
+// @Summary Отозвать календарную ленту
+// @Description Удаляет iCalendar-ленту пользователя, после чего ее ссылка перестает работать
+// @Tags calendar
+// @Produce json
+// @Param feed_id path int true "ID календарной ленты"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/calendar/feeds/{feed_id} [delete]
+func (h *CalendarHandler) DeleteCalendarFeed(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	feedID, err := strconv.Atoi(c.Param("feed_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid feed id"})
+		return
+	}
+
+	if err := h.repo.DeleteCalendarFeed(c.Request.Context(), userID, feedID); err != nil {
+		if err.Error() == "calendar feed not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "calendar feed not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to delete calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse{Message: "calendar feed deleted successfully"})
+}
+
+// GetCalendar godoc
+// @Summary Календарная лента задач
+// @Description Возвращает iCalendar-ленту (.ics) по токену без JWT. Каждая задача со сроком, где владелец ленты исполнитель, — событие на весь день срока. Завершенные задачи остаются в ленте с отметкой, отмененные помечаются как отмененные события; задачи, закрытые более 90 дней назад, не включаются. Лента строится при каждом запросе, поэтому календарь получает изменения задач при следующем обновлении подписки
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Токен ленты (с расширением .ics или без него)"
+// @Success 200 {string} string "VCALENDAR"
+// @Failure 404 {object} models.ErrorResponse
+// @Router /tasks/ical/{token} [get]
+func (h *CalendarHandler) GetCalendar(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	if token == "" {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "calendar feed not found"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	feed, err := h.repo.GetCalendarFeedByToken(ctx, token)
+	if err != nil {
+		if err.Error() == "calendar feed not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "calendar feed not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get calendar feed"})
+		return
+	}
+
+	// Лента рабочего пространства перестает работать, когда владелец покидает РП
+	if feed.WorkspaceID != nil {
+		if err := h.repo.ValidateUserInWorkspace(ctx, feed.UserID, *feed.WorkspaceID); err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "calendar feed not found"})
+			return
+		}
+	}
+
+	events, err := h.repo.GetCalendarEvents(ctx, feed.UserID, feed.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get calendar events"})
+		return
+	}
+
+	name := "Задачи"
+	if feed.WorkspaceName != nil {
+		name += ": " + *feed.WorkspaceName
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Disposition", `inline; filename="tasks.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", renderCalendar(name, events, time.Now()))
+}
+
+// generateCalendarToken генерирует случайный URL-безопасный токен календарной ленты
+func generateCalendarToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// toCalendarFeedResponse формирует ответ с лентой. Токен известен только при создании ленты:
+// в базе хранится его хеш, поэтому в списке лент ссылка не возвращается
+func toCalendarFeedResponse(feed *dm.CalendarFeed, token string) models.CalendarFeedResponse {
+	response := models.CalendarFeedResponse{
+		ID:             feed.ID,
+		WorkspaceID:    feed.WorkspaceID,
+		WorkspaceName:  feed.WorkspaceName,
+		CreatedAt:      feed.CreatedAt,
+		LastAccessedAt: feed.LastAccessedAt,
+	}
+	if token != "" {
+		response.URL = calendarFeedPath + token + ".ics"
+	}
+	return response
+}
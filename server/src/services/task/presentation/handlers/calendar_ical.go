@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	dm "github.com/diploma/task-service/data/models"
+)
+
+const (
+	// icalRefreshInterval — как часто календарным приложениям предлагается обновлять ленту
+	icalRefreshInterval = "PT15M"
+	// icalMaxLineOctets — максимальная длина строки iCalendar до переноса (RFC 5545, 3.1)
+	icalMaxLineOctets = 75
+)
+
+// icalPriorities — значение PRIORITY события для приоритета задачи (1 — наивысший)
+var icalPriorities = map[string]int{
+	dm.TaskPriorityCritical: 1,
+	dm.TaskPriorityHigh:     3,
+	dm.TaskPriorityNormal:   5,
+	dm.TaskPriorityLow:      9,
+}
+
+// renderCalendar формирует iCalendar-ленту, где каждая задача — событие на весь день срока.
+// UID события постоянен для задачи, а SEQUENCE и LAST-MODIFIED растут с каждым ее изменением,
+// поэтому календарь обновляет существующие события вместо создания новых
+func renderCalendar(name string, events []dm.CalendarEvent, now time.Time) []byte {
+	var buf bytes.Buffer
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	writeICalLine(&buf, "BEGIN:VCALENDAR")
+	writeICalLine(&buf, "VERSION:2.0")
+	writeICalLine(&buf, "PRODID:-//diploma//task-service//RU")
+	writeICalLine(&buf, "CALSCALE:GREGORIAN")
+	writeICalLine(&buf, "METHOD:PUBLISH")
+	writeICalLine(&buf, "X-WR-CALNAME:"+escapeICalText(name))
+	writeICalLine(&buf, "REFRESH-INTERVAL;VALUE=DURATION:"+icalRefreshInterval)
+	writeICalLine(&buf, "X-PUBLISHED-TTL:"+icalRefreshInterval)
+
+	for i := range events {
+		event := &events[i]
+		summary := event.Title
+		status := "CONFIRMED"
+		switch event.StatusCategory {
+		case dm.StatusCategoryDone:
+			summary = "✓ " + summary
+		case dm.StatusCategoryCancelled:
+			status = "CANCELLED"
+		}
+
+		description := fmt.Sprintf("Рабочее пространство: %s\nСтатус: %s", event.WorkspaceName, event.StatusName)
+		if event.Description != nil && *event.Description != "" {
+			description += "\n\n" + *event.Description
+		}
+
+		writeICalLine(&buf, "BEGIN:VEVENT")
+		writeICalLine(&buf, fmt.Sprintf("UID:task-%d@task-service", event.TaskID))
+		writeICalLine(&buf, "DTSTAMP:"+stamp)
+		writeICalLine(&buf, "LAST-MODIFIED:"+event.UpdatedAt.UTC().Format("20060102T150405Z"))
+		writeICalLine(&buf, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		writeICalLine(&buf, "DTSTART;VALUE=DATE:"+event.DueDate.Format("20060102"))
+		writeICalLine(&buf, "DTEND;VALUE=DATE:"+event.DueDate.AddDate(0, 0, 1).Format("20060102"))
+		writeICalLine(&buf, "SUMMARY:"+escapeICalText(summary))
+		writeICalLine(&buf, "DESCRIPTION:"+escapeICalText(description))
+		writeICalLine(&buf, "CATEGORIES:"+escapeICalText(event.WorkspaceName))
+		if priority, ok := icalPriorities[event.Priority]; ok {
+			writeICalLine(&buf, fmt.Sprintf("PRIORITY:%d", priority))
+		}
+		writeICalLine(&buf, "STATUS:"+status)
+		writeICalLine(&buf, "TRANSP:TRANSPARENT")
+		writeICalLine(&buf, "END:VEVENT")
+	}
+
+	writeICalLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// escapeICalText экранирует значение текстового свойства iCalendar
+func escapeICalText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writeICalLine записывает строку iCalendar, перенося ее по 75 байт без разрыва символов UTF-8
+func writeICalLine(buf *bytes.Buffer, line string) {
+	limit := icalMaxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Строка продолжения начинается с пробела, который входит в ее длину
+		limit = icalMaxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
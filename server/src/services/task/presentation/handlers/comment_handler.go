@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diploma/shared/kafka"
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/repository"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// mentionPattern находит упоминания вида @login или @login@example.com. Упоминание начинается
+// в начале текста или после пробела, поэтому адрес user@example.com в тексте не считается упоминанием
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([\p{L}\p{N}._%+\-@]+)`)
+
+type CommentHandler struct {
+	repo     *repository.Repository
+	producer *kafka.Producer
+}
+
+// NewCommentHandler создает обработчик комментариев. producer может быть nil — тогда уведомления не отправляются
+func NewCommentHandler(repo *repository.Repository, producer *kafka.Producer) *CommentHandler {
+	return &CommentHandler{repo: repo, producer: producer}
+}
+
+// CreateComment godoc
+// @Summary Добавить комментарий к задаче
+// @Description Добавляет комментарий к задаче. Упоминания @login разрешаются среди участников РП; создатель, исполнители и упомянутые пользователи получают уведомление
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param request body models.CreateTaskCommentRequest true "Текст комментария"
+// @Success 201 {object} models.TaskCommentResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTaskCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	mentions, err := h.repo.ResolveWorkspaceMentions(ctx, task.WorkspaceID, extractMentions(req.Text))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to resolve mentions"})
+		return
+	}
+
+	comment, err := h.repo.CreateTaskComment(ctx, task.ID, userID, req.Text, userIDs(mentions))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to create comment"})
+		return
+	}
+
+	go h.notifyCommentRecipients(task, comment, false)
+
+	c.JSON(http.StatusCreated, toCommentResponse(comment))
+}
+
+// GetComments godoc
+// @Summary Получить комментарии задачи
+// @Description Возвращает комментарии задачи в порядке добавления
+// @Tags comments
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param limit query int false "Количество комментариев (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} models.TaskCommentsResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/comments [get]
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	_, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil && v > 0 && v <= 100 {
+			limit = v
+		}
+	}
+
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if v, err := strconv.Atoi(offsetStr); err == nil && v >= 0 {
+			offset = v
+		}
+	}
+
+	comments, total, err := h.repo.GetTaskComments(c.Request.Context(), task.ID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get comments"})
+		return
+	}
+
+	commentResponses := []models.TaskCommentResponse{}
+	for i := range comments {
+		commentResponses = append(commentResponses, toCommentResponse(&comments[i]))
+	}
+
+	c.JSON(http.StatusOK, models.TaskCommentsResponse{
+		Comments: commentResponses,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
+
+// UpdateComment godoc
+// @Summary Изменить комментарий
+// @Description Изменяет текст комментария (только автор). Впервые упомянутые пользователи получают уведомление
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param comment_id path int true "ID комментария"
+// @Param request body models.UpdateTaskCommentRequest true "Новый текст комментария"
+// @Success 200 {object} models.TaskCommentResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/comments/{comment_id} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.loadComment(c, task.ID)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTaskCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	if comment.AuthorID == nil || *comment.AuthorID != userID {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "only comment author can edit it"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	mentions, err := h.repo.ResolveWorkspaceMentions(ctx, task.WorkspaceID, extractMentions(req.Text))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to resolve mentions"})
+		return
+	}
+
+	if err := h.repo.UpdateTaskComment(ctx, task.ID, comment.ID, userID, req.Text, userIDs(mentions)); err != nil {
+		if err.Error() == "comment not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "comment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update comment"})
+		return
+	}
+
+	previous := make(map[int]bool, len(comment.Mentions))
+	for _, mention := range comment.Mentions {
+		previous[mention.UserID] = true
+	}
+
+	updated, err := h.repo.GetTaskComment(ctx, task.ID, comment.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get updated comment"})
+		return
+	}
+
+	// Уведомляем только пользователей, упомянутых впервые
+	notify := *updated
+	notify.Mentions = nil
+	for _, mention := range updated.Mentions {
+		if !previous[mention.UserID] {
+			notify.Mentions = append(notify.Mentions, mention)
+		}
+	}
+	if len(notify.Mentions) > 0 {
+		go h.notifyCommentRecipients(task, &notify, true)
+	}
+
+	c.JSON(http.StatusOK, toCommentResponse(updated))
+}
+
+// DeleteComment godoc
+// @Summary Удалить комментарий
+// @Description Удаляет комментарий (автор или руководитель РП)
+// @Tags comments
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param comment_id path int true "ID комментария"
+// @Success 204
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/comments/{comment_id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.loadComment(c, task.ID)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if comment.AuthorID == nil || *comment.AuthorID != userID {
+		role, err := h.repo.GetUserRoleInWorkspace(ctx, userID, task.WorkspaceID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to check user role"})
+			return
+		}
+		if role != 2 {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "only comment author or workspace leader can delete it"})
+			return
+		}
+	}
+
+	if err := h.repo.DeleteTaskComment(ctx, task.ID, comment.ID, userID); err != nil {
+		if err.Error() == "comment not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "comment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to delete comment"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadTask проверяет пользователя и доступ к задаче из параметра id
+func loadTask(c *gin.Context, repo *repository.Repository) (int, *dm.TaskWithDetails, bool) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return 0, nil, false
+	}
+
+	taskID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid task id"})
+		return 0, nil, false
+	}
+
+	task, err := repo.GetTaskByID(c.Request.Context(), taskID, userID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "task not found"})
+			return 0, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task"})
+		return 0, nil, false
+	}
+
+	return userID, task, true
+}
+
+// loadComment получает комментарий задачи из параметра comment_id
+func (h *CommentHandler) loadComment(c *gin.Context, taskID int) (*dm.TaskComment, bool) {
+	commentID, err := strconv.Atoi(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid comment id"})
+		return nil, false
+	}
+
+	comment, err := h.repo.GetTaskComment(c.Request.Context(), taskID, commentID)
+	if err != nil {
+		if err.Error() == "comment not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "comment not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get comment"})
+		return nil, false
+	}
+
+	return comment, true
+}
+
+// notifyCommentRecipients публикует в Kafka уведомления о комментарии.
+// Для нового комментария уведомляются создатель, исполнители, наблюдатели и упомянутые пользователи,
+// при mentionsOnly — только упомянутые в comment.Mentions
+func (h *CommentHandler) notifyCommentRecipients(task *dm.TaskWithDetails, comment *dm.TaskComment, mentionsOnly bool) {
+	if h.producer == nil {
+		return
+	}
+
+	mentioned := make(map[int]bool, len(comment.Mentions))
+	recipients := make([]dm.WorkspaceUser, 0, len(comment.Mentions))
+	for _, mention := range comment.Mentions {
+		mentioned[mention.UserID] = true
+		recipients = append(recipients, mention)
+	}
+
+	if !mentionsOnly {
+		participants, err := h.repo.GetTaskParticipants(context.Background(), task.ID)
+		if err != nil {
+			log.Printf("Task comment %d: failed to get task participants: %v", comment.ID, err)
+		}
+		for _, participant := range participants {
+			if !mentioned[participant.UserID] {
+				recipients = append(recipients, participant)
+			}
+		}
+	}
+
+	// Пользователям в режиме «не беспокоить» уведомления не отправляются; расписание проверяется
+	// общим модулем dnd так же, как в уведомлениях чатов
+	schedules, err := h.repo.GetDNDSchedules(context.Background(), userIDs(recipients))
+	if err != nil {
+		log.Printf("Task comment %d: failed to get dnd schedules: %v", comment.ID, err)
+	}
+	now := time.Now()
+
+	authorID := 0
+	if comment.AuthorID != nil {
+		authorID = *comment.AuthorID
+	}
+	authorName := ""
+	if comment.AuthorName != nil {
+		authorName = *comment.AuthorName
+	}
+
+	for _, recipient := range recipients {
+		if recipient.UserID == authorID || schedules[recipient.UserID].ActiveAt(now) {
+			continue
+		}
+
+		event := kafka.TaskCommentNotificationEvent{
+			TaskID:         task.ID,
+			TaskTitle:      task.Title,
+			WorkspaceID:    task.WorkspaceID,
+			CommentID:      comment.ID,
+			AuthorID:       authorID,
+			AuthorName:     authorName,
+			Text:           comment.Text,
+			RecipientID:    recipient.UserID,
+			RecipientEmail: recipient.Login,
+			RecipientName:  recipient.Name,
+			Mentioned:      mentioned[recipient.UserID],
+			CreatedAt:      comment.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := h.producer.Publish(kafka.TopicTaskCommentNotification, event); err != nil {
+			log.Printf("Task comment %d: failed to publish notification for user %d: %v", comment.ID, recipient.UserID, err)
+		}
+	}
+}
+
+// extractMentions возвращает упомянутые в тексте логины в нижнем регистре
+func extractMentions(text string) []string {
+	var mentions []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		mention := strings.ToLower(strings.TrimRight(match[1], ".,-@"))
+		if mention != "" {
+			mentions = append(mentions, mention)
+		}
+	}
+	return mentions
+}
+
+// userIDs возвращает ID пользователей списка
+func userIDs(users []dm.WorkspaceUser) []int {
+	ids := make([]int, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.UserID)
+	}
+	return ids
+}
+
+// toCommentResponse преобразует комментарий в ответ API
+func toCommentResponse(comment *dm.TaskComment) models.TaskCommentResponse {
+	response := models.TaskCommentResponse{
+		ID:         comment.ID,
+		TaskID:     comment.TaskID,
+		AuthorID:   comment.AuthorID,
+		AuthorName: comment.AuthorName,
+		Text:       comment.Text,
+		Mentions:   []models.TaskCommentMentionResponse{},
+		CreatedAt:  comment.CreatedAt,
+		UpdatedAt:  comment.UpdatedAt,
+	}
+	for _, mention := range comment.Mentions {
+		response.Mentions = append(response.Mentions, models.TaskCommentMentionResponse{
+			UserID: mention.UserID,
+			Login:  mention.Login,
+			Name:   mention.Name,
+		})
+	}
+	return response
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "@Ivan, посмотри", want: []string{"ivan"}},
+		{text: "готово, @petr.", want: []string{"petr"}},
+		{text: "пиши на ivan@example.com", want: nil},
+		{text: "спроси @anna@example.com и @oleg", want: []string{"anna@example.com", "oleg"}},
+		{text: "(@ivan)", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := extractMentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("extractMentions(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/diploma/shared/etag"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// respondTaskConflict отвечает 412 с текущим состоянием задачи
+func (h *TaskHandler) respondTaskConflict(c *gin.Context, taskID, userID int) {
+	task, err := h.repo.GetTaskByID(c.Request.Context(), taskID, userID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "task not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task"})
+		return
+	}
+	etag.RespondConflict(c, "task was modified by another user", task.Version, h.convertToTaskResponse(task))
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/repository"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// labelColorPattern — цвет метки в формате #rrggbb
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+type LabelHandler struct {
+	repo *repository.Repository
+}
+
+func NewLabelHandler(repo *repository.Repository) *LabelHandler {
+	return &LabelHandler{repo: repo}
+}
+
+// GetLabels godoc
+// @Summary Получить метки рабочего пространства
+// @Description Возвращает метки задач рабочего пространства
+// @Tags labels
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Success 200 {object} models.LabelListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/labels/{workspace_id} [get]
+func (h *LabelHandler) GetLabels(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	workspaceID, err := strconv.Atoi(c.Param("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid workspace id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.repo.ValidateUserInWorkspace(ctx, userID, workspaceID); err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
+		return
+	}
+
+	labels, err := h.repo.GetLabels(ctx, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get labels"})
+		return
+	}
+
+	response := models.LabelListResponse{Labels: []models.LabelResponse{}}
+	for i := range labels {
+		response.Labels = append(response.Labels, toLabelResponse(&labels[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateLabel godoc
+// @Summary Создать метку
+// @Description Создает метку задач рабочего пространства (только руководитель РП). Название уникально в пределах РП без учета регистра
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param request body models.CreateLabelRequest true "Название и цвет метки"
+// @Success 201 {object} models.LabelResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/labels/{workspace_id} [post]
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	workspaceID, ok := authorizeWorkspaceLeader(c, h.repo, "only workspace leader can manage labels")
+	if !ok {
+		return
+	}
+
+	var req models.CreateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "label name is required"})
+		return
+	}
+
+	color, err := normalizeLabelColor(req.Color)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	userID, _ := getUserID(c)
+	label, err := h.repo.CreateLabel(c.Request.Context(), &dm.Label{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Color:       color,
+		CreatedBy:   &userID,
+	})
+	if err != nil {
+		if err.Error() == "label already exists" {
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "label with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to create label"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toLabelResponse(label))
+}
+
+// UpdateLabel godoc
+// @Summary Изменить метку
+// @Description Изменяет название и (или) цвет метки рабочего пространства (только руководитель РП)
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param label_id path int true "ID метки"
+// @Param request body models.UpdateLabelRequest true "Изменения метки"
+// @Success 200 {object} models.LabelResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/labels/{workspace_id}/{label_id} [put]
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	workspaceID, ok := authorizeWorkspaceLeader(c, h.repo, "only workspace leader can manage labels")
+	if !ok {
+		return
+	}
+
+	labelID, err := strconv.Atoi(c.Param("label_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid label id"})
+		return
+	}
+
+	var req models.UpdateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "label name is required"})
+			return
+		}
+		req.Name = &name
+	}
+	if req.Color != nil {
+		color, err := normalizeLabelColor(*req.Color)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		req.Color = &color
+	}
+
+	label, err := h.repo.UpdateLabel(c.Request.Context(), workspaceID, labelID, req.Name, req.Color)
+	if err != nil {
+		switch err.Error() {
+		case "label not found":
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "label not found"})
+		case "label already exists":
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "label with this name already exists"})
+		default:
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update label"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toLabelResponse(label))
+}
+
+// DeleteLabel godoc
+// @Summary Удалить метку
+// @Description Удаляет метку рабочего пространства и снимает ее со всех задач (только руководитель РП)
+// @Tags labels
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param label_id path int true "ID метки"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/labels/{workspace_id}/{label_id} [delete]
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	workspaceID, ok := authorizeWorkspaceLeader(c, h.repo, "only workspace leader can manage labels")
+	if !ok {
+		return
+	}
+
+	labelID, err := strconv.Atoi(c.Param("label_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid label id"})
+		return
+	}
+
+	if err := h.repo.DeleteLabel(c.Request.Context(), workspaceID, labelID); err != nil {
+		if err.Error() == "label not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "label not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to delete label"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// normalizeLabelColor приводит цвет метки к виду #rrggbb
+func normalizeLabelColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if !labelColorPattern.MatchString(color) {
+		return "", fmt.Errorf("invalid color, expected #rrggbb")
+	}
+	return color, nil
+}
+
+// toLabelResponse преобразует метку в ответ API
+func toLabelResponse(label *dm.Label) models.LabelResponse {
+	return models.LabelResponse{
+		ID:          label.ID,
+		WorkspaceID: label.WorkspaceID,
+		Name:        label.Name,
+		Color:       label.Color,
+		CreatedBy:   label.CreatedBy,
+		CreatedAt:   label.CreatedAt,
+	}
+}
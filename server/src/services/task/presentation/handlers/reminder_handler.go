@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/repository"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+type ReminderHandler struct {
+	repo        *repository.Repository
+	defaultDays []int
+}
+
+// NewReminderHandler создает обработчик настроек напоминаний.
+// defaultDays — за сколько дней до срока напоминать в РП без собственных настроек
+func NewReminderHandler(repo *repository.Repository, defaultDays []int) *ReminderHandler {
+	return &ReminderHandler{repo: repo, defaultDays: defaultDays}
+}
+
+// GetReminderSettings godoc
+// @Summary Получить настройки напоминаний
+// @Description Возвращает, за сколько дней до срока исполнители задач рабочего пространства получают напоминания и отправляется ли напоминание о просрочке
+// @Tags reminders
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Success 200 {object} models.ReminderSettingsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/reminders/{workspace_id} [get]
+func (h *ReminderHandler) GetReminderSettings(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	workspaceID, err := strconv.Atoi(c.Param("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid workspace id"})
+		return
+	}
+
+	if err := h.repo.ValidateUserInWorkspace(c.Request.Context(), userID, workspaceID); err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
+		return
+	}
+
+	h.respondWithSettings(c, workspaceID)
+}
+
+// UpdateReminderSettings godoc
+// @Summary Изменить настройки напоминаний
+// @Description Задает, за сколько дней до срока (от 0 до 30, не более 5 значений) исполнители задач получают напоминания, и включает напоминание о просрочке (только руководитель РП)
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param request body models.UpdateReminderSettingsRequest true "Настройки напоминаний"
+// @Success 200 {object} models.ReminderSettingsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/reminders/{workspace_id} [put]
+func (h *ReminderHandler) UpdateReminderSettings(c *gin.Context) {
+	workspaceID, ok := authorizeWorkspaceLeader(c, h.repo, "only workspace leader can manage reminders")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateReminderSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	// Убираем повторы и храним offsets по убыванию: сначала самое раннее напоминание
+	seen := make(map[int]bool, len(req.OffsetDays))
+	offsets := make([]int, 0, len(req.OffsetDays))
+	for _, days := range req.OffsetDays {
+		if !seen[days] {
+			seen[days] = true
+			offsets = append(offsets, days)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+
+	userID, _ := getUserID(c)
+	settings := &dm.ReminderSettings{
+		WorkspaceID:   workspaceID,
+		OffsetDays:    offsets,
+		NotifyOverdue: *req.NotifyOverdue,
+		UpdatedBy:     &userID,
+	}
+
+	if err := h.repo.SaveReminderSettings(c.Request.Context(), settings); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update reminder settings"})
+		return
+	}
+
+	h.respondWithSettings(c, workspaceID)
+}
+
+// ResetReminderSettings godoc
+// @Summary Сбросить настройки напоминаний
+// @Description Удаляет собственные настройки напоминаний рабочего пространства, после чего действуют настройки по умолчанию (только руководитель РП)
+// @Tags reminders
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Success 200 {object} models.ReminderSettingsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/reminders/{workspace_id} [delete]
+func (h *ReminderHandler) ResetReminderSettings(c *gin.Context) {
+	workspaceID, ok := authorizeWorkspaceLeader(c, h.repo, "only workspace leader can manage reminders")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteReminderSettings(c.Request.Context(), workspaceID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to reset reminder settings"})
+		return
+	}
+
+	h.respondWithSettings(c, workspaceID)
+}
+
+// respondWithSettings возвращает действующие настройки напоминаний РП
+func (h *ReminderHandler) respondWithSettings(c *gin.Context, workspaceID int) {
+	settings, err := h.repo.GetReminderSettings(c.Request.Context(), workspaceID, h.defaultDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get reminder settings"})
+		return
+	}
+
+	response := models.ReminderSettingsResponse{
+		WorkspaceID:   settings.WorkspaceID,
+		OffsetDays:    settings.OffsetDays,
+		NotifyOverdue: settings.NotifyOverdue,
+		IsDefault:     settings.IsDefault,
+		UpdatedAt:     settings.UpdatedAt,
+	}
+	if response.OffsetDays == nil {
+		response.OffsetDays = []int{}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
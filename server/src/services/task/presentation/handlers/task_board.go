@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/diploma/shared/kafka"
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultBoardColumnSize = 100
+	maxBoardColumnSize     = 500
+)
+
+// ========== Board Operations ==========
+
+// GetBoard godoc
+// @Summary Получить Kanban-доску
+// @Description Возвращает колонки доски рабочего пространства в порядке статусов workflow. Задачи каждой колонки упорядочены по позиции на доске; limit ограничивает количество задач в колонке
+// @Tags board
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param assignee_id query int false "Только задачи исполнителя"
+// @Param limit query int false "Максимум задач в колонке (по умолчанию 100, не более 500)"
+// @Success 200 {object} models.BoardResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/board/{workspace_id} [get]
+func (h *TaskHandler) GetBoard(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	workspaceID, err := strconv.Atoi(c.Param("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid workspace id"})
+		return
+	}
+
+	filter := dm.BoardFilter{Limit: defaultBoardColumnSize}
+	if filter.AssigneeID, err = parseOptionalID(c, "assignee_id"); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxBoardColumnSize {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid limit, expected 1-500"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.repo.ValidateUserInWorkspace(ctx, userID, workspaceID); err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
+		return
+	}
+
+	workflow, err := h.repo.GetWorkflow(ctx, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workflow"})
+		return
+	}
+
+	tasks, totals, err := h.repo.GetBoardTasks(ctx, workspaceID, userID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get board"})
+		return
+	}
+
+	columnTasks := make(map[int][]models.TaskResponse)
+	for i := range tasks {
+		columnTasks[tasks[i].Status] = append(columnTasks[tasks[i].Status], h.convertToTaskResponse(&tasks[i]))
+	}
+
+	response := models.BoardResponse{
+		WorkspaceID: workspaceID,
+		Columns:     []models.BoardColumnResponse{},
+	}
+	for _, status := range workflow.Statuses {
+		column := models.BoardColumnResponse{
+			Status:   status.Code,
+			Name:     status.Name,
+			Category: status.Category,
+			Total:    totals[status.Code],
+			Tasks:    columnTasks[status.Code],
+		}
+		if column.Tasks == nil {
+			column.Tasks = []models.TaskResponse{}
+		}
+		column.HasMore = column.Total > len(column.Tasks)
+		response.Columns = append(response.Columns, column)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// MoveTask godoc
+// @Summary Переместить задачу на доске
+// @Description Перемещает задачу в колонку статуса status на позицию position (с нуля) одним действием. Смена статуса подчиняется правилам workflow и зависимостям задачи; изменения статуса и позиции записываются в историю
+// @Tags board
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param request body models.MoveTaskRequest true "Колонка и позиция"
+// @Success 200 {object} models.TaskResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/move [post]
+func (h *TaskHandler) MoveTask(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	var req models.MoveTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	workflow, err := h.repo.GetWorkflow(ctx, task.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workflow"})
+		return
+	}
+
+	target, ok := workflow.Status(req.Status)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid task status"})
+		return
+	}
+
+	// Перемещение внутри колонки меняет только позицию
+	statusChanged := task.Status != req.Status
+	if statusChanged && !h.authorizeStatusChange(c, workflow, task, target, userID) {
+		return
+	}
+
+	err = h.repo.MoveTask(ctx, task.ID, userID, task.Status, req.Status, target.Name, *req.Position)
+	if err != nil {
+		switch err.Error() {
+		case "task not found":
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "task not found"})
+		case "task status changed":
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "task status was changed by another user"})
+		default:
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to move task"})
+		}
+		return
+	}
+
+	if statusChanged {
+		h.publishTaskChanged(ctx, kafka.TaskEventStatusChanged, task.ID, userID)
+		h.afterStatusChange(ctx, task, target)
+	} else {
+		h.publishTaskChanged(ctx, kafka.TaskEventUpdated, task.ID, userID)
+	}
+
+	h.respondWithTask(c, task.ID, userID)
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/diploma/shared/kafka"
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/repository"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Результаты массовой операции для задачи
+const (
+	bulkResultUpdated   = "updated"
+	bulkResultUnchanged = "unchanged"
+	bulkResultDeleted   = "deleted"
+	bulkResultFailed    = "failed"
+	bulkResultSkipped   = "skipped"
+)
+
+// ========== Bulk Operations ==========
+
+// BulkUpdateTasks godoc
+// @Summary Массовая операция над задачами
+// @Description Применяет к списку задач рабочего пространства смену статуса, добавление и удаление исполнителей и меток или удаление задач. Права проверяются для каждой задачи так же, как в одиночных операциях; допустимые изменения применяются в одной транзакции, история записывается с источником bulk. В режиме atomic операция отменяется целиком, если ее нельзя применить хотя бы к одной задаче
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param request body models.BulkTaskRequest true "Задачи и изменения"
+// @Success 200 {object} models.BulkTaskResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/bulk [post]
+func (h *TaskHandler) BulkUpdateTasks(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req models.BulkTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	op := dm.BulkTaskOperation{
+		AddAssignees:    uniqueIDs(req.AddAssignees),
+		RemoveAssignees: uniqueIDs(req.RemoveAssignees),
+		AddLabels:       uniqueIDs(req.AddLabels),
+		RemoveLabels:    uniqueIDs(req.RemoveLabels),
+		Delete:          req.Delete,
+	}
+	hasChanges := req.Status != nil || len(op.AddAssignees) > 0 || len(op.RemoveAssignees) > 0 ||
+		len(op.AddLabels) > 0 || len(op.RemoveLabels) > 0
+	if op.Delete && hasChanges {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "delete cannot be combined with other changes"})
+		return
+	}
+	if !op.Delete && !hasChanges {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "no changes specified"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.repo.ValidateUserInWorkspace(ctx, userID, req.WorkspaceID); err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
+		return
+	}
+
+	var workflow *dm.Workflow
+	if req.Status != nil {
+		workflow, err = h.repo.GetWorkflow(ctx, req.WorkspaceID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workflow"})
+			return
+		}
+		target, ok := workflow.Status(*req.Status)
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid task status"})
+			return
+		}
+		op.Status = target
+	}
+
+	// Новые исполнители и метки проверяются один раз для всех задач
+	for _, assigneeID := range op.AddAssignees {
+		if err := h.repo.ValidateUserInWorkspace(ctx, assigneeID, req.WorkspaceID); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("user %d is not a member of workspace", assigneeID)})
+			return
+		}
+	}
+	if len(op.AddLabels) > 0 {
+		ok, err := h.workspaceHasLabels(ctx, req.WorkspaceID, op.AddLabels)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to validate labels"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "label not found in workspace"})
+			return
+		}
+	}
+
+	taskIDs := uniqueIDs(req.TaskIDs)
+	tasks, err := h.repo.GetTasksByIDs(ctx, req.WorkspaceID, userID, taskIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get tasks"})
+		return
+	}
+	found := make(map[int]*dm.TaskWithDetails, len(tasks))
+	for i := range tasks {
+		found[tasks[i].ID] = &tasks[i]
+	}
+
+	// Права проверяются для каждой задачи до применения изменений
+	failures := make(map[int]models.BulkTaskResult)
+	var valid []dm.TaskWithDetails
+	for _, taskID := range taskIDs {
+		task, ok := found[taskID]
+		if !ok {
+			failures[taskID] = models.BulkTaskResult{Code: http.StatusNotFound, Error: "task not found"}
+			continue
+		}
+		if code, message := h.checkBulkOperation(ctx, workflow, task, op, userID); code != 0 {
+			failures[taskID] = models.BulkTaskResult{Code: code, Error: message}
+			continue
+		}
+		valid = append(valid, *task)
+	}
+
+	applied := len(valid) > 0 && (!req.Atomic || len(failures) == 0)
+	var changed map[int]int
+	if applied {
+		// Чаты удаляемых задач запоминаются до удаления для событий deleted
+		deletedChats := make(map[int][]int)
+		if op.Delete {
+			for i := range valid {
+				deletedChats[valid[i].ID] = h.taskChatsForEvent(ctx, valid[i].ID)
+			}
+		}
+
+		bulkCtx := repository.WithChangeSource(ctx, dm.ChangeSourceBulk)
+		changed, err = h.repo.ApplyBulkTaskOperation(bulkCtx, userID, valid, op)
+		if err != nil {
+			switch err.Error() {
+			case "task status changed", "task not found":
+				c.JSON(http.StatusConflict, models.ErrorResponse{Error: "tasks were changed by another user, retry the operation"})
+			default:
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to apply bulk operation"})
+			}
+			return
+		}
+
+		for i := range valid {
+			task := &valid[i]
+			if changed[task.ID] == 0 {
+				continue
+			}
+			switch {
+			case op.Delete:
+				h.publishTaskDeleted(task, deletedChats[task.ID], userID)
+			case op.Status != nil && task.Status != op.Status.Code:
+				h.publishTaskChanged(ctx, kafka.TaskEventStatusChanged, task.ID, userID)
+				h.afterStatusChange(ctx, task, op.Status)
+			case len(op.AddAssignees) > 0 || len(op.RemoveAssignees) > 0:
+				h.publishTaskChanged(ctx, kafka.TaskEventAssigneesChanged, task.ID, userID)
+			default:
+				h.publishTaskChanged(ctx, kafka.TaskEventUpdated, task.ID, userID)
+			}
+		}
+	}
+
+	response := models.BulkTaskResponse{
+		Applied: applied,
+		Results: make([]models.BulkTaskResult, 0, len(taskIDs)),
+	}
+	for _, taskID := range taskIDs {
+		result, failed := failures[taskID]
+		result.TaskID = taskID
+		switch {
+		case failed:
+			result.Result = bulkResultFailed
+			response.Failed++
+		case !applied:
+			result.Result = bulkResultSkipped
+		case op.Delete:
+			result.Result = bulkResultDeleted
+			response.Updated++
+		case changed[taskID] > 0:
+			result.Result = bulkResultUpdated
+			result.Changes = changed[taskID]
+			response.Updated++
+		default:
+			result.Result = bulkResultUnchanged
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// checkBulkOperation проверяет, что пользователь может применить операцию к задаче.
+// Правила совпадают с одиночными операциями. При отказе возвращает HTTP-код и текст ошибки
+func (h *TaskHandler) checkBulkOperation(ctx context.Context, workflow *dm.Workflow, task *dm.TaskWithDetails, op dm.BulkTaskOperation, userID int) (int, string) {
+	if op.Delete && task.Creator != userID {
+		return http.StatusForbidden, "only task creator can delete it"
+	}
+	if len(op.RemoveAssignees) > 0 && task.Creator != userID {
+		return http.StatusForbidden, "only task creator can remove assignees"
+	}
+	if op.Status != nil && task.Status != op.Status.Code {
+		return h.checkStatusChange(ctx, workflow, task, op.Status, userID)
+	}
+	return 0, ""
+}
+
+// uniqueIDs возвращает ID без повторов в исходном порядке
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/diploma/shared/kafka"
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/repository"
+)
+
+// ========== Task Event Operations ==========
+
+// PublishTaskChanged публикует событие tasks.changed с состоянием задачи после изменения.
+// extraChatIDs добавляются к прикрепленным чатам (например, только что открепленный чат).
+// producer может быть nil — тогда событие не публикуется. analytics — кэш отчетов, который сбрасывается для РП задачи
+func PublishTaskChanged(ctx context.Context, repo *repository.Repository, producer *kafka.Producer, analytics *AnalyticsCache, eventType string, taskID, actorID int, extraChatIDs ...int) {
+	if producer == nil {
+		return
+	}
+
+	task, err := repo.GetTaskByID(ctx, taskID, actorID)
+	if err != nil {
+		log.Printf("Task %d: failed to load task for %s event: %v", taskID, eventType, err)
+		return
+	}
+
+	chatIDs, err := taskChatIDs(ctx, repo, taskID)
+	if err != nil {
+		log.Printf("Task %d: failed to load chats for %s event: %v", taskID, eventType, err)
+		return
+	}
+	for _, chatID := range extraChatIDs {
+		chatIDs = appendUniqueID(chatIDs, chatID)
+	}
+
+	assignees, err := repo.GetTaskAssignees(ctx, taskID)
+	if err != nil {
+		log.Printf("Task %d: failed to load assignees for %s event: %v", taskID, eventType, err)
+		return
+	}
+	assigneeIDs := make([]int, 0, len(assignees))
+	for _, assignee := range assignees {
+		assigneeIDs = append(assigneeIDs, assignee.UserID)
+	}
+
+	// Наблюдатели получают те же изменения, что и исполнители
+	watchers, err := repo.GetTaskWatchers(ctx, taskID)
+	if err != nil {
+		log.Printf("Task %d: failed to load watchers for %s event: %v", taskID, eventType, err)
+		return
+	}
+	watcherIDs := make([]int, 0, len(watchers))
+	for _, watcher := range watchers {
+		watcherIDs = append(watcherIDs, watcher.UserID)
+	}
+
+	publishTaskEvent(producer, analytics, kafka.TaskChangedEvent{
+		Type:        eventType,
+		TaskID:      task.ID,
+		WorkspaceID: task.WorkspaceID,
+		ChatIDs:     chatIDs,
+		ActorID:     actorID,
+		Title:       task.Title,
+		Status:      task.Status,
+		StatusName:  task.StatusName,
+		Priority:    task.Priority,
+		DueDate:     task.Date.Format("2006-01-02"),
+		AssigneeIDs: assigneeIDs,
+		WatcherIDs:  watcherIDs,
+		ChangedAt:   time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// publishTaskChanged публикует событие изменения задачи от имени пользователя
+func (h *TaskHandler) publishTaskChanged(ctx context.Context, eventType string, taskID, actorID int, extraChatIDs ...int) {
+	PublishTaskChanged(ctx, h.repo, h.producer, h.analytics, eventType, taskID, actorID, extraChatIDs...)
+}
+
+// taskChatsForEvent возвращает чаты задачи до ее удаления, чтобы событие deleted дошло до их участников
+func (h *TaskHandler) taskChatsForEvent(ctx context.Context, taskID int) []int {
+	if h.producer == nil {
+		return nil
+	}
+	chatIDs, err := taskChatIDs(ctx, h.repo, taskID)
+	if err != nil {
+		log.Printf("Task %d: failed to load chats for deleted event: %v", taskID, err)
+	}
+	return chatIDs
+}
+
+// publishTaskDeleted публикует событие удаления задачи. Чаты передаются заранее: после удаления они уже не связаны с задачей
+func (h *TaskHandler) publishTaskDeleted(task *dm.TaskWithDetails, chatIDs []int, actorID int) {
+	if h.producer == nil {
+		return
+	}
+	if chatIDs == nil {
+		chatIDs = []int{}
+	}
+
+	publishTaskEvent(h.producer, h.analytics, kafka.TaskChangedEvent{
+		Type:        kafka.TaskEventDeleted,
+		TaskID:      task.ID,
+		WorkspaceID: task.WorkspaceID,
+		ChatIDs:     chatIDs,
+		ActorID:     actorID,
+		ChangedAt:   time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// publishTaskEvent публикует событие изменения задачи и сбрасывает кэш аналитики ее РП
+func publishTaskEvent(producer *kafka.Producer, analytics *AnalyticsCache, event kafka.TaskChangedEvent) {
+	analytics.invalidateWorkspace(event.WorkspaceID)
+	if err := producer.Publish(kafka.TopicTaskChanged, event); err != nil {
+		log.Printf("Task %d: failed to publish %s event: %v", event.TaskID, event.Type, err)
+	}
+}
+
+// taskChatIDs возвращает ID чатов, к которым прикреплена задача
+func taskChatIDs(ctx context.Context, repo *repository.Repository, taskID int) ([]int, error) {
+	chats, err := repo.GetTaskChats(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	chatIDs := make([]int, 0, len(chats))
+	for _, chat := range chats {
+		chatIDs = append(chatIDs, chat.ChatID)
+	}
+	return chatIDs, nil
+}
+
+func appendUniqueID(ids []int, id int) []int {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
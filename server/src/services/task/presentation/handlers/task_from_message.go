@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/diploma/shared/kafka"
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/repository"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// maxTaskTitleLength — максимальная длина названия задачи в символах
+const maxTaskTitleLength = 100
+
+// ========== Task From Message Operations ==========
+
+// CreateTaskFromMessage godoc
+// @Summary Создать задачу из сообщения чата
+// @Description Создает задачу в рабочем пространстве чата по сообщению: название — из первой строки сообщения (если не указано title), описание — полный текст. Задача прикрепляется к чату и хранит ссылку на сообщение, в чат публикуется системное сообщение. Если системное сообщение отправить не удалось, system_message_posted = false
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param request body models.CreateTaskFromMessageRequest true "Сообщение и данные задачи"
+// @Success 201 {object} models.TaskFromMessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/from-message [post]
+func (h *TaskHandler) CreateTaskFromMessage(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req models.CreateTaskFromMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	message, err := h.repo.GetChatMessage(ctx, req.MessageID)
+	if err != nil {
+		if err.Error() == "message not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "message not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get message"})
+		return
+	}
+
+	// Сообщение доступно только участникам чата
+	if err := h.repo.ValidateUserInChat(ctx, userID, message.ChatID); err != nil {
+		if err.Error() == "user not in chat" {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of chat"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to validate chat membership"})
+		return
+	}
+
+	if message.ArchivedAt != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "chat is archived"})
+		return
+	}
+
+	var title string
+	if req.Title != nil {
+		title = strings.TrimSpace(*req.Title)
+	} else {
+		title = titleFromMessage(message.Text)
+	}
+	if utf8.RuneCountInString(title) < 3 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "message is too short for task title, specify title"})
+		return
+	}
+
+	description := strings.TrimSpace(message.Text)
+	createReq := models.CreateTaskRequest{
+		WorkspaceID:   message.WorkspaceID,
+		Title:         title,
+		Date:          req.Date,
+		Status:        req.Status,
+		AssignedUsers: req.AssignedUsers,
+		ChatID:        &message.ChatID,
+		Priority:      req.Priority,
+		LabelIDs:      req.LabelIDs,
+	}
+	if description != "" {
+		createReq.Description = &description
+	}
+
+	// Изменения записываются в историю задачи с источником chat
+	c.Request = c.Request.WithContext(repository.WithChangeSource(ctx, dm.ChangeSourceChat))
+
+	task, ok := h.createTask(c, userID, &createReq, &message.ID)
+	if !ok {
+		return
+	}
+
+	posted := h.publishTaskCreatedFromMessage(task, message, userID)
+
+	c.JSON(http.StatusCreated, models.TaskFromMessageResponse{
+		TaskResponse:        h.convertToTaskResponse(task),
+		SystemMessagePosted: posted,
+	})
+}
+
+// publishTaskCreatedFromMessage публикует событие для системного сообщения в чате.
+// Возвращает false, если событие не отправлено
+func (h *TaskHandler) publishTaskCreatedFromMessage(task *dm.TaskWithDetails, message *dm.ChatMessage, actorID int) bool {
+	if h.producer == nil {
+		log.Printf("Task %d: kafka is not configured, system message for chat %d is not posted", task.ID, message.ChatID)
+		return false
+	}
+
+	event := kafka.TaskCreatedFromMessageEvent{
+		TaskID:      task.ID,
+		TaskTitle:   task.Title,
+		WorkspaceID: task.WorkspaceID,
+		ChatID:      message.ChatID,
+		MessageID:   message.ID,
+		ActorID:     actorID,
+		ActorName:   task.CreatorName,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := h.producer.Publish(kafka.TopicTaskCreatedFromMessage, event); err != nil {
+		log.Printf("Task %d: failed to publish task created from message event: %v", task.ID, err)
+		return false
+	}
+	return true
+}
+
+// titleFromMessage возвращает первую непустую строку сообщения, обрезанную до допустимой длины названия задачи
+func titleFromMessage(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if utf8.RuneCountInString(line) > maxTaskTitleLength {
+			line = strings.TrimSpace(string([]rune(line)[:maxTaskTitleLength]))
+		}
+		return line
+	}
+	return ""
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/diploma/shared/etag"
+	"github.com/diploma/shared/kafka"
 	dm "github.com/diploma/task-service/data/models"
 	"github.com/diploma/task-service/data/repository"
 	"github.com/diploma/task-service/presentation/models"
@@ -13,11 +17,15 @@ import (
 )
 
 type TaskHandler struct {
-	repo *repository.Repository
+	repo      *repository.Repository
+	producer  *kafka.Producer
+	analytics *AnalyticsCache
 }
 
-func NewTaskHandler(repo *repository.Repository) *TaskHandler {
-	return &TaskHandler{repo: repo}
+// NewTaskHandler создает обработчик задач. producer может быть nil — тогда события о задачах не публикуются.
+// analytics — кэш отчетов аналитики, который сбрасывается для РП при публикации события изменения его задачи
+func NewTaskHandler(repo *repository.Repository, producer *kafka.Producer, analytics *AnalyticsCache) *TaskHandler {
+	return &TaskHandler{repo: repo, producer: producer, analytics: analytics}
 }
 
 // getUserID извлекает ID пользователя из заголовка X-User-ID
@@ -57,10 +65,22 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
+	task, ok := h.createTask(c, userID, &req, nil)
+	if !ok {
+		return
+	}
+
+	etag.Set(c, task.Version)
+	c.JSON(http.StatusCreated, h.convertToTaskResponse(task))
+}
+
+// createTask создает задачу по проверенному запросу и возвращает информацию о ней.
+// sourceMessageID — сообщение чата, из которого создается задача. При ошибке отправляет ответ с ошибкой
+func (h *TaskHandler) createTask(c *gin.Context, userID int, req *models.CreateTaskRequest, sourceMessageID *int) (*dm.TaskWithDetails, bool) {
 	parsedDate, err := parseDate(req.Date)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid date format, expected YYYY-MM-DD"})
-		return
+		return nil, false
 	}
 
 	ctx := c.Request.Context()
@@ -68,34 +88,88 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	// Проверяем, что пользователь является участником РП
 	if err := h.repo.ValidateUserInWorkspace(ctx, userID, req.WorkspaceID); err != nil {
 		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
-		return
+		return nil, false
 	}
 
-	// Устанавливаем статус по умолчанию, если не указан
+	workflow, err := h.repo.GetWorkflow(ctx, req.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workflow"})
+		return nil, false
+	}
+
+	// Устанавливаем начальный статус workflow, если не указан
 	if req.Status == 0 {
-		req.Status = dm.TaskStatusCreated
+		initial := workflow.InitialStatus()
+		if initial == nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "workflow has no statuses"})
+			return nil, false
+		}
+		req.Status = initial.Code
 	}
 
-	// Проверяем валидность статуса
-	if !dm.IsValidTaskStatus(req.Status) {
+	// Проверяем, что статус есть в workflow РП
+	if _, ok := workflow.Status(req.Status); !ok {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid task status"})
-		return
+		return nil, false
+	}
+
+	// Правило повторения проверяется до создания задачи
+	var recurrenceRule, recurrenceMode string
+	if req.Recurrence != nil {
+		recurrenceRule, recurrenceMode, err = buildRecurrenceRule(req.Recurrence)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return nil, false
+		}
+	}
+
+	// Родительская задача должна находиться в том же РП
+	if req.ParentID != nil {
+		if err := h.repo.ValidateTaskOwnership(ctx, *req.ParentID, req.WorkspaceID); err != nil {
+			if err.Error() == "task not found in workspace" {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "parent task not found in workspace"})
+				return nil, false
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to validate parent task"})
+			return nil, false
+		}
+	}
+
+	// Метки должны принадлежать тому же РП
+	if len(req.LabelIDs) > 0 {
+		ok, err := h.workspaceHasLabels(ctx, req.WorkspaceID, req.LabelIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to validate labels"})
+			return nil, false
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "label not found in workspace"})
+			return nil, false
+		}
+	}
+
+	if req.Priority == "" {
+		req.Priority = dm.TaskPriorityNormal
 	}
 
 	// Создаем задачу
 	task := &dm.Task{
-		Creator:     userID,
-		WorkspaceID: req.WorkspaceID,
-		Title:       req.Title,
-		Description: req.Description,
-		Date:        parsedDate,
-		Status:      req.Status,
+		Creator:         userID,
+		WorkspaceID:     req.WorkspaceID,
+		Title:           req.Title,
+		Description:     req.Description,
+		Date:            parsedDate,
+		Status:          req.Status,
+		Priority:        req.Priority,
+		ParentID:        req.ParentID,
+		SourceMessageID: sourceMessageID,
+		EstimateMinutes: req.EstimateMinutes,
 	}
 
 	createdTask, err := h.repo.CreateTask(ctx, task)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to create task"})
-		return
+		return nil, false
 	}
 
 	// Добавляем исполнителей, если указаны
@@ -105,14 +179,29 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 			if err := h.repo.ValidateUserInWorkspace(ctx, assigneeID, req.WorkspaceID); err != nil {
 				continue // Пропускаем, если пользователь не в РП
 			}
-			h.repo.AddTaskAssignee(ctx, createdTask.ID, assigneeID)
+			h.repo.AddTaskAssignee(ctx, createdTask.ID, assigneeID, userID)
 		}
 	}
 
 	// Прикрепляем к чату, если указан
 	if req.ChatID != nil {
 		if err := h.repo.ValidateChatOwnership(ctx, *req.ChatID, req.WorkspaceID); err == nil {
-			h.repo.AttachTaskToChat(ctx, createdTask.ID, *req.ChatID)
+			h.repo.AttachTaskToChat(ctx, createdTask.ID, *req.ChatID, userID)
+		}
+	}
+
+	// Добавляем метки, если указаны
+	for _, labelID := range req.LabelIDs {
+		if err := h.repo.AddTaskLabel(ctx, createdTask.ID, labelID, userID); err != nil && err.Error() != "label already added" {
+			log.Printf("add label task %d label %d failed: %v", createdTask.ID, labelID, err)
+		}
+	}
+
+	// Создаем серию, если задача повторяющаяся
+	if req.Recurrence != nil {
+		if _, err := h.repo.CreateTaskSeries(ctx, createdTask.ID, recurrenceRule, recurrenceMode, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to create task series"})
+			return nil, false
 		}
 	}
 
@@ -120,19 +209,33 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	taskDetails, err := h.repo.GetTaskByID(ctx, createdTask.ID, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get created task"})
-		return
+		return nil, false
 	}
 
-	response := h.convertToTaskResponse(taskDetails)
-	c.JSON(http.StatusCreated, response)
+	h.publishTaskChanged(ctx, kafka.TaskEventCreated, taskDetails.ID, userID)
+
+	return taskDetails, true
 }
 
 // GetTasks godoc
 // @Summary Получить список задач
-// @Description Возвращает список задач в рабочем пространстве
+// @Description Возвращает страницу задач рабочего пространства с фильтрацией и сортировкой. Следующая страница запрашивается с параметром cursor из next_cursor
 // @Tags tasks
 // @Produce json
 // @Param workspace_id query int true "ID рабочего пространства"
+// @Param status query string false "Коды статусов через запятую"
+// @Param assignee_id query int false "ID исполнителя"
+// @Param creator_id query int false "ID создателя"
+// @Param due_from query string false "Срок не раньше (YYYY-MM-DD)"
+// @Param due_to query string false "Срок не позже (YYYY-MM-DD)"
+// @Param chat_id query int false "ID прикрепленного чата"
+// @Param overdue query bool false "Только просроченные (true) или только непросроченные (false) задачи"
+// @Param priority query string false "Приоритеты через запятую: low, normal, high, critical"
+// @Param label_id query string false "ID меток через запятую (задачи хотя бы с одной из меток)"
+// @Param q query string false "Поиск по названию и описанию"
+// @Param sort query string false "Сортировка: date, title, status, created; префикс - для убывания (по умолчанию -date)"
+// @Param limit query int false "Количество задач (по умолчанию 50, максимум 100)"
+// @Param cursor query string false "Курсор следующей страницы"
 // @Success 200 {object} models.TaskListResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
@@ -158,6 +261,12 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 		return
 	}
 
+	filter, err := parseTaskFilter(c, workspaceID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	ctx := c.Request.Context()
 
 	// Проверяем, что пользователь является участником РП
@@ -166,23 +275,47 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 		return
 	}
 
-	tasks, err := h.repo.GetTasksByWorkspace(ctx, workspaceID, userID)
+	response, _, err := h.getTaskPage(ctx, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get tasks"})
 		return
 	}
 
-	var taskResponses []models.TaskResponse
+	c.JSON(http.StatusOK, response)
+}
+
+// getTaskPage получает страницу списка задач по фильтру и возвращает ее вместе с задачами страницы
+func (h *TaskHandler) getTaskPage(ctx context.Context, filter dm.TaskFilter) (models.TaskListResponse, []dm.TaskWithDetails, error) {
+	// Запрашиваем на одну задачу больше, чтобы определить наличие следующей страницы
+	pageSize := filter.Limit
+	filter.Limit = pageSize + 1
+
+	tasks, total, err := h.repo.GetTasksByWorkspace(ctx, filter)
+	if err != nil {
+		return models.TaskListResponse{}, nil, err
+	}
+
+	hasMore := len(tasks) > pageSize
+	if hasMore {
+		tasks = tasks[:pageSize]
+	}
+
+	taskResponses := []models.TaskResponse{}
 	for _, task := range tasks {
 		taskResponses = append(taskResponses, h.convertToTaskResponse(&task))
 	}
 
 	response := models.TaskListResponse{
-		Tasks: taskResponses,
-		Total: len(taskResponses),
+		Tasks:   taskResponses,
+		Total:   total,
+		HasMore: hasMore,
+	}
+	if hasMore {
+		nextCursor := encodeTaskCursor(&tasks[len(tasks)-1], filter.SortField)
+		response.NextCursor = &nextCursor
 	}
 
-	c.JSON(http.StatusOK, response)
+	return response, tasks, nil
 }
 
 // GetTask godoc
@@ -223,22 +356,26 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 	}
 
 	response := h.convertToTaskResponse(task)
+	etag.Set(c, task.Version)
 	c.JSON(http.StatusOK, response)
 }
 
 // UpdateTask godoc
 // @Summary Обновить задачу
-// @Description Обновляет информацию о задаче
+// @Description Обновляет информацию о задаче. Заголовок If-Match должен содержать ETag текущей версии задачи
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path int true "ID задачи"
+// @Param If-Match header string true "ETag версии задачи или *"
 // @Param request body models.UpdateTaskRequest true "Обновляемые данные задачи"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 412 {object} models.VersionConflictResponse
+// @Failure 428 {object} models.ErrorResponse
 // @Security BearerAuth
 // @Router /tasks/{id} [put]
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
@@ -289,13 +426,34 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	ifMatch, ok := etag.IfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	if !etag.Matches(ifMatch, task.Version) {
+		etag.RespondConflict(c, "task was modified by another user", task.Version, h.convertToTaskResponse(task))
+		return
+	}
+
 	// Обновляем задачу
-	err = h.repo.UpdateTask(ctx, taskID, req.Title, req.Description, parsedDate)
+	version, err := h.repo.UpdateTask(ctx, taskID, userID, etag.ExpectedVersion(ifMatch, task.Version), req.Title, req.Description, parsedDate, req.Priority, req.EstimateMinutes)
 	if err != nil {
+		if err.Error() == "task version changed" {
+			h.respondTaskConflict(c, taskID, userID)
+			return
+		}
+		if err.Error() == "task not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "task not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update task"})
 		return
 	}
 
+	h.publishTaskChanged(ctx, kafka.TaskEventUpdated, taskID, userID)
+	etag.Set(c, version)
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "task updated successfully",
 	})
@@ -345,6 +503,8 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
+	chatIDs := h.taskChatsForEvent(ctx, taskID)
+
 	// Удаляем задачу
 	err = h.repo.DeleteTask(ctx, taskID)
 	if err != nil {
@@ -352,22 +512,28 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
+	h.publishTaskDeleted(task, chatIDs, userID)
+
 	c.Status(http.StatusNoContent)
 }
 
 // UpdateTaskStatus godoc
 // @Summary Изменить статус задачи
-// @Description Изменяет статус задачи
+// @Description Изменяет статус задачи. Переход должен быть разрешен workflow рабочего пространства для роли пользователя. Задачу с незавершенными блокирующими задачами нельзя перевести в завершенный статус. Заголовок If-Match должен содержать ETag текущей версии задачи
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Param id path int true "ID задачи"
+// @Param If-Match header string true "ETag версии задачи или *"
 // @Param request body models.UpdateTaskStatusRequest true "Новый статус"
 // @Success 200 {object} models.SuccessResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.VersionConflictResponse
+// @Failure 428 {object} models.ErrorResponse
 // @Security BearerAuth
 // @Router /tasks/{id}/status [put]
 func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
@@ -389,16 +555,10 @@ func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
 		return
 	}
 
-	// Проверяем валидность статуса
-	if !dm.IsValidTaskStatus(req.Status) {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid task status"})
-		return
-	}
-
 	ctx := c.Request.Context()
 
 	// Проверяем существование задачи
-	_, err = h.repo.GetTaskByID(ctx, taskID, userID)
+	task, err := h.repo.GetTaskByID(ctx, taskID, userID)
 	if err != nil {
 		if err.Error() == "task not found" {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "task not found"})
@@ -408,13 +568,53 @@ func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
 		return
 	}
 
+	ifMatch, ok := etag.IfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	if !etag.Matches(ifMatch, task.Version) {
+		etag.RespondConflict(c, "task was modified by another user", task.Version, h.convertToTaskResponse(task))
+		return
+	}
+
+	workflow, err := h.repo.GetWorkflow(ctx, task.WorkspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workflow"})
+		return
+	}
+
+	// Проверяем, что статус есть в workflow РП
+	target, ok := workflow.Status(req.Status)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid task status"})
+		return
+	}
+
+	if task.Status == req.Status {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "task already has this status"})
+		return
+	}
+
+	if !h.authorizeStatusChange(c, workflow, task, target, userID) {
+		return
+	}
+
 	// Обновляем статус
-	err = h.repo.UpdateTaskStatus(ctx, taskID, req.Status)
+	version, err := h.repo.UpdateTaskStatus(ctx, taskID, userID, etag.ExpectedVersion(ifMatch, task.Version), task.Status, req.Status, target.Name)
 	if err != nil {
+		if err.Error() == "task version changed" {
+			h.respondTaskConflict(c, taskID, userID)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update task status"})
 		return
 	}
 
+	h.publishTaskChanged(ctx, kafka.TaskEventStatusChanged, taskID, userID)
+	h.afterStatusChange(ctx, task, target)
+	etag.Set(c, version)
+
 	c.JSON(http.StatusOK, models.SuccessResponse{
 		Message: "task status updated successfully",
 	})
@@ -478,7 +678,7 @@ func (h *TaskHandler) AddTaskAssignees(c *gin.Context) {
 			continue // Пропускаем, если пользователь не в РП
 		}
 
-		if err := h.repo.AddTaskAssignee(ctx, taskID, assigneeID); err == nil {
+		if err := h.repo.AddTaskAssignee(ctx, taskID, assigneeID, userID); err == nil {
 			addedCount++
 		} else {
 			log.Printf("add assignee task %d user %d failed: %v", taskID, assigneeID, err)
@@ -488,6 +688,8 @@ func (h *TaskHandler) AddTaskAssignees(c *gin.Context) {
 	status := http.StatusCreated
 	if addedCount == 0 {
 		status = http.StatusBadRequest
+	} else {
+		h.publishTaskChanged(ctx, kafka.TaskEventAssigneesChanged, taskID, userID)
 	}
 	c.JSON(status, models.SuccessResponse{
 		Message: "assignees added successfully",
@@ -611,7 +813,7 @@ func (h *TaskHandler) RemoveTaskAssignee(c *gin.Context) {
 	}
 
 	// Удаляем исполнителя
-	err = h.repo.RemoveTaskAssignee(ctx, taskID, assigneeID)
+	err = h.repo.RemoveTaskAssignee(ctx, taskID, assigneeID, userID)
 	if err != nil {
 		if err.Error() == "assignee not found" {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "assignee not found"})
@@ -621,6 +823,8 @@ func (h *TaskHandler) RemoveTaskAssignee(c *gin.Context) {
 		return
 	}
 
+	h.publishTaskChanged(ctx, kafka.TaskEventAssigneesChanged, taskID, userID)
+
 	c.Status(http.StatusNoContent)
 }
 
@@ -687,7 +891,7 @@ func (h *TaskHandler) AttachTaskToChat(c *gin.Context) {
 	}
 
 	// Прикрепляем задачу к чату
-	err = h.repo.AttachTaskToChat(ctx, taskID, req.ChatID)
+	err = h.repo.AttachTaskToChat(ctx, taskID, req.ChatID, userID)
 	if err != nil {
 		log.Printf("attach task %d to chat %d: repo error: %v", taskID, req.ChatID, err)
 		if err.Error() == "task already attached to chat or invalid chat/task" {
@@ -698,6 +902,8 @@ func (h *TaskHandler) AttachTaskToChat(c *gin.Context) {
 		return
 	}
 
+	h.publishTaskChanged(ctx, kafka.TaskEventUpdated, taskID, userID)
+
 	c.JSON(http.StatusCreated, models.SuccessResponse{
 		Message: "task attached to chat successfully",
 	})
@@ -818,7 +1024,7 @@ func (h *TaskHandler) DetachTaskFromChat(c *gin.Context) {
 	}
 
 	// Открепляем задачу от чата
-	err = h.repo.DetachTaskFromChat(ctx, taskID, chatID)
+	err = h.repo.DetachTaskFromChat(ctx, taskID, chatID, userID)
 	if err != nil {
 		if err.Error() == "task not attached to chat" {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "task not attached to this chat"})
@@ -828,6 +1034,9 @@ func (h *TaskHandler) DetachTaskFromChat(c *gin.Context) {
 		return
 	}
 
+	// Открепленный чат тоже получает событие, чтобы убрать задачу из списка
+	h.publishTaskChanged(ctx, kafka.TaskEventUpdated, taskID, userID, chatID)
+
 	c.Status(http.StatusNoContent)
 }
 
@@ -835,11 +1044,16 @@ func (h *TaskHandler) DetachTaskFromChat(c *gin.Context) {
 
 // GetTaskHistory godoc
 // @Summary Получить историю изменений
-// @Description Возвращает историю изменений задачи
+// @Description Возвращает историю изменений задачи (новые записи первыми) с фильтрацией по полю и автору изменения
 // @Tags tasks
 // @Produce json
 // @Param id path int true "ID задачи"
+// @Param field query string false "Поле (created, title, description, date, status, assignee, chat, task)"
+// @Param actor_id query int false "ID автора изменения"
+// @Param limit query int false "Количество записей (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение"
 // @Success 200 {object} models.TaskHistoryResponse
+// @Failure 400 {object} models.ErrorResponse
 // @Failure 401 {object} models.ErrorResponse
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
@@ -871,25 +1085,60 @@ func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
 		return
 	}
 
-	history, err := h.repo.GetTaskHistory(ctx, taskID)
+	filter := dm.TaskHistoryFilter{
+		TaskID: taskID,
+		Field:  c.Query("field"),
+		Limit:  50,
+	}
+
+	if actorStr := c.Query("actor_id"); actorStr != "" {
+		actorID, err := strconv.Atoi(actorStr)
+		if err != nil || actorID <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid actor_id"})
+			return
+		}
+		filter.ActorID = &actorID
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil && v > 0 && v <= 100 {
+			filter.Limit = v
+		}
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if v, err := strconv.Atoi(offsetStr); err == nil && v >= 0 {
+			filter.Offset = v
+		}
+	}
+
+	history, total, err := h.repo.GetTaskHistory(ctx, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task history"})
 		return
 	}
 
-	var changeResponses []models.TaskChangeResponse
+	changeResponses := []models.TaskChangeResponse{}
 	for _, change := range history {
 		changeResponses = append(changeResponses, models.TaskChangeResponse{
 			ID:          change.ID,
-			Description: change.Description,
 			TaskID:      change.TaskID,
-			ChangedAt:   time.Now(), // В реальной реализации нужно хранить время изменения
+			ActorID:     change.ActorID,
+			ActorName:   change.ActorName,
+			Field:       change.Field,
+			OldValue:    change.OldValue,
+			NewValue:    change.NewValue,
+			Description: change.Description,
+			Source:      change.Source,
+			ChangedAt:   change.ChangedAt,
 		})
 	}
 
 	response := models.TaskHistoryResponse{
 		Changes: changeResponses,
-		Total:   len(changeResponses),
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -897,6 +1146,54 @@ func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
 
 // ========== Helper Methods ==========
 
+// authorizeStatusChange проверяет переход задачи в статус target (см. checkStatusChange). При отказе отправляет ответ с ошибкой
+func (h *TaskHandler) authorizeStatusChange(c *gin.Context, workflow *dm.Workflow, task *dm.TaskWithDetails, target *dm.TaskStatus, userID int) bool {
+	if code, message := h.checkStatusChange(c.Request.Context(), workflow, task, target, userID); code != 0 {
+		c.JSON(code, models.ErrorResponse{Error: message})
+		return false
+	}
+	return true
+}
+
+// checkStatusChange проверяет, что переход в статус target разрешен workflow, доступен пользователю
+// и не нарушает зависимостей. При отказе возвращает HTTP-код и текст ошибки
+func (h *TaskHandler) checkStatusChange(ctx context.Context, workflow *dm.Workflow, task *dm.TaskWithDetails, target *dm.TaskStatus, userID int) (int, string) {
+	// Проверяем, что переход разрешен workflow и доступен пользователю
+	transition, ok := workflow.Transition(task.Status, target.Code)
+	if !ok {
+		return http.StatusConflict, fmt.Sprintf("transition from %q to %q is not allowed", task.StatusName, target.Name)
+	}
+
+	allowed, err := canPerformTransition(ctx, h.repo, task, userID, transition.AllowedRoles)
+	if err != nil {
+		return http.StatusInternalServerError, "failed to check transition permissions"
+	}
+	if !allowed {
+		return http.StatusForbidden, "insufficient permissions for this status transition"
+	}
+
+	// Заблокированную задачу нельзя завершить, пока не завершены блокирующие задачи
+	if target.Category == dm.StatusCategoryDone && task.OpenBlockers > 0 {
+		return http.StatusConflict, "task is blocked by unfinished tasks"
+	}
+
+	return 0, ""
+}
+
+// afterStatusChange выполняет действия, следующие за изменением статуса задачи
+func (h *TaskHandler) afterStatusChange(ctx context.Context, task *dm.TaskWithDetails, target *dm.TaskStatus) {
+	// Завершение задачи серии создает следующую задачу серии
+	if target.Category == dm.StatusCategoryDone && task.SeriesID != nil {
+		automationCtx := repository.WithChangeSource(ctx, dm.ChangeSourceAutomation)
+		next, err := h.repo.CreateNextSeriesTask(automationCtx, *task.SeriesID, task.ID, dm.SeriesModeOnComplete)
+		if err != nil {
+			fmt.Printf("Warning: failed to create next series task: %v\n", err)
+		} else if next != nil {
+			h.publishTaskChanged(ctx, kafka.TaskEventCreated, next.ID, 0)
+		}
+	}
+}
+
 func parseDate(dateStr string) (time.Time, error) {
 	return time.Parse("2006-01-02", dateStr)
 }
@@ -904,18 +1201,31 @@ func parseDate(dateStr string) (time.Time, error) {
 // convertToTaskResponse преобразует модель данных в ответ API
 func (h *TaskHandler) convertToTaskResponse(task *dm.TaskWithDetails) models.TaskResponse {
 	return models.TaskResponse{
-		ID:            task.ID,
-		Creator:       task.Creator,
-		CreatorName:   task.CreatorName,
-		WorkspaceID:   task.WorkspaceID,
-		WorkspaceName: task.WorkspaceName,
-		Title:         task.Title,
-		Description:   task.Description,
-		Date:          task.Date,
-		Status:        task.Status,
-		StatusName:    dm.GetTaskStatusName(task.Status),
-		AssigneeCount: task.AssigneeCount,
-		ChatCount:     task.ChatCount,
-		CreatedAt:     task.CreatedAt,
+		ID:              task.ID,
+		Creator:         task.Creator,
+		CreatorName:     task.CreatorName,
+		WorkspaceID:     task.WorkspaceID,
+		WorkspaceName:   task.WorkspaceName,
+		Title:           task.Title,
+		Description:     task.Description,
+		Date:            task.Date,
+		Status:          task.Status,
+		StatusName:      task.StatusName,
+		Priority:        task.Priority,
+		Labels:          toTaskLabels(task.Labels),
+		AssigneeCount:   task.AssigneeCount,
+		ChatCount:       task.ChatCount,
+		CommentCount:    task.CommentCount,
+		ParentID:        task.ParentID,
+		Subtasks:        models.SubtaskProgress{Total: task.SubtaskCount, Done: task.SubtaskDone},
+		OpenBlockers:    task.OpenBlockers,
+		Blocked:         task.OpenBlockers > 0,
+		Overdue:         task.Overdue,
+		SeriesID:        task.SeriesID,
+		SourceMessageID: task.SourceMessageID,
+		EstimateMinutes: task.EstimateMinutes,
+		LoggedMinutes:   task.LoggedMinutes,
+		CreatedAt:       task.CreatedAt,
+		Version:         task.Version,
 	}
 }
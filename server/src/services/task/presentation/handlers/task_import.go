@@ -0,0 +1,507 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/diploma/shared/kafka"
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/repository"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Форматы импорта и экспорта задач
+const (
+	transferFormatCSV  = "csv"
+	transferFormatJSON = "json"
+)
+
+// Поля задачи в файле импорта и экспорта
+const (
+	importFieldTitle       = "title"
+	importFieldDescription = "description"
+	importFieldDate        = "date"
+	importFieldStatus      = "status"
+	importFieldAssignees   = "assignees"
+)
+
+// importFields — поля задачи в порядке колонок CSV при экспорте
+var importFields = []string{importFieldTitle, importFieldDescription, importFieldDate, importFieldStatus, importFieldAssignees}
+
+const (
+	// maxImportRows — максимальное количество задач в одном импорте
+	maxImportRows = 500
+	// maxImportBodyBytes — максимальный размер импортируемого файла
+	maxImportBodyBytes = 5 << 20
+)
+
+// importRow строка импорта: значения полей и ошибки разбора
+type importRow struct {
+	values map[string]string
+	errors []string
+}
+
+// ========== Import Operations ==========
+
+// ImportTasks godoc
+// @Summary Импорт задач
+// @Description Создает задачи рабочего пространства из CSV (с заголовком) или JSON-массива. Колонки сопоставляются с полями title, description, date, status и assignees по названию без учета регистра; другие названия колонок задаются параметрами columns[поле]=колонка. Каждая строка проверяется: название, дата YYYY-MM-DD, статус workflow (название или код) и логины исполнителей — участников РП. С dry_run=true возвращается только отчет; иначе задачи создаются в одной транзакции, если все строки корректны, и записываются в историю с источником import
+// @Tags tasks
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param format query string false "Формат: csv или json (по умолчанию по Content-Type)"
+// @Param dry_run query bool false "Только проверить строки, не создавая задачи"
+// @Success 200 {object} models.ImportTasksResponse "Отчет проверки (dry_run)"
+// @Success 201 {object} models.ImportTasksResponse
+// @Failure 400 {object} models.ImportTasksResponse "Есть некорректные строки"
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/import/{workspace_id} [post]
+func (h *TaskHandler) ImportTasks(c *gin.Context) {
+	userID, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid dry_run value"})
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = transferFormatJSON
+		if strings.Contains(c.ContentType(), "csv") {
+			format = transferFormatCSV
+		}
+	}
+	if format != transferFormatCSV && format != transferFormatJSON {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "unsupported format, expected csv or json"})
+		return
+	}
+
+	columns, err := importColumns(c.QueryMap("columns"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("failed to read import data, maximum size is %d MB", maxImportBodyBytes>>20)})
+		return
+	}
+
+	var rows []importRow
+	var ignored []string
+	if format == transferFormatCSV {
+		rows, ignored, err = parseImportCSV(body, columns)
+	} else {
+		rows, ignored, err = parseImportJSON(body, columns)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "no tasks to import"})
+		return
+	}
+	if len(rows) > maxImportRows {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("too many tasks, maximum is %d", maxImportRows)})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	workflow, err := h.repo.GetWorkflow(ctx, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workflow"})
+		return
+	}
+
+	// Логины исполнителей всех строк проверяются одним запросом
+	var logins []string
+	for _, row := range rows {
+		for _, login := range splitLogins(row.values[importFieldAssignees]) {
+			logins = append(logins, strings.ToLower(login))
+		}
+	}
+	users, err := h.repo.GetWorkspaceUsersByLogins(ctx, workspaceID, logins)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to validate assignees"})
+		return
+	}
+	members := make(map[string]int, len(users))
+	for _, user := range users {
+		members[strings.ToLower(user.Login)] = user.UserID
+	}
+
+	response := models.ImportTasksResponse{
+		WorkspaceID:    workspaceID,
+		DryRun:         dryRun,
+		Total:          len(rows),
+		IgnoredColumns: ignored,
+		Rows:           make([]models.ImportTaskRowResult, 0, len(rows)),
+	}
+	drafts := make([]dm.TaskDraft, 0, len(rows))
+	for i, row := range rows {
+		draft, errs := buildImportDraft(row, workflow, members)
+		draft.Task.Creator = userID
+		draft.Task.WorkspaceID = workspaceID
+
+		response.Rows = append(response.Rows, models.ImportTaskRowResult{
+			Row:    i + 1,
+			Title:  draft.Task.Title,
+			Errors: errs,
+		})
+		if len(errs) > 0 {
+			response.Invalid++
+			continue
+		}
+		response.Valid++
+		drafts = append(drafts, draft)
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	// Импорт применяется только целиком, чтобы его можно было повторить после исправления файла
+	if response.Invalid > 0 {
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	importCtx := repository.WithChangeSource(ctx, dm.ChangeSourceImport)
+	taskIDs, err := h.repo.CreateTaskDrafts(importCtx, drafts, "при импорте")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to import tasks"})
+		return
+	}
+
+	response.Applied = true
+	for i := range taskIDs {
+		taskID := taskIDs[i]
+		response.Rows[i].TaskID = &taskID
+		h.publishTaskChanged(ctx, kafka.TaskEventCreated, taskID, userID)
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ExportTasks godoc
+// @Summary Экспорт задач
+// @Description Выгружает задачи рабочего пространства в формате импорта: CSV с колонками title, description, date, status, assignees (логины через ;) или JSON-массив. Статус выгружается названием
+// @Tags tasks
+// @Produce json
+// @Produce text/csv
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param format query string false "Формат: csv или json (по умолчанию json)"
+// @Success 200 {array} models.TaskTransferRecord
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/export/{workspace_id} [get]
+func (h *TaskHandler) ExportTasks(c *gin.Context) {
+	_, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", transferFormatJSON)
+	if format != transferFormatCSV && format != transferFormatJSON {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "unsupported format, expected csv or json"})
+		return
+	}
+
+	tasks, err := h.repo.GetTasksForExport(c.Request.Context(), workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get tasks"})
+		return
+	}
+
+	records := make([]models.TaskTransferRecord, 0, len(tasks))
+	for i := range tasks {
+		records = append(records, models.TaskTransferRecord{
+			Title:       tasks[i].Title,
+			Description: tasks[i].Description,
+			Date:        tasks[i].Date.Format("2006-01-02"),
+			Status:      tasks[i].StatusName,
+			Assignees:   tasks[i].AssigneeLogins,
+		})
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="tasks-%d.%s"`, workspaceID, format))
+	if format == transferFormatJSON {
+		c.JSON(http.StatusOK, records)
+		return
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write(importFields)
+	for _, record := range records {
+		_ = writer.Write([]string{
+			escapeCSVCell(record.Title),
+			escapeCSVCell(stringOrEmpty(record.Description)),
+			record.Date,
+			escapeCSVCell(record.Status),
+			escapeCSVCell(strings.Join(record.Assignees, ";")),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to export tasks"})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// csvFormulaPrefixes — символы, с которых табличные редакторы начинают формулу
+const csvFormulaPrefixes = "=+-@"
+
+// escapeCSVCell защищает ячейку экспорта от выполнения как формулы в Excel и подобных редакторах:
+// значение, начинающееся с символа формулы, получает префикс '
+func escapeCSVCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCSVCell снимает префикс, добавленный escapeCSVCell, чтобы экспорт импортировался без изменений
+func unescapeCSVCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// importColumns возвращает названия колонок файла для каждого поля задачи.
+// По умолчанию колонка называется так же, как поле; mapping переопределяет названия
+func importColumns(mapping map[string]string) (map[string]string, error) {
+	columns := make(map[string]string, len(importFields))
+	for _, field := range importFields {
+		columns[field] = field
+	}
+	for field, column := range mapping {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("unknown import field: %s", field)
+		}
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			return nil, fmt.Errorf("empty column name for field %s", field)
+		}
+		columns[field] = column
+	}
+	return columns, nil
+}
+
+// parseImportCSV разбирает CSV с заголовком. Возвращает строки и колонки, не сопоставленные ни с одним полем
+func parseImportCSV(data []byte, columns map[string]string) ([]importRow, []string, error) {
+	// Excel добавляет BOM в начало CSV в UTF-8
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("CSV header is missing")
+	}
+
+	fields := make(map[string]string, len(columns))
+	for field, column := range columns {
+		fields[column] = field
+	}
+
+	indexes := make(map[string]int)
+	var ignored []string
+	for i, name := range records[0] {
+		field, ok := fields[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			ignored = append(ignored, name)
+			continue
+		}
+		indexes[field] = i
+	}
+	for _, field := range []string{importFieldTitle, importFieldDate} {
+		if _, ok := indexes[field]; !ok {
+			return nil, nil, fmt.Errorf("missing column %s for field %s", columns[field], field)
+		}
+	}
+
+	rows := make([]importRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := importRow{values: make(map[string]string, len(indexes))}
+		for field, i := range indexes {
+			if i < len(record) {
+				row.values[field] = unescapeCSVCell(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, ignored, nil
+}
+
+// parseImportJSON разбирает JSON-массив объектов. Возвращает строки и ключи, не сопоставленные ни с одним полем
+func parseImportJSON(data []byte, columns map[string]string) ([]importRow, []string, error) {
+	var records []map[string]interface{}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON, expected an array of tasks")
+	}
+
+	fields := make(map[string]string, len(columns))
+	for field, column := range columns {
+		fields[column] = field
+	}
+
+	ignoredKeys := make(map[string]bool)
+	rows := make([]importRow, 0, len(records))
+	for _, record := range records {
+		row := importRow{values: make(map[string]string, len(importFields))}
+		values := make(map[string]interface{}, len(record))
+		for key, value := range record {
+			name := strings.ToLower(strings.TrimSpace(key))
+			if _, ok := fields[name]; !ok {
+				ignoredKeys[key] = true
+				continue
+			}
+			values[name] = value
+		}
+
+		for _, field := range importFields {
+			value, ok := values[columns[field]]
+			if !ok {
+				continue
+			}
+			text, ok := importValue(value)
+			if !ok {
+				row.errors = append(row.errors, fmt.Sprintf("invalid value for field %s", field))
+				continue
+			}
+			row.values[field] = text
+		}
+		rows = append(rows, row)
+	}
+
+	ignored := make([]string, 0, len(ignoredKeys))
+	for key := range ignoredKeys {
+		ignored = append(ignored, key)
+	}
+	sort.Strings(ignored)
+
+	return rows, ignored, nil
+}
+
+// importValue приводит значение из JSON к строке. Список логинов объединяется через ;
+func importValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			text, ok := item.(string)
+			if !ok {
+				return "", false
+			}
+			items = append(items, text)
+		}
+		return strings.Join(items, ";"), true
+	default:
+		return "", false
+	}
+}
+
+// buildImportDraft проверяет строку импорта и строит по ней задачу. members — ID участников РП по логину
+func buildImportDraft(row importRow, workflow *dm.Workflow, members map[string]int) (dm.TaskDraft, []string) {
+	errs := row.errors
+	draft := dm.TaskDraft{Task: dm.Task{Priority: dm.TaskPriorityNormal}}
+
+	draft.Task.Title = strings.TrimSpace(row.values[importFieldTitle])
+	if length := utf8.RuneCountInString(draft.Task.Title); length < 3 || length > maxTaskTitleLength {
+		errs = append(errs, fmt.Sprintf("title must be 3-%d characters", maxTaskTitleLength))
+	}
+
+	if description := strings.TrimSpace(row.values[importFieldDescription]); description != "" {
+		draft.Task.Description = &description
+	}
+
+	date := strings.TrimSpace(row.values[importFieldDate])
+	if date == "" {
+		errs = append(errs, "date is required")
+	} else if parsed, err := parseDate(date); err != nil {
+		errs = append(errs, "invalid date format, expected YYYY-MM-DD")
+	} else {
+		draft.Task.Date = parsed
+	}
+
+	if status, ok := importStatus(workflow, strings.TrimSpace(row.values[importFieldStatus])); ok {
+		draft.Task.Status = status.Code
+	} else {
+		errs = append(errs, fmt.Sprintf("unknown status: %s", strings.TrimSpace(row.values[importFieldStatus])))
+	}
+
+	for _, login := range splitLogins(row.values[importFieldAssignees]) {
+		userID, ok := members[strings.ToLower(login)]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("user %s is not a member of workspace", login))
+			continue
+		}
+		draft.AssigneeIDs = append(draft.AssigneeIDs, userID)
+	}
+	draft.AssigneeIDs = uniqueIDs(draft.AssigneeIDs)
+
+	return draft, errs
+}
+
+// importStatus находит статус workflow по названию без учета регистра или по коду.
+// Пустое значение означает начальный статус
+func importStatus(workflow *dm.Workflow, value string) (*dm.TaskStatus, bool) {
+	if value == "" {
+		initial := workflow.InitialStatus()
+		return initial, initial != nil
+	}
+	for i := range workflow.Statuses {
+		if strings.EqualFold(workflow.Statuses[i].Name, value) {
+			return &workflow.Statuses[i], true
+		}
+	}
+	if code, err := strconv.Atoi(value); err == nil {
+		return workflow.Status(code)
+	}
+	return nil, false
+}
+
+// splitLogins разбивает список логинов, разделенных ;, запятыми или пробелами
+func splitLogins(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == ',' || unicode.IsSpace(r)
+	})
+}
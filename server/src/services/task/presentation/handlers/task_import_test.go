@@ -0,0 +1,28 @@
+package handlers
+
+import "testing"
+
+func TestEscapeCSVCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: `=HYPERLINK("http://evil.example","click")`, want: `'=HYPERLINK("http://evil.example","click")`},
+		{value: "+1", want: "'+1"},
+		{value: "-2+3", want: "'-2+3"},
+		{value: "@SUM(A1)", want: "'@SUM(A1)"},
+		{value: "Обычная задача", want: "Обычная задача"},
+		{value: "'quoted", want: "'quoted"},
+		{value: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		got := escapeCSVCell(tt.value)
+		if got != tt.want {
+			t.Errorf("escapeCSVCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if back := unescapeCSVCell(got); back != tt.value {
+			t.Errorf("unescapeCSVCell(%q) = %q, want %q", got, back, tt.value)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/diploma/shared/kafka"
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ========== Task Label Operations ==========
+
+// AddTaskLabels godoc
+// @Summary Добавить метки задаче
+// @Description Добавляет задаче метки ее рабочего пространства. Уже добавленные метки пропускаются
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param request body models.AddTaskLabelsRequest true "ID меток"
+// @Success 200 {object} models.TaskResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/labels [post]
+func (h *TaskHandler) AddTaskLabels(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	var req models.AddTaskLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Все метки проверяются до изменения задачи
+	ok, err := h.workspaceHasLabels(ctx, task.WorkspaceID, req.LabelIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to validate labels"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "label not found in workspace"})
+		return
+	}
+
+	for _, labelID := range req.LabelIDs {
+		if err := h.repo.AddTaskLabel(ctx, task.ID, labelID, userID); err != nil && err.Error() != "label already added" {
+			if err.Error() == "label not found" {
+				c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "label not found in workspace"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to add task label"})
+			return
+		}
+	}
+
+	h.publishTaskChanged(ctx, kafka.TaskEventUpdated, task.ID, userID)
+
+	h.respondWithTask(c, task.ID, userID)
+}
+
+// RemoveTaskLabel godoc
+// @Summary Удалить метку задачи
+// @Description Удаляет метку у задачи
+// @Tags labels
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param label_id path int true "ID метки"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/labels/{label_id} [delete]
+func (h *TaskHandler) RemoveTaskLabel(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	labelID, err := strconv.Atoi(c.Param("label_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid label id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.repo.RemoveTaskLabel(ctx, task.ID, labelID, userID); err != nil {
+		if err.Error() == "task label not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "task label not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to remove task label"})
+		return
+	}
+
+	h.publishTaskChanged(ctx, kafka.TaskEventUpdated, task.ID, userID)
+
+	c.Status(http.StatusNoContent)
+}
+
+// workspaceHasLabels проверяет, что все метки labelIDs принадлежат рабочему пространству
+func (h *TaskHandler) workspaceHasLabels(ctx context.Context, workspaceID int, labelIDs []int) (bool, error) {
+	labels, err := h.repo.GetLabels(ctx, workspaceID)
+	if err != nil {
+		return false, err
+	}
+
+	known := make(map[int]bool, len(labels))
+	for _, label := range labels {
+		known[label.ID] = true
+	}
+	for _, labelID := range labelIDs {
+		if !known[labelID] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// toTaskLabels преобразует метки задачи в ответ API
+func toTaskLabels(labels []dm.Label) []models.TaskLabel {
+	result := make([]models.TaskLabel, 0, len(labels))
+	for _, label := range labels {
+		result = append(result, models.TaskLabel{
+			ID:    label.ID,
+			Name:  label.Name,
+			Color: label.Color,
+		})
+	}
+	return result
+}
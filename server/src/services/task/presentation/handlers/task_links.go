@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ========== Subtask Operations ==========
+
+// GetSubtasks godoc
+// @Summary Получить подзадачи
+// @Description Возвращает подзадачи задачи и сводку их выполнения
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} models.SubtasksResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/subtasks [get]
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	subtasks, err := h.repo.GetSubtasks(c.Request.Context(), task.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get subtasks"})
+		return
+	}
+
+	response := models.SubtasksResponse{
+		Subtasks: []models.TaskResponse{},
+		Progress: models.SubtaskProgress{Total: task.SubtaskCount, Done: task.SubtaskDone},
+	}
+	for i := range subtasks {
+		response.Subtasks = append(response.Subtasks, h.convertToTaskResponse(&subtasks[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetTaskParent godoc
+// @Summary Назначить родительскую задачу
+// @Description Делает задачу подзадачей другой задачи того же рабочего пространства (только создатель задачи). Родитель не может быть подзадачей самой задачи
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param request body models.SetTaskParentRequest true "ID родительской задачи"
+// @Success 200 {object} models.TaskResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/parent [put]
+func (h *TaskHandler) SetTaskParent(c *gin.Context) {
+	userID, task, ok := h.loadOwnTask(c, "only task creator can change parent task")
+	if !ok {
+		return
+	}
+
+	var req models.SetTaskParentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	if err := h.repo.SetTaskParent(c.Request.Context(), task.ID, &req.ParentID, userID); err != nil {
+		respondTaskLinkError(c, err, "failed to set parent task")
+		return
+	}
+
+	h.respondWithTask(c, task.ID, userID)
+}
+
+// RemoveTaskParent godoc
+// @Summary Убрать родительскую задачу
+// @Description Делает подзадачу самостоятельной задачей (только создатель задачи)
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} models.TaskResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/parent [delete]
+func (h *TaskHandler) RemoveTaskParent(c *gin.Context) {
+	userID, task, ok := h.loadOwnTask(c, "only task creator can change parent task")
+	if !ok {
+		return
+	}
+
+	if task.ParentID == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "task has no parent task"})
+		return
+	}
+
+	if err := h.repo.SetTaskParent(c.Request.Context(), task.ID, nil, userID); err != nil {
+		respondTaskLinkError(c, err, "failed to remove parent task")
+		return
+	}
+
+	h.respondWithTask(c, task.ID, userID)
+}
+
+// ========== Dependency Operations ==========
+
+// GetTaskDependencies godoc
+// @Summary Получить зависимости задачи
+// @Description Возвращает задачи, блокирующие задачу (blocked_by), и задачи, которые она блокирует (blocks)
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} models.TaskDependenciesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/dependencies [get]
+func (h *TaskHandler) GetTaskDependencies(c *gin.Context) {
+	_, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	h.respondWithDependencies(c, task.ID, http.StatusOK)
+}
+
+// AddTaskDependency godoc
+// @Summary Добавить блокирующую задачу
+// @Description Отмечает, что задача заблокирована другой задачей того же рабочего пространства (только создатель задачи). Зависимости не могут образовывать цикл
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param request body models.AddTaskDependencyRequest true "ID блокирующей задачи"
+// @Success 201 {object} models.TaskDependenciesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/dependencies [post]
+func (h *TaskHandler) AddTaskDependency(c *gin.Context) {
+	userID, task, ok := h.loadOwnTask(c, "only task creator can manage dependencies")
+	if !ok {
+		return
+	}
+
+	var req models.AddTaskDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	if err := h.repo.AddTaskDependency(c.Request.Context(), task.ID, req.BlockedBy, userID); err != nil {
+		respondTaskLinkError(c, err, "failed to add task dependency")
+		return
+	}
+
+	h.respondWithDependencies(c, task.ID, http.StatusCreated)
+}
+
+// RemoveTaskDependency godoc
+// @Summary Удалить блокирующую задачу
+// @Description Удаляет зависимость задачи от блокирующей задачи (только создатель задачи)
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param blocker_id path int true "ID блокирующей задачи"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/dependencies/{blocker_id} [delete]
+func (h *TaskHandler) RemoveTaskDependency(c *gin.Context) {
+	userID, task, ok := h.loadOwnTask(c, "only task creator can manage dependencies")
+	if !ok {
+		return
+	}
+
+	blockerID, err := strconv.Atoi(c.Param("blocker_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid blocker id"})
+		return
+	}
+
+	if err := h.repo.RemoveTaskDependency(c.Request.Context(), task.ID, blockerID, userID); err != nil {
+		respondTaskLinkError(c, err, "failed to remove task dependency")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ========== Helper Methods ==========
+
+// loadOwnTask получает задачу из параметра id и проверяет, что пользователь — ее создатель
+func (h *TaskHandler) loadOwnTask(c *gin.Context, forbidden string) (int, *dm.TaskWithDetails, bool) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return 0, nil, false
+	}
+
+	if task.Creator != userID {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: forbidden})
+		return 0, nil, false
+	}
+
+	return userID, task, true
+}
+
+// respondWithTask возвращает актуальную информацию о задаче
+func (h *TaskHandler) respondWithTask(c *gin.Context, taskID, userID int) {
+	task, err := h.repo.GetTaskByID(c.Request.Context(), taskID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.convertToTaskResponse(task))
+}
+
+// respondWithDependencies возвращает зависимости задачи
+func (h *TaskHandler) respondWithDependencies(c *gin.Context, taskID, status int) {
+	blockedBy, blocks, err := h.repo.GetTaskDependencies(c.Request.Context(), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task dependencies"})
+		return
+	}
+
+	response := models.TaskDependenciesResponse{
+		BlockedBy: toTaskLinkResponses(blockedBy),
+		Blocks:    toTaskLinkResponses(blocks),
+	}
+	for i := range blockedBy {
+		if !blockedBy[i].IsResolved() {
+			response.Blocked = true
+			break
+		}
+	}
+
+	c.JSON(status, response)
+}
+
+// respondTaskLinkError преобразует ошибку изменения связей задач в ответ API
+func respondTaskLinkError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "task not found", "parent task not found", "blocking task not found", "dependency not found":
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+	case "tasks belong to different workspaces":
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+	case "task link cycle":
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "link would create a cycle between tasks"})
+	case "dependency already exists":
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: fallback})
+	}
+}
+
+// toTaskLinkResponses преобразует связанные задачи в ответ API
+func toTaskLinkResponses(links []dm.TaskLink) []models.TaskLinkResponse {
+	responses := make([]models.TaskLinkResponse, 0, len(links))
+	for i := range links {
+		responses = append(responses, models.TaskLinkResponse{
+			ID:         links[i].ID,
+			Title:      links[i].Title,
+			Status:     links[i].Status,
+			StatusName: links[i].StatusName,
+			Resolved:   links[i].IsResolved(),
+			LinkedAt:   links[i].LinkedAt,
+		})
+	}
+	return responses
+}
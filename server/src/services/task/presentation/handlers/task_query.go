@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTaskPageSize = 50
+	maxTaskPageSize     = 100
+	maxTaskSearchLength = 100
+)
+
+// parseTaskFilter разбирает параметры фильтрации, сортировки и пагинации списка задач
+func parseTaskFilter(c *gin.Context, workspaceID, userID int) (dm.TaskFilter, error) {
+	filter := dm.TaskFilter{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		SortField:   dm.TaskSortDate,
+		SortDesc:    true,
+		Limit:       defaultTaskPageSize,
+	}
+
+	if statusStr := c.Query("status"); statusStr != "" {
+		for _, part := range strings.Split(statusStr, ",") {
+			status, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || status <= 0 {
+				return filter, fmt.Errorf("invalid status")
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if priorityStr := c.Query("priority"); priorityStr != "" {
+		for _, part := range strings.Split(priorityStr, ",") {
+			priority := strings.TrimSpace(part)
+			if !isValidPriority(priority) {
+				return filter, fmt.Errorf("invalid priority, expected low, normal, high or critical")
+			}
+			filter.Priorities = append(filter.Priorities, priority)
+		}
+	}
+
+	if labelStr := c.Query("label_id"); labelStr != "" {
+		for _, part := range strings.Split(labelStr, ",") {
+			labelID, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || labelID <= 0 {
+				return filter, fmt.Errorf("invalid label_id")
+			}
+			filter.LabelIDs = append(filter.LabelIDs, labelID)
+		}
+	}
+
+	var err error
+	if filter.AssigneeID, err = parseOptionalID(c, "assignee_id"); err != nil {
+		return filter, err
+	}
+	if filter.CreatorID, err = parseOptionalID(c, "creator_id"); err != nil {
+		return filter, err
+	}
+	if filter.ChatID, err = parseOptionalID(c, "chat_id"); err != nil {
+		return filter, err
+	}
+
+	if overdueStr := c.Query("overdue"); overdueStr != "" {
+		overdue, err := strconv.ParseBool(overdueStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid overdue, expected true or false")
+		}
+		filter.Overdue = &overdue
+	}
+
+	if dueFrom := c.Query("due_from"); dueFrom != "" {
+		parsed, err := parseDate(dueFrom)
+		if err != nil {
+			return filter, fmt.Errorf("invalid due_from, expected YYYY-MM-DD")
+		}
+		filter.DueFrom = &parsed
+	}
+	if dueTo := c.Query("due_to"); dueTo != "" {
+		parsed, err := parseDate(dueTo)
+		if err != nil {
+			return filter, fmt.Errorf("invalid due_to, expected YYYY-MM-DD")
+		}
+		filter.DueTo = &parsed
+	}
+
+	filter.Search = strings.TrimSpace(c.Query("q"))
+	if len([]rune(filter.Search)) > maxTaskSearchLength {
+		return filter, fmt.Errorf("search query is too long")
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		if filter.SortField, filter.SortDesc, err = parseTaskSort(sort); err != nil {
+			return filter, err
+		}
+	}
+
+	if err := parseTaskPage(c, &filter); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// parseTaskSort разбирает сортировку списка задач вида date или -date
+func parseTaskSort(sort string) (string, bool, error) {
+	field := strings.TrimPrefix(sort, "-")
+	switch field {
+	case dm.TaskSortDate, dm.TaskSortTitle, dm.TaskSortStatus, dm.TaskSortCreated:
+	default:
+		return "", false, fmt.Errorf("invalid sort, expected date, title, status or created")
+	}
+	return field, strings.HasPrefix(sort, "-"), nil
+}
+
+// parseTaskPage разбирает размер страницы и курсор списка задач.
+// Поле сортировки фильтра должно быть уже задано, так как курсор к нему привязан
+func parseTaskPage(c *gin.Context, filter *dm.TaskFilter) error {
+	if limitStr := c.Query("limit"); limitStr != "" {
+		v, err := strconv.Atoi(limitStr)
+		if err != nil || v <= 0 || v > maxTaskPageSize {
+			return fmt.Errorf("invalid limit, expected a number from 1 to %d", maxTaskPageSize)
+		}
+		filter.Limit = v
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		decoded, err := decodeTaskCursor(cursor, filter.SortField)
+		if err != nil {
+			return fmt.Errorf("invalid cursor")
+		}
+		filter.Cursor = decoded
+	}
+
+	return nil
+}
+
+// isValidPriority проверяет, что приоритет — одно из значений TaskPriority*
+func isValidPriority(priority string) bool {
+	switch priority {
+	case dm.TaskPriorityLow, dm.TaskPriorityNormal, dm.TaskPriorityHigh, dm.TaskPriorityCritical:
+		return true
+	}
+	return false
+}
+
+// parseOptionalID разбирает необязательный положительный ID из query-параметра
+func parseOptionalID(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	return &id, nil
+}
+
+// encodeTaskCursor формирует курсор следующей страницы по последней задаче страницы
+func encodeTaskCursor(task *dm.TaskWithDetails, sortField string) string {
+	cursor := dm.TaskCursor{ID: task.ID}
+	switch sortField {
+	case dm.TaskSortDate:
+		cursor.Value = task.Date.Format("2006-01-02")
+	case dm.TaskSortTitle:
+		cursor.Value = task.Title
+	case dm.TaskSortStatus:
+		cursor.Value = strconv.Itoa(task.Status)
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeTaskCursor разбирает курсор, полученный от клиента, и проверяет,
+// что он соответствует текущему полю сортировки
+func decodeTaskCursor(value, sortField string) (*dm.TaskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor dm.TaskCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.ID <= 0 {
+		return nil, fmt.Errorf("invalid cursor id")
+	}
+
+	switch sortField {
+	case dm.TaskSortDate:
+		if _, err := parseDate(cursor.Value); err != nil {
+			return nil, err
+		}
+	case dm.TaskSortStatus:
+		if _, err := strconv.Atoi(cursor.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/recurrence"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ========== Series Operations ==========
+
+// SetTaskRecurrence godoc
+// @Summary Сделать задачу повторяющейся
+// @Description Создает серию повторяющихся задач, первой задачей которой становится задача (только создатель задачи). Следующая задача серии создается после завершения текущей (mode=on_complete) или когда наступает ее срок (mode=schedule) и получает исполнителей и чаты текущей задачи
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param request body models.RecurrenceRequest true "Правило повторения"
+// @Success 201 {object} models.TaskSeriesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/recurrence [post]
+func (h *TaskHandler) SetTaskRecurrence(c *gin.Context) {
+	userID, task, ok := h.loadOwnTask(c, "only task creator can make it recurring")
+	if !ok {
+		return
+	}
+
+	var req models.RecurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	rule, mode, err := buildRecurrenceRule(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	series, err := h.repo.CreateTaskSeries(c.Request.Context(), task.ID, rule, mode, userID)
+	if err != nil {
+		if err.Error() == "task already belongs to a series" {
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to create task series"})
+		return
+	}
+
+	h.respondWithSeries(c, series, userID, http.StatusCreated)
+}
+
+// GetTaskSeries godoc
+// @Summary Получить серию повторяющихся задач
+// @Description Возвращает правило повторения серии и ее задачи
+// @Tags series
+// @Produce json
+// @Param series_id path int true "ID серии"
+// @Success 200 {object} models.TaskSeriesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/series/{series_id} [get]
+func (h *TaskHandler) GetTaskSeries(c *gin.Context) {
+	userID, series, ok := h.loadSeries(c, false)
+	if !ok {
+		return
+	}
+
+	h.respondWithSeries(c, series, userID, http.StatusOK)
+}
+
+// UpdateTaskSeries godoc
+// @Summary Изменить серию повторяющихся задач
+// @Description Изменяет правило повторения серии. Переданные title и description применяются ко всем незавершенным задачам серии и к следующим задачам (создатель серии или руководитель РП)
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param series_id path int true "ID серии"
+// @Param request body models.UpdateTaskSeriesRequest true "Изменения серии"
+// @Success 200 {object} models.TaskSeriesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/series/{series_id} [put]
+func (h *TaskHandler) UpdateTaskSeries(c *gin.Context) {
+	userID, series, ok := h.loadSeries(c, true)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTaskSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	var rule, mode *string
+	if req.Recurrence != nil {
+		builtRule, builtMode, err := buildRecurrenceRule(req.Recurrence)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		rule, mode = &builtRule, &builtMode
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.repo.UpdateTaskSeries(ctx, series.ID, userID, rule, mode, req.Title, req.Description); err != nil {
+		if err.Error() == "series not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "series not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update task series"})
+		return
+	}
+
+	updated, err := h.repo.GetTaskSeries(ctx, series.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task series"})
+		return
+	}
+
+	h.respondWithSeries(c, updated, userID, http.StatusOK)
+}
+
+// CancelTaskSeries godoc
+// @Summary Отменить серию повторяющихся задач
+// @Description Останавливает создание задач серии. С параметром delete_open=true также удаляет незавершенные задачи серии (создатель серии или руководитель РП)
+// @Tags series
+// @Produce json
+// @Param series_id path int true "ID серии"
+// @Param delete_open query bool false "Удалить незавершенные задачи серии"
+// @Success 200 {object} models.CancelTaskSeriesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/series/{series_id} [delete]
+func (h *TaskHandler) CancelTaskSeries(c *gin.Context) {
+	_, series, ok := h.loadSeries(c, true)
+	if !ok {
+		return
+	}
+
+	deleteOpen := false
+	if value := c.Query("delete_open"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid delete_open, expected true or false"})
+			return
+		}
+		deleteOpen = parsed
+	}
+
+	deleted, err := h.repo.CancelTaskSeries(c.Request.Context(), series.ID, deleteOpen)
+	if err != nil {
+		if err.Error() == "series not found or already cancelled" {
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "series is already cancelled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to cancel task series"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CancelTaskSeriesResponse{
+		Message:      "task series cancelled successfully",
+		DeletedTasks: deleted,
+	})
+}
+
+// loadSeries получает серию из параметра series_id и проверяет доступ к ней.
+// manage требует, чтобы пользователь был создателем серии или руководителем РП
+func (h *TaskHandler) loadSeries(c *gin.Context, manage bool) (int, *dm.TaskSeries, bool) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return 0, nil, false
+	}
+
+	seriesID, err := strconv.Atoi(c.Param("series_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid series id"})
+		return 0, nil, false
+	}
+
+	ctx := c.Request.Context()
+
+	series, err := h.repo.GetTaskSeries(ctx, seriesID)
+	if err != nil {
+		if err.Error() == "series not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "series not found"})
+			return 0, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task series"})
+		return 0, nil, false
+	}
+
+	role, err := h.repo.GetUserRoleInWorkspace(ctx, userID, series.WorkspaceID)
+	if err != nil {
+		if err.Error() == "user is not a member of workspace" {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
+			return 0, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to check user role"})
+		return 0, nil, false
+	}
+
+	if manage && role != 2 && (series.Creator == nil || *series.Creator != userID) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "only series creator or workspace leader can manage the series"})
+		return 0, nil, false
+	}
+
+	return userID, series, true
+}
+
+// respondWithSeries возвращает серию вместе с ее задачами
+func (h *TaskHandler) respondWithSeries(c *gin.Context, series *dm.TaskSeries, userID, status int) {
+	tasks, err := h.repo.GetSeriesTasks(c.Request.Context(), series.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get series tasks"})
+		return
+	}
+
+	response := models.TaskSeriesResponse{
+		ID:          series.ID,
+		WorkspaceID: series.WorkspaceID,
+		Creator:     series.Creator,
+		Rule:        series.Rule,
+		Mode:        series.Mode,
+		StartDate:   series.StartDate,
+		Occurrences: series.Occurrences,
+		Active:      series.Active,
+		CreatedAt:   series.CreatedAt,
+		UpdatedAt:   series.UpdatedAt,
+		CancelledAt: series.CancelledAt,
+		Tasks:       []models.TaskResponse{},
+	}
+	for i := range tasks {
+		response.Tasks = append(response.Tasks, h.convertToTaskResponse(&tasks[i]))
+	}
+
+	c.JSON(status, response)
+}
+
+// buildRecurrenceRule проверяет запрос и возвращает правило повторения в формате RRULE и режим серии
+func buildRecurrenceRule(req *models.RecurrenceRequest) (string, string, error) {
+	mode := req.Mode
+	switch mode {
+	case "":
+		mode = dm.SeriesModeOnComplete
+	case dm.SeriesModeOnComplete, dm.SeriesModeSchedule:
+	default:
+		return "", "", fmt.Errorf("invalid mode, expected on_complete or schedule")
+	}
+
+	if req.RRule != "" {
+		if req.Frequency != "" || req.Interval != 0 || len(req.Weekdays) > 0 || req.MonthDay != 0 || req.Count != 0 || req.Until != nil {
+			return "", "", fmt.Errorf("use either rrule or frequency fields, not both")
+		}
+		rule, err := recurrence.Parse(req.RRule)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid rrule: %v", err)
+		}
+		return rule.String(), mode, nil
+	}
+
+	rule := &recurrence.Rule{
+		Frequency: strings.ToUpper(req.Frequency),
+		Interval:  req.Interval,
+		MonthDay:  req.MonthDay,
+		Count:     req.Count,
+	}
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+	for _, code := range req.Weekdays {
+		weekday, ok := recurrence.ParseWeekday(code)
+		if !ok {
+			return "", "", fmt.Errorf("invalid weekday %q, expected MO, TU, WE, TH, FR, SA or SU", code)
+		}
+		rule.Weekdays = append(rule.Weekdays, weekday)
+	}
+	if req.Until != nil {
+		until, err := parseDate(*req.Until)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid until, expected YYYY-MM-DD")
+		}
+		rule.Until = &until
+	}
+
+	if err := rule.Validate(); err != nil {
+		return "", "", err
+	}
+	return rule.String(), mode, nil
+}
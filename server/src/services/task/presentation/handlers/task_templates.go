@@ -0,0 +1,550 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/diploma/shared/kafka"
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// templateVariablePattern — переменная шаблона вида {{name}} (пробелы внутри скобок допускаются)
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// ========== Template Operations ==========
+
+// GetTaskTemplates godoc
+// @Summary Получить шаблоны задач
+// @Description Возвращает шаблоны задач рабочего пространства без подзадач
+// @Tags templates
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Success 200 {object} models.TaskTemplateListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/templates/{workspace_id} [get]
+func (h *TaskHandler) GetTaskTemplates(c *gin.Context) {
+	_, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	templates, err := h.repo.GetTaskTemplates(c.Request.Context(), workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task templates"})
+		return
+	}
+
+	response := models.TaskTemplateListResponse{Templates: []models.TaskTemplateResponse{}}
+	for i := range templates {
+		response.Templates = append(response.Templates, toTaskTemplateResponse(&templates[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateTaskTemplate godoc
+// @Summary Создать шаблон задачи
+// @Description Создает шаблон задачи рабочего пространства с исполнителями, метками и подзадачами по умолчанию (только руководитель РП). Название и описание могут содержать переменные {{name}}
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param request body models.TaskTemplateRequest true "Шаблон задачи"
+// @Success 201 {object} models.TaskTemplateResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/templates/{workspace_id} [post]
+func (h *TaskHandler) CreateTaskTemplate(c *gin.Context) {
+	workspaceID, ok := authorizeWorkspaceLeader(c, h.repo, "only workspace leader can manage templates")
+	if !ok {
+		return
+	}
+
+	template, ok := h.bindTaskTemplate(c, workspaceID)
+	if !ok {
+		return
+	}
+
+	userID, _ := getUserID(c)
+	template.CreatedBy = &userID
+
+	created, err := h.repo.CreateTaskTemplate(c.Request.Context(), template)
+	if err != nil {
+		if err.Error() == "template already exists" {
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "template with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to create task template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTaskTemplateResponse(created))
+}
+
+// GetTaskTemplate godoc
+// @Summary Получить шаблон задачи
+// @Description Возвращает шаблон задачи с подзадачами и списком переменных
+// @Tags templates
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param template_id path int true "ID шаблона"
+// @Success 200 {object} models.TaskTemplateResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/templates/{workspace_id}/{template_id} [get]
+func (h *TaskHandler) GetTaskTemplate(c *gin.Context) {
+	_, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	template, ok := h.loadTaskTemplate(c, workspaceID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, toTaskTemplateResponse(template))
+}
+
+// UpdateTaskTemplate godoc
+// @Summary Изменить шаблон задачи
+// @Description Заменяет содержимое шаблона задачи, включая подзадачи (только руководитель РП). Созданные по шаблону задачи не меняются
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param template_id path int true "ID шаблона"
+// @Param request body models.TaskTemplateRequest true "Шаблон задачи"
+// @Success 200 {object} models.TaskTemplateResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/templates/{workspace_id}/{template_id} [put]
+func (h *TaskHandler) UpdateTaskTemplate(c *gin.Context) {
+	workspaceID, ok := authorizeWorkspaceLeader(c, h.repo, "only workspace leader can manage templates")
+	if !ok {
+		return
+	}
+
+	templateID, err := strconv.Atoi(c.Param("template_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid template id"})
+		return
+	}
+
+	template, ok := h.bindTaskTemplate(c, workspaceID)
+	if !ok {
+		return
+	}
+	template.ID = templateID
+
+	updated, err := h.repo.UpdateTaskTemplate(c.Request.Context(), template)
+	if err != nil {
+		switch err.Error() {
+		case "template not found":
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "template not found"})
+		case "template already exists":
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "template with this name already exists"})
+		default:
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update task template"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toTaskTemplateResponse(updated))
+}
+
+// DeleteTaskTemplate godoc
+// @Summary Удалить шаблон задачи
+// @Description Удаляет шаблон задачи рабочего пространства (только руководитель РП). Созданные по шаблону задачи сохраняются
+// @Tags templates
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param template_id path int true "ID шаблона"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/templates/{workspace_id}/{template_id} [delete]
+func (h *TaskHandler) DeleteTaskTemplate(c *gin.Context) {
+	workspaceID, ok := authorizeWorkspaceLeader(c, h.repo, "only workspace leader can manage templates")
+	if !ok {
+		return
+	}
+
+	templateID, err := strconv.Atoi(c.Param("template_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid template id"})
+		return
+	}
+
+	if err := h.repo.DeleteTaskTemplate(c.Request.Context(), workspaceID, templateID); err != nil {
+		if err.Error() == "template not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to delete task template"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// InstantiateTaskTemplate godoc
+// @Summary Создать задачу по шаблону
+// @Description Создает задачу с подзадачами по шаблону одной операцией: переменные {{name}} заменяются значениями из variables, срок подзадачи — срок задачи со смещением due_offset_days. Создателем задач становится текущий пользователь, исполнители вне РП и удаленные метки пропускаются
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param template_id path int true "ID шаблона"
+// @Param request body models.InstantiateTemplateRequest true "Срок и значения переменных"
+// @Success 201 {object} models.InstantiateTemplateResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/templates/{workspace_id}/{template_id}/instantiate [post]
+func (h *TaskHandler) InstantiateTaskTemplate(c *gin.Context) {
+	userID, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	var req models.InstantiateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	date, err := parseDate(req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid date format, expected YYYY-MM-DD"})
+		return
+	}
+
+	template, ok := h.loadTaskTemplate(c, workspaceID)
+	if !ok {
+		return
+	}
+
+	// Все переменные шаблона должны получить значения
+	for _, name := range templateVariables(template) {
+		if strings.TrimSpace(req.Variables[name]) == "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("missing value for template variable %s", name)})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+
+	if req.ChatID != nil {
+		if err := h.repo.ValidateChatOwnership(ctx, *req.ChatID, workspaceID); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "chat not found in task workspace"})
+			return
+		}
+	}
+
+	workflow, err := h.repo.GetWorkflow(ctx, workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workflow"})
+		return
+	}
+	initial := workflow.InitialStatus()
+	if initial == nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "workflow has no statuses"})
+		return
+	}
+
+	root := dm.TaskDraft{
+		Task: dm.Task{
+			Creator:         userID,
+			WorkspaceID:     workspaceID,
+			Title:           renderTemplateText(template.Title, req.Variables),
+			Description:     renderTemplateDescription(template.Description, req.Variables),
+			Date:            date,
+			Status:          initial.Code,
+			Priority:        template.Priority,
+			EstimateMinutes: template.EstimateMinutes,
+		},
+		AssigneeIDs: template.AssigneeIDs,
+		LabelIDs:    template.LabelIDs,
+	}
+	subtasks := make([]dm.TaskDraft, 0, len(template.Subtasks))
+	for _, subtask := range template.Subtasks {
+		subtasks = append(subtasks, dm.TaskDraft{
+			Task: dm.Task{
+				Creator:         userID,
+				WorkspaceID:     workspaceID,
+				Title:           renderTemplateText(subtask.Title, req.Variables),
+				Description:     renderTemplateDescription(subtask.Description, req.Variables),
+				Date:            date.AddDate(0, 0, subtask.DueOffsetDays),
+				Status:          initial.Code,
+				Priority:        subtask.Priority,
+				EstimateMinutes: subtask.EstimateMinutes,
+			},
+			AssigneeIDs: subtask.AssigneeIDs,
+			LabelIDs:    subtask.LabelIDs,
+		})
+	}
+
+	// Названия проверяются после подстановки переменных
+	for _, draft := range append([]dm.TaskDraft{root}, subtasks...) {
+		length := utf8.RuneCountInString(draft.Task.Title)
+		if length < 3 || length > maxTaskTitleLength {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("task title must be 3-%d characters after substitution: %s", maxTaskTitleLength, draft.Task.Title)})
+			return
+		}
+	}
+
+	note := fmt.Sprintf("по шаблону «%s»", template.Name)
+	taskIDs, err := h.repo.CreateTaskTree(ctx, root, subtasks, req.ChatID, note)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to create tasks from template"})
+		return
+	}
+
+	response := models.InstantiateTemplateResponse{Subtasks: []models.TaskResponse{}}
+	for i, taskID := range taskIDs {
+		h.publishTaskChanged(ctx, kafka.TaskEventCreated, taskID, userID)
+
+		task, err := h.repo.GetTaskByID(ctx, taskID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get created task"})
+			return
+		}
+		if i == 0 {
+			response.Task = h.convertToTaskResponse(task)
+		} else {
+			response.Subtasks = append(response.Subtasks, h.convertToTaskResponse(task))
+		}
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// authorizeWorkspaceMember проверяет, что пользователь — участник РП из параметра workspace_id,
+// и возвращает ID пользователя и РП
+func (h *TaskHandler) authorizeWorkspaceMember(c *gin.Context) (int, int, bool) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return 0, 0, false
+	}
+
+	workspaceID, err := strconv.Atoi(c.Param("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid workspace id"})
+		return 0, 0, false
+	}
+
+	if err := h.repo.ValidateUserInWorkspace(c.Request.Context(), userID, workspaceID); err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
+		return 0, 0, false
+	}
+
+	return userID, workspaceID, true
+}
+
+// loadTaskTemplate получает шаблон из параметра template_id
+func (h *TaskHandler) loadTaskTemplate(c *gin.Context, workspaceID int) (*dm.TaskTemplate, bool) {
+	templateID, err := strconv.Atoi(c.Param("template_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid template id"})
+		return nil, false
+	}
+
+	template, err := h.repo.GetTaskTemplate(c.Request.Context(), workspaceID, templateID)
+	if err != nil {
+		if err.Error() == "template not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "template not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task template"})
+		return nil, false
+	}
+
+	return template, true
+}
+
+// bindTaskTemplate читает шаблон из запроса и проверяет, что исполнители и метки относятся к РП
+func (h *TaskHandler) bindTaskTemplate(c *gin.Context, workspaceID int) (*dm.TaskTemplate, bool) {
+	var req models.TaskTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return nil, false
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "template name is required"})
+		return nil, false
+	}
+
+	template := &dm.TaskTemplate{
+		WorkspaceID:     workspaceID,
+		Name:            name,
+		Title:           strings.TrimSpace(req.Title),
+		Description:     req.Description,
+		Priority:        defaultPriority(req.Priority),
+		EstimateMinutes: req.EstimateMinutes,
+		AssigneeIDs:     uniqueIDs(req.AssigneeIDs),
+		LabelIDs:        uniqueIDs(req.LabelIDs),
+		Subtasks:        make([]dm.TaskTemplateSubtask, 0, len(req.Subtasks)),
+	}
+	assigneeIDs := append([]int{}, template.AssigneeIDs...)
+	labelIDs := append([]int{}, template.LabelIDs...)
+	for _, subtask := range req.Subtasks {
+		item := dm.TaskTemplateSubtask{
+			Title:           strings.TrimSpace(subtask.Title),
+			Description:     subtask.Description,
+			Priority:        defaultPriority(subtask.Priority),
+			EstimateMinutes: subtask.EstimateMinutes,
+			DueOffsetDays:   subtask.DueOffsetDays,
+			AssigneeIDs:     uniqueIDs(subtask.AssigneeIDs),
+			LabelIDs:        uniqueIDs(subtask.LabelIDs),
+		}
+		template.Subtasks = append(template.Subtasks, item)
+		assigneeIDs = append(assigneeIDs, item.AssigneeIDs...)
+		labelIDs = append(labelIDs, item.LabelIDs...)
+	}
+
+	ctx := c.Request.Context()
+
+	for _, assigneeID := range uniqueIDs(assigneeIDs) {
+		if err := h.repo.ValidateUserInWorkspace(ctx, assigneeID, workspaceID); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("user %d is not a member of workspace", assigneeID)})
+			return nil, false
+		}
+	}
+	if len(labelIDs) > 0 {
+		ok, err := h.workspaceHasLabels(ctx, workspaceID, labelIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to validate labels"})
+			return nil, false
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "label not found in workspace"})
+			return nil, false
+		}
+	}
+
+	return template, true
+}
+
+// defaultPriority возвращает приоритет normal, если приоритет не указан
+func defaultPriority(priority string) string {
+	if priority == "" {
+		return dm.TaskPriorityNormal
+	}
+	return priority
+}
+
+// templateVariables возвращает отсортированные имена переменных, используемых в шаблоне
+func templateVariables(template *dm.TaskTemplate) []string {
+	texts := []string{template.Title, stringOrEmpty(template.Description)}
+	for _, subtask := range template.Subtasks {
+		texts = append(texts, subtask.Title, stringOrEmpty(subtask.Description))
+	}
+
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, text := range texts {
+		for _, match := range templateVariablePattern.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renderTemplateText подставляет значения переменных в текст шаблона
+func renderTemplateText(text string, variables map[string]string) string {
+	rendered := templateVariablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := templateVariablePattern.FindStringSubmatch(placeholder)[1]
+		return strings.TrimSpace(variables[name])
+	})
+	return strings.TrimSpace(rendered)
+}
+
+// renderTemplateDescription подставляет переменные в необязательное описание
+func renderTemplateDescription(text *string, variables map[string]string) *string {
+	if text == nil {
+		return nil
+	}
+	rendered := renderTemplateText(*text, variables)
+	if rendered == "" {
+		return nil
+	}
+	return &rendered
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// toTaskTemplateResponse преобразует шаблон задачи в ответ API
+func toTaskTemplateResponse(template *dm.TaskTemplate) models.TaskTemplateResponse {
+	response := models.TaskTemplateResponse{
+		ID:              template.ID,
+		WorkspaceID:     template.WorkspaceID,
+		Name:            template.Name,
+		Title:           template.Title,
+		Description:     template.Description,
+		Priority:        template.Priority,
+		EstimateMinutes: template.EstimateMinutes,
+		AssigneeIDs:     nonNilIDs(template.AssigneeIDs),
+		LabelIDs:        nonNilIDs(template.LabelIDs),
+		Variables:       templateVariables(template),
+		CreatedBy:       template.CreatedBy,
+		CreatedAt:       template.CreatedAt,
+		UpdatedAt:       template.UpdatedAt,
+	}
+	for _, subtask := range template.Subtasks {
+		response.Subtasks = append(response.Subtasks, models.TaskTemplateSubtaskResponse{
+			Title:           subtask.Title,
+			Description:     subtask.Description,
+			Priority:        subtask.Priority,
+			EstimateMinutes: subtask.EstimateMinutes,
+			DueOffsetDays:   subtask.DueOffsetDays,
+			AssigneeIDs:     nonNilIDs(subtask.AssigneeIDs),
+			LabelIDs:        nonNilIDs(subtask.LabelIDs),
+		})
+	}
+	return response
+}
+
+func nonNilIDs(ids []int) []int {
+	if ids == nil {
+		return []int{}
+	}
+	return ids
+}
@@ -0,0 +1,420 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ========== Time Tracking Operations ==========
+
+// StartTimer godoc
+// @Summary Запустить таймер
+// @Description Запускает таймер учета времени текущего пользователя по задаче (только исполнитель). У пользователя может быть запущен только один таймер
+// @Tags time
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param request body models.StartTimerRequest false "Комментарий к записи"
+// @Success 201 {object} models.TimeEntryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/time/start [post]
+func (h *TaskHandler) StartTimer(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	var req models.StartTimerRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+			return
+		}
+	}
+
+	if !h.authorizeTimeLogging(c, task, userID) {
+		return
+	}
+
+	entry, err := h.repo.StartTimer(c.Request.Context(), task.ID, userID, req.Note)
+	if err != nil {
+		if err.Error() == "timer already running" {
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "timer already running, stop it first"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to start timer"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTimeEntryResponse(entry))
+}
+
+// StopTimer godoc
+// @Summary Остановить таймер
+// @Description Останавливает запущенный таймер текущего пользователя по задаче
+// @Tags time
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} models.TimeEntryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/time/stop [post]
+func (h *TaskHandler) StopTimer(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	entry, err := h.repo.StopTimer(c.Request.Context(), task.ID, userID)
+	if err != nil {
+		if err.Error() == "timer not running" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "timer is not running for this task"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to stop timer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toTimeEntryResponse(entry))
+}
+
+// CreateTimeEntry godoc
+// @Summary Добавить запись учета времени
+// @Description Добавляет завершенную запись учета времени текущего пользователя по задаче вручную (только исполнитель)
+// @Tags time
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param request body models.CreateTimeEntryRequest true "Начало и длительность"
+// @Success 201 {object} models.TimeEntryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/time [post]
+func (h *TaskHandler) CreateTimeEntry(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	startedAt := req.StartedAt.UTC()
+	endedAt := startedAt.Add(time.Duration(req.Minutes) * time.Minute)
+	if endedAt.After(time.Now().UTC()) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "time entry cannot end in the future"})
+		return
+	}
+
+	if !h.authorizeTimeLogging(c, task, userID) {
+		return
+	}
+
+	entry, err := h.repo.CreateTimeEntry(c.Request.Context(), &dm.TimeEntry{
+		TaskID:    task.ID,
+		UserID:    userID,
+		StartedAt: startedAt,
+		EndedAt:   &endedAt,
+		Note:      req.Note,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to create time entry"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTimeEntryResponse(entry))
+}
+
+// GetTimeEntries godoc
+// @Summary Получить учет времени задачи
+// @Description Возвращает записи учета времени задачи, оценку и суммарное учтенное время (по завершенным записям)
+// @Tags time
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} models.TimeEntriesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/time [get]
+func (h *TaskHandler) GetTimeEntries(c *gin.Context) {
+	_, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	entries, err := h.repo.GetTimeEntries(c.Request.Context(), task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get time entries"})
+		return
+	}
+
+	response := models.TimeEntriesResponse{
+		TaskID:          task.ID,
+		EstimateMinutes: task.EstimateMinutes,
+		LoggedMinutes:   task.LoggedMinutes,
+		Entries:         []models.TimeEntryResponse{},
+	}
+	for i := range entries {
+		response.Entries = append(response.Entries, toTimeEntryResponse(&entries[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteTimeEntry godoc
+// @Summary Удалить запись учета времени
+// @Description Удаляет запись учета времени (автор записи или руководитель РП)
+// @Tags time
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Param entry_id path int true "ID записи"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/time/{entry_id} [delete]
+func (h *TaskHandler) DeleteTimeEntry(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	entryID, err := strconv.Atoi(c.Param("entry_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid time entry id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	entry, err := h.repo.GetTimeEntry(ctx, task.ID, entryID)
+	if err != nil {
+		if err.Error() == "time entry not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "time entry not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get time entry"})
+		return
+	}
+
+	if entry.UserID != userID {
+		role, err := h.repo.GetUserRoleInWorkspace(ctx, userID, task.WorkspaceID)
+		if err != nil || role != 2 {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "only entry author or workspace leader can delete time entry"})
+			return
+		}
+	}
+
+	if err := h.repo.DeleteTimeEntry(ctx, task.ID, entryID); err != nil {
+		if err.Error() == "time entry not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "time entry not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to delete time entry"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetTimeReport godoc
+// @Summary Отчет по учтенному времени
+// @Description Суммирует учтенное время рабочего пространства по пользователям и задачам за период (по дате начала записи). Руководитель РП видит время всех участников, остальные — только свое. format=csv возвращает отчет в CSV
+// @Tags time
+// @Produce json
+// @Produce text/csv
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param from query string false "Начало периода (YYYY-MM-DD)"
+// @Param to query string false "Конец периода (YYYY-MM-DD, включительно)"
+// @Param user_id query int false "ID пользователя"
+// @Param task_id query int false "ID задачи"
+// @Param format query string false "Формат ответа: json (по умолчанию) или csv"
+// @Success 200 {object} models.TimeReportResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/time-report/{workspace_id} [get]
+func (h *TaskHandler) GetTimeReport(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	workspaceID, err := strconv.Atoi(c.Param("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid workspace id"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid format, expected json or csv"})
+		return
+	}
+
+	filter := dm.TimeReportFilter{WorkspaceID: workspaceID}
+	if filter.UserID, err = parseOptionalID(c, "user_id"); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if filter.TaskID, err = parseOptionalID(c, "task_id"); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+	if from := c.Query("from"); from != "" {
+		parsed, err := parseDate(from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid from, expected YYYY-MM-DD"})
+			return
+		}
+		filter.From = &parsed
+	}
+	if to := c.Query("to"); to != "" {
+		parsed, err := parseDate(to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid to, expected YYYY-MM-DD"})
+			return
+		}
+		filter.To = &parsed
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid period, to is before from"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	role, err := h.repo.GetUserRoleInWorkspace(ctx, userID, workspaceID)
+	if err != nil {
+		if err.Error() == "user is not a member of workspace" {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to check user role"})
+		return
+	}
+
+	// Участник, не являющийся руководителем, видит только свое время
+	if role != 2 {
+		if filter.UserID != nil && *filter.UserID != userID {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "only workspace leader can view time of other users"})
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	rows, err := h.repo.GetTimeReport(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get time report"})
+		return
+	}
+
+	if format == "csv" {
+		writeTimeReportCSV(c, workspaceID, rows)
+		return
+	}
+
+	response := models.TimeReportResponse{
+		WorkspaceID: workspaceID,
+		Rows:        []models.TimeReportRowResponse{},
+	}
+	if filter.From != nil {
+		from := filter.From.Format("2006-01-02")
+		response.From = &from
+	}
+	if filter.To != nil {
+		to := filter.To.Format("2006-01-02")
+		response.To = &to
+	}
+	for _, row := range rows {
+		response.TotalMinutes += row.Minutes
+		response.Rows = append(response.Rows, models.TimeReportRowResponse{
+			UserID:    row.UserID,
+			UserName:  row.UserName,
+			TaskID:    row.TaskID,
+			TaskTitle: row.TaskTitle,
+			Entries:   row.Entries,
+			Minutes:   row.Minutes,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// writeTimeReportCSV отправляет отчет по учтенному времени в CSV.
+// BOM в начале файла нужен, чтобы табличные редакторы распознали UTF-8
+func writeTimeReportCSV(c *gin.Context, workspaceID int, rows []dm.TimeReportRow) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="time-report-%d.csv"`, workspaceID))
+	c.Status(http.StatusOK)
+
+	c.Writer.WriteString("\ufeff")
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"user_id", "user_name", "task_id", "task_title", "entries", "minutes", "hours"})
+	for _, row := range rows {
+		writer.Write([]string{
+			strconv.Itoa(row.UserID),
+			row.UserName,
+			strconv.Itoa(row.TaskID),
+			row.TaskTitle,
+			strconv.Itoa(row.Entries),
+			strconv.Itoa(row.Minutes),
+			fmt.Sprintf("%.2f", float64(row.Minutes)/60),
+		})
+	}
+	writer.Flush()
+}
+
+// authorizeTimeLogging проверяет, что пользователь является исполнителем задачи и может учитывать по ней время
+func (h *TaskHandler) authorizeTimeLogging(c *gin.Context, task *dm.TaskWithDetails, userID int) bool {
+	assigned, err := h.repo.IsTaskAssignee(c.Request.Context(), task.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to check task assignee"})
+		return false
+	}
+	if !assigned {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "only task assignees can log time"})
+		return false
+	}
+	return true
+}
+
+// toTimeEntryResponse преобразует запись учета времени в ответ API
+func toTimeEntryResponse(entry *dm.TimeEntry) models.TimeEntryResponse {
+	return models.TimeEntryResponse{
+		ID:        entry.ID,
+		TaskID:    entry.TaskID,
+		UserID:    entry.UserID,
+		UserName:  entry.UserName,
+		StartedAt: entry.StartedAt,
+		EndedAt:   entry.EndedAt,
+		Minutes:   entry.Minutes,
+		Running:   entry.EndedAt == nil,
+		Note:      entry.Note,
+	}
+}
@@ -0,0 +1,526 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// taskPriorityOrder — порядок групп по приоритету, от наивысшего
+var taskPriorityOrder = []string{dm.TaskPriorityCritical, dm.TaskPriorityHigh, dm.TaskPriorityNormal, dm.TaskPriorityLow}
+
+// ========== View Operations ==========
+
+// GetTaskViews godoc
+// @Summary Получить сохраненные представления
+// @Description Возвращает представления списка задач рабочего пространства, доступные пользователю: сначала собственные, затем общие представления других участников
+// @Tags views
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Success 200 {object} models.TaskViewListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/views/{workspace_id} [get]
+func (h *TaskHandler) GetTaskViews(c *gin.Context) {
+	userID, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	views, err := h.repo.GetTaskViews(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task views"})
+		return
+	}
+
+	response := models.TaskViewListResponse{Views: []models.TaskViewResponse{}}
+	for i := range views {
+		response.Views = append(response.Views, toTaskViewResponse(&views[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateTaskView godoc
+// @Summary Сохранить представление
+// @Description Сохраняет под названием фильтр, сортировку и группировку списка задач рабочего пространства. Общее представление (is_shared) видят все участники РП, но изменить его может только владелец
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param request body models.TaskViewRequest true "Представление"
+// @Success 201 {object} models.TaskViewResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/views/{workspace_id} [post]
+func (h *TaskHandler) CreateTaskView(c *gin.Context) {
+	userID, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.bindTaskView(c, workspaceID)
+	if !ok {
+		return
+	}
+	view.OwnerID = userID
+
+	created, err := h.repo.CreateTaskView(c.Request.Context(), view)
+	if err != nil {
+		if err.Error() == "view already exists" {
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "view with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to create task view"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTaskViewResponse(created))
+}
+
+// GetTaskView godoc
+// @Summary Получить представление
+// @Description Возвращает собственное или общее представление списка задач
+// @Tags views
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param view_id path int true "ID представления"
+// @Success 200 {object} models.TaskViewResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/views/{workspace_id}/{view_id} [get]
+func (h *TaskHandler) GetTaskView(c *gin.Context) {
+	userID, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.loadTaskView(c, workspaceID, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, toTaskViewResponse(view))
+}
+
+// UpdateTaskView godoc
+// @Summary Изменить представление
+// @Description Заменяет название, фильтр, сортировку, группировку и видимость представления (только владелец)
+// @Tags views
+// @Accept json
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param view_id path int true "ID представления"
+// @Param request body models.TaskViewRequest true "Представление"
+// @Success 200 {object} models.TaskViewResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/views/{workspace_id}/{view_id} [put]
+func (h *TaskHandler) UpdateTaskView(c *gin.Context) {
+	userID, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	existing, ok := h.loadTaskView(c, workspaceID, userID)
+	if !ok {
+		return
+	}
+	if existing.OwnerID != userID {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "only view owner can change view"})
+		return
+	}
+
+	view, ok := h.bindTaskView(c, workspaceID)
+	if !ok {
+		return
+	}
+	view.ID = existing.ID
+
+	updated, err := h.repo.UpdateTaskView(c.Request.Context(), view)
+	if err != nil {
+		switch err.Error() {
+		case "view not found":
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "view not found"})
+		case "view already exists":
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "view with this name already exists"})
+		default:
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update task view"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, toTaskViewResponse(updated))
+}
+
+// DeleteTaskView godoc
+// @Summary Удалить представление
+// @Description Удаляет представление списка задач. Собственное представление удаляет владелец, общее — владелец или руководитель РП
+// @Tags views
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param view_id path int true "ID представления"
+// @Success 204
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/views/{workspace_id}/{view_id} [delete]
+func (h *TaskHandler) DeleteTaskView(c *gin.Context) {
+	userID, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.loadTaskView(c, workspaceID, userID)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if view.OwnerID != userID {
+		role, err := h.repo.GetUserRoleInWorkspace(ctx, userID, workspaceID)
+		if err != nil || role != 2 {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "only view owner or workspace leader can delete view"})
+			return
+		}
+	}
+
+	if err := h.repo.DeleteTaskView(ctx, workspaceID, view.ID); err != nil {
+		if err.Error() == "view not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "view not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to delete task view"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetTaskViewResults godoc
+// @Summary Получить задачи представления
+// @Description Возвращает страницу задач, отобранных фильтром представления, в его сортировке. Если у представления задана группировка, ответ содержит группы с количеством задач по всему фильтру и ID задач текущей страницы: по статусу (в порядке workflow), приоритету или метке (задача с несколькими метками попадает в несколько групп, задачи без меток — в группу none)
+// @Tags views
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param view_id path int true "ID представления"
+// @Param limit query int false "Количество задач (по умолчанию 50, максимум 100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} models.TaskViewResultsResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/views/{workspace_id}/{view_id}/tasks [get]
+func (h *TaskHandler) GetTaskViewResults(c *gin.Context) {
+	userID, workspaceID, ok := h.authorizeWorkspaceMember(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.loadTaskView(c, workspaceID, userID)
+	if !ok {
+		return
+	}
+
+	filter := dm.TaskFilter{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Statuses:    view.Statuses,
+		AssigneeID:  view.AssigneeID,
+		CreatorID:   view.CreatorID,
+		DueFrom:     view.DueFrom,
+		DueTo:       view.DueTo,
+		ChatID:      view.ChatID,
+		Overdue:     view.Overdue,
+		Priorities:  view.Priorities,
+		LabelIDs:    view.LabelIDs,
+		Search:      view.Search,
+		SortField:   view.SortField,
+		SortDesc:    view.SortDesc,
+		Limit:       defaultTaskPageSize,
+	}
+	if err := parseTaskPage(c, &filter); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	page, tasks, err := h.getTaskPage(ctx, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get tasks"})
+		return
+	}
+
+	response := models.TaskViewResultsResponse{
+		View:       toTaskViewResponse(view),
+		Tasks:      page.Tasks,
+		Total:      page.Total,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+	}
+
+	if view.GroupBy != nil {
+		var workflow *dm.Workflow
+		if *view.GroupBy == dm.TaskGroupByStatus {
+			workflow, err = h.repo.GetWorkflow(ctx, workspaceID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workflow"})
+				return
+			}
+		}
+		counts, err := h.repo.CountTaskGroups(ctx, filter, *view.GroupBy)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to count task groups"})
+			return
+		}
+		response.Groups = groupTasks(*view.GroupBy, counts, tasks, workflow)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// loadTaskView получает доступное пользователю представление из параметра view_id
+func (h *TaskHandler) loadTaskView(c *gin.Context, workspaceID, userID int) (*dm.TaskView, bool) {
+	viewID, err := strconv.Atoi(c.Param("view_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid view id"})
+		return nil, false
+	}
+
+	view, err := h.repo.GetTaskView(c.Request.Context(), workspaceID, viewID, userID)
+	if err != nil {
+		if err.Error() == "view not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "view not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task view"})
+		return nil, false
+	}
+
+	return view, true
+}
+
+// bindTaskView читает представление из запроса и проверяет фильтр так же, как параметры GET /tasks
+func (h *TaskHandler) bindTaskView(c *gin.Context, workspaceID int) (*dm.TaskView, bool) {
+	var req models.TaskViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return nil, false
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "view name is required"})
+		return nil, false
+	}
+
+	view := &dm.TaskView{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		IsShared:    req.IsShared,
+		Statuses:    uniqueIDs(req.Filter.Statuses),
+		Priorities:  []string{},
+		LabelIDs:    uniqueIDs(req.Filter.LabelIDs),
+		AssigneeID:  req.Filter.AssigneeID,
+		CreatorID:   req.Filter.CreatorID,
+		ChatID:      req.Filter.ChatID,
+		Overdue:     req.Filter.Overdue,
+		Search:      strings.TrimSpace(req.Filter.Search),
+		SortField:   dm.TaskSortDate,
+		SortDesc:    true,
+		GroupBy:     req.GroupBy,
+	}
+
+	for _, priority := range req.Filter.Priorities {
+		if !containsString(view.Priorities, priority) {
+			view.Priorities = append(view.Priorities, priority)
+		}
+	}
+
+	if req.Filter.DueFrom != nil {
+		parsed, err := parseDate(*req.Filter.DueFrom)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid due_from, expected YYYY-MM-DD"})
+			return nil, false
+		}
+		view.DueFrom = &parsed
+	}
+	if req.Filter.DueTo != nil {
+		parsed, err := parseDate(*req.Filter.DueTo)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid due_to, expected YYYY-MM-DD"})
+			return nil, false
+		}
+		view.DueTo = &parsed
+	}
+
+	if req.Sort != "" {
+		var err error
+		if view.SortField, view.SortDesc, err = parseTaskSort(req.Sort); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+			return nil, false
+		}
+	}
+
+	if len(view.LabelIDs) > 0 {
+		ok, err := h.workspaceHasLabels(c.Request.Context(), workspaceID, view.LabelIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to validate labels"})
+			return nil, false
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "label not found in workspace"})
+			return nil, false
+		}
+	}
+
+	return view, true
+}
+
+// toTaskViewResponse преобразует представление в ответ API
+func toTaskViewResponse(view *dm.TaskView) models.TaskViewResponse {
+	response := models.TaskViewResponse{
+		ID:          view.ID,
+		WorkspaceID: view.WorkspaceID,
+		OwnerID:     view.OwnerID,
+		OwnerName:   view.OwnerName,
+		Name:        view.Name,
+		IsShared:    view.IsShared,
+		Filter: models.TaskViewFilter{
+			Statuses:   view.Statuses,
+			Priorities: view.Priorities,
+			LabelIDs:   view.LabelIDs,
+			AssigneeID: view.AssigneeID,
+			CreatorID:  view.CreatorID,
+			ChatID:     view.ChatID,
+			Overdue:    view.Overdue,
+			Search:     view.Search,
+		},
+		Sort:      view.SortField,
+		GroupBy:   view.GroupBy,
+		CreatedAt: view.CreatedAt,
+		UpdatedAt: view.UpdatedAt,
+	}
+	if view.SortDesc {
+		response.Sort = "-" + view.SortField
+	}
+	if view.DueFrom != nil {
+		dueFrom := view.DueFrom.Format("2006-01-02")
+		response.Filter.DueFrom = &dueFrom
+	}
+	if view.DueTo != nil {
+		dueTo := view.DueTo.Format("2006-01-02")
+		response.Filter.DueTo = &dueTo
+	}
+	return response
+}
+
+// groupTasks строит группы представления: counts — количество задач в группах по всему фильтру,
+// tasks — задачи текущей страницы, их ID раскладываются по группам с сохранением порядка.
+// Группы по статусу идут в порядке workflow, по приоритету — от наивысшего, по метке — по названию метки
+func groupTasks(groupBy string, counts []dm.TaskGroupCount, tasks []dm.TaskWithDetails, workflow *dm.Workflow) []models.TaskGroupResponse {
+	groups := []models.TaskGroupResponse{}
+	index := make(map[string]int)
+	group := func(key, name string) int {
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, models.TaskGroupResponse{Key: key, Name: name, TaskIDs: []int{}})
+		}
+		return i
+	}
+	add := func(key, name string, taskID int) {
+		i := group(key, name)
+		groups[i].TaskIDs = append(groups[i].TaskIDs, taskID)
+	}
+
+	for _, count := range counts {
+		groups[group(count.Key, count.Name)].Count = count.Count
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		switch groupBy {
+		case dm.TaskGroupByStatus:
+			add(strconv.Itoa(task.Status), task.StatusName, task.ID)
+		case dm.TaskGroupByPriority:
+			add(task.Priority, task.Priority, task.ID)
+		case dm.TaskGroupByLabel:
+			if len(task.Labels) == 0 {
+				add(dm.TaskGroupNone, "Без метки", task.ID)
+			}
+			for _, label := range task.Labels {
+				add(strconv.Itoa(label.ID), label.Name, task.ID)
+			}
+		}
+	}
+
+	rank := func(group models.TaskGroupResponse) int {
+		switch groupBy {
+		case dm.TaskGroupByStatus:
+			if workflow != nil {
+				for i, status := range workflow.Statuses {
+					if strconv.Itoa(status.Code) == group.Key {
+						return i
+					}
+				}
+			}
+			return len(groups)
+		case dm.TaskGroupByPriority:
+			for i, priority := range taskPriorityOrder {
+				if priority == group.Key {
+					return i
+				}
+			}
+			return len(taskPriorityOrder)
+		case dm.TaskGroupByLabel:
+			if group.Key == dm.TaskGroupNone {
+				return 1
+			}
+		}
+		return 0
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		ri, rj := rank(groups[i]), rank(groups[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return groupBy == dm.TaskGroupByLabel && strings.ToLower(groups[i].Name) < strings.ToLower(groups[j].Name)
+	})
+
+	return groups
+}
+
+// containsString проверяет, что строка есть в списке
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ========== Watcher Operations ==========
+
+// WatchTask godoc
+// @Summary Наблюдать за задачей
+// @Description Добавляет текущего пользователя в наблюдатели задачи. Наблюдатели получают те же уведомления об изменениях и комментариях, что и исполнители, но не отвечают за задачу. Создатель задачи и авторы комментариев становятся наблюдателями автоматически
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} models.TaskWatchersResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/watch [post]
+func (h *TaskHandler) WatchTask(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.AddTaskWatcher(c.Request.Context(), task.ID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to watch task"})
+		return
+	}
+
+	h.respondWithWatchers(c, task.ID, userID)
+}
+
+// UnwatchTask godoc
+// @Summary Перестать наблюдать за задачей
+// @Description Удаляет текущего пользователя из наблюдателей задачи. Исполнители продолжают получать уведомления как исполнители
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} models.TaskWatchersResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/watch [delete]
+func (h *TaskHandler) UnwatchTask(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RemoveTaskWatcher(c.Request.Context(), task.ID, userID); err != nil {
+		if err.Error() == "watcher not found" {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "user is not watching task"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to unwatch task"})
+		return
+	}
+
+	h.respondWithWatchers(c, task.ID, userID)
+}
+
+// GetTaskWatchers godoc
+// @Summary Получить список наблюдателей
+// @Description Возвращает наблюдателей задачи и признак того, что текущий пользователь наблюдает за ней
+// @Tags tasks
+// @Produce json
+// @Param id path int true "ID задачи"
+// @Success 200 {object} models.TaskWatchersResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/{id}/watchers [get]
+func (h *TaskHandler) GetTaskWatchers(c *gin.Context) {
+	userID, task, ok := loadTask(c, h.repo)
+	if !ok {
+		return
+	}
+
+	h.respondWithWatchers(c, task.ID, userID)
+}
+
+// GetWatchedTasks godoc
+// @Summary Задачи, за которыми я наблюдаю
+// @Description Возвращает задачи, за которыми наблюдает текущий пользователь, во всех его рабочих пространствах или в одном РП, упорядоченные по сроку. Завершенные и отмененные задачи возвращаются только с include_closed=true
+// @Tags tasks
+// @Produce json
+// @Param workspace_id query int false "ID рабочего пространства"
+// @Param include_closed query bool false "Включить завершенные и отмененные задачи"
+// @Param limit query int false "Количество задач (по умолчанию 50, максимум 100)"
+// @Param offset query int false "Смещение"
+// @Success 200 {object} models.TaskListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/watching [get]
+func (h *TaskHandler) GetWatchedTasks(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var workspaceID *int
+	if workspaceStr := c.Query("workspace_id"); workspaceStr != "" {
+		id, err := strconv.Atoi(workspaceStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid workspace id"})
+			return
+		}
+		workspaceID = &id
+	}
+
+	includeClosed := false
+	if includeStr := c.Query("include_closed"); includeStr != "" {
+		includeClosed, err = strconv.ParseBool(includeStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid include_closed value"})
+			return
+		}
+	}
+
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil && v > 0 && v <= 100 {
+			limit = v
+		}
+	}
+
+	offset := 0
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if v, err := strconv.Atoi(offsetStr); err == nil && v >= 0 {
+			offset = v
+		}
+	}
+
+	tasks, total, err := h.repo.GetWatchedTasks(c.Request.Context(), userID, workspaceID, includeClosed, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get watched tasks"})
+		return
+	}
+
+	response := models.TaskListResponse{
+		Tasks:   make([]models.TaskResponse, 0, len(tasks)),
+		Total:   total,
+		HasMore: offset+len(tasks) < total,
+	}
+	for i := range tasks {
+		response.Tasks = append(response.Tasks, h.convertToTaskResponse(&tasks[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithWatchers отвечает списком наблюдателей задачи
+func (h *TaskHandler) respondWithWatchers(c *gin.Context, taskID, userID int) {
+	watchers, err := h.repo.GetTaskWatchers(c.Request.Context(), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get task watchers"})
+		return
+	}
+
+	response := models.TaskWatchersResponse{
+		Watchers: make([]models.TaskWatcherResponse, 0, len(watchers)),
+		Total:    len(watchers),
+	}
+	for _, watcher := range watchers {
+		if watcher.UserID == userID {
+			response.Watching = true
+		}
+		response.Watchers = append(response.Watchers, models.TaskWatcherResponse{
+			UserID:    watcher.UserID,
+			Login:     watcher.Login,
+			Name:      watcher.Name,
+			Surname:   watcher.Surname,
+			WatchedAt: watcher.WatchedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	dm "github.com/diploma/task-service/data/models"
+	"github.com/diploma/task-service/data/repository"
+	"github.com/diploma/task-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+type WorkflowHandler struct {
+	repo *repository.Repository
+}
+
+func NewWorkflowHandler(repo *repository.Repository) *WorkflowHandler {
+	return &WorkflowHandler{repo: repo}
+}
+
+// GetWorkflow godoc
+// @Summary Получить workflow задач
+// @Description Возвращает статусы задач рабочего пространства, допустимые переходы и роли, которым они разрешены
+// @Tags workflows
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Success 200 {object} models.WorkflowResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/workflows/{workspace_id} [get]
+func (h *WorkflowHandler) GetWorkflow(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	workspaceID, err := strconv.Atoi(c.Param("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid workspace id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.repo.ValidateUserInWorkspace(ctx, userID, workspaceID); err != nil {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
+		return
+	}
+
+	h.respondWithWorkflow(c, workspaceID)
+}
+
+// UpdateWorkflow godoc
+// @Summary Изменить workflow задач
+// @Description Заменяет статусы задач рабочего пространства и переходы между ними (только руководитель РП). Статусы, в которых находятся задачи, удалить нельзя
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Param request body models.UpdateWorkflowRequest true "Workflow"
+// @Success 200 {object} models.WorkflowResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/workflows/{workspace_id} [put]
+func (h *WorkflowHandler) UpdateWorkflow(c *gin.Context) {
+	workspaceID, ok := h.authorizeLeader(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid request data"})
+		return
+	}
+
+	statuses, transitions, err := buildWorkflow(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.repo.ReplaceWorkflow(c.Request.Context(), workspaceID, statuses, transitions); err != nil {
+		if err.Error() == "status in use" {
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "some tasks have a status that is missing from the new workflow"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update workflow"})
+		return
+	}
+
+	h.respondWithWorkflow(c, workspaceID)
+}
+
+// ResetWorkflow godoc
+// @Summary Сбросить workflow задач
+// @Description Удаляет собственный workflow рабочего пространства, после чего используется workflow по умолчанию (только руководитель РП)
+// @Tags workflows
+// @Produce json
+// @Param workspace_id path int true "ID рабочего пространства"
+// @Success 200 {object} models.WorkflowResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /tasks/workflows/{workspace_id} [delete]
+func (h *WorkflowHandler) ResetWorkflow(c *gin.Context) {
+	workspaceID, ok := h.authorizeLeader(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.ResetWorkflow(c.Request.Context(), workspaceID); err != nil {
+		if err.Error() == "status in use" {
+			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "some tasks have a status that is missing from the default workflow"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to reset workflow"})
+		return
+	}
+
+	h.respondWithWorkflow(c, workspaceID)
+}
+
+// authorizeLeader проверяет, что пользователь — руководитель РП, и возвращает ID РП
+func (h *WorkflowHandler) authorizeLeader(c *gin.Context) (int, bool) {
+	return authorizeWorkspaceLeader(c, h.repo, "only workspace leader can manage workflows")
+}
+
+// authorizeWorkspaceLeader проверяет, что пользователь — руководитель РП из параметра workspace_id,
+// и возвращает ID РП. forbidden — текст ошибки для остальных участников РП
+func authorizeWorkspaceLeader(c *gin.Context, repo *repository.Repository, forbidden string) (int, bool) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return 0, false
+	}
+
+	workspaceID, err := strconv.Atoi(c.Param("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid workspace id"})
+		return 0, false
+	}
+
+	role, err := repo.GetUserRoleInWorkspace(c.Request.Context(), userID, workspaceID)
+	if err != nil {
+		if err.Error() == "user is not a member of workspace" {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of workspace"})
+			return 0, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to check user role"})
+		return 0, false
+	}
+	if role != 2 {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: forbidden})
+		return 0, false
+	}
+
+	return workspaceID, true
+}
+
+// respondWithWorkflow возвращает действующий workflow РП
+func (h *WorkflowHandler) respondWithWorkflow(c *gin.Context, workspaceID int) {
+	workflow, err := h.repo.GetWorkflow(c.Request.Context(), workspaceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workflow"})
+		return
+	}
+
+	response := models.WorkflowResponse{
+		WorkspaceID: workspaceID,
+		IsDefault:   workflow.IsDefault,
+		Statuses:    []models.WorkflowStatusResponse{},
+		Transitions: []models.WorkflowTransitionResponse{},
+	}
+	for _, status := range workflow.Statuses {
+		response.Statuses = append(response.Statuses, models.WorkflowStatusResponse{
+			Code:      status.Code,
+			Name:      status.Name,
+			Category:  status.Category,
+			IsInitial: status.IsInitial,
+			Position:  status.Position,
+		})
+	}
+	for _, transition := range workflow.Transitions {
+		response.Transitions = append(response.Transitions, models.WorkflowTransitionResponse{
+			From:         transition.FromStatus,
+			To:           transition.ToStatus,
+			AllowedRoles: transition.AllowedRoles,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// buildWorkflow проверяет запрос и преобразует его в статусы и переходы.
+// Порядок статусов в запросе задает их позицию
+func buildWorkflow(req *models.UpdateWorkflowRequest) ([]dm.TaskStatus, []dm.TaskStatusTransition, error) {
+	codes := make(map[int]bool, len(req.Statuses))
+	statuses := make([]dm.TaskStatus, 0, len(req.Statuses))
+	initialCount := 0
+
+	for i, status := range req.Statuses {
+		if codes[status.Code] {
+			return nil, nil, fmt.Errorf("duplicate status code %d", status.Code)
+		}
+		codes[status.Code] = true
+		if status.IsInitial {
+			initialCount++
+		}
+		statuses = append(statuses, dm.TaskStatus{
+			Code:      status.Code,
+			Name:      status.Name,
+			Category:  status.Category,
+			IsInitial: status.IsInitial,
+			Position:  i + 1,
+		})
+	}
+
+	if initialCount != 1 {
+		return nil, nil, fmt.Errorf("workflow must have exactly one initial status")
+	}
+
+	pairs := make(map[[2]int]bool, len(req.Transitions))
+	transitions := make([]dm.TaskStatusTransition, 0, len(req.Transitions))
+
+	for _, transition := range req.Transitions {
+		if !codes[transition.From] || !codes[transition.To] {
+			return nil, nil, fmt.Errorf("transition %d -> %d references unknown status", transition.From, transition.To)
+		}
+		if transition.From == transition.To {
+			return nil, nil, fmt.Errorf("transition %d -> %d must change the status", transition.From, transition.To)
+		}
+
+		pair := [2]int{transition.From, transition.To}
+		if pairs[pair] {
+			return nil, nil, fmt.Errorf("duplicate transition %d -> %d", transition.From, transition.To)
+		}
+		pairs[pair] = true
+
+		for _, role := range transition.AllowedRoles {
+			if !dm.IsValidTransitionRole(role) {
+				return nil, nil, fmt.Errorf("invalid role %q, expected member, leader, creator or assignee", role)
+			}
+		}
+
+		transitions = append(transitions, dm.TaskStatusTransition{
+			FromStatus:   transition.From,
+			ToStatus:     transition.To,
+			AllowedRoles: transition.AllowedRoles,
+		})
+	}
+
+	return statuses, transitions, nil
+}
+
+// canPerformTransition проверяет, что пользователь обладает одной из ролей перехода.
+// Пустой список ролей разрешает переход любому участнику РП
+func canPerformTransition(ctx context.Context, repo *repository.Repository, task *dm.TaskWithDetails, userID int, roles []string) (bool, error) {
+	if len(roles) == 0 {
+		return true, nil
+	}
+
+	for _, role := range roles {
+		switch role {
+		case dm.TransitionRoleMember:
+			return true, nil
+		case dm.TransitionRoleCreator:
+			if task.Creator == userID {
+				return true, nil
+			}
+		case dm.TransitionRoleLeader:
+			workspaceRole, err := repo.GetUserRoleInWorkspace(ctx, userID, task.WorkspaceID)
+			if err != nil {
+				return false, err
+			}
+			if workspaceRole == 2 {
+				return true, nil
+			}
+		case dm.TransitionRoleAssignee:
+			assigned, err := repo.IsTaskAssignee(ctx, task.ID, userID)
+			if err != nil {
+				return false, err
+			}
+			if assigned {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
@@ -6,25 +6,52 @@ import (
 
 // CreateTaskRequest запрос на создание задачи
 type CreateTaskRequest struct {
-	WorkspaceID   int     `json:"workspace_id" binding:"required"`
-	Title         string  `json:"title" binding:"required,min=3,max=100"`
-	Description   *string `json:"description,omitempty"`
+	WorkspaceID     int     `json:"workspace_id" binding:"required"`
+	Title           string  `json:"title" binding:"required,min=3,max=100"`
+	Description     *string `json:"description,omitempty"`
+	Date            string  `json:"date" binding:"required"` // ожидаем YYYY-MM-DD
+	Status          int     `json:"status,omitempty"`
+	AssignedUsers   []int   `json:"assigned_users,omitempty"`
+	ChatID          *int    `json:"chat_id,omitempty"`
+	ParentID        *int    `json:"parent_id,omitempty"`
+	Priority        string  `json:"priority,omitempty" binding:"omitempty,oneof=low normal high critical"`
+	LabelIDs        []int   `json:"label_ids,omitempty"`
+	EstimateMinutes *int    `json:"estimate_minutes,omitempty" binding:"omitempty,min=1"` // оценка трудозатрат в минутах
+	// Recurrence делает задачу первой задачей серии повторяющихся задач
+	Recurrence *RecurrenceRequest `json:"recurrence,omitempty"`
+}
+
+// CreateTaskFromMessageRequest запрос на создание задачи из сообщения чата.
+// Без title название берется из первой строки сообщения, описание — весь текст сообщения
+type CreateTaskFromMessageRequest struct {
+	MessageID     int     `json:"message_id" binding:"required"`
+	Title         *string `json:"title,omitempty" binding:"omitempty,min=3,max=100"`
 	Date          string  `json:"date" binding:"required"` // ожидаем YYYY-MM-DD
 	Status        int     `json:"status,omitempty"`
 	AssignedUsers []int   `json:"assigned_users,omitempty"`
-	ChatID        *int    `json:"chat_id,omitempty"`
+	Priority      string  `json:"priority,omitempty" binding:"omitempty,oneof=low normal high critical"`
+	LabelIDs      []int   `json:"label_ids,omitempty"`
 }
 
 // UpdateTaskRequest запрос на обновление задачи
 type UpdateTaskRequest struct {
-	Title       *string `json:"title,omitempty"`
-	Description *string `json:"description,omitempty"`
-	Date        *string `json:"date,omitempty"` // YYYY-MM-DD
+	Title           *string `json:"title,omitempty"`
+	Description     *string `json:"description,omitempty"`
+	Date            *string `json:"date,omitempty"` // YYYY-MM-DD
+	Priority        *string `json:"priority,omitempty" binding:"omitempty,oneof=low normal high critical"`
+	EstimateMinutes *int    `json:"estimate_minutes,omitempty" binding:"omitempty,min=0"` // 0 удаляет оценку
 }
 
 // UpdateTaskStatusRequest запрос на изменение статуса задачи
 type UpdateTaskStatusRequest struct {
-	Status int `json:"status" binding:"required,min=1,max=5"`
+	Status int `json:"status" binding:"required,min=1"`
+}
+
+// MoveTaskRequest запрос на перемещение задачи на доске.
+// Position — позиция в колонке с нуля; позиция больше размера колонки означает конец колонки
+type MoveTaskRequest struct {
+	Status   int  `json:"status" binding:"required,min=1"`
+	Position *int `json:"position" binding:"required,min=0"`
 }
 
 // AddTaskAssigneesRequest запрос на добавление исполнителей
@@ -39,25 +66,142 @@ type AttachTaskToChatRequest struct {
 
 // TaskResponse ответ с информацией о задаче
 type TaskResponse struct {
-	ID            int       `json:"id"`
-	Creator       int       `json:"creator"`
-	CreatorName   string    `json:"creator_name"`
-	WorkspaceID   int       `json:"workspace_id"`
-	WorkspaceName string    `json:"workspace_name"`
-	Title         string    `json:"title"`
-	Description   *string   `json:"description,omitempty"`
-	Date          time.Time `json:"date"`
-	Status        int       `json:"status"`
-	StatusName    string    `json:"status_name"`
-	AssigneeCount int       `json:"assignee_count"`
-	ChatCount     int       `json:"chat_count"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID              int             `json:"id"`
+	Creator         int             `json:"creator"`
+	CreatorName     string          `json:"creator_name"`
+	WorkspaceID     int             `json:"workspace_id"`
+	WorkspaceName   string          `json:"workspace_name"`
+	Title           string          `json:"title"`
+	Description     *string         `json:"description,omitempty"`
+	Date            time.Time       `json:"date"`
+	Status          int             `json:"status"`
+	StatusName      string          `json:"status_name"`
+	Priority        string          `json:"priority"`
+	Labels          []TaskLabel     `json:"labels"`
+	AssigneeCount   int             `json:"assignee_count"`
+	ChatCount       int             `json:"chat_count"`
+	CommentCount    int             `json:"comment_count"`
+	ParentID        *int            `json:"parent_id,omitempty"`
+	Subtasks        SubtaskProgress `json:"subtasks"`
+	OpenBlockers    int             `json:"open_blocker_count"`
+	Blocked         bool            `json:"blocked"`
+	Overdue         bool            `json:"overdue"`
+	SeriesID        *int            `json:"series_id,omitempty"`
+	SourceMessageID *int            `json:"source_message_id,omitempty"`
+	EstimateMinutes *int            `json:"estimate_minutes,omitempty"`
+	LoggedMinutes   int             `json:"logged_minutes"`
+	CreatedAt       time.Time       `json:"created_at"`
+	Version         int             `json:"version"` // совпадает со значением ETag
+}
+
+// TaskFromMessageResponse ответ на создание задачи из сообщения чата
+type TaskFromMessageResponse struct {
+	TaskResponse
+	// SystemMessagePosted false, если событие для системного сообщения в чате не удалось отправить
+	// (Kafka не настроена или недоступна): задача создана, но сообщение в чате не появится
+	SystemMessagePosted bool `json:"system_message_posted"`
+}
+
+// TaskLabel метка в ответе с задачей
+type TaskLabel struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// SubtaskProgress сводка выполнения подзадач
+type SubtaskProgress struct {
+	Total int `json:"total"`
+	Done  int `json:"done"`
 }
 
 // TaskListResponse ответ со списком задач
 type TaskListResponse struct {
-	Tasks []TaskResponse `json:"tasks"`
-	Total int            `json:"total"`
+	Tasks      []TaskResponse `json:"tasks"`
+	Total      int            `json:"total"`
+	HasMore    bool           `json:"has_more"`
+	NextCursor *string        `json:"next_cursor,omitempty"`
+}
+
+// BoardResponse ответ с Kanban-доской рабочего пространства
+type BoardResponse struct {
+	WorkspaceID int                   `json:"workspace_id"`
+	Columns     []BoardColumnResponse `json:"columns"`
+}
+
+// BoardColumnResponse колонка доски: статус и его задачи в порядке колонки
+type BoardColumnResponse struct {
+	Status   int            `json:"status"`
+	Name     string         `json:"name"`
+	Category string         `json:"category"`
+	Total    int            `json:"total"`
+	HasMore  bool           `json:"has_more"`
+	Tasks    []TaskResponse `json:"tasks"`
+}
+
+// CreateLabelRequest запрос на создание метки рабочего пространства
+type CreateLabelRequest struct {
+	Name  string `json:"name" binding:"required,min=1,max=50"`
+	Color string `json:"color" binding:"required"` // #rrggbb
+}
+
+// UpdateLabelRequest запрос на изменение метки рабочего пространства
+type UpdateLabelRequest struct {
+	Name  *string `json:"name,omitempty" binding:"omitempty,min=1,max=50"`
+	Color *string `json:"color,omitempty"` // #rrggbb
+}
+
+// LabelResponse ответ с меткой рабочего пространства
+type LabelResponse struct {
+	ID          int       `json:"id"`
+	WorkspaceID int       `json:"workspace_id"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color"`
+	CreatedBy   *int      `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// LabelListResponse ответ со списком меток рабочего пространства
+type LabelListResponse struct {
+	Labels []LabelResponse `json:"labels"`
+}
+
+// AddTaskLabelsRequest запрос на добавление меток задаче
+type AddTaskLabelsRequest struct {
+	LabelIDs []int `json:"label_ids" binding:"required,min=1"`
+}
+
+// SetTaskParentRequest запрос на назначение родительской задачи
+type SetTaskParentRequest struct {
+	ParentID int `json:"parent_id" binding:"required,min=1"`
+}
+
+// SubtasksResponse ответ со списком подзадач
+type SubtasksResponse struct {
+	Subtasks []TaskResponse  `json:"subtasks"`
+	Progress SubtaskProgress `json:"progress"`
+}
+
+// AddTaskDependencyRequest запрос на добавление блокирующей задачи
+type AddTaskDependencyRequest struct {
+	BlockedBy int `json:"blocked_by" binding:"required,min=1"`
+}
+
+// TaskLinkResponse ответ с информацией о связанной задаче
+type TaskLinkResponse struct {
+	ID         int       `json:"id"`
+	Title      string    `json:"title"`
+	Status     int       `json:"status"`
+	StatusName string    `json:"status_name"`
+	Resolved   bool      `json:"resolved"`
+	LinkedAt   time.Time `json:"linked_at"`
+}
+
+// TaskDependenciesResponse ответ с зависимостями задачи
+type TaskDependenciesResponse struct {
+	BlockedBy []TaskLinkResponse `json:"blocked_by"`
+	Blocks    []TaskLinkResponse `json:"blocks"`
+	Blocked   bool               `json:"blocked"`
 }
 
 // TaskAssigneeResponse ответ с информацией об исполнителе
@@ -76,6 +220,22 @@ type TaskAssigneesResponse struct {
 	Total     int                    `json:"total"`
 }
 
+// TaskWatcherResponse ответ с информацией о наблюдателе задачи
+type TaskWatcherResponse struct {
+	UserID    int       `json:"user_id"`
+	Login     string    `json:"login"`
+	Name      string    `json:"name"`
+	Surname   string    `json:"surname"`
+	WatchedAt time.Time `json:"watched_at"`
+}
+
+// TaskWatchersResponse ответ со списком наблюдателей задачи
+type TaskWatchersResponse struct {
+	Watchers []TaskWatcherResponse `json:"watchers"`
+	Total    int                   `json:"total"`
+	Watching bool                  `json:"watching"` // текущий пользователь наблюдает за задачей
+}
+
 // TaskChatResponse ответ с информацией о чате задачи
 type TaskChatResponse struct {
 	ChatID      int    `json:"chat_id"`
@@ -91,18 +251,496 @@ type TaskChatsResponse struct {
 	Total int                `json:"total"`
 }
 
+// CreateTaskCommentRequest запрос на добавление комментария к задаче
+type CreateTaskCommentRequest struct {
+	Text string `json:"text" binding:"required,min=1,max=4000"`
+}
+
+// UpdateTaskCommentRequest запрос на изменение комментария
+type UpdateTaskCommentRequest struct {
+	Text string `json:"text" binding:"required,min=1,max=4000"`
+}
+
+// TaskCommentMentionResponse упомянутый в комментарии пользователь
+type TaskCommentMentionResponse struct {
+	UserID int    `json:"user_id"`
+	Login  string `json:"login"`
+	Name   string `json:"name"`
+}
+
+// TaskCommentResponse ответ с комментарием к задаче
+type TaskCommentResponse struct {
+	ID         int                          `json:"id"`
+	TaskID     int                          `json:"task_id"`
+	AuthorID   *int                         `json:"author_id,omitempty"`
+	AuthorName *string                      `json:"author_name,omitempty"`
+	Text       string                       `json:"text"`
+	Mentions   []TaskCommentMentionResponse `json:"mentions"`
+	CreatedAt  time.Time                    `json:"created_at"`
+	UpdatedAt  *time.Time                   `json:"updated_at,omitempty"`
+}
+
+// TaskCommentsResponse ответ со списком комментариев задачи
+type TaskCommentsResponse struct {
+	Comments []TaskCommentResponse `json:"comments"`
+	Total    int                   `json:"total"`
+	Limit    int                   `json:"limit"`
+	Offset   int                   `json:"offset"`
+}
+
 // TaskChangeResponse ответ с информацией об изменении задачи
 type TaskChangeResponse struct {
-	ID          int       `json:"id"`
-	Description string    `json:"description"`
-	TaskID      int       `json:"task_id"`
-	ChangedAt   time.Time `json:"changed_at"`
+	ID          int        `json:"id"`
+	TaskID      int        `json:"task_id"`
+	ActorID     *int       `json:"actor_id,omitempty"`
+	ActorName   *string    `json:"actor_name,omitempty"`
+	Field       string     `json:"field"`
+	OldValue    *string    `json:"old_value,omitempty"`
+	NewValue    *string    `json:"new_value,omitempty"`
+	Description string     `json:"description"`
+	Source      string     `json:"source"`
+	ChangedAt   *time.Time `json:"changed_at,omitempty"`
 }
 
 // TaskHistoryResponse ответ с историей изменений задачи
 type TaskHistoryResponse struct {
 	Changes []TaskChangeResponse `json:"changes"`
 	Total   int                  `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+}
+
+// WorkflowStatusRequest статус задачи в workflow
+type WorkflowStatusRequest struct {
+	Code      int    `json:"code" binding:"required,min=1"`
+	Name      string `json:"name" binding:"required,min=1,max=50"`
+	Category  string `json:"category" binding:"required,oneof=open in_progress done cancelled"`
+	IsInitial bool   `json:"is_initial"`
+}
+
+// WorkflowTransitionRequest допустимый переход между статусами.
+// Пустой allowed_roles означает, что переход доступен любому участнику РП
+type WorkflowTransitionRequest struct {
+	From         int      `json:"from" binding:"required,min=1"`
+	To           int      `json:"to" binding:"required,min=1"`
+	AllowedRoles []string `json:"allowed_roles,omitempty"`
+}
+
+// UpdateWorkflowRequest запрос на замену workflow рабочего пространства
+type UpdateWorkflowRequest struct {
+	Statuses    []WorkflowStatusRequest     `json:"statuses" binding:"required,min=1,dive"`
+	Transitions []WorkflowTransitionRequest `json:"transitions" binding:"dive"`
+}
+
+// WorkflowStatusResponse ответ со статусом задачи
+type WorkflowStatusResponse struct {
+	Code      int    `json:"code"`
+	Name      string `json:"name"`
+	Category  string `json:"category"`
+	IsInitial bool   `json:"is_initial"`
+	Position  int    `json:"position"`
+}
+
+// WorkflowTransitionResponse ответ с переходом между статусами
+type WorkflowTransitionResponse struct {
+	From         int      `json:"from"`
+	To           int      `json:"to"`
+	AllowedRoles []string `json:"allowed_roles"`
+}
+
+// WorkflowResponse ответ с workflow рабочего пространства
+type WorkflowResponse struct {
+	WorkspaceID int                          `json:"workspace_id"`
+	IsDefault   bool                         `json:"is_default"`
+	Statuses    []WorkflowStatusResponse     `json:"statuses"`
+	Transitions []WorkflowTransitionResponse `json:"transitions"`
+}
+
+// RecurrenceRequest правило повторения задачи: либо поля frequency/interval/weekdays/month_day,
+// либо строка rrule (подмножество RRULE: FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL)
+type RecurrenceRequest struct {
+	Frequency string   `json:"frequency,omitempty"` // daily, weekly, monthly
+	Interval  int      `json:"interval,omitempty"`
+	Weekdays  []string `json:"weekdays,omitempty"`  // MO, TU, WE, TH, FR, SA, SU
+	MonthDay  int      `json:"month_day,omitempty"` // 1..31, -1 — последний день месяца
+	Count     int      `json:"count,omitempty"`
+	Until     *string  `json:"until,omitempty"` // YYYY-MM-DD
+	RRule     string   `json:"rrule,omitempty"`
+	Mode      string   `json:"mode,omitempty"` // on_complete (по умолчанию) или schedule
+}
+
+// UpdateTaskSeriesRequest запрос на изменение серии повторяющихся задач
+type UpdateTaskSeriesRequest struct {
+	Recurrence  *RecurrenceRequest `json:"recurrence,omitempty"`
+	Title       *string            `json:"title,omitempty" binding:"omitempty,min=3,max=100"`
+	Description *string            `json:"description,omitempty"`
+}
+
+// TaskSeriesResponse ответ с информацией о серии повторяющихся задач
+type TaskSeriesResponse struct {
+	ID          int            `json:"id"`
+	WorkspaceID int            `json:"workspace_id"`
+	Creator     *int           `json:"creator,omitempty"`
+	Rule        string         `json:"rule"`
+	Mode        string         `json:"mode"`
+	StartDate   time.Time      `json:"start_date"`
+	Occurrences int            `json:"occurrences"`
+	Active      bool           `json:"active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   *time.Time     `json:"updated_at,omitempty"`
+	CancelledAt *time.Time     `json:"cancelled_at,omitempty"`
+	Tasks       []TaskResponse `json:"tasks"`
+}
+
+// CancelTaskSeriesResponse ответ на отмену серии повторяющихся задач
+type CancelTaskSeriesResponse struct {
+	Message      string `json:"message"`
+	DeletedTasks int    `json:"deleted_tasks"`
+}
+
+// TaskTemplateRequest запрос на создание или замену шаблона задачи.
+// Title и Description шаблона и подзадач могут содержать переменные {{name}}
+type TaskTemplateRequest struct {
+	Name            string                       `json:"name" binding:"required,min=1,max=100"`
+	Title           string                       `json:"title" binding:"required,min=3,max=200"`
+	Description     *string                      `json:"description,omitempty"`
+	Priority        string                       `json:"priority,omitempty" binding:"omitempty,oneof=low normal high critical"`
+	EstimateMinutes *int                         `json:"estimate_minutes,omitempty" binding:"omitempty,min=1"`
+	AssigneeIDs     []int                        `json:"assignee_ids,omitempty" binding:"max=20"`
+	LabelIDs        []int                        `json:"label_ids,omitempty" binding:"max=20"`
+	Subtasks        []TaskTemplateSubtaskRequest `json:"subtasks,omitempty" binding:"max=50,dive"`
+}
+
+// TaskTemplateSubtaskRequest подзадача шаблона. DueOffsetDays смещает срок относительно основной задачи
+type TaskTemplateSubtaskRequest struct {
+	Title           string  `json:"title" binding:"required,min=3,max=200"`
+	Description     *string `json:"description,omitempty"`
+	Priority        string  `json:"priority,omitempty" binding:"omitempty,oneof=low normal high critical"`
+	EstimateMinutes *int    `json:"estimate_minutes,omitempty" binding:"omitempty,min=1"`
+	DueOffsetDays   int     `json:"due_offset_days,omitempty" binding:"min=-365,max=365"`
+	AssigneeIDs     []int   `json:"assignee_ids,omitempty" binding:"max=20"`
+	LabelIDs        []int   `json:"label_ids,omitempty" binding:"max=20"`
+}
+
+// TaskTemplateResponse ответ с шаблоном задачи
+type TaskTemplateResponse struct {
+	ID              int                           `json:"id"`
+	WorkspaceID     int                           `json:"workspace_id"`
+	Name            string                        `json:"name"`
+	Title           string                        `json:"title"`
+	Description     *string                       `json:"description,omitempty"`
+	Priority        string                        `json:"priority"`
+	EstimateMinutes *int                          `json:"estimate_minutes,omitempty"`
+	AssigneeIDs     []int                         `json:"assignee_ids"`
+	LabelIDs        []int                         `json:"label_ids"`
+	Variables       []string                      `json:"variables"` // переменные, требуемые при создании задачи
+	Subtasks        []TaskTemplateSubtaskResponse `json:"subtasks,omitempty"`
+	CreatedBy       *int                          `json:"created_by,omitempty"`
+	CreatedAt       time.Time                     `json:"created_at"`
+	UpdatedAt       time.Time                     `json:"updated_at"`
+}
+
+// TaskTemplateSubtaskResponse подзадача шаблона в ответе
+type TaskTemplateSubtaskResponse struct {
+	Title           string  `json:"title"`
+	Description     *string `json:"description,omitempty"`
+	Priority        string  `json:"priority"`
+	EstimateMinutes *int    `json:"estimate_minutes,omitempty"`
+	DueOffsetDays   int     `json:"due_offset_days"`
+	AssigneeIDs     []int   `json:"assignee_ids"`
+	LabelIDs        []int   `json:"label_ids"`
+}
+
+// TaskTemplateListResponse ответ со списком шаблонов задач рабочего пространства
+type TaskTemplateListResponse struct {
+	Templates []TaskTemplateResponse `json:"templates"`
+}
+
+// InstantiateTemplateRequest запрос на создание задачи с подзадачами по шаблону
+type InstantiateTemplateRequest struct {
+	Date      string            `json:"date" binding:"required"` // срок основной задачи, YYYY-MM-DD
+	Variables map[string]string `json:"variables,omitempty"`     // значения переменных {{name}}
+	ChatID    *int              `json:"chat_id,omitempty"`       // чат, к которому прикрепляется основная задача
+}
+
+// InstantiateTemplateResponse ответ с задачами, созданными по шаблону
+type InstantiateTemplateResponse struct {
+	Task     TaskResponse   `json:"task"`
+	Subtasks []TaskResponse `json:"subtasks"`
+}
+
+// TaskViewFilter фильтр сохраненного представления; поля соответствуют параметрам GET /tasks
+type TaskViewFilter struct {
+	Statuses   []int    `json:"statuses,omitempty" binding:"max=20,dive,min=1"`
+	Priorities []string `json:"priorities,omitempty" binding:"max=4,dive,oneof=low normal high critical"`
+	LabelIDs   []int    `json:"label_ids,omitempty" binding:"max=20,dive,min=1"`
+	AssigneeID *int     `json:"assignee_id,omitempty" binding:"omitempty,min=1"`
+	CreatorID  *int     `json:"creator_id,omitempty" binding:"omitempty,min=1"`
+	ChatID     *int     `json:"chat_id,omitempty" binding:"omitempty,min=1"`
+	Overdue    *bool    `json:"overdue,omitempty"`
+	DueFrom    *string  `json:"due_from,omitempty"` // YYYY-MM-DD
+	DueTo      *string  `json:"due_to,omitempty"`   // YYYY-MM-DD
+	Search     string   `json:"q,omitempty" binding:"max=100"`
+}
+
+// TaskViewRequest запрос на сохранение представления списка задач
+type TaskViewRequest struct {
+	Name     string         `json:"name" binding:"required,min=1,max=100"`
+	IsShared bool           `json:"is_shared"`
+	Filter   TaskViewFilter `json:"filter"`
+	Sort     string         `json:"sort,omitempty"`                                                     // как параметр sort в GET /tasks, по умолчанию -date
+	GroupBy  *string        `json:"group_by,omitempty" binding:"omitempty,oneof=status priority label"` // группировка результатов
+}
+
+// TaskViewResponse ответ с сохраненным представлением списка задач
+type TaskViewResponse struct {
+	ID          int            `json:"id"`
+	WorkspaceID int            `json:"workspace_id"`
+	OwnerID     int            `json:"owner_id"`
+	OwnerName   string         `json:"owner_name"`
+	Name        string         `json:"name"`
+	IsShared    bool           `json:"is_shared"`
+	Filter      TaskViewFilter `json:"filter"`
+	Sort        string         `json:"sort"`
+	GroupBy     *string        `json:"group_by,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TaskViewListResponse ответ со списком представлений, доступных пользователю
+type TaskViewListResponse struct {
+	Views []TaskViewResponse `json:"views"`
+}
+
+// TaskGroupResponse группа задач результатов представления
+type TaskGroupResponse struct {
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Count   int    `json:"count"`    // задач группы во всем представлении
+	TaskIDs []int  `json:"task_ids"` // задачи группы на текущей странице
+}
+
+// TaskViewResultsResponse страница задач, отобранных сохраненным представлением
+type TaskViewResultsResponse struct {
+	View       TaskViewResponse    `json:"view"`
+	Tasks      []TaskResponse      `json:"tasks"`
+	Groups     []TaskGroupResponse `json:"groups,omitempty"`
+	Total      int                 `json:"total"`
+	HasMore    bool                `json:"has_more"`
+	NextCursor *string             `json:"next_cursor,omitempty"`
+}
+
+// UpdateReminderSettingsRequest запрос на изменение настроек напоминаний о сроках задач
+type UpdateReminderSettingsRequest struct {
+	OffsetDays    []int `json:"offset_days" binding:"max=5,dive,min=0,max=30"` // за сколько дней до срока напоминать
+	NotifyOverdue *bool `json:"notify_overdue" binding:"required"`
+}
+
+// ReminderSettingsResponse ответ с настройками напоминаний о сроках задач
+type ReminderSettingsResponse struct {
+	WorkspaceID   int        `json:"workspace_id"`
+	OffsetDays    []int      `json:"offset_days"`
+	NotifyOverdue bool       `json:"notify_overdue"`
+	IsDefault     bool       `json:"is_default"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
+// CreateCalendarFeedRequest запрос на создание календарной ленты.
+// Без workspace_id лента содержит задачи всех рабочих пространств пользователя
+type CreateCalendarFeedRequest struct {
+	WorkspaceID *int `json:"workspace_id,omitempty"`
+}
+
+// CalendarFeedResponse ответ с календарной лентой
+type CalendarFeedResponse struct {
+	ID             int        `json:"id"`
+	WorkspaceID    *int       `json:"workspace_id,omitempty"`
+	WorkspaceName  *string    `json:"workspace_name,omitempty"`
+	URL            string     `json:"url,omitempty"` // путь ленты относительно адреса API, только при создании
+	CreatedAt      time.Time  `json:"created_at"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+}
+
+// CalendarFeedListResponse ответ со списком календарных лент пользователя
+type CalendarFeedListResponse struct {
+	Feeds []CalendarFeedResponse `json:"feeds"`
+}
+
+// TaskTransferRecord задача в JSON-формате импорта и экспорта. Статус задается названием или кодом,
+// исполнители — логинами участников рабочего пространства
+type TaskTransferRecord struct {
+	Title       string   `json:"title"`
+	Description *string  `json:"description,omitempty"`
+	Date        string   `json:"date"` // YYYY-MM-DD
+	Status      string   `json:"status,omitempty"`
+	Assignees   []string `json:"assignees"`
+}
+
+// ImportTaskRowResult результат проверки и импорта строки
+type ImportTaskRowResult struct {
+	Row    int      `json:"row"` // номер строки данных, начиная с 1
+	Title  string   `json:"title"`
+	TaskID *int     `json:"task_id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportTasksResponse отчет об импорте задач
+type ImportTasksResponse struct {
+	WorkspaceID    int                   `json:"workspace_id"`
+	DryRun         bool                  `json:"dry_run"`
+	Applied        bool                  `json:"applied"` // задачи созданы
+	Total          int                   `json:"total"`
+	Valid          int                   `json:"valid"`
+	Invalid        int                   `json:"invalid"`
+	IgnoredColumns []string              `json:"ignored_columns,omitempty"`
+	Rows           []ImportTaskRowResult `json:"rows"`
+}
+
+// BulkTaskRequest запрос на массовую операцию над задачами рабочего пространства.
+// Удаление не совмещается с другими изменениями
+type BulkTaskRequest struct {
+	WorkspaceID     int   `json:"workspace_id" binding:"required"`
+	TaskIDs         []int `json:"task_ids" binding:"required,min=1,max=100,dive,min=1"`
+	Status          *int  `json:"status,omitempty"`
+	AddAssignees    []int `json:"add_assignees,omitempty" binding:"max=20"`
+	RemoveAssignees []int `json:"remove_assignees,omitempty" binding:"max=20"`
+	AddLabels       []int `json:"add_labels,omitempty" binding:"max=20"`
+	RemoveLabels    []int `json:"remove_labels,omitempty" binding:"max=20"`
+	Delete          bool  `json:"delete,omitempty"`
+	// Atomic отменяет операцию целиком, если ее нельзя применить хотя бы к одной задаче
+	Atomic bool `json:"atomic,omitempty"`
+}
+
+// BulkTaskResult результат массовой операции для задачи
+type BulkTaskResult struct {
+	TaskID  int    `json:"task_id"`
+	Result  string `json:"result"`            // updated, unchanged, deleted, failed или skipped
+	Changes int    `json:"changes,omitempty"` // количество записей истории
+	Code    int    `json:"code,omitempty"`    // HTTP-код ошибки для failed
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkTaskResponse ответ на массовую операцию над задачами
+type BulkTaskResponse struct {
+	Applied bool             `json:"applied"`
+	Updated int              `json:"updated"`
+	Failed  int              `json:"failed"`
+	Results []BulkTaskResult `json:"results"`
+}
+
+// StartTimerRequest запрос на запуск таймера учета времени
+type StartTimerRequest struct {
+	Note *string `json:"note,omitempty" binding:"omitempty,max=500"`
+}
+
+// CreateTimeEntryRequest запрос на добавление записи учета времени вручную
+type CreateTimeEntryRequest struct {
+	StartedAt time.Time `json:"started_at" binding:"required"` // RFC 3339
+	Minutes   int       `json:"minutes" binding:"required,min=1,max=1440"`
+	Note      *string   `json:"note,omitempty" binding:"omitempty,max=500"`
+}
+
+// TimeEntryResponse ответ с записью учета времени
+type TimeEntryResponse struct {
+	ID        int        `json:"id"`
+	TaskID    int        `json:"task_id"`
+	UserID    int        `json:"user_id"`
+	UserName  string     `json:"user_name"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Minutes   int        `json:"minutes"`
+	Running   bool       `json:"running"`
+	Note      *string    `json:"note,omitempty"`
+}
+
+// TimeEntriesResponse ответ с записями учета времени задачи
+type TimeEntriesResponse struct {
+	TaskID          int                 `json:"task_id"`
+	EstimateMinutes *int                `json:"estimate_minutes,omitempty"`
+	LoggedMinutes   int                 `json:"logged_minutes"`
+	Entries         []TimeEntryResponse `json:"entries"`
+}
+
+// TimeReportRowResponse учтенное время пользователя по задаче
+type TimeReportRowResponse struct {
+	UserID    int    `json:"user_id"`
+	UserName  string `json:"user_name"`
+	TaskID    int    `json:"task_id"`
+	TaskTitle string `json:"task_title"`
+	Entries   int    `json:"entries"`
+	Minutes   int    `json:"minutes"`
+}
+
+// TimeReportResponse отчет по учтенному времени рабочего пространства
+type TimeReportResponse struct {
+	WorkspaceID  int                     `json:"workspace_id"`
+	From         *string                 `json:"from,omitempty"`
+	To           *string                 `json:"to,omitempty"`
+	TotalMinutes int                     `json:"total_minutes"`
+	Rows         []TimeReportRowResponse `json:"rows"`
+}
+
+// StatusTimeResponse время, проведенное задачами в статусе
+type StatusTimeResponse struct {
+	Status       int     `json:"status"`
+	StatusName   string  `json:"status_name"`
+	Category     string  `json:"category"`
+	Transitions  int     `json:"transitions"`
+	AvgHours     float64 `json:"avg_hours"`
+	CurrentTasks int     `json:"current_tasks"`
+}
+
+// CycleTimeResponse время в статусах и время выполнения задач рабочего пространства за период
+type CycleTimeResponse struct {
+	WorkspaceID    int                  `json:"workspace_id"`
+	LabelID        *int                 `json:"label_id,omitempty"`
+	From           string               `json:"from"`
+	To             string               `json:"to"`
+	Statuses       []StatusTimeResponse `json:"statuses"`
+	CompletedTasks int                  `json:"completed_tasks"`
+	AvgCycleHours  *float64             `json:"avg_cycle_hours"`
+	AvgLeadHours   *float64             `json:"avg_lead_hours"`
+	GeneratedAt    time.Time            `json:"generated_at"`
+}
+
+// ThroughputWeekResponse созданные и закрытые за неделю задачи
+type ThroughputWeekResponse struct {
+	WeekStart string `json:"week_start"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+	Cancelled int    `json:"cancelled"`
+}
+
+// ThroughputResponse пропускная способность рабочего пространства по неделям
+type ThroughputResponse struct {
+	WorkspaceID    int                      `json:"workspace_id"`
+	LabelID        *int                     `json:"label_id,omitempty"`
+	From           string                   `json:"from"`
+	To             string                   `json:"to"`
+	Weeks          []ThroughputWeekResponse `json:"weeks"`
+	TotalCreated   int                      `json:"total_created"`
+	TotalCompleted int                      `json:"total_completed"`
+	TotalCancelled int                      `json:"total_cancelled"`
+	GeneratedAt    time.Time                `json:"generated_at"`
+}
+
+// BurndownDayResponse состояние задач на конец дня
+type BurndownDayResponse struct {
+	Date      string  `json:"date"`
+	Remaining int     `json:"remaining"`
+	Ideal     float64 `json:"ideal"`
+	Created   int     `json:"created"`
+	Completed int     `json:"completed"`
+}
+
+// BurndownResponse burndown рабочего пространства за период
+type BurndownResponse struct {
+	WorkspaceID int                   `json:"workspace_id"`
+	LabelID     *int                  `json:"label_id,omitempty"`
+	From        string                `json:"from"`
+	To          string                `json:"to"`
+	Days        []BurndownDayResponse `json:"days"`
+	GeneratedAt time.Time             `json:"generated_at"`
 }
 
 // ErrorResponse ответ с ошибкой
@@ -110,6 +748,12 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// VersionConflictResponse ответ на обновление устаревшей версии ресурса
+type VersionConflictResponse struct {
+	Error   string      `json:"error"`
+	Current interface{} `json:"current"` // текущее состояние ресурса
+}
+
 // SuccessResponse успешный ответ
 type SuccessResponse struct {
 	Message string      `json:"message"`
@@ -70,6 +70,95 @@ Support Team`,
 	return e.sendEmail(event.UserEmail, subject, body)
 }
 
+// SendChatMessageNotification отправляет уведомление о новом сообщении в чате
+func (e *EmailService) SendChatMessageNotification(event kafka.ChatMessageNotificationEvent) error {
+	subject := fmt.Sprintf("New message in %s", event.ChatName)
+	if event.Mentioned {
+		subject = fmt.Sprintf("%s mentioned you in %s", event.AuthorName, event.ChatName)
+	}
+
+	body := fmt.Sprintf(`Hello %s,
+
+%s wrote in %s:
+
+%s
+
+Date: %s
+
+You can change notification settings for this chat or set up a do-not-disturb schedule in the messenger.
+
+Best regards,
+Messenger Team`,
+		event.RecipientName,
+		event.AuthorName,
+		event.ChatName,
+		event.Text,
+		event.SentAt,
+	)
+
+	return e.sendEmail(event.RecipientEmail, subject, body)
+}
+
+// SendTaskCommentNotification отправляет уведомление о комментарии к задаче
+func (e *EmailService) SendTaskCommentNotification(event kafka.TaskCommentNotificationEvent) error {
+	subject := fmt.Sprintf("New comment on task %s", event.TaskTitle)
+	if event.Mentioned {
+		subject = fmt.Sprintf("%s mentioned you in task %s", event.AuthorName, event.TaskTitle)
+	}
+
+	body := fmt.Sprintf(`Hello %s,
+
+%s commented on task "%s":
+
+%s
+
+Date: %s
+
+Best regards,
+Messenger Team`,
+		event.RecipientName,
+		event.AuthorName,
+		event.TaskTitle,
+		event.Text,
+		event.CreatedAt,
+	)
+
+	return e.sendEmail(event.RecipientEmail, subject, body)
+}
+
+// SendTaskDueReminder отправляет напоминание о сроке задачи
+func (e *EmailService) SendTaskDueReminder(event kafka.TaskDueReminderEvent) error {
+	var subject, when string
+	switch {
+	case event.Overdue:
+		subject = fmt.Sprintf("Task %s is overdue", event.TaskTitle)
+		when = fmt.Sprintf("was due on %s", event.DueDate)
+	case event.DaysLeft == 0:
+		subject = fmt.Sprintf("Task %s is due today", event.TaskTitle)
+		when = "is due today"
+	default:
+		subject = fmt.Sprintf("Task %s is due in %d day(s)", event.TaskTitle, event.DaysLeft)
+		when = fmt.Sprintf("is due on %s", event.DueDate)
+	}
+
+	body := fmt.Sprintf(`Hello %s,
+
+Task "%s" in workspace "%s" %s.
+
+Current status: %s
+
+Best regards,
+Messenger Team`,
+		event.RecipientName,
+		event.TaskTitle,
+		event.WorkspaceName,
+		when,
+		event.StatusName,
+	)
+
+	return e.sendEmail(event.RecipientEmail, subject, body)
+}
+
 // sendEmail отправляет email через SMTP
 func (e *EmailService) sendEmail(to, subject, body string) error {
 	if e.smtpHost == "" || e.smtpUser == "" {
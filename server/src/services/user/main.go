@@ -93,11 +93,74 @@ func main() {
 				return nil
 			}
 
-			// Подписываемся на топик
+			// Обработчик уведомлений о новых сообщениях в чатах
+			chatNotificationHandler := func(topic string, message []byte) error {
+				var event kafka.ChatMessageNotificationEvent
+				if err := json.Unmarshal(message, &event); err != nil {
+					log.Printf("Failed to unmarshal chat message notification event: %v", err)
+					return err
+				}
+
+				log.Printf("Processing chat message notification for user %d in chat %d", event.RecipientID, event.ChatID)
+
+				if err := emailService.SendChatMessageNotification(event); err != nil {
+					log.Printf("Failed to send chat notification email: %v", err)
+					return err
+				}
+
+				return nil
+			}
+
+			// Обработчик уведомлений о комментариях к задачам
+			taskCommentHandler := func(topic string, message []byte) error {
+				var event kafka.TaskCommentNotificationEvent
+				if err := json.Unmarshal(message, &event); err != nil {
+					log.Printf("Failed to unmarshal task comment notification event: %v", err)
+					return err
+				}
+
+				log.Printf("Processing task comment notification for user %d on task %d", event.RecipientID, event.TaskID)
+
+				if err := emailService.SendTaskCommentNotification(event); err != nil {
+					log.Printf("Failed to send task comment email: %v", err)
+					return err
+				}
+
+				return nil
+			}
+
+			// Обработчик напоминаний о сроках задач
+			taskReminderHandler := func(topic string, message []byte) error {
+				var event kafka.TaskDueReminderEvent
+				if err := json.Unmarshal(message, &event); err != nil {
+					log.Printf("Failed to unmarshal task due reminder event: %v", err)
+					return err
+				}
+
+				log.Printf("Processing task due reminder for user %d on task %d", event.RecipientID, event.TaskID)
+
+				if err := emailService.SendTaskDueReminder(event); err != nil {
+					log.Printf("Failed to send task reminder email: %v", err)
+					return err
+				}
+
+				return nil
+			}
+
+			// Подписываемся на топики
 			go func() {
 				if err := kafkaConsumer.Subscribe(kafka.TopicComplaintStatusChanged, messageHandler); err != nil {
 					log.Printf("Failed to subscribe to Kafka topic: %v", err)
 				}
+				if err := kafkaConsumer.Subscribe(kafka.TopicChatMessageNotification, chatNotificationHandler); err != nil {
+					log.Printf("Failed to subscribe to Kafka topic: %v", err)
+				}
+				if err := kafkaConsumer.Subscribe(kafka.TopicTaskCommentNotification, taskCommentHandler); err != nil {
+					log.Printf("Failed to subscribe to Kafka topic: %v", err)
+				}
+				if err := kafkaConsumer.Subscribe(kafka.TopicTaskDueReminder, taskReminderHandler); err != nil {
+					log.Printf("Failed to subscribe to Kafka topic: %v", err)
+				}
 			}()
 
 			defer kafkaConsumer.Close()
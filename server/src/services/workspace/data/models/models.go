@@ -22,6 +22,7 @@ type WorkspaceWithDetails struct {
 	ChatsCount   int       `db:"chats_count"`
 	TasksCount   int       `db:"tasks_count"`
 	CreatedAt    time.Time `db:"created_at"`
+	Version      int       `db:"version"` // увеличивается при каждом изменении РП
 }
 
 // UserWorkspace представляет РП пользователя с его ролью
@@ -54,7 +55,29 @@ type WorkspaceMember struct {
 
 // Tariff представляет тарифный план
 type Tariff struct {
-	ID          int    `db:"id"`
-	Name        string `db:"name"`
-	Description string `db:"description"`
+	ID                   int    `db:"id"`
+	Name                 string `db:"name"`
+	Description          string `db:"description"`
+	MessageRetentionDays *int   `db:"message_retention_days"` // nil — хранить бессрочно
+	RetentionAction      string `db:"retention_action"`       // delete или anonymize
+}
+
+// Действия с сообщениями по истечении срока хранения
+const (
+	RetentionActionDelete    = "delete"
+	RetentionActionAnonymize = "anonymize"
+)
+
+// RetentionPolicy представляет политику хранения сообщений РП.
+// Пустые поля переопределения означают, что используется значение из тарифа
+type RetentionPolicy struct {
+	WorkspaceID           int        `db:"workspacesid"`
+	RetentionDays         *int       `db:"retention_days"`
+	RetentionAction       *string    `db:"retention_action"`
+	LegalHold             bool       `db:"legal_hold"`
+	LegalHoldReason       *string    `db:"legal_hold_reason"`
+	UpdatedBy             *int       `db:"updated_by"`
+	UpdatedAt             *time.Time `db:"updated_at"`
+	TariffRetentionDays   *int       `db:"tariff_retention_days"`
+	TariffRetentionAction string     `db:"tariff_retention_action"`
 }
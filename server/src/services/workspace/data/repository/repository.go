@@ -57,7 +57,8 @@ func (r *Repository) GetWorkspaceByID(ctx context.Context, workspaceID int) (*mo
 			COALESCE((SELECT COUNT(*) FROM "userinworkspace" WHERE workspacesid = w.id), 0) as members_count,
 			COALESCE((SELECT COUNT(*) FROM chats WHERE workspacesid = w.id), 0) as chats_count,
 			COALESCE((SELECT COUNT(*) FROM tasks WHERE workspacesid = w.id), 0) as tasks_count,
-			NOW() as created_at
+			NOW() as created_at,
+			w.version
 		FROM workspaces w
 		LEFT JOIN tariffs t ON w.tariffsid = t.id
 		WHERE w.id = $1
@@ -75,6 +76,7 @@ func (r *Repository) GetWorkspaceByID(ctx context.Context, workspaceID int) (*mo
 		&workspace.ChatsCount,
 		&workspace.TasksCount,
 		&workspace.CreatedAt,
+		&workspace.Version,
 	)
 
 	if err != nil {
@@ -134,7 +136,8 @@ func (r *Repository) GetAllWorkspaces(ctx context.Context) ([]models.WorkspaceWi
 			COALESCE((SELECT COUNT(*) FROM "userinworkspace" WHERE workspacesid = w.id), 0) as members_count,
 			COALESCE((SELECT COUNT(*) FROM chats WHERE workspacesid = w.id), 0) as chats_count,
 			COALESCE((SELECT COUNT(*) FROM tasks WHERE workspacesid = w.id), 0) as tasks_count,
-			NOW() as created_at
+			NOW() as created_at,
+			w.version
 		FROM workspaces w
 		LEFT JOIN tariffs t ON w.tariffsid = t.id
 		ORDER BY w.id
@@ -160,6 +163,7 @@ func (r *Repository) GetAllWorkspaces(ctx context.Context) ([]models.WorkspaceWi
 			&workspace.ChatsCount,
 			&workspace.TasksCount,
 			&workspace.CreatedAt,
+			&workspace.Version,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan workspace: %w", err)
@@ -170,24 +174,29 @@ func (r *Repository) GetAllWorkspaces(ctx context.Context) ([]models.WorkspaceWi
 	return workspaces, nil
 }
 
-// UpdateWorkspace обновляет параметры рабочего пространства
-func (r *Repository) UpdateWorkspace(ctx context.Context, workspaceID int, name string, tariffID int) error {
+// UpdateWorkspace обновляет параметры рабочего пространства версии version и возвращает новую версию.
+// Версия 0 не проверяется. Если РП успело измениться, возвращает ошибку "workspace version changed"
+func (r *Repository) UpdateWorkspace(ctx context.Context, workspaceID, version int, name string, tariffID int) (int, error) {
 	query := `
 		UPDATE workspaces
-		SET name = $1, tariffsid = $2
-		WHERE id = $3
+		SET name = $1, tariffsid = $2, version = version + 1
+		WHERE id = $3 AND ($4::INT4 = 0 OR version = $4::INT4)
+		RETURNING version
 	`
 
-	result, err := r.db.Pool.Exec(ctx, query, name, tariffID, workspaceID)
+	var newVersion int
+	err := r.db.Pool.QueryRow(ctx, query, name, tariffID, workspaceID, version).Scan(&newVersion)
 	if err != nil {
-		return fmt.Errorf("failed to update workspace: %w", err)
-	}
-
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("workspace not found")
+		if err == pgx.ErrNoRows {
+			if version == 0 {
+				return 0, fmt.Errorf("workspace not found")
+			}
+			return 0, fmt.Errorf("workspace version changed")
+		}
+		return 0, fmt.Errorf("failed to update workspace: %w", err)
 	}
 
-	return nil
+	return newVersion, nil
 }
 
 // DeleteWorkspace удаляет рабочее пространство
@@ -448,9 +457,27 @@ func (r *Repository) ChangeLeader(ctx context.Context, workspaceID, oldLeaderID,
 
 // ========== Tariff Operations ==========
 
+const tariffColumns = `id, name, description, message_retention_days, retention_action`
+
+// scanTariff сканирует строку тарифа
+func scanTariff(row pgx.Row) (*models.Tariff, error) {
+	var tariff models.Tariff
+	err := row.Scan(
+		&tariff.ID,
+		&tariff.Name,
+		&tariff.Description,
+		&tariff.MessageRetentionDays,
+		&tariff.RetentionAction,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &tariff, nil
+}
+
 // GetAllTariffs получает список всех тарифов
 func (r *Repository) GetAllTariffs(ctx context.Context) ([]models.Tariff, error) {
-	query := `SELECT id, name, description FROM tariffs ORDER BY id`
+	query := `SELECT ` + tariffColumns + ` FROM tariffs ORDER BY id`
 
 	rows, err := r.db.Pool.Query(ctx, query)
 	if err != nil {
@@ -460,45 +487,36 @@ func (r *Repository) GetAllTariffs(ctx context.Context) ([]models.Tariff, error)
 
 	var tariffs []models.Tariff
 	for rows.Next() {
-		var tariff models.Tariff
-		err := rows.Scan(&tariff.ID, &tariff.Name, &tariff.Description)
+		tariff, err := scanTariff(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tariff: %w", err)
 		}
-		tariffs = append(tariffs, tariff)
+		tariffs = append(tariffs, *tariff)
 	}
 
 	return tariffs, nil
 }
 
 // CreateTariff создает новый тариф
-func (r *Repository) CreateTariff(ctx context.Context, name, description string) (*models.Tariff, error) {
+func (r *Repository) CreateTariff(ctx context.Context, name, description string, retentionDays *int, retentionAction string) (*models.Tariff, error) {
 	query := `
-		INSERT INTO tariffs (name, description)
-		VALUES ($1, $2)
-		RETURNING id, name, description
-	`
-
-	var tariff models.Tariff
-	err := r.db.Pool.QueryRow(ctx, query, name, description).Scan(
-		&tariff.ID,
-		&tariff.Name,
-		&tariff.Description,
-	)
+		INSERT INTO tariffs (name, description, message_retention_days, retention_action)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + tariffColumns
 
+	tariff, err := scanTariff(r.db.Pool.QueryRow(ctx, query, name, description, retentionDays, retentionAction))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tariff: %w", err)
 	}
 
-	return &tariff, nil
+	return tariff, nil
 }
 
 // GetTariffByID получает тариф по идентификатору
 func (r *Repository) GetTariffByID(ctx context.Context, tariffID int) (*models.Tariff, error) {
-	query := `SELECT id, name, description FROM tariffs WHERE id = $1`
+	query := `SELECT ` + tariffColumns + ` FROM tariffs WHERE id = $1`
 
-	var tariff models.Tariff
-	err := r.db.Pool.QueryRow(ctx, query, tariffID).Scan(&tariff.ID, &tariff.Name, &tariff.Description)
+	tariff, err := scanTariff(r.db.Pool.QueryRow(ctx, query, tariffID))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("tariff not found")
@@ -506,25 +524,18 @@ func (r *Repository) GetTariffByID(ctx context.Context, tariffID int) (*models.T
 		return nil, fmt.Errorf("failed to get tariff: %w", err)
 	}
 
-	return &tariff, nil
+	return tariff, nil
 }
 
 // UpdateTariff обновляет тариф
-func (r *Repository) UpdateTariff(ctx context.Context, tariffID int, name, description string) (*models.Tariff, error) {
+func (r *Repository) UpdateTariff(ctx context.Context, tariffID int, name, description string, retentionDays *int, retentionAction string) (*models.Tariff, error) {
 	query := `
 		UPDATE tariffs
-		SET name = $1, description = $2
-		WHERE id = $3
-		RETURNING id, name, description
-	`
-
-	var tariff models.Tariff
-	err := r.db.Pool.QueryRow(ctx, query, name, description, tariffID).Scan(
-		&tariff.ID,
-		&tariff.Name,
-		&tariff.Description,
-	)
+		SET name = $1, description = $2, message_retention_days = $3, retention_action = $4
+		WHERE id = $5
+		RETURNING ` + tariffColumns
 
+	tariff, err := scanTariff(r.db.Pool.QueryRow(ctx, query, name, description, retentionDays, retentionAction, tariffID))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("tariff not found")
@@ -532,7 +543,7 @@ func (r *Repository) UpdateTariff(ctx context.Context, tariffID int, name, descr
 		return nil, fmt.Errorf("failed to update tariff: %w", err)
 	}
 
-	return &tariff, nil
+	return tariff, nil
 }
 
 // TariffExists проверяет существование тарифа
@@ -573,3 +584,77 @@ func (r *Repository) AdminExists(ctx context.Context, adminID int) (bool, error)
 
 	return exists, nil
 }
+
+// ========== Retention Policy Operations ==========
+
+// GetRetentionPolicy получает политику хранения сообщений РП вместе со значениями тарифа по умолчанию
+func (r *Repository) GetRetentionPolicy(ctx context.Context, workspaceID int) (*models.RetentionPolicy, error) {
+	query := `
+		SELECT w.id, p.retention_days, p.retention_action,
+		       COALESCE(p.legal_hold, FALSE), p.legal_hold_reason, p.updated_by, p.updated_at,
+		       t.message_retention_days, COALESCE(t.retention_action, 'delete')
+		FROM workspaces w
+		LEFT JOIN tariffs t ON t.id = w.tariffsid
+		LEFT JOIN workspace_retention_policies p ON p.workspacesid = w.id
+		WHERE w.id = $1
+	`
+
+	var policy models.RetentionPolicy
+	err := r.db.Pool.QueryRow(ctx, query, workspaceID).Scan(
+		&policy.WorkspaceID,
+		&policy.RetentionDays,
+		&policy.RetentionAction,
+		&policy.LegalHold,
+		&policy.LegalHoldReason,
+		&policy.UpdatedBy,
+		&policy.UpdatedAt,
+		&policy.TariffRetentionDays,
+		&policy.TariffRetentionAction,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("workspace not found")
+		}
+		return nil, fmt.Errorf("failed to get retention policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// UpsertRetentionPolicy сохраняет переопределение срока хранения сообщений РП
+func (r *Repository) UpsertRetentionPolicy(ctx context.Context, workspaceID, updatedBy int, retentionDays *int, retentionAction *string) error {
+	query := `
+		INSERT INTO workspace_retention_policies (workspacesid, retention_days, retention_action, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (workspacesid) DO UPDATE
+		SET retention_days = EXCLUDED.retention_days,
+		    retention_action = EXCLUDED.retention_action,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = NOW()
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, workspaceID, retentionDays, retentionAction, updatedBy); err != nil {
+		return fmt.Errorf("failed to save retention policy: %w", err)
+	}
+
+	return nil
+}
+
+// SetWorkspaceLegalHold включает или снимает legal hold для РП
+func (r *Repository) SetWorkspaceLegalHold(ctx context.Context, workspaceID, updatedBy int, legalHold bool, reason *string) error {
+	query := `
+		INSERT INTO workspace_retention_policies (workspacesid, legal_hold, legal_hold_reason, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (workspacesid) DO UPDATE
+		SET legal_hold = EXCLUDED.legal_hold,
+		    legal_hold_reason = EXCLUDED.legal_hold_reason,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = NOW()
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, workspaceID, legalHold, reason, updatedBy); err != nil {
+		return fmt.Errorf("failed to set legal hold: %w", err)
+	}
+
+	return nil
+}
@@ -4,9 +4,13 @@ go 1.23
 
 toolchain go1.23.4
 
-replace github.com/diploma/shared/metrics => ./shared/metrics
+replace (
+	github.com/diploma/shared/etag => ./shared/etag
+	github.com/diploma/shared/metrics => ./shared/metrics
+)
 
 require (
+	github.com/diploma/shared/etag v0.0.0
 	github.com/diploma/shared/metrics v0.0.0
 	github.com/gin-gonic/gin v1.10.0
 	github.com/jackc/pgx/v5 v5.5.0
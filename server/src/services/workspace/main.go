@@ -59,12 +59,13 @@ func main() {
 	// Создаем обработчики
 	workspaceHandler := handlers.NewWorkspaceHandler(repo)
 	tariffHandler := handlers.NewTariffHandler(repo)
+	retentionHandler := handlers.NewRetentionHandler(repo)
 
 	// Создаем метрики
 	serviceMetrics := metrics.NewServiceMetrics("workspace-service")
 
 	// Настраиваем роутер
-	router := setupRouter(workspaceHandler, tariffHandler, serviceMetrics)
+	router := setupRouter(workspaceHandler, tariffHandler, retentionHandler, serviceMetrics)
 
 	// Создаем HTTP сервер
 	srv := &http.Server{
@@ -97,7 +98,7 @@ func main() {
 	log.Println("Server exited")
 }
 
-func setupRouter(workspaceHandler *handlers.WorkspaceHandler, tariffHandler *handlers.TariffHandler, serviceMetrics *metrics.ServiceMetrics) *gin.Engine {
+func setupRouter(workspaceHandler *handlers.WorkspaceHandler, tariffHandler *handlers.TariffHandler, retentionHandler *handlers.RetentionHandler, serviceMetrics *metrics.ServiceMetrics) *gin.Engine {
 	router := gin.Default()
 
 	// Swagger документация
@@ -137,6 +138,11 @@ func setupRouter(workspaceHandler *handlers.WorkspaceHandler, tariffHandler *han
 
 		// Смена руководителя
 		api.PUT("/:id/leader", workspaceHandler.ChangeLeader)
+
+		// Политика хранения сообщений
+		api.GET("/:id/retention", retentionHandler.GetRetentionPolicy)
+		api.PUT("/:id/retention", retentionHandler.UpdateRetentionPolicy)
+		api.PUT("/:id/legal-hold", retentionHandler.UpdateLegalHold)
 	}
 
 	// Health check
@@ -151,7 +157,8 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-User-ID, X-User-Role, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-User-ID, X-User-Role, accept, origin, Cache-Control, X-Requested-With, If-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
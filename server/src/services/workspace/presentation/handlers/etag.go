@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/diploma/shared/etag"
+	"github.com/diploma/workspace-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+// respondWorkspaceConflict отвечает 412 с текущим состоянием РП
+func (h *WorkspaceHandler) respondWorkspaceConflict(c *gin.Context, workspaceID int) {
+	workspace, err := h.repo.GetWorkspaceByID(c.Request.Context(), workspaceID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "workspace not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workspace"})
+		return
+	}
+	etag.RespondConflict(c, "workspace was modified by another user", workspace.Version, toWorkspaceDetailsResponse(workspace))
+}
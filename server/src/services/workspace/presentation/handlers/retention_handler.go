@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	dbmodels "github.com/diploma/workspace-service/data/models"
+	"github.com/diploma/workspace-service/data/repository"
+	"github.com/diploma/workspace-service/presentation/models"
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionHandler struct {
+	repo *repository.Repository
+}
+
+func NewRetentionHandler(repo *repository.Repository) *RetentionHandler {
+	return &RetentionHandler{repo: repo}
+}
+
+// GetRetentionPolicy godoc
+// @Summary Получить политику хранения сообщений
+// @Description Возвращает действующий срок хранения сообщений РП, его источник (workspace, tariff или none — бессрочно) и состояние legal hold (руководитель РП или администратор)
+// @Tags retention
+// @Produce json
+// @Param id path int true "ID рабочего пространства"
+// @Success 200 {object} models.RetentionPolicyResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /workspaces/{id}/retention [get]
+func (h *RetentionHandler) GetRetentionPolicy(c *gin.Context) {
+	workspaceID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	h.respondWithPolicy(c, workspaceID)
+}
+
+// UpdateRetentionPolicy godoc
+// @Summary Изменить срок хранения сообщений
+// @Description Переопределяет срок хранения сообщений и действие по его истечении (delete / anonymize) для РП. Пустые поля возвращают значения тарифа (руководитель РП или администратор)
+// @Tags retention
+// @Accept json
+// @Produce json
+// @Param id path int true "ID рабочего пространства"
+// @Param request body models.UpdateRetentionPolicyRequest true "Срок хранения"
+// @Success 200 {object} models.RetentionPolicyResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /workspaces/{id}/retention [put]
+func (h *RetentionHandler) UpdateRetentionPolicy(c *gin.Context) {
+	workspaceID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	userID, _ := getUserID(c)
+	if err := h.repo.UpsertRetentionPolicy(c.Request.Context(), workspaceID, userID, req.RetentionDays, req.RetentionAction); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update retention policy"})
+		return
+	}
+
+	h.respondWithPolicy(c, workspaceID)
+}
+
+// UpdateLegalHold godoc
+// @Summary Включить или снять legal hold
+// @Description Приостанавливает (или возобновляет) очистку сообщений и удаление чатов РП (руководитель РП или администратор)
+// @Tags retention
+// @Accept json
+// @Produce json
+// @Param id path int true "ID рабочего пространства"
+// @Param request body models.UpdateLegalHoldRequest true "Состояние legal hold"
+// @Success 200 {object} models.RetentionPolicyResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Security BearerAuth
+// @Router /workspaces/{id}/legal-hold [put]
+func (h *RetentionHandler) UpdateLegalHold(c *gin.Context) {
+	workspaceID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	reason := req.Reason
+	if !*req.LegalHold {
+		reason = nil
+	}
+
+	userID, _ := getUserID(c)
+	if err := h.repo.SetWorkspaceLegalHold(c.Request.Context(), workspaceID, userID, *req.LegalHold, reason); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to update legal hold"})
+		return
+	}
+
+	h.respondWithPolicy(c, workspaceID)
+}
+
+// authorize проверяет, что пользователь — руководитель РП или администратор, и возвращает ID РП
+func (h *RetentionHandler) authorize(c *gin.Context) (int, bool) {
+	userID, err := getUserID(c)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return 0, false
+	}
+
+	workspaceID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid workspace id"})
+		return 0, false
+	}
+
+	if isAdmin(c) {
+		return workspaceID, true
+	}
+
+	role, err := h.repo.GetUserRoleInWorkspace(c.Request.Context(), userID, workspaceID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not a member") {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "user is not a member of this workspace"})
+			return 0, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to check user role"})
+		return 0, false
+	}
+	if role != 2 {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "insufficient permissions"})
+		return 0, false
+	}
+
+	return workspaceID, true
+}
+
+// respondWithPolicy возвращает действующую политику хранения РП
+func (h *RetentionHandler) respondWithPolicy(c *gin.Context, workspaceID int) {
+	policy, err := h.repo.GetRetentionPolicy(c.Request.Context(), workspaceID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "workspace not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get retention policy"})
+		return
+	}
+
+	response := models.RetentionPolicyResponse{
+		WorkspaceID:           policy.WorkspaceID,
+		RetentionDays:         policy.TariffRetentionDays,
+		RetentionAction:       policy.TariffRetentionAction,
+		Source:                "tariff",
+		OverrideDays:          policy.RetentionDays,
+		OverrideAction:        policy.RetentionAction,
+		TariffRetentionDays:   policy.TariffRetentionDays,
+		TariffRetentionAction: policy.TariffRetentionAction,
+		LegalHold:             policy.LegalHold,
+		LegalHoldReason:       policy.LegalHoldReason,
+		UpdatedBy:             policy.UpdatedBy,
+	}
+
+	if policy.RetentionDays != nil {
+		response.RetentionDays = policy.RetentionDays
+		response.Source = "workspace"
+	}
+	if policy.RetentionAction != nil {
+		response.RetentionAction = *policy.RetentionAction
+	}
+	if response.RetentionDays == nil {
+		response.Source = "none"
+	}
+	if response.RetentionAction == "" {
+		response.RetentionAction = dbmodels.RetentionActionDelete
+	}
+	if policy.UpdatedAt != nil {
+		response.UpdatedAt = policy.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
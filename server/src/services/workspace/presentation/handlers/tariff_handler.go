@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	dbmodels "github.com/diploma/workspace-service/data/models"
 	"github.com/diploma/workspace-service/data/repository"
 	"github.com/diploma/workspace-service/presentation/models"
 	"github.com/gin-gonic/gin"
@@ -40,9 +41,11 @@ func (h *TariffHandler) GetTariffs(c *gin.Context) {
 
 	for _, tariff := range tariffs {
 		response.Tariffs = append(response.Tariffs, models.TariffResponse{
-			ID:          tariff.ID,
-			Name:        tariff.Name,
-			Description: tariff.Description,
+			ID:                   tariff.ID,
+			Name:                 tariff.Name,
+			Description:          tariff.Description,
+			MessageRetentionDays: tariff.MessageRetentionDays,
+			RetentionAction:      tariff.RetentionAction,
 		})
 	}
 
@@ -82,7 +85,7 @@ func (h *TariffHandler) CreateTariff(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
-	tariff, err := h.repo.CreateTariff(ctx, req.Name, req.Description)
+	tariff, err := h.repo.CreateTariff(ctx, req.Name, req.Description, req.MessageRetentionDays, retentionActionOrDefault(req.RetentionAction))
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique") {
 			c.JSON(http.StatusConflict, models.ErrorResponse{Error: "tariff with this name already exists"})
@@ -93,9 +96,11 @@ func (h *TariffHandler) CreateTariff(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, models.TariffResponse{
-		ID:          tariff.ID,
-		Name:        tariff.Name,
-		Description: tariff.Description,
+		ID:                   tariff.ID,
+		Name:                 tariff.Name,
+		Description:          tariff.Description,
+		MessageRetentionDays: tariff.MessageRetentionDays,
+		RetentionAction:      tariff.RetentionAction,
 	})
 }
 
@@ -139,7 +144,7 @@ func (h *TariffHandler) UpdateTariff(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
-	tariff, err := h.repo.UpdateTariff(ctx, tariffID, req.Name, req.Description)
+	tariff, err := h.repo.UpdateTariff(ctx, tariffID, req.Name, req.Description, req.MessageRetentionDays, retentionActionOrDefault(req.RetentionAction))
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "tariff not found"})
@@ -150,8 +155,18 @@ func (h *TariffHandler) UpdateTariff(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, models.TariffResponse{
-		ID:          tariff.ID,
-		Name:        tariff.Name,
-		Description: tariff.Description,
+		ID:                   tariff.ID,
+		Name:                 tariff.Name,
+		Description:          tariff.Description,
+		MessageRetentionDays: tariff.MessageRetentionDays,
+		RetentionAction:      tariff.RetentionAction,
 	})
 }
+
+// retentionActionOrDefault возвращает действие по истечении срока хранения, по умолчанию — удаление
+func retentionActionOrDefault(action string) string {
+	if action == "" {
+		return dbmodels.RetentionActionDelete
+	}
+	return action
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/diploma/shared/etag"
+	dbmodels "github.com/diploma/workspace-service/data/models"
 	"github.com/diploma/workspace-service/data/repository"
 	"github.com/diploma/workspace-service/presentation/models"
 	"github.com/gin-gonic/gin"
@@ -327,7 +329,13 @@ func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, models.WorkspaceDetailsResponse{
+	etag.Set(c, workspace.Version)
+	c.JSON(http.StatusOK, toWorkspaceDetailsResponse(workspace))
+}
+
+// toWorkspaceDetailsResponse преобразует РП с дополнительной информацией в ответ API
+func toWorkspaceDetailsResponse(workspace *dbmodels.WorkspaceWithDetails) models.WorkspaceDetailsResponse {
+	return models.WorkspaceDetailsResponse{
 		ID:        workspace.ID,
 		Name:      workspace.Name,
 		Creator:   workspace.Creator,
@@ -340,16 +348,18 @@ func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
 		MembersCount: workspace.MembersCount,
 		ChatsCount:   workspace.ChatsCount,
 		TasksCount:   workspace.TasksCount,
-	})
+		Version:      workspace.Version,
+	}
 }
 
 // UpdateWorkspace godoc
 // @Summary Обновить РП
-// @Description Обновляет параметры рабочего пространства (только руководитель)
+// @Description Обновляет параметры рабочего пространства (только руководитель). Заголовок If-Match должен содержать ETag текущей версии РП
 // @Tags workspaces
 // @Accept json
 // @Produce json
 // @Param id path int true "ID рабочего пространства"
+// @Param If-Match header string true "ETag версии РП или *"
 // @Param request body models.UpdateWorkspaceRequest true "Новые данные РП"
 // @Success 200 {object} models.WorkspaceResponse
 // @Failure 400 {object} models.ErrorResponse
@@ -357,6 +367,8 @@ func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
 // @Failure 403 {object} models.ErrorResponse
 // @Failure 404 {object} models.ErrorResponse
 // @Failure 409 {object} models.ErrorResponse
+// @Failure 412 {object} models.VersionConflictResponse
+// @Failure 428 {object} models.ErrorResponse
 // @Security BearerAuth
 // @Router /workspaces/{id} [put]
 func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
@@ -395,6 +407,26 @@ func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
 		return
 	}
 
+	workspace, err := h.repo.GetWorkspaceByID(ctx, workspaceID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "workspace not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "failed to get workspace"})
+		return
+	}
+
+	ifMatch, ok := etag.IfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	if !etag.Matches(ifMatch, workspace.Version) {
+		etag.RespondConflict(c, "workspace was modified by another user", workspace.Version, toWorkspaceDetailsResponse(workspace))
+		return
+	}
+
 	// Проверяем существование тарифа
 	tariffExists, err := h.repo.TariffExists(ctx, req.TariffID)
 	if err != nil {
@@ -418,9 +450,13 @@ func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
 	}
 
 	// Обновляем РП
-	err = h.repo.UpdateWorkspace(ctx, workspaceID, req.Name, req.TariffID)
+	version, err := h.repo.UpdateWorkspace(ctx, workspaceID, etag.ExpectedVersion(ifMatch, workspace.Version), req.Name, req.TariffID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if err.Error() == "workspace version changed" {
+			h.respondWorkspaceConflict(c, workspaceID)
+			return
+		}
+		if err.Error() == "workspace not found" {
 			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "workspace not found"})
 			return
 		}
@@ -438,9 +474,11 @@ func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
 		return
 	}
 
+	etag.Set(c, version)
 	c.JSON(http.StatusOK, models.WorkspaceResponse{
 		ID:        workspaceID,
 		Name:      req.Name,
+		Creator:   workspace.Creator,
 		TariffsID: req.TariffID,
 		TariffID:  req.TariffID,
 		Tariff: &models.TariffInfo{
@@ -448,6 +486,7 @@ func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
 			Name:        tariff.Name,
 			Description: tariff.Description,
 		},
+		Version: version,
 	})
 }
 
@@ -31,14 +31,18 @@ type ChangeLeaderRequest struct {
 
 // CreateTariffRequest запрос на создание тарифа
 type CreateTariffRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Name                 string `json:"name" binding:"required"`
+	Description          string `json:"description" binding:"required"`
+	MessageRetentionDays *int   `json:"message_retention_days" binding:"omitempty,min=1"`
+	RetentionAction      string `json:"retention_action" binding:"omitempty,oneof=delete anonymize"`
 }
 
 // UpdateTariffRequest запрос на обновление тарифа
 type UpdateTariffRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Name                 string `json:"name" binding:"required"`
+	Description          string `json:"description" binding:"required"`
+	MessageRetentionDays *int   `json:"message_retention_days" binding:"omitempty,min=1"`
+	RetentionAction      string `json:"retention_action" binding:"omitempty,oneof=delete anonymize"`
 }
 
 // WorkspaceResponse ответ с информацией о РП
@@ -50,6 +54,7 @@ type WorkspaceResponse struct {
 	TariffID  int         `json:"tariff_id,omitempty"`
 	Tariff    *TariffInfo `json:"tariff,omitempty"`
 	CreatedAt string      `json:"created_at,omitempty"`
+	Version   int         `json:"version,omitempty"` // совпадает со значением ETag
 }
 
 // WorkspaceDetailsResponse детальная информация о РП
@@ -62,6 +67,7 @@ type WorkspaceDetailsResponse struct {
 	ChatsCount   int        `json:"chats_count"`
 	TasksCount   int        `json:"tasks_count"`
 	CreatedAt    string     `json:"created_at,omitempty"`
+	Version      int        `json:"version"` // совпадает со значением ETag
 }
 
 // TariffInfo информация о тарифе
@@ -148,9 +154,11 @@ type LeaderChangedResponse struct {
 
 // TariffResponse информация о тарифе
 type TariffResponse struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID                   int    `json:"id"`
+	Name                 string `json:"name"`
+	Description          string `json:"description"`
+	MessageRetentionDays *int   `json:"message_retention_days"`
+	RetentionAction      string `json:"retention_action"`
 }
 
 // TariffsResponse список тарифов
@@ -158,7 +166,42 @@ type TariffsResponse struct {
 	Tariffs []TariffResponse `json:"tariffs"`
 }
 
+// UpdateRetentionPolicyRequest запрос на изменение срока хранения сообщений РП.
+// Пустые поля сбрасывают переопределение к значениям тарифа
+type UpdateRetentionPolicyRequest struct {
+	RetentionDays   *int    `json:"retention_days" binding:"omitempty,min=1"`
+	RetentionAction *string `json:"retention_action" binding:"omitempty,oneof=delete anonymize"`
+}
+
+// UpdateLegalHoldRequest запрос на включение или снятие legal hold
+type UpdateLegalHoldRequest struct {
+	LegalHold *bool   `json:"legal_hold" binding:"required"`
+	Reason    *string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// RetentionPolicyResponse политика хранения сообщений РП
+type RetentionPolicyResponse struct {
+	WorkspaceID           int     `json:"workspace_id"`
+	RetentionDays         *int    `json:"retention_days"`
+	RetentionAction       string  `json:"retention_action"`
+	Source                string  `json:"source"`
+	OverrideDays          *int    `json:"override_days"`
+	OverrideAction        *string `json:"override_action"`
+	TariffRetentionDays   *int    `json:"tariff_retention_days"`
+	TariffRetentionAction string  `json:"tariff_retention_action"`
+	LegalHold             bool    `json:"legal_hold"`
+	LegalHoldReason       *string `json:"legal_hold_reason,omitempty"`
+	UpdatedBy             *int    `json:"updated_by,omitempty"`
+	UpdatedAt             string  `json:"updated_at,omitempty"`
+}
+
 // ErrorResponse ответ с ошибкой
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+// VersionConflictResponse ответ на обновление устаревшей версии ресурса
+type VersionConflictResponse struct {
+	Error   string      `json:"error"`
+	Current interface{} `json:"current"` // текущее состояние ресурса
+}
@@ -0,0 +1,55 @@
+package dnd
+
+import "time"
+
+// Schedule расписание режима «не беспокоить» пользователя. Расписание настраивается в chat-service,
+// а уведомления по нему фильтруют все сервисы, которые их рассылают
+type Schedule struct {
+	Enabled   bool
+	StartTime string // HH:MM
+	EndTime   string // HH:MM
+	Timezone  string // IANA, например Europe/Moscow
+}
+
+// ParseClock разбирает время в формате HH:MM в минуты от начала суток
+func ParseClock(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// ActiveAt проверяет, действует ли режим «не беспокоить» в момент now.
+// Время начала и окончания задается в часовом поясе расписания (неизвестный пояс — UTC),
+// интервал может переходить через полночь (например, 22:00–08:00). Начало входит в интервал,
+// окончание — нет; одинаковые начало и окончание означают, что режим не действует
+func (s *Schedule) ActiveAt(now time.Time) bool {
+	if s == nil || !s.Enabled {
+		return false
+	}
+
+	start, ok := ParseClock(s.StartTime)
+	if !ok {
+		return false
+	}
+	end, ok := ParseClock(s.EndTime)
+	if !ok {
+		return false
+	}
+	if start == end {
+		return false
+	}
+
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	current := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return current >= start && current < end
+	}
+	return current >= start || current < end
+}
@@ -0,0 +1,37 @@
+package dnd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActiveAt(t *testing.T) {
+	// 2026-10-18 21:30 UTC — 00:30 следующего дня по Москве (UTC+3)
+	now := time.Date(2026, time.October, 18, 21, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule *Schedule
+		want     bool
+	}{
+		{name: "nil schedule", schedule: nil, want: false},
+		{name: "disabled", schedule: &Schedule{Enabled: false, StartTime: "21:00", EndTime: "22:00", Timezone: "UTC"}, want: false},
+		{name: "inside daytime window", schedule: &Schedule{Enabled: true, StartTime: "21:00", EndTime: "22:00", Timezone: "UTC"}, want: true},
+		{name: "start is inclusive", schedule: &Schedule{Enabled: true, StartTime: "21:30", EndTime: "22:00", Timezone: "UTC"}, want: true},
+		{name: "end is exclusive", schedule: &Schedule{Enabled: true, StartTime: "20:00", EndTime: "21:30", Timezone: "UTC"}, want: false},
+		{name: "overnight before midnight", schedule: &Schedule{Enabled: true, StartTime: "21:00", EndTime: "08:00", Timezone: "UTC"}, want: true},
+		{name: "overnight after midnight in time zone", schedule: &Schedule{Enabled: true, StartTime: "23:00", EndTime: "08:00", Timezone: "Europe/Moscow"}, want: true},
+		{name: "outside overnight window in time zone", schedule: &Schedule{Enabled: true, StartTime: "01:00", EndTime: "08:00", Timezone: "Europe/Moscow"}, want: false},
+		{name: "unknown time zone falls back to UTC", schedule: &Schedule{Enabled: true, StartTime: "21:00", EndTime: "22:00", Timezone: "Mars/Olympus"}, want: true},
+		{name: "equal start and end", schedule: &Schedule{Enabled: true, StartTime: "21:00", EndTime: "21:00", Timezone: "UTC"}, want: false},
+		{name: "invalid time", schedule: &Schedule{Enabled: true, StartTime: "9pm", EndTime: "22:00", Timezone: "UTC"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.ActiveAt(now); got != tt.want {
+				t.Errorf("ActiveAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
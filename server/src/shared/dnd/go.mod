@@ -0,0 +1,3 @@
+module github.com/diploma/shared/dnd
+
+go 1.21
@@ -0,0 +1,86 @@
+package etag
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Format возвращает ETag для версии ресурса
+func Format(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Set записывает в ответ ETag версии ресурса
+func Set(c *gin.Context, version int) {
+	c.Header("ETag", Format(version))
+}
+
+// IfMatchHeader возвращает значение заголовка If-Match. Если заголовка нет, отвечает 428
+func IfMatchHeader(c *gin.Context) (string, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return "", false
+	}
+	return header, true
+}
+
+// tags разбирает список ETag из заголовка If-Match, разделенный запятыми.
+// Запятая внутри кавычек не разделяет значения
+func tags(header string) []string {
+	var result []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(header); i++ {
+		switch header[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				result = append(result, strings.TrimSpace(header[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(result, strings.TrimSpace(header[start:]))
+}
+
+// isAny проверяет, что If-Match содержит "*" — подходит любая версия ресурса
+func isAny(header string) bool {
+	for _, tag := range tags(header) {
+		if tag == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches проверяет, что заголовок If-Match содержит ETag версии ресурса или "*".
+// If-Match использует строгое сравнение (RFC 9110), поэтому слабые ETag (W/"3") не подходят
+func Matches(header string, version int) bool {
+	etag := Format(version)
+	for _, tag := range tags(header) {
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpectedVersion возвращает версию, которую должен проверить репозиторий при записи.
+// Для If-Match: * версия не проверяется (0)
+func ExpectedVersion(header string, version int) int {
+	if isAny(header) {
+		return 0
+	}
+	return version
+}
+
+// RespondConflict отвечает 412 с текущим состоянием ресурса в поле current и его ETag
+func RespondConflict(c *gin.Context, message string, version int, current interface{}) {
+	Set(c, version)
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": message, "current": current})
+}
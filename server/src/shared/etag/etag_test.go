@@ -0,0 +1,101 @@
+package etag
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "same version", header: `"3"`, want: true},
+		{name: "other version", header: `"2"`, want: false},
+		{name: "any version", header: `*`, want: true},
+		{name: "list with current version", header: `"1", "3"`, want: true},
+		{name: "list without current version", header: `"1","2"`, want: false},
+		{name: "list with any", header: `"1", *`, want: true},
+		{name: "weak validator", header: `W/"3"`, want: false},
+		{name: "unquoted version", header: `3`, want: false},
+		{name: "comma inside quotes", header: `"3,4"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.header, 3); got != tt.want {
+				t.Errorf("Matches(%q, 3) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectedVersion(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{header: `"3"`, want: 3},
+		{header: `*`, want: 0},
+		{header: `"2", *`, want: 0},
+		{header: `"*"`, want: 3},
+	}
+
+	for _, tt := range tests {
+		if got := ExpectedVersion(tt.header, 3); got != tt.want {
+			t.Errorf("ExpectedVersion(%q, 3) = %d, want %d", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestIfMatchHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+	if _, ok := IfMatchHeader(c); ok {
+		t.Fatal("IfMatchHeader() without header = true, want false")
+	}
+	if recorder.Code != http.StatusPreconditionRequired {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusPreconditionRequired)
+	}
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+	c.Request.Header.Set("If-Match", ` "7" `)
+	if header, ok := IfMatchHeader(c); !ok || header != `"7"` {
+		t.Errorf("IfMatchHeader() = %q, %v, want %q, true", header, ok, `"7"`)
+	}
+}
+
+func TestRespondConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	RespondConflict(c, "task was modified by another user", 5, map[string]int{"id": 1})
+
+	if recorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusPreconditionFailed)
+	}
+	if got := recorder.Header().Get("ETag"); got != `"5"` {
+		t.Errorf("ETag = %q, want %q", got, `"5"`)
+	}
+
+	var body struct {
+		Error   string         `json:"error"`
+		Current map[string]int `json:"current"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON body: %v", err)
+	}
+	if body.Error != "task was modified by another user" || body.Current["id"] != 1 {
+		t.Errorf("body = %+v, want error message and current resource", body)
+	}
+}
@@ -15,9 +15,100 @@ type ComplaintStatusChangedEvent struct {
 	ChangedAt         string `json:"changed_at"`
 }
 
+// ChatMessageNotificationEvent событие уведомления участника чата о новом сообщении
+type ChatMessageNotificationEvent struct {
+	ChatID         int    `json:"chat_id"`
+	ChatName       string `json:"chat_name"`
+	MessageID      int    `json:"message_id"`
+	AuthorID       int    `json:"author_id"`
+	AuthorName     string `json:"author_name"`
+	Text           string `json:"text"`
+	RecipientID    int    `json:"recipient_id"`
+	RecipientEmail string `json:"recipient_email"`
+	RecipientName  string `json:"recipient_name"`
+	Mentioned      bool   `json:"mentioned"`
+	SentAt         string `json:"sent_at"`
+}
+
+// TaskCommentNotificationEvent событие уведомления участника задачи о новом комментарии
+type TaskCommentNotificationEvent struct {
+	TaskID         int    `json:"task_id"`
+	TaskTitle      string `json:"task_title"`
+	WorkspaceID    int    `json:"workspace_id"`
+	CommentID      int    `json:"comment_id"`
+	AuthorID       int    `json:"author_id"`
+	AuthorName     string `json:"author_name"`
+	Text           string `json:"text"`
+	RecipientID    int    `json:"recipient_id"`
+	RecipientEmail string `json:"recipient_email"`
+	RecipientName  string `json:"recipient_name"`
+	Mentioned      bool   `json:"mentioned"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// TaskDueReminderEvent событие напоминания исполнителю о приближающемся или прошедшем сроке задачи
+type TaskDueReminderEvent struct {
+	TaskID         int    `json:"task_id"`
+	TaskTitle      string `json:"task_title"`
+	WorkspaceID    int    `json:"workspace_id"`
+	WorkspaceName  string `json:"workspace_name"`
+	StatusName     string `json:"status_name"`
+	DueDate        string `json:"due_date"`
+	DaysLeft       int    `json:"days_left"`
+	Overdue        bool   `json:"overdue"`
+	RecipientID    int    `json:"recipient_id"`
+	RecipientEmail string `json:"recipient_email"`
+	RecipientName  string `json:"recipient_name"`
+	SentAt         string `json:"sent_at"`
+}
+
+// TaskCreatedFromMessageEvent событие создания задачи из сообщения чата
+type TaskCreatedFromMessageEvent struct {
+	TaskID      int    `json:"task_id"`
+	TaskTitle   string `json:"task_title"`
+	WorkspaceID int    `json:"workspace_id"`
+	ChatID      int    `json:"chat_id"`
+	MessageID   int    `json:"message_id"`
+	ActorID     int    `json:"actor_id"`
+	ActorName   string `json:"actor_name"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// TaskChangedEvent событие изменения задачи для обновления клиентов в реальном времени.
+// Для удаленной задачи заполнены только идентификаторы, чаты и автор изменения
+type TaskChangedEvent struct {
+	Type        string `json:"type"` // created, updated, status_changed, assignees_changed, deleted
+	TaskID      int    `json:"task_id"`
+	WorkspaceID int    `json:"workspace_id"`
+	ChatIDs     []int  `json:"chat_ids"`
+	ActorID     int    `json:"actor_id"`
+	Title       string `json:"title,omitempty"`
+	Status      int    `json:"status,omitempty"`
+	StatusName  string `json:"status_name,omitempty"`
+	Priority    string `json:"priority,omitempty"`
+	DueDate     string `json:"due_date,omitempty"`
+	AssigneeIDs []int  `json:"assignee_ids,omitempty"`
+	WatcherIDs  []int  `json:"watcher_ids,omitempty"`
+	ChangedAt   string `json:"changed_at"`
+}
+
+// Типы событий изменения задачи
+const (
+	TaskEventCreated          = "created"
+	TaskEventUpdated          = "updated"
+	TaskEventStatusChanged    = "status_changed"
+	TaskEventAssigneesChanged = "assignees_changed"
+	TaskEventDeleted          = "deleted"
+)
+
 // Kafka топики
 const (
-	TopicComplaintStatusChanged = "complaints.status.changed"
+	TopicComplaintStatusChanged  = "complaints.status.changed"
+	TopicChatMessageNotification = "chats.message.notification"
+	TopicTaskCommentNotification = "tasks.comment.notification"
+	TopicTaskDueReminder         = "tasks.due.reminder"
+	TopicTaskCreatedFromMessage  = "tasks.created.from.message"
+	TopicTaskChanged             = "tasks.changed"
 )
 
 
@@ -167,9 +167,10 @@ def test_task_and_complaint_flow(
     status_resp = requests.put(
         f"{gateway_url}{task_api_path}/{task_id}/status",
         json={"status": 2},
-        headers=auth_header(leader["access_token"]),
+        headers={**auth_header(leader["access_token"]), "If-Match": f'"{task_body["version"]}"'},
     )
     assert status_resp.status_code == 200
+    assert status_resp.headers["ETag"] == f'"{task_body["version"] + 1}"'
 
     attach_resp = requests.post(
         f"{gateway_url}{task_api_path}/{task_id}/chats",
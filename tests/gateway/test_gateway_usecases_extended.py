@@ -297,7 +297,7 @@ def test_task_assignment_history_and_chat_links(
     upd_task = requests.put(
         f"{gateway_url}{task_api_path}/{task_id}",
         json={"title": "Task updated", "description": "Updated"},
-        headers=auth_header(leader["access_token"]),
+        headers={**auth_header(leader["access_token"]), "If-Match": "*"},
     )
     _assert_ok(upd_task)
 
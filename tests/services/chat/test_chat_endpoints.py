@@ -16,10 +16,20 @@
 - PUT /api/v1/chats/:chat_id/messages/:message_id - Редактировать сообщение
 - DELETE /api/v1/chats/:chat_id/messages/:message_id - Удалить сообщение
 - PUT /api/v1/chats/:id/messages/read - Отметить как прочитанное
+- GET/PUT /api/v1/chats/:id/notifications - Настройки уведомлений в чате
+- GET/PUT/DELETE /api/v1/chats/notifications/dnd - Расписание «не беспокоить»
+- POST/GET /api/v1/chats/:id/invites - Приглашения в чат
+- DELETE /api/v1/chats/:id/invites/:invite_id - Отозвать приглашение
+- GET /api/v1/chats/:id/invites/:invite_id/redemptions - Аудит присоединений
+- POST /api/v1/chats/invites/:code/join - Присоединиться по приглашению
+- PUT /api/v1/chats/:id/legal-hold - Legal hold чата и очистка сообщений по политике хранения
 """
+import os
 import pytest
 import requests
 import time
+from datetime import datetime, timedelta, timezone
+from psycopg2.extras import RealDictCursor
 
 # Константы для тестов
 TEST_USER_ID = 1
@@ -423,7 +433,7 @@ class TestChatUpdate:
         response = requests.put(
             url,
             json=update_data,
-            headers=user_auth_headers
+            headers={**user_auth_headers, "If-Match": "*"}
         )
         
         assert response.status_code == 200
@@ -472,9 +482,10 @@ class TestChatDelete:
     def test_delete_chat_success(
         self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers
     ):
-        """Успешное удаление чата"""
+        """Руководитель РП планирует удаление чата"""
         workspace = workspace_with_members
         leader = workspace["leader"]
+        member = workspace["members"][1]
         
         # Создаем чат
         create_url = f"{chat_service_url}{chat_api_path}"
@@ -482,7 +493,7 @@ class TestChatDelete:
             "name": "Test Chat",
             "type": 2,
             "workspace_id": workspace["workspace_id"],
-            "members": [m["user_id"] for m in workspace["members"][:2]]
+            "members": [TEST_USER_ID, member["user_id"]]
         }
         create_response = requests.post(
             create_url,
@@ -490,22 +501,100 @@ class TestChatDelete:
             headers=user_auth_headers
         )
         chat_id = create_response.json()["id"]
-        
-        # Удаляем чат
         url = f"{chat_service_url}{chat_api_path}/{chat_id}"
+        
+        # Участник, не являющийся руководителем РП, удалить чат не может
+        forbidden_response = requests.delete(
+            url,
+            headers={"Authorization": f"Bearer {member['token']}"}
+        )
+        assert forbidden_response.status_code == 403
+        
+        # Руководитель РП планирует удаление
         response = requests.delete(
             url,
-            headers=user_auth_headers
+            headers={"Authorization": f"Bearer {leader['token']}"}
         )
         
-        assert response.status_code == 204
+        assert response.status_code == 202
+        data = response.json()
+        assert data["chat_id"] == chat_id
+        assert data["delete_scheduled_at"]
         
-        # Проверяем, что чат удален
+        # До окончательного удаления чат доступен и помечен как ожидающий удаления
         get_response = requests.get(
             url,
+            headers={"Authorization": f"Bearer {leader['token']}"}
+        )
+        assert get_response.status_code == 200
+        chat = get_response.json()
+        assert chat["archived"] is True
+        assert chat["delete_scheduled_at"] == data["delete_scheduled_at"]
+        
+        # Повторный запрос не сдвигает дату удаления
+        repeat_response = requests.delete(
+            url,
+            headers={"Authorization": f"Bearer {leader['token']}"}
+        )
+        assert repeat_response.status_code == 202
+        assert repeat_response.json()["delete_scheduled_at"] == data["delete_scheduled_at"]
+
+    def test_delete_chat_purged_after_grace_period(
+        self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers, db_connection
+    ):
+        """Чат окончательно удаляется после истечения срока хранения.
+
+        Требует запуска Chat Service с CHAT_PURGE_INTERVAL_MINUTES=1:
+        тест ждет очередного прохода фоновой очистки не дольше CHAT_PURGE_WAIT_SECONDS
+        """
+        workspace = workspace_with_members
+        leader = workspace["leader"]
+        
+        # Создаем чат
+        create_url = f"{chat_service_url}{chat_api_path}"
+        chat_data = {
+            "name": "Test Chat",
+            "type": 2,
+            "workspace_id": workspace["workspace_id"],
+            "members": [TEST_USER_ID]
+        }
+        create_response = requests.post(
+            create_url,
+            json=chat_data,
             headers=user_auth_headers
         )
-        assert get_response.status_code == 404
+        chat_id = create_response.json()["id"]
+        url = f"{chat_service_url}{chat_api_path}/{chat_id}"
+        
+        response = requests.delete(
+            url,
+            headers={"Authorization": f"Bearer {leader['token']}"}
+        )
+        assert response.status_code == 202
+        
+        # Сдвигаем дату удаления в прошлое, имитируя истечение срока хранения
+        cursor = db_connection.cursor()
+        cursor.execute(
+            "UPDATE chats SET delete_scheduled_at = NOW() - INTERVAL '1 minute' WHERE id = %s",
+            (chat_id,)
+        )
+        db_connection.commit()
+        cursor.close()
+        
+        # Ждем, пока фоновая очистка удалит чат
+        wait_seconds = int(os.getenv("CHAT_PURGE_WAIT_SECONDS", "90"))
+        deadline = time.time() + wait_seconds
+        status_code = None
+        while time.time() < deadline:
+            status_code = requests.get(
+                url,
+                headers={"Authorization": f"Bearer {leader['token']}"}
+            ).status_code
+            if status_code == 404:
+                break
+            time.sleep(5)
+        
+        assert status_code == 404
 
     def test_delete_chat_forbidden(
         self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers
@@ -940,4 +1029,518 @@ class TestMessages:
         assert data["last_read_message_id"] == message_id
 
 
+class TestNotificationSettings:
+    """Тесты настроек уведомлений участника и расписания «не беспокоить»"""
+
+    def test_update_notification_settings_success(
+        self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers
+    ):
+        """Участник переключается на упоминания, заглушает чат и снимает mute"""
+        workspace = workspace_with_members
+        member = workspace["members"][1]
+        member_headers = {"Authorization": f"Bearer {member['token']}"}
+
+        # Создаем чат
+        create_url = f"{chat_service_url}{chat_api_path}"
+        chat_data = {
+            "name": "Test Chat",
+            "type": 2,
+            "workspace_id": workspace["workspace_id"],
+            "members": [TEST_USER_ID, member["user_id"]]
+        }
+        create_response = requests.post(
+            create_url,
+            json=chat_data,
+            headers=user_auth_headers
+        )
+        chat_id = create_response.json()["id"]
+        url = f"{chat_service_url}{chat_api_path}/{chat_id}/notifications"
 
+        # По умолчанию участник получает все сообщения
+        response = requests.get(url, headers=member_headers)
+        assert response.status_code == 200
+        data = response.json()
+        assert data["chat_id"] == chat_id
+        assert data["user_id"] == member["user_id"]
+        assert data["notification_level"] == "all"
+        assert data["muted"] is False
+
+        # Только упоминания и mute на час
+        muted_until = (datetime.now(timezone.utc) + timedelta(hours=1)).strftime("%Y-%m-%dT%H:%M:%SZ")
+        response = requests.put(
+            url,
+            json={"notification_level": "mentions", "muted_until": muted_until},
+            headers=member_headers
+        )
+        assert response.status_code == 200
+        data = response.json()
+        assert data["notification_level"] == "mentions"
+        assert data["muted"] is True
+        assert data["muted_until"] == muted_until
+
+        # Настройки сохраняются только для этого участника
+        get_response = requests.get(url, headers=member_headers)
+        assert get_response.json()["notification_level"] == "mentions"
+        own_response = requests.get(url, headers=user_auth_headers)
+        assert own_response.json()["notification_level"] == "all"
+
+        # Запрос без muted_until снимает mute
+        response = requests.put(
+            url,
+            json={"notification_level": "all"},
+            headers=member_headers
+        )
+        assert response.status_code == 200
+        data = response.json()
+        assert data["notification_level"] == "all"
+        assert data["muted"] is False
+        assert "muted_until" not in data
+
+    def test_update_notification_settings_invalid(
+        self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers
+    ):
+        """Неизвестный уровень уведомлений и невалидный muted_until"""
+        workspace = workspace_with_members
+
+        # Создаем чат
+        create_url = f"{chat_service_url}{chat_api_path}"
+        chat_data = {
+            "name": "Test Chat",
+            "type": 2,
+            "workspace_id": workspace["workspace_id"],
+            "members": [TEST_USER_ID]
+        }
+        create_response = requests.post(
+            create_url,
+            json=chat_data,
+            headers=user_auth_headers
+        )
+        chat_id = create_response.json()["id"]
+        url = f"{chat_service_url}{chat_api_path}/{chat_id}/notifications"
+
+        response = requests.put(
+            url,
+            json={"notification_level": "none"},
+            headers=user_auth_headers
+        )
+        assert response.status_code == 400
+
+        response = requests.put(
+            url,
+            json={"notification_level": "mentions", "muted_until": "tomorrow"},
+            headers=user_auth_headers
+        )
+        assert response.status_code == 400
+
+    def test_notification_settings_not_member(
+        self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers
+    ):
+        """Пользователь не из чата не может читать и менять настройки уведомлений"""
+        workspace = workspace_with_members
+        outsider = workspace["members"][2]
+        outsider_headers = {"Authorization": f"Bearer {outsider['token']}"}
+
+        # Создаем чат без этого пользователя
+        create_url = f"{chat_service_url}{chat_api_path}"
+        chat_data = {
+            "name": "Test Chat",
+            "type": 2,
+            "workspace_id": workspace["workspace_id"],
+            "members": [TEST_USER_ID]
+        }
+        create_response = requests.post(
+            create_url,
+            json=chat_data,
+            headers=user_auth_headers
+        )
+        chat_id = create_response.json()["id"]
+        url = f"{chat_service_url}{chat_api_path}/{chat_id}/notifications"
+
+        assert requests.get(url, headers=outsider_headers).status_code == 403
+        response = requests.put(
+            url,
+            json={"notification_level": "mentions"},
+            headers=outsider_headers
+        )
+        assert response.status_code == 403
+
+    def test_dnd_schedule_lifecycle(
+        self, chat_service_url, chat_api_path, workspace_with_members
+    ):
+        """Создание, чтение и удаление расписания «не беспокоить» через полночь"""
+        member = workspace_with_members["members"][4]
+        member_headers = {"Authorization": f"Bearer {member['token']}"}
+        url = f"{chat_service_url}{chat_api_path}/notifications/dnd"
+
+        requests.delete(url, headers=member_headers)
+        try:
+            assert requests.get(url, headers=member_headers).status_code == 404
+
+            schedule = {
+                "enabled": True,
+                "start_time": "22:00",
+                "end_time": "08:00",
+                "timezone": "Europe/Moscow"
+            }
+            response = requests.put(url, json=schedule, headers=member_headers)
+            assert response.status_code == 200
+            data = response.json()
+            assert data["enabled"] is True
+            assert data["start_time"] == "22:00"
+            assert data["end_time"] == "08:00"
+            assert data["timezone"] == "Europe/Moscow"
+            assert "active" in data
+
+            get_response = requests.get(url, headers=member_headers)
+            assert get_response.status_code == 200
+            assert get_response.json()["start_time"] == "22:00"
+        finally:
+            delete_response = requests.delete(url, headers=member_headers)
+
+        assert delete_response.status_code == 204
+        assert requests.get(url, headers=member_headers).status_code == 404
+
+    def test_dnd_schedule_invalid(
+        self, chat_service_url, chat_api_path, user_auth_headers
+    ):
+        """Невалидное время, совпадающие границы и неизвестный часовой пояс"""
+        url = f"{chat_service_url}{chat_api_path}/notifications/dnd"
+
+        invalid_schedules = [
+            {"enabled": True, "start_time": "25:00", "end_time": "08:00"},
+            {"enabled": True, "start_time": "22:00", "end_time": "8am"},
+            {"enabled": True, "start_time": "22:00", "end_time": "22:00"},
+            {"enabled": True, "start_time": "22:00", "end_time": "08:00", "timezone": "Mars/Olympus"},
+        ]
+        for schedule in invalid_schedules:
+            response = requests.put(url, json=schedule, headers=user_auth_headers)
+            assert response.status_code == 400, schedule
+
+
+class TestChatInvites:
+    """Тесты приглашений в чат"""
+
+    @staticmethod
+    def _create_chat(chat_service_url, chat_api_path, workspace, headers):
+        """Создать групповой чат, в котором состоит только тестовый пользователь"""
+        response = requests.post(
+            f"{chat_service_url}{chat_api_path}",
+            json={
+                "name": "Invite Chat",
+                "type": 2,
+                "workspace_id": workspace["workspace_id"],
+                "members": [TEST_USER_ID]
+            },
+            headers=headers
+        )
+        return response.json()["id"]
+
+    def test_invite_usage_cap(
+        self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers
+    ):
+        """Повторный вход участника не расходует лимит, исчерпанное приглашение возвращает 410"""
+        workspace = workspace_with_members
+        first = workspace["members"][1]
+        second = workspace["members"][2]
+        chat_id = self._create_chat(chat_service_url, chat_api_path, workspace, user_auth_headers)
+        invites_url = f"{chat_service_url}{chat_api_path}/{chat_id}/invites"
+
+        response = requests.post(invites_url, json={"max_uses": 1}, headers=user_auth_headers)
+        assert response.status_code == 201
+        invite = response.json()
+        assert invite["chat_id"] == chat_id
+        assert invite["max_uses"] == 1
+        assert invite["uses"] == 0
+        assert invite["active"] is True
+        join_url = f"{chat_service_url}{chat_api_path}/invites/{invite['code']}/join"
+
+        # Первый пользователь присоединяется
+        response = requests.post(join_url, headers={"Authorization": f"Bearer {first['token']}"})
+        assert response.status_code == 201
+        data = response.json()
+        assert data["chat_id"] == chat_id
+        assert data["role"] == 1
+        assert data["already_member"] is False
+
+        # Повторный переход участника чата не расходует приглашение
+        response = requests.post(join_url, headers={"Authorization": f"Bearer {first['token']}"})
+        assert response.status_code == 200
+        assert response.json()["already_member"] is True
+
+        invites = requests.get(invites_url, headers=user_auth_headers).json()["invites"]
+        current = next(i for i in invites if i["id"] == invite["id"])
+        assert current["uses"] == 1
+        assert current["active"] is False
+
+        # Лимит исчерпан
+        response = requests.post(join_url, headers={"Authorization": f"Bearer {second['token']}"})
+        assert response.status_code == 410
+
+        # В аудите одна запись — о первом присоединении
+        redemptions_url = f"{invites_url}/{invite['id']}/redemptions"
+        response = requests.get(redemptions_url, headers=user_auth_headers)
+        assert response.status_code == 200
+        data = response.json()
+        assert data["total"] == 1
+        assert data["redemptions"][0]["user_id"] == first["user_id"]
+
+    def test_invite_expired(
+        self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers, db_connection
+    ):
+        """Истекшее приглашение возвращает 410, срок в прошлом при создании — 400"""
+        workspace = workspace_with_members
+        member = workspace["members"][3]
+        chat_id = self._create_chat(chat_service_url, chat_api_path, workspace, user_auth_headers)
+        invites_url = f"{chat_service_url}{chat_api_path}/{chat_id}/invites"
+
+        past = (datetime.now(timezone.utc) - timedelta(hours=1)).strftime("%Y-%m-%dT%H:%M:%SZ")
+        response = requests.post(invites_url, json={"expires_at": past}, headers=user_auth_headers)
+        assert response.status_code == 400
+
+        future = (datetime.now(timezone.utc) + timedelta(hours=1)).strftime("%Y-%m-%dT%H:%M:%SZ")
+        response = requests.post(invites_url, json={"expires_at": future}, headers=user_auth_headers)
+        assert response.status_code == 201
+        invite = response.json()
+        assert invite["expires_at"] == future
+
+        # Сдвигаем срок действия в прошлое, имитируя истечение приглашения
+        cursor = db_connection.cursor()
+        cursor.execute(
+            "UPDATE chat_invites SET expires_at = NOW() - INTERVAL '1 day' WHERE id = %s",
+            (invite["id"],)
+        )
+        db_connection.commit()
+        cursor.close()
+
+        join_url = f"{chat_service_url}{chat_api_path}/invites/{invite['code']}/join"
+        response = requests.post(join_url, headers={"Authorization": f"Bearer {member['token']}"})
+        assert response.status_code == 410
+
+        invites = requests.get(invites_url, headers=user_auth_headers).json()["invites"]
+        current = next(i for i in invites if i["id"] == invite["id"])
+        assert current["active"] is False
+        assert current["uses"] == 0
+
+    def test_invite_revoke(
+        self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers
+    ):
+        """Отзывать приглашение может только администратор чата, отозванное приглашение возвращает 410"""
+        workspace = workspace_with_members
+        member = workspace["members"][4]
+        member_headers = {"Authorization": f"Bearer {member['token']}"}
+        chat_id = self._create_chat(chat_service_url, chat_api_path, workspace, user_auth_headers)
+        invites_url = f"{chat_service_url}{chat_api_path}/{chat_id}/invites"
+
+        invite = requests.post(invites_url, json={}, headers=user_auth_headers).json()
+        revoke_url = f"{invites_url}/{invite['id']}"
+
+        # Пользователь, не администрирующий чат, отозвать приглашение не может
+        assert requests.delete(revoke_url, headers=member_headers).status_code == 403
+
+        response = requests.delete(revoke_url, headers=user_auth_headers)
+        assert response.status_code == 204
+
+        join_url = f"{chat_service_url}{chat_api_path}/invites/{invite['code']}/join"
+        response = requests.post(join_url, headers=member_headers)
+        assert response.status_code == 410
+
+        invites = requests.get(invites_url, headers=user_auth_headers).json()["invites"]
+        current = next(i for i in invites if i["id"] == invite["id"])
+        assert current["revoked"] is True
+        assert current["active"] is False
+
+        # Повторный отзыв
+        assert requests.delete(revoke_url, headers=user_auth_headers).status_code == 404
+
+
+class TestMessageRetention:
+    """Тесты очистки сообщений по политике хранения РП и legal hold.
+
+    Требуют запуска Chat Service с RETENTION_PURGE_INTERVAL_MINUTES=1:
+    тесты ждут очередного прохода фоновой очистки не дольше RETENTION_PURGE_WAIT_SECONDS
+    """
+
+    @staticmethod
+    def _create_chat_with_old_message(chat_service_url, chat_api_path, workspace, headers, db_connection, text):
+        """Создать чат с сообщением, отправленным 10 дней назад"""
+        create_response = requests.post(
+            f"{chat_service_url}{chat_api_path}",
+            json={
+                "name": "Retention Chat",
+                "type": 2,
+                "workspace_id": workspace["workspace_id"],
+                "members": [TEST_USER_ID]
+            },
+            headers=headers
+        )
+        chat_id = create_response.json()["id"]
+        send_response = requests.post(
+            f"{chat_service_url}{chat_api_path}/{chat_id}/messages",
+            json={"text": text},
+            headers=headers
+        )
+        message_id = send_response.json()["id"]
+
+        # messages.date хранит Unix timestamp
+        cursor = db_connection.cursor()
+        cursor.execute(
+            "UPDATE messages SET date = date - 10 * 86400 WHERE id = %s",
+            (message_id,)
+        )
+        db_connection.commit()
+        cursor.close()
+        return chat_id, message_id
+
+    @staticmethod
+    def _set_retention_policy(db_connection, workspace_id, days, action):
+        """Задать политику хранения РП, возвращает прежнюю политику"""
+        cursor = db_connection.cursor(cursor_factory=RealDictCursor)
+        cursor.execute(
+            "SELECT retention_days, retention_action, legal_hold FROM workspace_retention_policies WHERE workspacesid = %s",
+            (workspace_id,)
+        )
+        previous = cursor.fetchone()
+        cursor.execute(
+            """
+            INSERT INTO workspace_retention_policies (workspacesid, retention_days, retention_action, legal_hold)
+            VALUES (%s, %s, %s, FALSE)
+            ON CONFLICT (workspacesid) DO UPDATE
+            SET retention_days = EXCLUDED.retention_days,
+                retention_action = EXCLUDED.retention_action,
+                legal_hold = FALSE
+            """,
+            (workspace_id, days, action)
+        )
+        db_connection.commit()
+        cursor.close()
+        return previous
+
+    @staticmethod
+    def _restore_retention_policy(db_connection, workspace_id, previous):
+        """Вернуть политику хранения РП, действовавшую до теста"""
+        cursor = db_connection.cursor()
+        if previous is None:
+            cursor.execute("DELETE FROM workspace_retention_policies WHERE workspacesid = %s", (workspace_id,))
+        else:
+            cursor.execute(
+                """
+                UPDATE workspace_retention_policies
+                SET retention_days = %s, retention_action = %s, legal_hold = %s
+                WHERE workspacesid = %s
+                """,
+                (previous["retention_days"], previous["retention_action"], previous["legal_hold"], workspace_id)
+            )
+        db_connection.commit()
+        cursor.close()
+
+    @staticmethod
+    def _wait_for(condition):
+        """Ждать, пока condition() не станет истинным, не дольше RETENTION_PURGE_WAIT_SECONDS"""
+        wait_seconds = int(os.getenv("RETENTION_PURGE_WAIT_SECONDS", "90"))
+        deadline = time.time() + wait_seconds
+        while time.time() < deadline:
+            if condition():
+                return True
+            time.sleep(5)
+        return condition()
+
+    def test_legal_hold_forbidden_for_member(
+        self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers
+    ):
+        """Legal hold может менять только руководитель РП"""
+        workspace = workspace_with_members
+        member = workspace["members"][1]
+
+        create_response = requests.post(
+            f"{chat_service_url}{chat_api_path}",
+            json={
+                "name": "Retention Chat",
+                "type": 2,
+                "workspace_id": workspace["workspace_id"],
+                "members": [TEST_USER_ID, member["user_id"]]
+            },
+            headers=user_auth_headers
+        )
+        chat_id = create_response.json()["id"]
+
+        response = requests.put(
+            f"{chat_service_url}{chat_api_path}/{chat_id}/legal-hold",
+            json={"legal_hold": True},
+            headers={"Authorization": f"Bearer {member['token']}"}
+        )
+        assert response.status_code == 403
+
+    def test_purge_deletes_old_messages_except_legal_hold(
+        self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers, db_connection
+    ):
+        """Старые сообщения удаляются, сообщения чата под legal hold сохраняются"""
+        workspace = workspace_with_members
+        leader = workspace["leader"]
+
+        _, purged_id = self._create_chat_with_old_message(
+            chat_service_url, chat_api_path, workspace, user_auth_headers, db_connection, "Old message"
+        )
+        held_chat_id, held_id = self._create_chat_with_old_message(
+            chat_service_url, chat_api_path, workspace, user_auth_headers, db_connection, "Held message"
+        )
+
+        # Руководитель РП ставит чат на legal hold
+        response = requests.put(
+            f"{chat_service_url}{chat_api_path}/{held_chat_id}/legal-hold",
+            json={"legal_hold": True, "reason": "Судебный запрос"},
+            headers={"Authorization": f"Bearer {leader['token']}"}
+        )
+        assert response.status_code == 200
+        assert response.json()["legal_hold"] is True
+
+        def message_exists(message_id):
+            cursor = db_connection.cursor()
+            cursor.execute("SELECT 1 FROM messages WHERE id = %s", (message_id,))
+            exists = cursor.fetchone() is not None
+            cursor.close()
+            return exists
+
+        previous = self._set_retention_policy(db_connection, workspace["workspace_id"], 1, "delete")
+        try:
+            assert self._wait_for(lambda: not message_exists(purged_id))
+            assert message_exists(held_id)
+        finally:
+            self._restore_retention_policy(db_connection, workspace["workspace_id"], previous)
+            requests.put(
+                f"{chat_service_url}{chat_api_path}/{held_chat_id}/legal-hold",
+                json={"legal_hold": False},
+                headers={"Authorization": f"Bearer {leader['token']}"}
+            )
+
+    def test_purge_anonymizes_old_messages(
+        self, chat_service_url, chat_api_path, workspace_with_members, user_auth_headers, db_connection
+    ):
+        """Обезличенное сообщение остается в истории без текста и с автором Unknown"""
+        workspace = workspace_with_members
+
+        chat_id, message_id = self._create_chat_with_old_message(
+            chat_service_url, chat_api_path, workspace, user_auth_headers, db_connection, "Secret message"
+        )
+
+        def anonymized():
+            cursor = db_connection.cursor(cursor_factory=RealDictCursor)
+            cursor.execute("SELECT anonymized_at FROM messages WHERE id = %s", (message_id,))
+            row = cursor.fetchone()
+            cursor.close()
+            return row is not None and row["anonymized_at"] is not None
+
+        previous = self._set_retention_policy(db_connection, workspace["workspace_id"], 1, "anonymize")
+        try:
+            assert self._wait_for(anonymized)
+        finally:
+            self._restore_retention_policy(db_connection, workspace["workspace_id"], previous)
+
+        response = requests.get(
+            f"{chat_service_url}{chat_api_path}/{chat_id}/messages",
+            headers=user_auth_headers
+        )
+        assert response.status_code == 200
+        message = next(m for m in response.json()["messages"] if m["id"] == message_id)
+        assert message["text"] == ""
+        assert message["user_id"] == 0
+        assert message["user_name"] == "Unknown"
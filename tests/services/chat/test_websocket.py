@@ -13,6 +13,7 @@
 - message_deleted
 - user_typing / user_stopped_typing
 - user_joined / user_left
+- notification (с учетом уровня уведомлений и mute участника)
 - error
 """
 import pytest
@@ -465,4 +466,86 @@ class TestWebSocketChatEvents:
             member_client.close()
 
 
+class TestWebSocketNotifications:
+    """Тесты уведомлений для участников, не открывших чат"""
 
+    @staticmethod
+    def _notifications(client, chat_id, timeout=2):
+        """Собрать уведомления по чату, пришедшие за timeout секунд"""
+        received = []
+        deadline = time.time() + timeout
+        while time.time() < deadline:
+            message = client.receive(timeout=max(deadline - time.time(), 0.1))
+            if message and message.get("type") == "notification" and message.get("chat_id") == chat_id:
+                received.append(message)
+        return received
+
+    def test_mentions_only_and_mute(
+        self, chat_service_url, chat_api_path, workspace_with_members
+    ):
+        """Участник с уровнем mentions получает только упоминания, заглушенный — ничего"""
+        workspace = workspace_with_members
+        leader = workspace["leader"]
+        member = workspace["members"][3]
+        leader_headers = {"Authorization": f"Bearer {leader['token']}"}
+        member_headers = {"Authorization": f"Bearer {member['token']}"}
+
+        # Создаем чат
+        create_url = f"{chat_service_url}{chat_api_path}"
+        chat_data = {
+            "name": "Test Chat",
+            "type": 2,
+            "workspace_id": workspace["workspace_id"],
+            "members": [leader["user_id"], member["user_id"]]
+        }
+        create_response = requests.post(
+            create_url,
+            json=chat_data,
+            headers=leader_headers
+        )
+        chat_id = create_response.json()["id"]
+        messages_url = f"{chat_service_url}{chat_api_path}/{chat_id}/messages"
+        settings_url = f"{chat_service_url}{chat_api_path}/{chat_id}/notifications"
+
+        # Участник хочет получать только упоминания
+        response = requests.put(
+            settings_url,
+            json={"notification_level": "mentions"},
+            headers=member_headers
+        )
+        assert response.status_code == 200
+
+        # Участник подключен по WebSocket, но чат не открывает
+        member_client = WebSocketClient(
+            f"{chat_service_url}{chat_api_path}/ws",
+            member["token"]
+        )
+
+        try:
+            member_client.connect()
+            time.sleep(0.5)
+
+            # Сообщение без упоминания не уведомляет
+            requests.post(messages_url, json={"text": "Plain message"}, headers=leader_headers)
+            assert self._notifications(member_client, chat_id) == []
+
+            # Упоминание по части логина до @ уведомляет с флагом mentioned
+            mention = member["login"].split("@")[0]
+            requests.post(messages_url, json={"text": f"@{mention}, посмотри"}, headers=leader_headers)
+            notifications = self._notifications(member_client, chat_id, timeout=3)
+            assert len(notifications) == 1
+            assert notifications[0]["mentioned"] is True
+            assert notifications[0]["message"]["text"] == f"@{mention}, посмотри"
+
+            # Заглушенный чат не уведомляет даже об упоминаниях
+            response = requests.put(
+                settings_url,
+                json={"notification_level": "mentions", "muted_until": "2999-01-01T00:00:00Z"},
+                headers=member_headers
+            )
+            assert response.status_code == 200
+            requests.post(messages_url, json={"text": f"@{mention} again"}, headers=leader_headers)
+            assert self._notifications(member_client, chat_id) == []
+
+        finally:
+            member_client.close()
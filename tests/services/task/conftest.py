@@ -0,0 +1,277 @@
+"""
+Фикстуры для тестов Task Service
+"""
+import pytest
+import os
+import time
+import psycopg2
+from psycopg2.extras import RealDictCursor
+import requests
+from datetime import date, timedelta
+
+# URL сервисов
+TASK_SERVICE_URL = os.getenv("TASK_SERVICE_URL", "http://localhost:8085")
+TASK_API_PATH = "/api/v1/tasks"
+
+# Настройки БД
+DB_HOST = os.getenv("DB_HOST", "localhost")
+DB_PORT = os.getenv("DB_PORT", "5432")
+DB_NAME = os.getenv("DB_NAME", "messenger_db")
+DB_USER = os.getenv("DB_USER", "user")
+DB_PASSWORD = os.getenv("DB_PASSWORD", "password")
+
+# Руководитель тестовых РП и участники (не пересекаемся с пользователями тестов чатов)
+TEST_LEADER_ID = 1
+MEMBER_BASE_ID = 200
+MEMBERS_COUNT = 5
+
+
+def _connect():
+    return psycopg2.connect(
+        host=DB_HOST,
+        port=DB_PORT,
+        dbname=DB_NAME,
+        user=DB_USER,
+        password=DB_PASSWORD
+    )
+
+
+def _headers(user_id):
+    """Заголовки, которые API Gateway передает Task Service"""
+    return {
+        "X-User-ID": str(user_id),
+        "X-User-Role": "user"
+    }
+
+
+@pytest.fixture(scope="session")
+def task_service_url():
+    """Базовый URL для Task Service"""
+    return TASK_SERVICE_URL
+
+
+@pytest.fixture(scope="session")
+def task_api_path():
+    """Базовый путь API Task Service"""
+    return TASK_API_PATH
+
+
+@pytest.fixture(scope="session")
+def tasks_url(task_service_url, task_api_path):
+    """Полный URL API задач"""
+    return f"{task_service_url}{task_api_path}"
+
+
+@pytest.fixture(scope="session")
+def db_connection():
+    """Соединение с базой данных"""
+    try:
+        conn = _connect()
+        conn.autocommit = True
+        yield conn
+        conn.close()
+    except Exception as e:
+        pytest.skip(f"Database connection failed: {e}")
+
+
+@pytest.fixture
+def db_cursor(db_connection):
+    """Курсор БД"""
+    cursor = db_connection.cursor(cursor_factory=RealDictCursor)
+    yield cursor
+    cursor.close()
+
+
+@pytest.fixture
+def lock_connection(db_connection):
+    """
+    Отдельное соединение с ручным управлением транзакцией.
+    Тесты конкурентных изменений держат в нем блокировку строки задачи,
+    пока запрос к сервису ждет эту блокировку.
+    """
+    conn = _connect()
+    yield conn
+    conn.rollback()
+    conn.close()
+
+
+@pytest.fixture
+def wait_for_lock_waiter(db_connection):
+    """Ждет, пока запрос сервиса не встанет в ожидание блокировки строки таблицы tasks"""
+    def _wait(timeout=10):
+        cursor = db_connection.cursor()
+        deadline = time.time() + timeout
+        try:
+            while time.time() < deadline:
+                cursor.execute(
+                    """
+                    SELECT 1 FROM pg_stat_activity
+                    WHERE wait_event_type = 'Lock' AND pid <> pg_backend_pid()
+                      AND query ILIKE '%%FROM tasks%%'
+                    """
+                )
+                if cursor.fetchone():
+                    return True
+                time.sleep(0.1)
+            return False
+        finally:
+            cursor.close()
+    return _wait
+
+
+def _ensure_user(conn, user_id, login):
+    cur = conn.cursor(cursor_factory=RealDictCursor)
+    cur.execute(
+        """
+        INSERT INTO users (id, login, password, status, surname, name)
+        VALUES (%s, %s, 'stub', 0, 'Test', 'User')
+        ON CONFLICT (id) DO UPDATE SET login = EXCLUDED.login
+        RETURNING id
+        """,
+        (user_id, login),
+    )
+    row = cur.fetchone()
+    cur.close()
+    return row["id"]
+
+
+def _ensure_admin(conn, admin_id, login):
+    cur = conn.cursor(cursor_factory=RealDictCursor)
+    cur.execute(
+        """
+        INSERT INTO administrators (id, login, password)
+        VALUES (%s, %s, 'stub')
+        ON CONFLICT (id) DO UPDATE SET login = EXCLUDED.login
+        RETURNING id
+        """,
+        (admin_id, login),
+    )
+    row = cur.fetchone()
+    cur.close()
+    return row["id"]
+
+
+def _ensure_member(conn, workspace_id, user_id, role):
+    """Добавляет пользователя в РП с ролью role (1 — участник, 2 — руководитель)"""
+    cur = conn.cursor()
+    cur.execute(
+        """
+        INSERT INTO "userinworkspace" (usersid, workspacesid, role, date)
+        SELECT %s, %s, %s, NOW()
+        WHERE NOT EXISTS (
+            SELECT 1 FROM "userinworkspace" WHERE usersid = %s AND workspacesid = %s
+        )
+        """,
+        (user_id, workspace_id, role, user_id, workspace_id),
+    )
+    cur.close()
+
+
+def _ensure_workspace(conn, name, members):
+    """Создает РП name с руководителем TEST_LEADER_ID и участниками members"""
+    cursor = conn.cursor(cursor_factory=RealDictCursor)
+
+    # Гарантируем наличие тарифа
+    cursor.execute(
+        "INSERT INTO tariffs (name, description) VALUES ('Test Tariff', 'Test Description') ON CONFLICT DO NOTHING RETURNING id"
+    )
+    tariff_row = cursor.fetchone()
+    if not tariff_row:
+        cursor.execute("SELECT id FROM tariffs WHERE name = 'Test Tariff'")
+        tariff_id = cursor.fetchone()["id"]
+    else:
+        tariff_id = tariff_row["id"]
+
+    _ensure_user(conn, TEST_LEADER_ID, "admin@test.local")
+    _ensure_admin(conn, TEST_LEADER_ID, "admin@test.local")
+
+    cursor.execute(
+        """
+        INSERT INTO workspaces (name, creator, tariffsid)
+        VALUES (%s, %s, %s)
+        ON CONFLICT (name) DO NOTHING
+        RETURNING id
+        """,
+        (name, TEST_LEADER_ID, tariff_id),
+    )
+    row = cursor.fetchone()
+    if row:
+        workspace_id = row["id"]
+    else:
+        cursor.execute("SELECT id FROM workspaces WHERE name = %s", (name,))
+        workspace_id = cursor.fetchone()["id"]
+    cursor.close()
+
+    _ensure_member(conn, workspace_id, TEST_LEADER_ID, 2)
+    for member in members:
+        _ensure_member(conn, workspace_id, member["user_id"], 1)
+
+    return {
+        "workspace_id": workspace_id,
+        "leader": {"user_id": TEST_LEADER_ID, "headers": _headers(TEST_LEADER_ID)},
+        "members": members,
+    }
+
+
+@pytest.fixture(scope="session")
+def task_users(db_connection):
+    """Участники тестовых РП, создаются напрямую в БД"""
+    users = []
+    for i in range(MEMBERS_COUNT):
+        uid = MEMBER_BASE_ID + i
+        login = f"task-user-{uid}@example.com"
+        _ensure_user(db_connection, uid, login)
+        users.append({"user_id": uid, "login": login, "headers": _headers(uid)})
+    return users
+
+
+@pytest.fixture(scope="session")
+def task_workspace(db_connection, task_users):
+    """Основное РП тестов задач с workflow по умолчанию"""
+    return _ensure_workspace(db_connection, "task-test", task_users)
+
+
+@pytest.fixture(scope="session")
+def other_workspace(db_connection, task_users):
+    """Второе РП тех же пользователей — для проверок связей между РП и лент РП"""
+    return _ensure_workspace(db_connection, "task-test-other", task_users)
+
+
+@pytest.fixture(scope="session")
+def workflow_workspace(db_connection, task_users):
+    """РП, workflow которого меняют тесты; после тестов workflow сбрасывается"""
+    return _ensure_workspace(db_connection, "task-test-workflow", task_users)
+
+
+@pytest.fixture
+def create_task(tasks_url):
+    """Фабрика задач: создает задачу через API и возвращает ответ сервиса"""
+    def _create(workspace_id, headers, title="Test Task", **fields):
+        payload = {
+            "workspace_id": workspace_id,
+            "title": title,
+            "date": (date.today() + timedelta(days=7)).isoformat(),
+        }
+        payload.update(fields)
+        response = requests.post(tasks_url, json=payload, headers=headers)
+        assert response.status_code == 201, response.text
+        return response.json()
+    return _create
+
+
+@pytest.fixture
+def set_status(tasks_url):
+    """Меняет статус задачи через PUT /tasks/:id/status без сверки версии (If-Match: *)"""
+    def _set(task_id, status, headers):
+        return requests.put(
+            f"{tasks_url}/{task_id}/status",
+            json={"status": status},
+            headers={**headers, "If-Match": "*"}
+        )
+    return _set
+
+
+@pytest.fixture(scope="session")
+def unique_token():
+    """Уникальная строка для поиска задач текущего запуска через q"""
+    return f"run{int(time.time() * 1000)}"
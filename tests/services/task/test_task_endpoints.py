@@ -0,0 +1,718 @@
+"""
+Функциональные тесты для Task Service
+
+Покрывает сценарии, для которых важны правила сервиса, а не только формат ответа:
+- PUT /api/v1/tasks/workflows/:workspace_id, PUT /api/v1/tasks/:id/status - Workflow и права переходов
+- GET /api/v1/tasks - Постраничная выборка по курсору
+- POST /api/v1/tasks/:id/dependencies - Зависимости задач
+- POST /api/v1/tasks/:id/move - Перемещение задачи на доске
+- POST /api/v1/tasks/bulk - Массовые операции
+- /api/v1/tasks/calendar/feeds, GET /api/v1/tasks/ical/:token - Календарные ленты
+- /api/v1/tasks/views/:workspace_id - Сохраненные представления
+"""
+import threading
+import pytest
+import requests
+
+# Workflow с переходом в «Готово» только для исполнителя и руководителя РП
+CUSTOM_WORKFLOW = {
+    "statuses": [
+        {"code": 1, "name": "Бэклог", "category": "open", "is_initial": True},
+        {"code": 2, "name": "В работе", "category": "in_progress"},
+        {"code": 4, "name": "Готово", "category": "done"}
+    ],
+    "transitions": [
+        {"from": 1, "to": 2},
+        {"from": 2, "to": 4, "allowed_roles": ["assignee", "leader"]}
+    ]
+}
+
+
+class TestWorkflowTransitions:
+    """Тесты workflow рабочего пространства и проверок переходов статусов"""
+
+    def test_update_workflow_forbidden_for_member(
+        self, tasks_url, workflow_workspace
+    ):
+        """Workflow может менять только руководитель РП"""
+        workspace = workflow_workspace
+        member = workspace["members"][0]
+
+        url = f"{tasks_url}/workflows/{workspace['workspace_id']}"
+        response = requests.put(url, json=CUSTOM_WORKFLOW, headers=member["headers"])
+        assert response.status_code == 403
+
+        response = requests.delete(url, headers=member["headers"])
+        assert response.status_code == 403
+
+    def test_update_workflow_invalid(
+        self, tasks_url, workflow_workspace
+    ):
+        """Workflow без единственного начального статуса или с переходом в неизвестный статус отклоняется"""
+        workspace = workflow_workspace
+        url = f"{tasks_url}/workflows/{workspace['workspace_id']}"
+
+        two_initial = {
+            "statuses": [
+                {"code": 1, "name": "Бэклог", "category": "open", "is_initial": True},
+                {"code": 2, "name": "В работе", "category": "in_progress", "is_initial": True}
+            ],
+            "transitions": []
+        }
+        response = requests.put(url, json=two_initial, headers=workspace["leader"]["headers"])
+        assert response.status_code == 400
+
+        unknown_status = {
+            "statuses": CUSTOM_WORKFLOW["statuses"],
+            "transitions": [{"from": 1, "to": 9}]
+        }
+        response = requests.put(url, json=unknown_status, headers=workspace["leader"]["headers"])
+        assert response.status_code == 400
+
+    def test_transition_roles(
+        self, tasks_url, workflow_workspace, create_task, set_status
+    ):
+        """Переходы проверяются по workflow РП и ролям пользователя"""
+        workspace = workflow_workspace
+        leader = workspace["leader"]
+        creator, assignee, outsider = workspace["members"][:3]
+        url = f"{tasks_url}/workflows/{workspace['workspace_id']}"
+
+        # Начинаем с workflow по умолчанию на случай прерванного прошлого запуска
+        requests.delete(url, headers=leader["headers"])
+        response = requests.put(url, json=CUSTOM_WORKFLOW, headers=leader["headers"])
+        assert response.status_code == 200
+        workflow = response.json()
+        assert workflow["is_default"] is False
+        assert [s["code"] for s in workflow["statuses"]] == [1, 2, 4]
+
+        try:
+            # Новая задача получает начальный статус workflow
+            task = create_task(
+                workspace["workspace_id"], creator["headers"],
+                title="Workflow task", assigned_users=[assignee["user_id"]]
+            )
+            assert task["status"] == 1
+            assert task["status_name"] == "Бэклог"
+
+            # Без If-Match статус не меняется
+            response = requests.put(
+                f"{tasks_url}/{task['id']}/status",
+                json={"status": 2},
+                headers=outsider["headers"]
+            )
+            assert response.status_code == 428
+
+            # Переход без ограничений доступен любому участнику РП
+            assert set_status(task["id"], 2, outsider["headers"]).status_code == 200
+
+            # Статуса нет в workflow
+            assert set_status(task["id"], 3, creator["headers"]).status_code == 400
+
+            # Переход не описан в workflow
+            assert set_status(task["id"], 1, creator["headers"]).status_code == 409
+
+            # Завершить задачу может только исполнитель или руководитель РП
+            assert set_status(task["id"], 4, outsider["headers"]).status_code == 403
+            assert set_status(task["id"], 4, creator["headers"]).status_code == 403
+            response = set_status(task["id"], 4, assignee["headers"])
+            assert response.status_code == 200
+
+            task_response = requests.get(f"{tasks_url}/{task['id']}", headers=creator["headers"])
+            assert task_response.json()["status"] == 4
+
+            # Статус, в котором находится задача, удалить нельзя
+            without_done = {
+                "statuses": CUSTOM_WORKFLOW["statuses"][:2],
+                "transitions": CUSTOM_WORKFLOW["transitions"][:1]
+            }
+            response = requests.put(url, json=without_done, headers=leader["headers"])
+            assert response.status_code == 409
+        finally:
+            response = requests.delete(url, headers=leader["headers"])
+
+        assert response.status_code == 200
+        assert response.json()["is_default"] is True
+
+
+class TestTaskPagination:
+    """Тесты постраничной выборки задач по курсору"""
+
+    def test_cursor_pages_are_stable(
+        self, tasks_url, task_workspace, create_task, unique_token
+    ):
+        """Страницы не теряют и не повторяют задачи, даже если между запросами появилась новая задача"""
+        workspace = task_workspace
+        member = workspace["members"][0]
+        token = f"{unique_token}page"
+
+        # Одинаковый срок: порядок внутри срока задается ID задачи
+        created = [
+            create_task(workspace["workspace_id"], member["headers"], title=f"Page {token} {i}", date="2030-01-15")
+            for i in range(5)
+        ]
+        created_ids = {task["id"] for task in created}
+
+        params = {"workspace_id": workspace["workspace_id"], "q": token, "sort": "date", "limit": 2}
+        first = requests.get(tasks_url, params=params, headers=member["headers"])
+        assert first.status_code == 200
+        first_page = first.json()
+        assert first_page["total"] == 5
+        assert first_page["has_more"] is True
+        assert len(first_page["tasks"]) == 2
+
+        # Новая задача с тем же сроком попадает в конец выборки и не сдвигает уже выданные страницы
+        extra = create_task(workspace["workspace_id"], member["headers"], title=f"Page {token} extra", date="2030-01-15")
+
+        seen = [task["id"] for task in first_page["tasks"]]
+        cursor = first_page["next_cursor"]
+        while cursor:
+            response = requests.get(tasks_url, params={**params, "cursor": cursor}, headers=member["headers"])
+            assert response.status_code == 200
+            page = response.json()
+            assert len(page["tasks"]) <= 2
+            seen.extend(task["id"] for task in page["tasks"])
+            cursor = page.get("next_cursor") if page["has_more"] else None
+
+        assert len(seen) == len(set(seen))
+        assert created_ids <= set(seen)
+        assert extra["id"] in seen
+        assert seen == sorted(seen)
+
+    def test_cursor_bound_to_sort(
+        self, tasks_url, task_workspace, create_task, unique_token
+    ):
+        """Курсор одной сортировки не принимается для другой"""
+        workspace = task_workspace
+        member = workspace["members"][0]
+        token = f"{unique_token}sort"
+        for i in range(3):
+            create_task(workspace["workspace_id"], member["headers"], title=f"Sort {token} {i}")
+
+        params = {"workspace_id": workspace["workspace_id"], "q": token, "sort": "title", "limit": 1}
+        response = requests.get(tasks_url, params=params, headers=member["headers"])
+        cursor = response.json()["next_cursor"]
+
+        response = requests.get(
+            tasks_url,
+            params={**params, "sort": "-date", "cursor": cursor},
+            headers=member["headers"]
+        )
+        assert response.status_code == 400
+
+        response = requests.get(
+            tasks_url,
+            params={**params, "cursor": "not-a-cursor"},
+            headers=member["headers"]
+        )
+        assert response.status_code == 400
+
+    @pytest.mark.parametrize("limit", ["0", "101", "ten"])
+    def test_invalid_limit(self, tasks_url, task_workspace, limit):
+        """Размер страницы вне 1..100 отклоняется"""
+        workspace = task_workspace
+        response = requests.get(
+            tasks_url,
+            params={"workspace_id": workspace["workspace_id"], "limit": limit},
+            headers=workspace["members"][0]["headers"]
+        )
+        assert response.status_code == 400
+
+
+class TestTaskDependencies:
+    """Тесты зависимостей между задачами"""
+
+    def test_dependency_cycle_rejected(
+        self, tasks_url, task_workspace, create_task
+    ):
+        """Зависимость, замыкающая цепочку блокировок, отклоняется с 409"""
+        workspace = task_workspace
+        creator = workspace["members"][0]
+        other = workspace["members"][1]
+        first, second, third = [
+            create_task(workspace["workspace_id"], creator["headers"], title=f"Chain {i}")
+            for i in range(3)
+        ]
+
+        url = f"{tasks_url}/{first['id']}/dependencies"
+        response = requests.post(url, json={"blocked_by": second["id"]}, headers=creator["headers"])
+        assert response.status_code == 201
+        data = response.json()
+        assert data["blocked"] is True
+        assert [t["id"] for t in data["blocked_by"]] == [second["id"]]
+
+        response = requests.post(
+            f"{tasks_url}/{second['id']}/dependencies",
+            json={"blocked_by": third["id"]},
+            headers=creator["headers"]
+        )
+        assert response.status_code == 201
+
+        # third -> first замкнет цепочку first -> second -> third
+        response = requests.post(
+            f"{tasks_url}/{third['id']}/dependencies",
+            json={"blocked_by": first["id"]},
+            headers=creator["headers"]
+        )
+        assert response.status_code == 409
+
+        # Повторная зависимость
+        response = requests.post(url, json={"blocked_by": second["id"]}, headers=creator["headers"])
+        assert response.status_code == 409
+
+        # Зависимостями управляет только создатель задачи
+        response = requests.post(url, json={"blocked_by": third["id"]}, headers=other["headers"])
+        assert response.status_code == 403
+
+    def test_dependency_across_workspaces_rejected(
+        self, tasks_url, task_workspace, other_workspace, create_task
+    ):
+        """Связывать можно только задачи одного рабочего пространства"""
+        creator = task_workspace["members"][0]
+        task = create_task(task_workspace["workspace_id"], creator["headers"], title="Local task")
+        foreign = create_task(other_workspace["workspace_id"], creator["headers"], title="Foreign task")
+
+        response = requests.post(
+            f"{tasks_url}/{task['id']}/dependencies",
+            json={"blocked_by": foreign["id"]},
+            headers=creator["headers"]
+        )
+        assert response.status_code == 400
+
+        response = requests.put(
+            f"{tasks_url}/{task['id']}/parent",
+            json={"parent_id": foreign["id"]},
+            headers=creator["headers"]
+        )
+        assert response.status_code == 400
+
+    def test_blocked_task_cannot_be_completed(
+        self, tasks_url, task_workspace, create_task, set_status
+    ):
+        """Задачу с незавершенной блокирующей задачей нельзя завершить"""
+        workspace = task_workspace
+        creator = workspace["members"][0]
+        blocked = create_task(workspace["workspace_id"], creator["headers"], title="Blocked task", status=3)
+        blocker = create_task(workspace["workspace_id"], creator["headers"], title="Blocker task", status=3)
+
+        response = requests.post(
+            f"{tasks_url}/{blocked['id']}/dependencies",
+            json={"blocked_by": blocker["id"]},
+            headers=creator["headers"]
+        )
+        assert response.status_code == 201
+
+        task = requests.get(f"{tasks_url}/{blocked['id']}", headers=creator["headers"]).json()
+        assert task["blocked"] is True
+        assert task["open_blocker_count"] == 1
+
+        assert set_status(blocked["id"], 4, creator["headers"]).status_code == 409
+
+        # Массовая смена статуса проверяет зависимости так же
+        response = requests.post(
+            f"{tasks_url}/bulk",
+            json={"workspace_id": workspace["workspace_id"], "task_ids": [blocked["id"]], "status": 4},
+            headers=creator["headers"]
+        )
+        assert response.status_code == 200
+        result = response.json()["results"][0]
+        assert result["result"] == "failed"
+        assert result["code"] == 409
+
+        # После завершения блокирующей задачи задачу можно завершить
+        assert set_status(blocker["id"], 4, creator["headers"]).status_code == 200
+        task = requests.get(f"{tasks_url}/{blocked['id']}", headers=creator["headers"]).json()
+        assert task["blocked"] is False
+        assert set_status(blocked["id"], 4, creator["headers"]).status_code == 200
+
+
+class TestBoardMove:
+    """Тесты перемещения задач на доске"""
+
+    def test_move_to_column_position(self, tasks_url, task_workspace, create_task):
+        """Задача переходит в другую колонку на указанную позицию"""
+        workspace = task_workspace
+        member = workspace["members"][0]
+        task = create_task(workspace["workspace_id"], member["headers"], title="Board task")
+
+        response = requests.post(
+            f"{tasks_url}/{task['id']}/move",
+            json={"status": 2, "position": 0},
+            headers=member["headers"]
+        )
+        assert response.status_code == 200
+        assert response.json()["status"] == 2
+
+        response = requests.get(
+            f"{tasks_url}/board/{workspace['workspace_id']}",
+            params={"limit": 1},
+            headers=member["headers"]
+        )
+        assert response.status_code == 200
+        column = next(col for col in response.json()["columns"] if col["status"] == 2)
+        assert column["tasks"][0]["id"] == task["id"]
+
+    def test_move_with_stale_status_conflict(
+        self, tasks_url, task_workspace, create_task, db_cursor,
+        lock_connection, wait_for_lock_waiter
+    ):
+        """Если статус задачи изменился, пока перемещение ждало блокировку, возвращается 409"""
+        workspace = task_workspace
+        member = workspace["members"][0]
+        task = create_task(workspace["workspace_id"], member["headers"], title="Stale board task")
+
+        # Держим блокировку строки задачи, пока запрос перемещения не встанет в очередь за ней
+        lock_cursor = lock_connection.cursor()
+        lock_cursor.execute("SELECT id FROM tasks WHERE id = %s FOR UPDATE", (task["id"],))
+
+        result = {}
+
+        def move():
+            result["response"] = requests.post(
+                f"{tasks_url}/{task['id']}/move",
+                json={"status": 2, "position": 0},
+                headers=member["headers"],
+                timeout=30
+            )
+
+        thread = threading.Thread(target=move)
+        thread.start()
+        try:
+            assert wait_for_lock_waiter(), "move request did not wait for the task row lock"
+
+            # Параллельное изменение статуса, которое запрос увидит после блокировки
+            lock_cursor.execute("UPDATE tasks SET status = 3 WHERE id = %s", (task["id"],))
+            lock_connection.commit()
+        finally:
+            lock_connection.rollback()
+            thread.join(timeout=30)
+
+        assert result["response"].status_code == 409
+
+        db_cursor.execute("SELECT status FROM tasks WHERE id = %s", (task["id"],))
+        assert db_cursor.fetchone()["status"] == 3
+
+    def test_move_invalid_status(self, tasks_url, task_workspace, create_task):
+        """Перемещение в статус, которого нет в workflow РП, возвращает 400"""
+        workspace = task_workspace
+        member = workspace["members"][0]
+        task = create_task(workspace["workspace_id"], member["headers"], title="Board invalid status")
+
+        response = requests.post(
+            f"{tasks_url}/{task['id']}/move",
+            json={"status": 99, "position": 0},
+            headers=member["headers"]
+        )
+        assert response.status_code == 400
+
+
+class TestBulkOperations:
+    """Тесты массовых операций над задачами"""
+
+    def test_atomic_bulk_skipped_on_partial_failure(
+        self, tasks_url, task_workspace, create_task
+    ):
+        """В режиме atomic отказ по одной задаче отменяет операцию для всех"""
+        workspace = task_workspace
+        creator = workspace["members"][0]
+        other = workspace["members"][1]
+        own = create_task(workspace["workspace_id"], creator["headers"], title="Bulk own task")
+        foreign = create_task(workspace["workspace_id"], other["headers"], title="Bulk foreign task")
+
+        response = requests.post(
+            f"{tasks_url}/bulk",
+            json={
+                "workspace_id": workspace["workspace_id"],
+                "task_ids": [own["id"], foreign["id"]],
+                "delete": True,
+                "atomic": True
+            },
+            headers=creator["headers"]
+        )
+        assert response.status_code == 200
+        data = response.json()
+        assert data["applied"] is False
+        assert data["updated"] == 0
+        assert data["failed"] == 1
+        results = {r["task_id"]: r for r in data["results"]}
+        assert results[own["id"]]["result"] == "skipped"
+        assert results[foreign["id"]]["result"] == "failed"
+        assert results[foreign["id"]]["code"] == 403
+
+        response = requests.get(f"{tasks_url}/{own['id']}", headers=creator["headers"])
+        assert response.status_code == 200
+
+    def test_bulk_applies_valid_tasks_without_atomic(
+        self, tasks_url, task_workspace, create_task
+    ):
+        """Без atomic операция применяется к задачам, прошедшим проверку"""
+        workspace = task_workspace
+        creator = workspace["members"][0]
+        other = workspace["members"][1]
+        own = create_task(workspace["workspace_id"], creator["headers"], title="Bulk own task")
+        foreign = create_task(workspace["workspace_id"], other["headers"], title="Bulk foreign task")
+
+        response = requests.post(
+            f"{tasks_url}/bulk",
+            json={
+                "workspace_id": workspace["workspace_id"],
+                "task_ids": [own["id"], foreign["id"]],
+                "delete": True
+            },
+            headers=creator["headers"]
+        )
+        assert response.status_code == 200
+        data = response.json()
+        assert data["applied"] is True
+        results = {r["task_id"]: r for r in data["results"]}
+        assert results[own["id"]]["result"] == "deleted"
+        assert results[foreign["id"]]["result"] == "failed"
+
+        assert requests.get(f"{tasks_url}/{own['id']}", headers=creator["headers"]).status_code == 404
+        assert requests.get(f"{tasks_url}/{foreign['id']}", headers=other["headers"]).status_code == 200
+
+    def test_bulk_rolled_back_on_concurrent_change(
+        self, tasks_url, task_workspace, create_task, db_cursor,
+        lock_connection, wait_for_lock_waiter
+    ):
+        """Если статус одной из задач изменился параллельно, не применяется ни одно изменение"""
+        workspace = task_workspace
+        member = workspace["members"][0]
+        first = create_task(workspace["workspace_id"], member["headers"], title="Bulk first task")
+        second = create_task(workspace["workspace_id"], member["headers"], title="Bulk second task")
+
+        # Сервис блокирует задачи по возрастанию id: первая будет заблокирована им, вторая — ждать нас
+        lock_cursor = lock_connection.cursor()
+        lock_cursor.execute("SELECT id FROM tasks WHERE id = %s FOR UPDATE", (second["id"],))
+
+        result = {}
+
+        def bulk():
+            result["response"] = requests.post(
+                f"{tasks_url}/bulk",
+                json={
+                    "workspace_id": workspace["workspace_id"],
+                    "task_ids": [first["id"], second["id"]],
+                    "status": 2
+                },
+                headers=member["headers"],
+                timeout=30
+            )
+
+        thread = threading.Thread(target=bulk)
+        thread.start()
+        try:
+            assert wait_for_lock_waiter(), "bulk request did not wait for the task row lock"
+
+            lock_cursor.execute("UPDATE tasks SET status = 3 WHERE id = %s", (second["id"],))
+            lock_connection.commit()
+        finally:
+            lock_connection.rollback()
+            thread.join(timeout=30)
+
+        assert result["response"].status_code == 409
+
+        db_cursor.execute(
+            "SELECT id, status FROM tasks WHERE id = ANY(%s)", ([first["id"], second["id"]],)
+        )
+        statuses = {row["id"]: row["status"] for row in db_cursor.fetchall()}
+        assert statuses == {first["id"]: 1, second["id"]: 3}
+
+
+class TestCalendarFeeds:
+    """Тесты календарных лент iCalendar и отзыва их токенов"""
+
+    def test_feed_contains_assigned_tasks(
+        self, task_service_url, tasks_url, task_workspace, create_task
+    ):
+        """Лента доступна без JWT по ссылке из ответа и содержит задачи пользователя со сроком"""
+        workspace = task_workspace
+        user = workspace["members"][3]
+        task = create_task(
+            workspace["workspace_id"], user["headers"],
+            title="Calendar task", assigned_users=[user["user_id"]]
+        )
+
+        response = requests.post(f"{tasks_url}/calendar/feeds", json={}, headers=user["headers"])
+        assert response.status_code == 201
+        feed = response.json()
+        assert feed["url"].endswith(".ics")
+
+        response = requests.get(f"{task_service_url}{feed['url']}")
+        assert response.status_code == 200
+        assert response.headers["Content-Type"].startswith("text/calendar")
+        assert f"UID:task-{task['id']}@task-service" in response.text
+
+        # Ссылка показывается только при создании
+        response = requests.get(f"{tasks_url}/calendar/feeds", headers=user["headers"])
+        assert response.status_code == 200
+        listed = next(f for f in response.json()["feeds"] if f["id"] == feed["id"])
+        assert "url" not in listed
+
+    def test_recreated_feed_invalidates_old_token(
+        self, task_service_url, tasks_url, task_workspace
+    ):
+        """Повторное создание ленты той же области выдает новый токен, старая ссылка перестает работать"""
+        user = task_workspace["members"][3]
+        payload = {"workspace_id": task_workspace["workspace_id"]}
+
+        old = requests.post(f"{tasks_url}/calendar/feeds", json=payload, headers=user["headers"]).json()
+        new = requests.post(f"{tasks_url}/calendar/feeds", json=payload, headers=user["headers"]).json()
+        assert old["id"] == new["id"]
+        assert old["url"] != new["url"]
+
+        assert requests.get(f"{task_service_url}{old['url']}").status_code == 404
+        assert requests.get(f"{task_service_url}{new['url']}").status_code == 200
+
+    def test_revoked_feed_not_found(self, task_service_url, tasks_url, task_workspace):
+        """Отозванная лента возвращает 404, отзыв чужой или отозванной ленты — тоже 404"""
+        user = task_workspace["members"][3]
+        other = task_workspace["members"][2]
+        feed = requests.post(f"{tasks_url}/calendar/feeds", json={}, headers=user["headers"]).json()
+
+        response = requests.delete(f"{tasks_url}/calendar/feeds/{feed['id']}", headers=other["headers"])
+        assert response.status_code == 404
+
+        response = requests.delete(f"{tasks_url}/calendar/feeds/{feed['id']}", headers=user["headers"])
+        assert response.status_code == 200
+
+        assert requests.get(f"{task_service_url}{feed['url']}").status_code == 404
+
+        response = requests.delete(f"{tasks_url}/calendar/feeds/{feed['id']}", headers=user["headers"])
+        assert response.status_code == 404
+
+    def test_workspace_feed_stops_after_leaving_workspace(
+        self, task_service_url, tasks_url, other_workspace, db_cursor
+    ):
+        """Лента РП перестает работать, когда владелец покидает РП"""
+        workspace_id = other_workspace["workspace_id"]
+        user = other_workspace["members"][4]
+        feed = requests.post(
+            f"{tasks_url}/calendar/feeds", json={"workspace_id": workspace_id}, headers=user["headers"]
+        ).json()
+        assert requests.get(f"{task_service_url}{feed['url']}").status_code == 200
+
+        db_cursor.execute(
+            'DELETE FROM "userinworkspace" WHERE usersid = %s AND workspacesid = %s',
+            (user["user_id"], workspace_id)
+        )
+        try:
+            assert requests.get(f"{task_service_url}{feed['url']}").status_code == 404
+        finally:
+            db_cursor.execute(
+                'INSERT INTO "userinworkspace" (usersid, workspacesid, role, date) VALUES (%s, %s, 1, NOW())',
+                (user["user_id"], workspace_id)
+            )
+
+
+class TestTaskViews:
+    """Тесты сохраненных представлений и их видимости"""
+
+    def test_private_view_hidden_from_others(self, tasks_url, task_workspace, unique_token):
+        """Личное представление видно только владельцу"""
+        workspace = task_workspace
+        owner = workspace["members"][0]
+        other = workspace["members"][1]
+        views_url = f"{tasks_url}/views/{workspace['workspace_id']}"
+
+        response = requests.post(
+            views_url,
+            json={"name": f"{unique_token} private", "filter": {"q": unique_token}},
+            headers=owner["headers"]
+        )
+        assert response.status_code == 201
+        view = response.json()
+        assert view["is_shared"] is False
+
+        # Имя представления уникально для владельца
+        response = requests.post(
+            views_url,
+            json={"name": f"{unique_token} private", "filter": {}},
+            headers=owner["headers"]
+        )
+        assert response.status_code == 409
+
+        listed = requests.get(views_url, headers=owner["headers"]).json()["views"]
+        assert view["id"] in [v["id"] for v in listed]
+
+        listed = requests.get(views_url, headers=other["headers"]).json()["views"]
+        assert view["id"] not in [v["id"] for v in listed]
+
+        response = requests.get(f"{views_url}/{view['id']}", headers=other["headers"])
+        assert response.status_code == 404
+        response = requests.get(f"{views_url}/{view['id']}/tasks", headers=other["headers"])
+        assert response.status_code == 404
+
+    def test_shared_view_permissions(self, tasks_url, task_workspace, unique_token):
+        """Общее представление видят все участники, менять может владелец, удалить — владелец или руководитель"""
+        workspace = task_workspace
+        owner = workspace["members"][0]
+        other = workspace["members"][1]
+        leader = workspace["leader"]
+        views_url = f"{tasks_url}/views/{workspace['workspace_id']}"
+
+        response = requests.post(
+            views_url,
+            json={"name": f"{unique_token} shared", "is_shared": True, "filter": {"q": unique_token}},
+            headers=owner["headers"]
+        )
+        assert response.status_code == 201
+        view = response.json()
+        view_url = f"{views_url}/{view['id']}"
+
+        listed = requests.get(views_url, headers=other["headers"]).json()["views"]
+        assert view["id"] in [v["id"] for v in listed]
+        response = requests.get(view_url, headers=other["headers"])
+        assert response.status_code == 200
+        assert response.json()["owner_id"] == owner["user_id"]
+
+        response = requests.put(
+            view_url,
+            json={"name": f"{unique_token} renamed", "is_shared": True, "filter": {}},
+            headers=other["headers"]
+        )
+        assert response.status_code == 403
+
+        response = requests.delete(view_url, headers=other["headers"])
+        assert response.status_code == 403
+
+        response = requests.delete(view_url, headers=leader["headers"])
+        assert response.status_code == 204
+
+        response = requests.get(view_url, headers=owner["headers"])
+        assert response.status_code == 404
+
+    def test_view_groups_count_whole_filter(
+        self, tasks_url, task_workspace, create_task, unique_token
+    ):
+        """Счетчики групп считаются по всем задачам представления, а не только по странице"""
+        workspace = task_workspace
+        owner = workspace["members"][2]
+        token = f"{unique_token}grp"
+        created = [
+            create_task(workspace["workspace_id"], owner["headers"], title=f"View {token} {i}", status=status)
+            for i, status in enumerate([1, 1, 2])
+        ]
+
+        views_url = f"{tasks_url}/views/{workspace['workspace_id']}"
+        response = requests.post(
+            views_url,
+            json={"name": f"{token} grouped", "filter": {"q": token}, "group_by": "status"},
+            headers=owner["headers"]
+        )
+        assert response.status_code == 201
+        view = response.json()
+
+        response = requests.get(
+            f"{views_url}/{view['id']}/tasks", params={"limit": 1}, headers=owner["headers"]
+        )
+        assert response.status_code == 200
+        data = response.json()
+        assert data["total"] == len(created)
+        assert data["has_more"] is True
+        assert len(data["tasks"]) == 1
+
+        counts = {group["key"]: group["count"] for group in data["groups"]}
+        assert counts == {"1": 2, "2": 1}
+        page_ids = [task_id for group in data["groups"] for task_id in group["task_ids"]]
+        assert page_ids == [data["tasks"][0]["id"]]
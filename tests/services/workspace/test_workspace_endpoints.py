@@ -274,22 +274,56 @@ class TestWorkspaceManagement:
         )
         new_tariff_id = ct_resp.json().get("id")
 
+        # Получаем текущую версию РП
+        url = f"{workspace_service_url}{workspace_api_path}/{workspace_id}"
+        get_response = requests.get(url, headers=user_auth_headers)
+        etag = get_response.headers["ETag"]
+
         # Обновляем РП
         update_data = {
             "name": "Updated Workspace Name",
             "tariff_id": new_tariff_id
         }
-        url = f"{workspace_service_url}{workspace_api_path}/{workspace_id}"
         response = requests.put(
             url,
             json=update_data,
-            headers=user_auth_headers
+            headers={**user_auth_headers, "If-Match": etag}
         )
 
         assert response.status_code == 200
         data = response.json()
         assert data["name"] == update_data["name"]
         assert data["tariff_id"] == new_tariff_id
+        assert response.headers["ETag"] != etag
+
+    def test_update_workspace_version_conflict(
+        self, workspace_service_url, workspace_api_path, admin_auth_headers,
+        workspace_data, user_auth_headers, clean_workspace_data, tariff_id
+    ):
+        """Обновление РП без If-Match или с устаревшей версией"""
+        create_url = f"{workspace_service_url}{workspace_api_path}"
+        create_response = requests.post(
+            create_url,
+            json=workspace_data,
+            headers=admin_auth_headers
+        )
+        workspace_id = create_response.json()["id"]
+
+        url = f"{workspace_service_url}{workspace_api_path}/{workspace_id}"
+        update_data = {"name": f"Conflict Workspace {workspace_id}", "tariff_id": tariff_id}
+
+        response = requests.put(url, json=update_data, headers=user_auth_headers)
+        assert response.status_code == 428
+
+        response = requests.put(
+            url,
+            json=update_data,
+            headers={**user_auth_headers, "If-Match": '"999"'}
+        )
+        assert response.status_code == 412
+        data = response.json()
+        assert data["current"]["id"] == workspace_id
+        assert response.headers["ETag"] == f'"{data["current"]["version"]}"'
 
     def test_update_workspace_forbidden(
         self, workspace_service_url, workspace_api_path, admin_auth_headers,